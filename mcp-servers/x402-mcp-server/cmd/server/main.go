@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
 	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/webhook"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -31,22 +34,28 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize structured logger
-	logLevel := logger.INFO
-	if cfg.Logging.Level == "DEBUG" {
-		logLevel = logger.DEBUG
-	} else if cfg.Logging.Level == "WARN" {
-		logLevel = logger.WARN
-	} else if cfg.Logging.Level == "ERROR" {
-		logLevel = logger.ERROR
+	// Fail fast on secrets the enabled modes need, rather than at first use
+	if err := cfg.CheckSecrets(nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid environment: %v\n", err)
+		os.Exit(1)
 	}
 
-	log := logger.New(logLevel, os.Stderr)
+	// Initialize structured logger
+	log := logger.New(logger.ParseLevel(cfg.Logging.Level), os.Stderr)
+	log.SetRedactFields(cfg.Logging.RedactFields)
 	log.Info("Starting x402 Payment MCP Server", map[string]interface{}{
 		"version": serverVersion,
 		"config":  configPath,
 	})
 
+	if cfg.TestMode() {
+		log.Warn("==================== TEST MODE ====================", map[string]interface{}{
+			"environment":      cfg.Environment,
+			"blocked_networks": cfg.MainnetNetworks(),
+			"override":         "pass allow_mainnet: true to settle on a mainnet network",
+		})
+	}
+
 	// Create MCP server instance
 	mcpServer := server.NewMCPServer(
 		serverName,
@@ -71,6 +80,14 @@ func main() {
 		os.Exit(1)
 	}
 
+	requiredResponseTool := tools.NewCreatePaymentRequiredResponseTool(x402Server)
+	if err := x402Server.AddTool(requiredResponseTool); err != nil {
+		log.Error("Failed to add create_payment_required_response tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
 	verifyPaymentTool := tools.NewVerifyPaymentTool(x402Server)
 	if err := x402Server.AddTool(verifyPaymentTool); err != nil {
 		log.Error("Failed to add verify_payment tool", map[string]interface{}{
@@ -87,6 +104,222 @@ func main() {
 		os.Exit(1)
 	}
 
+	verifyPayloadTool := tools.NewVerifyPaymentPayloadTool(verifyPaymentTool)
+	if err := x402Server.AddTool(verifyPayloadTool); err != nil {
+		log.Error("Failed to add verify_payment_payload tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	settlePayloadTool := tools.NewSettlePaymentPayloadTool(settlePaymentTool)
+	if err := x402Server.AddTool(settlePayloadTool); err != nil {
+		log.Error("Failed to add settle_payment_payload tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	buildCalldataTool := tools.NewBuildSettlementCalldataTool(x402Server)
+	if err := x402Server.AddTool(buildCalldataTool); err != nil {
+		log.Error("Failed to add build_settlement_calldata tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	decodeRequirementTool := tools.NewDecodePaymentRequirementTool(x402Server)
+	if err := x402Server.AddTool(decodeRequirementTool); err != nil {
+		log.Error("Failed to add decode_payment_requirement tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	paymentFieldsTool := tools.NewGetPaymentFieldsTool(x402Server)
+	if err := x402Server.AddTool(paymentFieldsTool); err != nil {
+		log.Error("Failed to add get_payment_fields tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	balanceTool := tools.NewGetBalanceTool(x402Server)
+	if err := x402Server.AddTool(balanceTool); err != nil {
+		log.Error("Failed to add get_balance tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	// Throwaway-key example authorizations are only offered in the test environment
+	if cfg.TestMode() {
+		testAuthTool := tools.NewGenerateTestAuthorizationTool(x402Server)
+		if err := x402Server.AddTool(testAuthTool); err != nil {
+			log.Error("Failed to add generate_test_authorization tool", map[string]interface{}{
+				"error": err.Error(),
+			})
+			os.Exit(1)
+		}
+	}
+
+	verifyTxTool := tools.NewVerifySettlementTxTool(x402Server)
+	if err := x402Server.AddTool(verifyTxTool); err != nil {
+		log.Error("Failed to add verify_settlement_tx tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	checkNoncesTool := tools.NewCheckNonceUsageTool(x402Server)
+	if err := x402Server.AddTool(checkNoncesTool); err != nil {
+		log.Error("Failed to add check_nonce_usage tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	validateAcceptsTool := tools.NewValidateAcceptsTool(x402Server)
+	if err := x402Server.AddTool(validateAcceptsTool); err != nil {
+		log.Error("Failed to add validate_accepts tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	domainTool := tools.NewGetDomainSeparatorTool(x402Server)
+	if err := x402Server.AddTool(domainTool); err != nil {
+		log.Error("Failed to add get_domain_separator tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	estimateTool := tools.NewEstimateSettlementTimeTool(x402Server)
+	if err := x402Server.AddTool(estimateTool); err != nil {
+		log.Error("Failed to add estimate_settlement_time tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	paymentStatusTool := tools.NewGetPaymentStatusTool(x402Server)
+	if err := x402Server.AddTool(paymentStatusTool); err != nil {
+		log.Error("Failed to add get_payment_status tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	statusTool := tools.NewGetSettlementStatusTool(x402Server)
+	if err := x402Server.AddTool(statusTool); err != nil {
+		log.Error("Failed to add get_settlement_status tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	volumeTool := tools.NewGetSettledVolumeTool(x402Server)
+	if err := x402Server.AddTool(volumeTool); err != nil {
+		log.Error("Failed to add get_settled_volume tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	digestsTool := tools.NewPrepareAuthorizationDigestsTool(x402Server)
+	if err := x402Server.AddTool(digestsTool); err != nil {
+		log.Error("Failed to add prepare_authorization_digests tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	capabilitiesTool := tools.NewGetCapabilitiesTool(x402Server, serverVersion)
+	if err := x402Server.AddTool(capabilitiesTool); err != nil {
+		log.Error("Failed to add get_capabilities tool", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	// Hold tool calls until caches are warm and upstreams are reachable
+	x402Server.AddWarmupCheck("eip712_domains", func(ctx context.Context) error {
+		if err := verifyPaymentTool.Warmup(ctx); err != nil {
+			return err
+		}
+		return settlePaymentTool.Warmup(ctx)
+	})
+	for name, network := range cfg.Networks {
+		x402Server.AddWarmupCheck("rpc:"+name, func(ctx context.Context) error {
+			return rpc.CheckChainID(ctx, network.RPCURL, network.ChainID, cfg.AllowPrivateURLs)
+		})
+		if !cfg.Settlement.IsOnChain() {
+			x402Server.AddWarmupCheck("facilitator:"+name, func(ctx context.Context) error {
+				return settlePaymentTool.FacilitatorClient().Ping(ctx, name)
+			})
+		}
+	}
+	x402Server.StartWarmup()
+
+	// Periodically re-validate USDC contracts, marking networks degraded on failure
+	if cfg.ContractChecks.Enabled() {
+		x402Server.GetContractMonitor().Start()
+	}
+
+	// Re-check facilitator settlements left pending (when reconciliation is enabled)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	settlePaymentTool.Start(ctx)
+	defer settlePaymentTool.Stop()
+
+	// Expose tool, settlement, and facilitator metrics to Prometheus
+	if err := x402Server.StartMetricsListener(); err != nil {
+		log.Error("Failed to start metrics listener", map[string]interface{}{
+			"error": err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	// Accept facilitator settlement callbacks instead of relying solely on polling
+	if cfg.Webhook.Enabled() {
+		secret := os.Getenv(cfg.Webhook.SecretEnv) // Presence checked by CheckSecrets
+
+		callbackServer := webhook.NewServer(cfg.Webhook.ListenAddr, cfg.Webhook.CallbackPath(), webhook.NewHandler(
+			settlePaymentTool.FacilitatorClient(),
+			[]byte(secret),
+			x402Server.GetAuditStore(),
+			x402Server.GetMetrics(),
+			log,
+		))
+
+		go func() {
+			log.Info("Listening for settlement callbacks", map[string]interface{}{
+				"addr": cfg.Webhook.ListenAddr,
+				"path": cfg.Webhook.CallbackPath(),
+			})
+			if err := callbackServer.ListenAndServe(); err != nil {
+				log.Error("Webhook listener stopped", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}()
+	}
+
+	// Re-read config.yaml on SIGHUP; an invalid file is logged and the running config kept
+	x402Server.OnConfigReload(func(_, newCfg *config.Config) {
+		log.SetLevel(logger.ParseLevel(newCfg.Logging.Level))
+		log.SetRedactFields(newCfg.Logging.RedactFields)
+	})
+	watcher := config.NewWatcher(configPath, x402Server.ReloadConfig)
+	watcher.OnError(func(err error) {
+		log.Error("Config reload rejected", map[string]interface{}{
+			"error": err.Error(),
+		})
+	})
+	watcher.Start()
+	defer watcher.Stop()
+
 	// Register tools with MCP server
 	if err := x402Server.RegisterTools(mcpServer); err != nil {
 		log.Error("Failed to register tools", map[string]interface{}{
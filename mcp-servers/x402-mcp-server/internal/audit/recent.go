@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"sort"
+	"time"
+)
+
+// RecentSettlements returns the latest settled record per network and nonce at or after
+// since, newest first and at most limit of them (0 = all). Any event reporting a settled
+// status counts: receipts settled at submission and pending settlements later settled by
+// reconciliation or a facilitator callback alike.
+func RecentSettlements(records []Record, since time.Time, limit int) []Record {
+	type key struct{ network, nonce string }
+
+	latest := make(map[key]Record)
+	for _, record := range records {
+		if record.Status != "settled" || record.Nonce == "" || record.Timestamp.Before(since) {
+			continue
+		}
+		k := key{record.Network, record.Nonce}
+		if existing, exists := latest[k]; !exists || record.Timestamp.After(existing.Timestamp) {
+			latest[k] = record
+		}
+	}
+
+	settled := make([]Record, 0, len(latest))
+	for _, record := range latest {
+		settled = append(settled, record)
+	}
+	sort.Slice(settled, func(i, j int) bool {
+		return settled[i].Timestamp.After(settled[j].Timestamp)
+	})
+
+	if limit > 0 && len(settled) > limit {
+		settled = settled[:limit]
+	}
+	return settled
+}
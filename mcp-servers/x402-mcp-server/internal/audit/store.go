@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"sync"
+	"time"
+)
+
+// Audit event types
+const (
+	EventSettlementReconciled = "settlement_reconciled" // A pending settlement transitioned to settled/failed
+	EventSettlementCallback   = "settlement_callback"   // A facilitator webhook reported a settlement status
+	EventSettlementReceipt    = "settlement_receipt"    // settle_payment returned a settled or pending result
+)
+
+// Record is a single audit trail entry
+type Record struct {
+	Timestamp      time.Time `json:"timestamp"`
+	Event          string    `json:"event"`
+	Network        string    `json:"network"`
+	Nonce          string    `json:"nonce,omitempty"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Status         string    `json:"status,omitempty"`
+	TxHash         string    `json:"tx_hash,omitempty"`
+	Payee          string    `json:"payee,omitempty"` // Receipts only
+	Value          string    `json:"value,omitempty"` // Receipts only, USDC atomic units
+	Error          string    `json:"error,omitempty"`
+}
+
+// Store persists audit records
+type Store interface {
+	Append(record Record) error
+	Records() ([]Record, error)
+}
+
+// MemoryStore is an in-process audit store
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records []Record
+}
+
+// NewMemoryStore creates an empty in-memory audit store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make([]Record, 0),
+	}
+}
+
+// Append adds a record, stamping it with the current time if unset
+func (m *MemoryStore) Append(record Record) error {
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.records = append(m.records, record)
+	return nil
+}
+
+// Records returns a copy of all records in append order
+func (m *MemoryStore) Records() ([]Record, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]Record(nil), m.records...), nil
+}
@@ -0,0 +1,74 @@
+package audit
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PayeeVolume is the USDC settled to one payee
+type PayeeVolume struct {
+	Payee       string   // EIP-55 checksummed address
+	Total       *big.Int // USDC atomic units
+	Settlements int
+}
+
+// SettledVolume aggregates receipts into per-payee totals of settlements that became settled
+// within [since, until). A settlement (matched by network and nonce) counts once, at the first
+// record reporting it settled: the receipt itself, or a later reconciliation or callback for a
+// receipt recorded as pending. Results are sorted by descending total, then payee.
+func SettledVolume(records []Record, since, until time.Time) []PayeeVolume {
+	receipts := make(map[string]Record)
+	settledAt := make(map[string]time.Time)
+	for _, record := range records {
+		key := record.Network + "/" + strings.ToLower(record.Nonce)
+		if record.Event == EventSettlementReceipt {
+			if _, exists := receipts[key]; !exists {
+				receipts[key] = record
+			}
+		}
+		if record.Status == "settled" {
+			if at, exists := settledAt[key]; !exists || record.Timestamp.Before(at) {
+				settledAt[key] = record.Timestamp
+			}
+		}
+	}
+
+	volumes := make(map[string]*PayeeVolume)
+	for key, receipt := range receipts {
+		at, settled := settledAt[key]
+		if !settled || at.Before(since) || !at.Before(until) {
+			continue
+		}
+
+		value, ok := new(big.Int).SetString(receipt.Value, 10)
+		if !ok {
+			continue
+		}
+
+		payee := common.HexToAddress(receipt.Payee).Hex()
+		volume, exists := volumes[payee]
+		if !exists {
+			volume = &PayeeVolume{Payee: payee, Total: new(big.Int)}
+			volumes[payee] = volume
+		}
+		volume.Total.Add(volume.Total, value)
+		volume.Settlements++
+	}
+
+	result := make([]PayeeVolume, 0, len(volumes))
+	for _, volume := range volumes {
+		result = append(result, *volume)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if cmp := result[i].Total.Cmp(result[j].Total); cmp != 0 {
+			return cmp > 0
+		}
+		return result[i].Payee < result[j].Payee
+	})
+
+	return result
+}
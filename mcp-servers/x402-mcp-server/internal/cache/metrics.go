@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+)
+
+// MetricEvictionAge is the histogram of entry age (seconds) when entries leave a cache
+// Ages far below the TTL mean entries are invalidated before they pay off; ages far above
+// it mean the sweep interval, not the TTL, bounds how long stale entries linger.
+const MetricEvictionAge = "x402_cache_eviction_age_seconds"
+
+// EvictionAgeBuckets are the histogram upper bounds (seconds) for MetricEvictionAge
+var EvictionAgeBuckets = []float64{1, 5, 15, 30, 60, 120, 300, 600, 900, 1800, 3600}
+
+// EvictionRecorder returns a hook recording eviction ages under the given cache label
+func EvictionRecorder(registry *metrics.Registry, cacheName string) EvictionHook {
+	return func(age time.Duration, reason string) {
+		registry.ObserveHistogram(MetricEvictionAge, EvictionAgeBuckets, metrics.Labels{
+			"cache":  cacheName,
+			"reason": reason,
+		}, age.Seconds())
+	}
+}
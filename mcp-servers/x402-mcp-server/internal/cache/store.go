@@ -0,0 +1,46 @@
+package cache
+
+import "time"
+
+// Store is a pluggable key-value store with per-entry expiry
+// Values are opaque bytes so an implementation can be shared by several server replicas
+// (e.g. one backed by Redis); MemoryStore is the in-process default.
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	DeletePrefix(prefix string) int
+}
+
+// MemoryStore is an in-process Store backed by a TTLCache
+type MemoryStore struct {
+	entries *TTLCache
+}
+
+// NewMemoryStore creates an in-process store whose entries default to ttl
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{entries: NewTTLCache(ttl)}
+}
+
+// OnEvict installs a hook observing the age of every entry removed from the store
+func (m *MemoryStore) OnEvict(hook EvictionHook) {
+	m.entries.OnEvict(hook)
+}
+
+// Get retrieves a value; returns (nil, false) when missing or expired
+func (m *MemoryStore) Get(key string) ([]byte, bool) {
+	value, found := m.entries.Get(key)
+	if !found {
+		return nil, false
+	}
+	return value.([]byte), true
+}
+
+// Set stores a value for ttl
+func (m *MemoryStore) Set(key string, value []byte, ttl time.Duration) {
+	m.entries.SetWithTTL(key, value, ttl)
+}
+
+// DeletePrefix removes all entries whose key starts with prefix
+func (m *MemoryStore) DeletePrefix(prefix string) int {
+	return m.entries.DeletePrefix(prefix)
+}
@@ -1,36 +1,117 @@
 package cache
 
 import (
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Entry represents a cached item with expiration
 type Entry struct {
 	Value     interface{}
+	CreatedAt time.Time
 	ExpiresAt time.Time
 }
 
+// Eviction reasons reported to an EvictionHook
+const (
+	EvictionExpired = "expired" // Removed by the background sweep after its TTL
+	EvictionDeleted = "deleted" // Removed explicitly (Delete, DeletePrefix, Clear)
+)
+
+// EvictionHook is called with an entry's age when it leaves the cache
+// Hooks run after the cache lock is released and may not block for long.
+type EvictionHook func(age time.Duration, reason string)
+
+// Stats is a point-in-time view of a cache's effectiveness
+type Stats struct {
+	Hits      uint64 // Lookups that found a live entry
+	Misses    uint64 // Lookups that found no entry or an expired one
+	Evictions uint64 // Expired entries removed by the sweep
+	Size      int    // Entries currently held (including expired, not yet swept)
+}
+
+// ToMap converts the stats to a map for logging
+func (s Stats) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"hits":      s.Hits,
+		"misses":    s.Misses,
+		"evictions": s.Evictions,
+		"size":      s.Size,
+	}
+}
+
 // TTLCache is a thread-safe in-memory cache with time-to-live expiration
 type TTLCache struct {
 	mu      sync.RWMutex
 	entries map[string]Entry
 	ttl     time.Duration
+	now     func() time.Time
+	onEvict EvictionHook
+
+	// Counters are atomic so Get records hits and misses under the shared read lock
+	// rather than taking the write lock, keeping concurrent reads from serializing
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+
+	closeOnce sync.Once
+	stop      chan struct{} // Closed by Close to end the sweeper
+	done      chan struct{} // Closed when the sweeper has exited (nil = no sweeper)
 }
 
-// NewTTLCache creates a new TTL cache with the specified default TTL
+// NewTTLCache creates a new TTL cache with the specified default TTL, swept every TTL/2
 func NewTTLCache(ttl time.Duration) *TTLCache {
+	return NewTTLCacheWithSweep(ttl, ttl/2)
+}
+
+// NewTTLCacheWithSweep creates a TTL cache whose expired entries are removed by a
+// background sweeper every interval (<= 0 = no sweeper; expired entries are then only
+// hidden by Get until RemoveExpired is called). Close stops the sweeper.
+func NewTTLCacheWithSweep(ttl, interval time.Duration) *TTLCache {
 	cache := &TTLCache{
 		entries: make(map[string]Entry),
 		ttl:     ttl,
+		now:     time.Now,
+		stop:    make(chan struct{}),
 	}
 
-	// Start background cleanup goroutine
-	go cache.cleanup()
+	if interval > 0 {
+		cache.done = make(chan struct{})
+		go cache.sweep(interval)
+	}
 
 	return cache
 }
 
+// Close stops the background sweeper, if any, and waits for it to exit
+// The cache remains usable; expired entries are just no longer swept.
+func (c *TTLCache) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stop)
+	})
+	if c.done != nil {
+		<-c.done
+	}
+}
+
+// OnEvict installs a hook observing the age of every entry removed from the cache
+func (c *TTLCache) OnEvict(hook EvictionHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onEvict = hook
+}
+
+// SetClock replaces the time source, letting tests control entry ages
+func (c *TTLCache) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
 // Set stores a value with the default TTL
 func (c *TTLCache) Set(key string, value interface{}) {
 	c.SetWithTTL(key, value, c.ttl)
@@ -41,9 +122,11 @@ func (c *TTLCache) SetWithTTL(key string, value interface{}, ttl time.Duration)
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	now := c.now()
 	c.entries[key] = Entry{
 		Value:     value,
-		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
 	}
 }
 
@@ -55,31 +138,66 @@ func (c *TTLCache) Get(key string) (interface{}, bool) {
 
 	entry, exists := c.entries[key]
 	if !exists {
+		c.misses.Add(1)
 		return nil, false
 	}
 
 	// Check if expired
-	if time.Now().After(entry.ExpiresAt) {
+	if c.now().After(entry.ExpiresAt) {
+		c.misses.Add(1)
 		return nil, false
 	}
 
+	c.hits.Add(1)
 	return entry.Value, true
 }
 
 // Delete removes an entry from the cache
 func (c *TTLCache) Delete(key string) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
+	entry, exists := c.entries[key]
 	delete(c.entries, key)
+	age := c.now().Sub(entry.CreatedAt)
+	hook := c.onEvict
+	c.mu.Unlock()
+
+	if exists && hook != nil {
+		hook(age, EvictionDeleted)
+	}
+}
+
+// DeletePrefix removes all entries whose key starts with prefix
+// Returns the number of entries removed
+func (c *TTLCache) DeletePrefix(prefix string) int {
+	c.mu.Lock()
+	now := c.now()
+	ages := make([]time.Duration, 0)
+	for key, entry := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+			ages = append(ages, now.Sub(entry.CreatedAt))
+		}
+	}
+	hook := c.onEvict
+	c.mu.Unlock()
+
+	notify(hook, ages, EvictionDeleted)
+	return len(ages)
 }
 
 // Clear removes all entries from the cache
 func (c *TTLCache) Clear() {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
+	now := c.now()
+	ages := make([]time.Duration, 0, len(c.entries))
+	for _, entry := range c.entries {
+		ages = append(ages, now.Sub(entry.CreatedAt))
+	}
 	c.entries = make(map[string]Entry)
+	hook := c.onEvict
+	c.mu.Unlock()
+
+	notify(hook, ages, EvictionDeleted)
 }
 
 // Size returns the number of entries in the cache (including expired)
@@ -90,25 +208,74 @@ func (c *TTLCache) Size() int {
 	return len(c.entries)
 }
 
-// cleanup periodically removes expired entries
-func (c *TTLCache) cleanup() {
-	ticker := time.NewTicker(c.ttl / 2)
+// Hits returns the number of Get calls that found a live entry
+func (c *TTLCache) Hits() uint64 {
+	return c.hits.Load()
+}
+
+// Misses returns the number of Get calls that found no live entry
+func (c *TTLCache) Misses() uint64 {
+	return c.misses.Load()
+}
+
+// Evictions returns the number of expired entries removed by sweeps
+func (c *TTLCache) Evictions() uint64 {
+	return c.evictions.Load()
+}
+
+// Stats returns the hit, miss, and eviction counts with the current size
+func (c *TTLCache) Stats() Stats {
+	return Stats{
+		Hits:      c.Hits(),
+		Misses:    c.Misses(),
+		Evictions: c.Evictions(),
+		Size:      c.Size(),
+	}
+}
+
+// sweep removes expired entries every interval until Close is called
+func (c *TTLCache) sweep(interval time.Duration) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		c.removeExpired()
+	for {
+		select {
+		case <-ticker.C:
+			c.RemoveExpired()
+		case <-c.stop:
+			return
+		}
 	}
 }
 
-// removeExpired deletes all expired entries
-func (c *TTLCache) removeExpired() {
+// RemoveExpired deletes all expired entries, returning how many were removed
+// The background sweeper calls this every interval; callers may also sweep on demand.
+func (c *TTLCache) RemoveExpired() int {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	now := time.Now()
+	now := c.now()
+	ages := make([]time.Duration, 0)
 	for key, entry := range c.entries {
 		if now.After(entry.ExpiresAt) {
 			delete(c.entries, key)
+			ages = append(ages, now.Sub(entry.CreatedAt))
 		}
 	}
+	hook := c.onEvict
+	c.mu.Unlock()
+
+	c.evictions.Add(uint64(len(ages)))
+	notify(hook, ages, EvictionExpired)
+	return len(ages)
+}
+
+// notify reports evicted entry ages to the hook, if installed
+func notify(hook EvictionHook, ages []time.Duration, reason string) {
+	if hook == nil {
+		return
+	}
+	for _, age := range ages {
+		hook(age, reason)
+	}
 }
@@ -0,0 +1,50 @@
+package canonical
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// JSON encodes v as canonical JSON: object keys sorted, no insignificant whitespace,
+// and no HTML escaping. Structs are normalized through their JSON form, so two values
+// with the same fields in a different order produce identical bytes.
+func JSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	// Decode into generic maps so struct field order is replaced by sorted keys
+	// UseNumber keeps numeric literals exact instead of round-tripping through float64
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to normalize value: %w", err)
+	}
+
+	// encoding/json writes map keys in sorted order
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(generic); err != nil {
+		return nil, fmt.Errorf("failed to encode canonical JSON: %w", err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Hash returns the Keccak-256 hash of v's canonical JSON encoding
+func Hash(v interface{}) (common.Hash, error) {
+	data, err := JSON(v)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return crypto.Keccak256Hash(data), nil
+}
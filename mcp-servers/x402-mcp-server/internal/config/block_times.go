@@ -0,0 +1,33 @@
+package config
+
+import "time"
+
+// knownBlockTimes maps chain ID to its typical block interval, used for timing estimates
+var knownBlockTimes = map[uint64]time.Duration{
+	1:        12 * time.Second,       // Ethereum
+	10:       2 * time.Second,        // Optimism
+	137:      2 * time.Second,        // Polygon PoS
+	8453:     2 * time.Second,        // Base
+	42161:    250 * time.Millisecond, // Arbitrum One
+	43114:    2 * time.Second,        // Avalanche C-Chain
+	43113:    2 * time.Second,        // Avalanche Fuji
+	80002:    2 * time.Second,        // Polygon Amoy
+	84532:    2 * time.Second,        // Base Sepolia
+	421614:   250 * time.Millisecond, // Arbitrum Sepolia
+	11155111: 12 * time.Second,       // Ethereum Sepolia
+}
+
+// DefaultBlockTime is assumed for chains missing from the built-in table
+const DefaultBlockTime = 12 * time.Second
+
+// BlockTime returns the network's average block time: block_time_seconds when set,
+// else the built-in value for its chain ID, else DefaultBlockTime
+func (n *NetworkConfig) BlockTime() time.Duration {
+	if n.BlockTimeSeconds > 0 {
+		return time.Duration(n.BlockTimeSeconds * float64(time.Second))
+	}
+	if blockTime, known := knownBlockTimes[n.ChainID]; known {
+		return blockTime
+	}
+	return DefaultBlockTime
+}
@@ -1,35 +1,734 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/netguard"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
 	"gopkg.in/yaml.v3"
 )
 
 // Config represents the complete MCP server configuration
 type Config struct {
-	Networks map[string]NetworkConfig `yaml:"networks"`
-	EIP712   EIP712Config             `yaml:"eip712"`
-	Logging  LoggingConfig            `yaml:"logging"`
-	Cache    CacheConfig              `yaml:"cache"`
+	Networks       map[string]NetworkConfig `yaml:"networks"`
+	EIP712         EIP712Config             `yaml:"eip712"`
+	Logging        LoggingConfig            `yaml:"logging"`
+	Cache          CacheConfig              `yaml:"cache"`
+	Settlement     SettlementConfig         `yaml:"settlement"`
+	Verification   VerificationConfig       `yaml:"verification"`
+	Tools          ToolsConfig              `yaml:"tools"`
+	Reconciliation ReconciliationConfig     `yaml:"reconciliation"`
+	Readiness      ReadinessConfig          `yaml:"readiness"`
+	Webhook        WebhookConfig            `yaml:"webhook"`
+	Metrics        MetricsConfig            `yaml:"metrics"`
+	Tracing        TracingConfig            `yaml:"tracing"`
+	Display        DisplayConfig            `yaml:"display"`
+	Retry          RetryConfig              `yaml:"retry"`
+	Redirects      RedirectConfig           `yaml:"redirects"`
+	Events         EventsConfig             `yaml:"events"`
+	Estimates      EstimatesConfig          `yaml:"estimates"`
+	Limits         LimitsConfig             `yaml:"limits"`
+	RPC            RPCConfig                `yaml:"rpc"`
+	Requirements   RequirementsConfig       `yaml:"requirements"`
+	Pricing        PricingConfig            `yaml:"pricing"`
+	ContractChecks ContractChecksConfig     `yaml:"contract_checks"`
+	Audit          AuditConfig              `yaml:"audit"`
+	DeadLetter     DeadLetterConfig         `yaml:"dead_letter"`
+	Refunds        RefundsConfig            `yaml:"refunds"`
+
+	AllowPrivateURLs bool   `yaml:"allow_private_urls"` // Permit loopback/private facilitator and RPC URLs (local dev only)
+	Environment      string `yaml:"environment"`        // production (default) | test: refuse mainnet settlement unless allow_mainnet is passed
 }
 
 // EIP712Config contains EIP-712 domain parameters
 type EIP712Config struct {
-	DomainName    string `yaml:"domain_name"`    // "USD Coin"
-	DomainVersion string `yaml:"domain_version"` // "2"
+	DomainName    string `yaml:"domain_name"`    // "USD Coin" (empty = built-in per-chain USDC domain name)
+	DomainVersion string `yaml:"domain_version"` // "2" (empty = built-in per-chain USDC domain version)
 }
 
 // LoggingConfig defines logging behavior
 type LoggingConfig struct {
-	Level  string `yaml:"level"`  // DEBUG, INFO, WARN, ERROR
-	Format string `yaml:"format"` // json
+	Level        string   `yaml:"level"`         // DEBUG, INFO, WARN, ERROR
+	Format       string   `yaml:"format"`        // json
+	RedactFields []string `yaml:"redact_fields"` // Log field keys truncated to a 0x1234… prefix, e.g. r, s, nonce
 }
 
 // CacheConfig defines cache behavior for settlement idempotency
 type CacheConfig struct {
-	SettlementTTLMinutes int `yaml:"settlement_ttl_minutes"` // 10
+	SettlementTTLMinutes int `yaml:"settlement_ttl_minutes"` // 10 - settled results (final, reused for idempotency)
+	PendingTTLSeconds    int `yaml:"pending_ttl_seconds"`    // Pending/failed results (0 = not cached, always refreshed)
+	GraceMs              int `yaml:"grace_ms"`               // Serve just-expired results this long while one background refresh runs (0 = disabled)
+	SweepIntervalSeconds int `yaml:"sweep_interval_seconds"` // Remove expired settlement results in the background this often instead of on every write (0 = on write)
+}
+
+// SettledTTL returns how long settled results are reused
+func (c *CacheConfig) SettledTTL() time.Duration {
+	return time.Duration(c.SettlementTTLMinutes) * time.Minute
+}
+
+// PendingTTL returns how long pending and failed results are reused (0 = not cached)
+func (c *CacheConfig) PendingTTL() time.Duration {
+	return time.Duration(c.PendingTTLSeconds) * time.Second
+}
+
+// SweepInterval returns how often expired settlement results are swept in the background (0 = on write)
+func (c *CacheConfig) SweepInterval() time.Duration {
+	return time.Duration(c.SweepIntervalSeconds) * time.Second
+}
+
+// Grace returns how long an expired result is still served while it is refreshed (0 = disabled)
+func (c *CacheConfig) Grace() time.Duration {
+	return time.Duration(c.GraceMs) * time.Millisecond
+}
+
+// VerificationConfig defines additional acceptance rules for payment authorizations
+type VerificationConfig struct {
+	MaxAuthorizationAgeSeconds int64 `yaml:"max_authorization_age_seconds"` // Reject if now - validAfter exceeds this (0 = disabled); rejects any zero validAfter
+	RequireChecksum            bool  `yaml:"require_checksum"`              // Reject mixed-case addresses with a bad EIP-55 checksum
+
+	Profile string `yaml:"profile"` // lenient (default) | strict: reject zero validAfter/validBefore and zero nonces
+
+	AddressFormat string `yaml:"address_format"` // hex (default) | caip10 for signer_address/from/to in results
+	Overpayment   string `yaml:"overpayment"`    // reject (default) | accept | accept_and_refund_excess when value exceeds expected_value_human
+	RValueReuse   string `yaml:"r_value_reuse"`  // off (default) | alert | block when a signer reuses an ECDSA r value across messages
+	EIP155V       string `yaml:"eip155_v"`       // reject (default) | accept | match_chain for v encoded as chainId*2 + 35/36
+
+	WeakNonce                 string `yaml:"weak_nonce"`                    // off (default) | warn | reject for all-zero, sequential, or low-entropy nonces
+	WeakNonceMinDistinctBytes int    `yaml:"weak_nonce_min_distinct_bytes"` // Fewer distinct byte values than this is weak (0 = 12)
+
+	AcceptedSchemes []string `yaml:"accepted_schemes"` // x402 schemes accepted in payment payloads (empty = exact)
+
+	EIP1271 bool `yaml:"eip1271"` // Verify payers with contract code (smart contract wallets) via isValidSignature over the network's RPC
+
+	PayeeAllowlist []string `yaml:"payee_allowlist"` // Contract payees (to) accepted without policy action; EOA payees are unaffected (empty = disabled)
+	PayeePolicy    string   `yaml:"payee_policy"`    // reject (default) | warn for contract payees missing from payee_allowlist
+
+	Multisig []MultisigWallet `yaml:"multisig"` // Payer wallets verified by M-of-N owner signatures
+}
+
+// AcceptsScheme reports whether payment payloads using scheme are accepted
+func (v *VerificationConfig) AcceptsScheme(scheme string) bool {
+	if len(v.AcceptedSchemes) == 0 {
+		return scheme == x402.SchemeExact
+	}
+	for _, accepted := range v.AcceptedSchemes {
+		if scheme == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// Address formats for verification results
+const (
+	AddressFormatHex    = "hex"    // 0x-prefixed address (default)
+	AddressFormatCAIP10 = "caip10" // eip155:<chainId>:0x... account ID
+)
+
+// ValidAddressFormat reports whether format is a supported address format ("" means hex)
+func ValidAddressFormat(format string) bool {
+	return format == "" || format == AddressFormatHex || format == AddressFormatCAIP10
+}
+
+// Overpayment policies for authorizations worth more than the expected amount
+const (
+	OverpaymentReject                = "reject"                   // Fail with amount_mismatch (default)
+	OverpaymentAccept                = "accept"                   // Settle the full authorized value
+	OverpaymentAcceptAndRefundExcess = "accept_and_refund_excess" // Settle, then queue the excess as a refund due to the payer (needs refunds.path)
+)
+
+// ValidOverpayment reports whether policy is a supported overpayment policy ("" means reject)
+func ValidOverpayment(policy string) bool {
+	return policy == "" || policy == OverpaymentReject || policy == OverpaymentAccept || policy == OverpaymentAcceptAndRefundExcess
+}
+
+// AcceptsOverpayment reports whether authorizations above the expected amount are accepted
+func (v *VerificationConfig) AcceptsOverpayment() bool {
+	return v.Overpayment == OverpaymentAccept || v.Overpayment == OverpaymentAcceptAndRefundExcess
+}
+
+// Signature r value reuse monitoring modes
+// Two signatures from one key sharing r (a repeated ECDSA nonce) expose that key.
+const (
+	RValueReuseOff   = "off"   // Not monitored (default)
+	RValueReuseAlert = "alert" // Log a CRITICAL alert; the signature is still accepted
+	RValueReuseBlock = "block" // Alert and reject the signature
+)
+
+// ValidRValueReuse reports whether mode is a supported r value reuse mode ("" means off)
+func ValidRValueReuse(mode string) bool {
+	return mode == "" || mode == RValueReuseOff || mode == RValueReuseAlert || mode == RValueReuseBlock
+}
+
+// MonitorsRValues reports whether signature r values are recorded for reuse detection
+func (v *VerificationConfig) MonitorsRValues() bool {
+	return v.RValueReuse == RValueReuseAlert || v.RValueReuse == RValueReuseBlock
+}
+
+// Policies for EIP-155 encoded signature v values (chainId*2 + 35/36)
+const (
+	EIP155VReject     = "reject"      // Only 27/28 are accepted (default)
+	EIP155VAccept     = "accept"      // Derive the recovery id and ignore the embedded chain ID
+	EIP155VMatchChain = "match_chain" // Accept only when the embedded chain ID is the network's
+)
+
+// ValidEIP155V reports whether policy is a supported EIP-155 v policy ("" means reject)
+func ValidEIP155V(policy string) bool {
+	return policy == "" || policy == EIP155VReject || policy == EIP155VAccept || policy == EIP155VMatchChain
+}
+
+// Verification profiles for authorization fields that are optional on-chain
+const (
+	ProfileLenient = "lenient" // Accept zero validAfter and other on-chain defaults (default)
+	ProfileStrict  = "strict"  // Require explicit non-zero validAfter/validBefore and a non-zero nonce
+)
+
+// ValidProfile reports whether profile is a supported verification profile ("" means lenient)
+func ValidProfile(profile string) bool {
+	return profile == "" || profile == ProfileLenient || profile == ProfileStrict
+}
+
+// Strict reports whether authorizations are checked against the strict profile
+func (v *VerificationConfig) Strict() bool {
+	return v.Profile == ProfileStrict
+}
+
+// Weak nonce modes for predictable authorization nonces
+const (
+	WeakNonceOff    = "off"    // No check (default)
+	WeakNonceWarn   = "warn"   // Log a warning and flag verify results; verification is unaffected
+	WeakNonceReject = "reject" // Fail verification with weak_nonce
+)
+
+// DefaultWeakNonceMinDistinctBytes is the distinct byte threshold when unset
+// A uniformly random 32-byte nonce has about 30 distinct values; fewer than 12 is vanishingly unlikely.
+const DefaultWeakNonceMinDistinctBytes = 12
+
+// ValidWeakNonce reports whether mode is a supported weak nonce mode ("" means off)
+func ValidWeakNonce(mode string) bool {
+	return mode == "" || mode == WeakNonceOff || mode == WeakNonceWarn || mode == WeakNonceReject
+}
+
+// ChecksWeakNonces reports whether nonces are checked for predictability
+func (v *VerificationConfig) ChecksWeakNonces() bool {
+	return v.WeakNonce == WeakNonceWarn || v.WeakNonce == WeakNonceReject
+}
+
+// WeakNonceThreshold returns the minimum distinct byte values of an acceptable nonce
+func (v *VerificationConfig) WeakNonceThreshold() int {
+	if v.WeakNonceMinDistinctBytes <= 0 {
+		return DefaultWeakNonceMinDistinctBytes
+	}
+	return v.WeakNonceMinDistinctBytes
+}
+
+// Policies for contract payees missing from verification.payee_allowlist
+const (
+	PayeePolicyReject = "reject" // Fail verification and settlement with payee_not_allowlisted (default)
+	PayeePolicyWarn   = "warn"   // Log a warning and flag verify results; the payment is accepted
+)
+
+// ValidPayeePolicy reports whether policy is a supported payee policy ("" means reject)
+func ValidPayeePolicy(policy string) bool {
+	return policy == "" || policy == PayeePolicyReject || policy == PayeePolicyWarn
+}
+
+// ChecksPayeeAllowlist reports whether contract payees are checked against payee_allowlist
+func (v *VerificationConfig) ChecksPayeeAllowlist() bool {
+	return len(v.PayeeAllowlist) > 0
+}
+
+// AllowlistsPayee reports whether address is on payee_allowlist (case-insensitive)
+func (v *VerificationConfig) AllowlistsPayee(address string) bool {
+	for _, allowed := range v.PayeeAllowlist {
+		if strings.EqualFold(allowed, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayConfig defines how human-readable amounts are rendered in tool results
+type DisplayConfig struct {
+	AmountFormat string `yaml:"amount_format"` // plain (default, "1000.5") | grouped ("1,000.5"); atomic fields are unaffected
+	ByteEncoding string `yaml:"byte_encoding"` // hex (default) | base64 for authorization nonces and signature r/s in results
+}
+
+// Amount formats for human-readable amounts
+const (
+	AmountFormatPlain   = "plain"   // Machine-friendly digits (default)
+	AmountFormatGrouped = "grouped" // Thousands separated by commas
+)
+
+// Encodings for 32-byte nonces and signature components in tool results
+// Inputs accept either encoding regardless of this setting.
+const (
+	ByteEncodingHex    = "hex"    // 0x-prefixed hex (default)
+	ByteEncodingBase64 = "base64" // Standard padded base64 (RFC 4648)
+)
+
+// ValidByteEncoding reports whether encoding is a supported byte encoding ("" means hex)
+func ValidByteEncoding(encoding string) bool {
+	return encoding == "" || encoding == ByteEncodingHex || encoding == ByteEncodingBase64
+}
+
+// ValidAmountFormat reports whether format is a supported amount format ("" means plain)
+func ValidAmountFormat(format string) bool {
+	return format == "" || format == AmountFormatPlain || format == AmountFormatGrouped
+}
+
+// MultisigWallet is an M-of-N signing policy for a payer address
+type MultisigWallet struct {
+	Wallet    string   `yaml:"wallet"`    // Payer (authorization "from") address
+	Threshold int      `yaml:"threshold"` // Distinct owner signatures required
+	Owners    []string `yaml:"owners"`    // Authorized signer addresses
+}
+
+// MultisigPolicy returns the multisig policy for a wallet address (case-insensitive)
+func (v *VerificationConfig) MultisigPolicy(wallet string) (*MultisigWallet, bool) {
+	for i := range v.Multisig {
+		if strings.EqualFold(v.Multisig[i].Wallet, wallet) {
+			return &v.Multisig[i], true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks the wallet, owners, and threshold
+func (m *MultisigWallet) Validate() error {
+	if !addressPattern.MatchString(m.Wallet) {
+		return fmt.Errorf("wallet must be valid Ethereum address (0x + 40 hex chars)")
+	}
+
+	seen := make(map[string]bool)
+	for _, owner := range m.Owners {
+		if !addressPattern.MatchString(owner) {
+			return fmt.Errorf("owner %s must be valid Ethereum address", owner)
+		}
+		if seen[strings.ToLower(owner)] {
+			return fmt.Errorf("duplicate owner %s", owner)
+		}
+		seen[strings.ToLower(owner)] = true
+	}
+
+	if m.Threshold < 1 || m.Threshold > len(m.Owners) {
+		return fmt.Errorf("threshold must be between 1 and %d owners, got %d", len(m.Owners), m.Threshold)
+	}
+
+	return nil
+}
+
+// ReconciliationConfig defines the background re-check of pending settlements
+type ReconciliationConfig struct {
+	IntervalSeconds         int `yaml:"interval_seconds"`           // Seconds between passes (0 = disabled)
+	BatchSize               int `yaml:"batch_size"`                 // Maximum pending settlements checked per pass, oldest first (0 = all)
+	UnattestedMaxAgeSeconds int `yaml:"unattested_max_age_seconds"` // Fail settlements still unattested after N seconds pending (0 = 3600)
+}
+
+// DefaultUnattestedMaxAge is how long an unattested settlement stays pending when unset
+const DefaultUnattestedMaxAge = time.Hour
+
+// Enabled reports whether pending settlement reconciliation should run
+func (r *ReconciliationConfig) Enabled() bool {
+	return r.IntervalSeconds > 0
+}
+
+// UnattestedMaxAge returns how long a settlement pending only for a missing or invalid
+// attestation is re-checked before reconciliation fails it
+func (r *ReconciliationConfig) UnattestedMaxAge() time.Duration {
+	if r.UnattestedMaxAgeSeconds == 0 {
+		return DefaultUnattestedMaxAge
+	}
+	return time.Duration(r.UnattestedMaxAgeSeconds) * time.Second
+}
+
+// ContractChecksConfig defines the periodic health check of configured USDC contracts
+type ContractChecksConfig struct {
+	IntervalSeconds int `yaml:"interval_seconds"` // Seconds between name()/decimals() checks (0 = disabled)
+}
+
+// Enabled reports whether USDC contracts should be checked periodically
+func (c *ContractChecksConfig) Enabled() bool {
+	return c.IntervalSeconds > 0
+}
+
+// AuditConfig defines what the audit trail records beyond settlement status transitions
+type AuditConfig struct {
+	RecordReceipts bool   `yaml:"record_receipts"` // Record payee and value of settle_payment results for volume reporting
+	Path           string `yaml:"path"`            // JSON-lines file keeping the audit trail across restarts (empty = in memory)
+
+	WarmCacheMinutes    int `yaml:"warm_cache_minutes"`     // On startup, seed the settlement idempotency cache with settlements recorded in the last N minutes (0 = disabled)
+	WarmCacheMaxEntries int `yaml:"warm_cache_max_entries"` // Most recent settlements seeded (0 = 1000)
+}
+
+// DefaultWarmCacheMaxEntries bounds settlement cache warming when unset
+const DefaultWarmCacheMaxEntries = 1000
+
+// WarmsCache reports whether the settlement cache is seeded from the audit trail on startup
+func (a *AuditConfig) WarmsCache() bool {
+	return a.WarmCacheMinutes > 0
+}
+
+// WarmCacheWindow returns how far back settlements are seeded into the cache
+func (a *AuditConfig) WarmCacheWindow() time.Duration {
+	return time.Duration(a.WarmCacheMinutes) * time.Minute
+}
+
+// WarmCacheLimit returns the maximum number of settlements seeded into the cache
+func (a *AuditConfig) WarmCacheLimit() int {
+	if a.WarmCacheMaxEntries <= 0 {
+		return DefaultWarmCacheMaxEntries
+	}
+	return a.WarmCacheMaxEntries
+}
+
+// DeadLetterConfig defines where permanently failed settlements are kept for inspection or replay
+type DeadLetterConfig struct {
+	Path string `yaml:"path"` // JSON-lines file of failed authorizations and reasons (empty = disabled)
+}
+
+// Enabled reports whether permanently failed settlements are dead-lettered
+func (d *DeadLetterConfig) Enabled() bool {
+	return d.Path != ""
+}
+
+// RefundsConfig defines where refunds due on accepted overpayments are queued for the operator
+type RefundsConfig struct {
+	Path string `yaml:"path"` // JSON-lines file of refunds owed to payers (empty = disabled)
+}
+
+// Enabled reports whether refunds due are queued
+func (r *RefundsConfig) Enabled() bool {
+	return r.Path != ""
+}
+
+// ReadinessConfig defines the startup warmup gate
+type ReadinessConfig struct {
+	WarmupTimeoutSeconds int `yaml:"warmup_timeout_seconds"` // Max warmup before serving degraded (0 = 30)
+}
+
+// DefaultWarmupTimeout bounds startup warmup when unset
+const DefaultWarmupTimeout = 30 * time.Second
+
+// WarmupTimeout returns how long tool calls are held back while warming up
+func (r *ReadinessConfig) WarmupTimeout() time.Duration {
+	if r.WarmupTimeoutSeconds <= 0 {
+		return DefaultWarmupTimeout
+	}
+	return time.Duration(r.WarmupTimeoutSeconds) * time.Second
+}
+
+// WebhookConfig defines the HTTP listener for facilitator settlement callbacks
+type WebhookConfig struct {
+	ListenAddr string `yaml:"listen_addr"` // e.g. ":8402" (empty = disabled)
+	Path       string `yaml:"path"`        // Callback path (default /x402/settlement-callback)
+	SecretEnv  string `yaml:"secret_env"`  // Env var holding the shared HMAC secret
+}
+
+// DefaultWebhookPath is the callback path used when webhook.path is unset
+const DefaultWebhookPath = "/x402/settlement-callback"
+
+// Enabled reports whether the webhook listener should be started
+func (w *WebhookConfig) Enabled() bool {
+	return w.ListenAddr != ""
+}
+
+// CallbackPath returns the configured callback path or the default
+func (w *WebhookConfig) CallbackPath() string {
+	if w.Path == "" {
+		return DefaultWebhookPath
+	}
+	return w.Path
+}
+
+// MetricsConfig defines the HTTP listener exposing metrics to Prometheus
+type MetricsConfig struct {
+	Enabled bool `yaml:"enabled"` // Serve GET /metrics in the Prometheus text or OpenMetrics format
+	Port    int  `yaml:"port"`    // Listen port (0 = 9402)
+}
+
+// TracingConfig controls how W3C trace context sent by MCP clients is used
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"` // Read the traceparent in tools/call _meta; with metrics enabled, tool latency samples carry its trace ID as an exemplar
+}
+
+// ExemplarsEnabled reports whether latency observations carry trace ID exemplars,
+// which needs both tracing and metrics enabled
+func (c *Config) ExemplarsEnabled() bool {
+	return c.Tracing.Enabled && c.Metrics.Enabled
+}
+
+// DefaultMetricsPort is the metrics listen port used when metrics.port is unset
+const DefaultMetricsPort = 9402
+
+// MetricsPath is the path the metrics listener serves
+const MetricsPath = "/metrics"
+
+// ListenAddr returns the address the metrics listener binds
+func (m *MetricsConfig) ListenAddr() string {
+	port := m.Port
+	if port == 0 {
+		port = DefaultMetricsPort
+	}
+	return fmt.Sprintf(":%d", port)
+}
+
+// ToolsConfig selects which MCP tools are exposed
+type ToolsConfig struct {
+	Enabled     []string `yaml:"enabled"`      // If set, only these tools are exposed
+	Disabled    []string `yaml:"disabled"`     // Tools never exposed (takes precedence over enabled)
+	OnDuplicate string   `yaml:"on_duplicate"` // error (default) | replace | skip when a tool name is added twice
+}
+
+// Policies for adding a tool whose name is already registered
+const (
+	DuplicateToolError   = "error"   // Reject the second tool (default)
+	DuplicateToolReplace = "replace" // The later tool replaces the registered one
+	DuplicateToolSkip    = "skip"    // Keep the registered tool and ignore the later one
+)
+
+// ValidDuplicateToolPolicy reports whether policy is a supported duplicate tool policy ("" means error)
+func ValidDuplicateToolPolicy(policy string) bool {
+	return policy == "" || policy == DuplicateToolError || policy == DuplicateToolReplace || policy == DuplicateToolSkip
+}
+
+// IsEnabled reports whether the named tool should be exposed
+func (t *ToolsConfig) IsEnabled(name string) bool {
+	for _, disabled := range t.Disabled {
+		if disabled == name {
+			return false
+		}
+	}
+
+	if len(t.Enabled) == 0 {
+		return true
+	}
+
+	for _, enabled := range t.Enabled {
+		if enabled == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Settlement modes
+const (
+	SettlementModeFacilitator = "facilitator" // Submit via x402 facilitator (default)
+	SettlementModeOnChain     = "onchain"     // Submit receiveWithAuthorization directly with a relayer key
+)
+
+// SettlementConfig defines how verified authorizations are settled
+type SettlementConfig struct {
+	Mode          string `yaml:"mode"`            // facilitator | onchain
+	RelayerKeyEnv string `yaml:"relayer_key_env"` // Env var holding the relayer private key (onchain mode)
+
+	FacilitatorAPIKeyEnv string `yaml:"facilitator_api_key_env"` // Env var holding a key sent as a Bearer token to facilitators (empty = no auth)
+
+	MaxInFlight    map[string]int `yaml:"max_in_flight"`    // Concurrent submissions per network (unset/0 = unlimited)
+	QueueTimeoutMs int            `yaml:"queue_timeout_ms"` // Max wait for a free slot before settlement_queue_full (0 = 5000)
+
+	RequirePriorVerify bool `yaml:"require_prior_verify"` // Refuse to settle authorizations not verified by verify_payment within the result TTL
+	RequireAttestation bool `yaml:"require_attestation"`  // Reject facilitator responses without a valid receipt signature (needs facilitator_public_key per network)
+
+	EnforceRequirementTimeout bool `yaml:"enforce_requirement_timeout"` // Reject settlement later than a supplied requirement's maxTimeoutSeconds after issuance
+	AllowFacilitatorOverride  bool `yaml:"allow_facilitator_override"`  // Accept facilitator_url_override in settle inputs (test environment only)
+}
+
+// DefaultQueueTimeout is how long a settlement waits for an in-flight slot when unset
+const DefaultQueueTimeout = 5 * time.Second
+
+// QueueTimeout returns how long a settlement may wait for an in-flight slot
+func (s *SettlementConfig) QueueTimeout() time.Duration {
+	if s.QueueTimeoutMs <= 0 {
+		return DefaultQueueTimeout
+	}
+	return time.Duration(s.QueueTimeoutMs) * time.Millisecond
+}
+
+// IsOnChain reports whether settlement is submitted directly on-chain
+func (s *SettlementConfig) IsOnChain() bool {
+	return s.Mode == SettlementModeOnChain
+}
+
+// RetryConfig defines backoff for retrying transient facilitator failures
+type RetryConfig struct {
+	MaxRetries  int    `yaml:"max_retries"`   // Retries after a transport error or HTTP 500/502/503 (0 = disabled)
+	BaseDelayMs int    `yaml:"base_delay_ms"` // Delay before the first retry, doubled per attempt (0 = 200)
+	MaxDelayMs  int    `yaml:"max_delay_ms"`  // Cap on any single delay (0 = 5000)
+	Jitter      string `yaml:"jitter"`        // full (default) | none
+}
+
+// Retry jitter modes
+const (
+	JitterFull = "full" // Uniform random delay in [0, backoff] so recovering facilitators see spread-out retries
+	JitterNone = "none" // Exact exponential backoff (deterministic)
+)
+
+// Retry delay defaults
+const (
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+	DefaultRetryMaxDelay  = 5 * time.Second
+)
+
+// ValidJitter reports whether mode is a supported jitter mode ("" means full)
+func ValidJitter(mode string) bool {
+	return mode == "" || mode == JitterFull || mode == JitterNone
+}
+
+// BaseDelay returns the delay before the first retry
+func (r *RetryConfig) BaseDelay() time.Duration {
+	if r.BaseDelayMs <= 0 {
+		return DefaultRetryBaseDelay
+	}
+	return time.Duration(r.BaseDelayMs) * time.Millisecond
+}
+
+// MaxDelay returns the cap on any single retry delay
+func (r *RetryConfig) MaxDelay() time.Duration {
+	if r.MaxDelayMs <= 0 {
+		return DefaultRetryMaxDelay
+	}
+	return time.Duration(r.MaxDelayMs) * time.Millisecond
+}
+
+// RedirectConfig defines which HTTP redirects facilitator requests may follow
+type RedirectConfig struct {
+	MaxRedirects   int  `yaml:"max_redirects"`    // Redirects followed per request (0 = none; signed bodies never leave the configured URL)
+	AllowCrossHost bool `yaml:"allow_cross_host"` // Follow redirects to a different host (default: refused)
+}
+
+// Event publishers
+const (
+	EventPublisherNone = "none" // Discard settlement events (default)
+	EventPublisherNATS = "nats" // Publish to a NATS subject
+)
+
+// EventsConfig defines where settlement outcome events are published
+type EventsConfig struct {
+	Publisher        string `yaml:"publisher"`          // none (default) | nats
+	NATSURL          string `yaml:"nats_url"`           // nats://host:4222 (nats publisher)
+	Subject          string `yaml:"subject"`            // Subject for settlement events (default x402.settlements)
+	PublishTimeoutMs int    `yaml:"publish_timeout_ms"` // Bound on each publish (0 = 2000)
+}
+
+// DefaultEventSubject is the subject settlement events are published to when unset
+const DefaultEventSubject = "x402.settlements"
+
+// DefaultPublishTimeout bounds each event publish when unset
+const DefaultPublishTimeout = 2 * time.Second
+
+// EventSubject returns the configured subject or the default
+func (e *EventsConfig) EventSubject() string {
+	if e.Subject == "" {
+		return DefaultEventSubject
+	}
+	return e.Subject
+}
+
+// PublishTimeout returns how long a single event publish may take
+func (e *EventsConfig) PublishTimeout() time.Duration {
+	if e.PublishTimeoutMs <= 0 {
+		return DefaultPublishTimeout
+	}
+	return time.Duration(e.PublishTimeoutMs) * time.Millisecond
+}
+
+// EstimatesConfig defines fallbacks for settlement timing estimates
+type EstimatesConfig struct {
+	SubmitLatencyMs int `yaml:"submit_latency_ms"` // Assumed submission latency before any settlement is measured (0 = 2000)
+}
+
+// DefaultSubmitLatency is the assumed submission latency when unset
+const DefaultSubmitLatency = 2 * time.Second
+
+// SubmitLatency returns the assumed submission latency used until metrics are recorded
+func (e *EstimatesConfig) SubmitLatency() time.Duration {
+	if e.SubmitLatencyMs <= 0 {
+		return DefaultSubmitLatency
+	}
+	return time.Duration(e.SubmitLatencyMs) * time.Millisecond
+}
+
+// LimitsConfig bounds how long each tool call may run, independently of facilitator timeouts
+type LimitsConfig struct {
+	DefaultToolTimeoutMs int            `yaml:"default_tool_timeout_ms"` // Budget for tools without an entry in tool_timeouts (0 = 120000)
+	ToolTimeouts         map[string]int `yaml:"tool_timeouts"`           // Per-tool budget in milliseconds, keyed by tool name
+}
+
+// DefaultToolTimeout is the per-call budget for tools without a configured timeout
+const DefaultToolTimeout = 2 * time.Minute
+
+// ToolTimeout returns the execution budget for the named tool
+func (l *LimitsConfig) ToolTimeout(name string) time.Duration {
+	if ms, exists := l.ToolTimeouts[name]; exists && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	if l.DefaultToolTimeoutMs > 0 {
+		return time.Duration(l.DefaultToolTimeoutMs) * time.Millisecond
+	}
+	return DefaultToolTimeout
+}
+
+// RPCConfig bounds RPC traffic shared by every feature reading chain state
+type RPCConfig struct {
+	MaxConcurrent  int `yaml:"max_concurrent"`   // Concurrent RPC requests across all networks and features (0 = unlimited)
+	QueueTimeoutMs int `yaml:"queue_timeout_ms"` // Wait for a free slot before failing the call (0 = 5000)
+}
+
+// QueueTimeout returns how long an RPC call waits for a free slot (0 = the rpc package default)
+func (r *RPCConfig) QueueTimeout() time.Duration {
+	return time.Duration(r.QueueTimeoutMs) * time.Millisecond
+}
+
+// RequirementsConfig defines defaults for created payment requirements
+type RequirementsConfig struct {
+	DefaultOutputSchema string `yaml:"default_output_schema"` // Output schema template used when the caller names none (empty = no outputSchema)
+	ValiditySeconds     int    `yaml:"validity_seconds"`      // How long generated requirements stay valid (0 = 86400)
+}
+
+// DefaultRequirementValidity is how long generated requirements stay valid when unset
+const DefaultRequirementValidity = 24 * time.Hour
+
+// Validity returns how long generated requirements stay valid
+// A requirement's issuance time is its valid_until minus this validity.
+func (r *RequirementsConfig) Validity() time.Duration {
+	if r.ValiditySeconds <= 0 {
+		return DefaultRequirementValidity
+	}
+	return time.Duration(r.ValiditySeconds) * time.Second
+}
+
+// PricingConfig selects the price oracle converting fiat amounts (amount_fiat) to atomic units
+type PricingConfig struct {
+	Oracle       string             `yaml:"oracle"`        // static (default) | http
+	StaticPrices map[string]float64 `yaml:"static_prices"` // static oracle: USD per token keyed by asset address (unlisted = 1.0, USDC)
+	OracleURL    string             `yaml:"oracle_url"`    // http oracle: GET <url>?asset=<address> returning {"usd_per_token": <price>}
+	TimeoutMs    int                `yaml:"timeout_ms"`    // http oracle request bound (0 = 3000)
+	USDPerFiat   map[string]float64 `yaml:"usd_per_fiat"`  // USD value of one unit of each non-USD fiat_currency, e.g. {EUR: 1.08}
+}
+
+// Price oracle implementations
+const (
+	PriceOracleStatic = "static" // Prices from static_prices (default)
+	PriceOracleHTTP   = "http"   // Prices fetched from oracle_url
+)
+
+// DefaultPricingTimeout bounds each http oracle request when unset
+const DefaultPricingTimeout = 3 * time.Second
+
+// Timeout returns the http oracle request bound
+func (p *PricingConfig) Timeout() time.Duration {
+	if p.TimeoutMs <= 0 {
+		return DefaultPricingTimeout
+	}
+	return time.Duration(p.TimeoutMs) * time.Millisecond
 }
 
 // LoadConfig reads and parses the YAML configuration file
@@ -43,38 +742,425 @@ func LoadConfig(path string) (*Config, error) {
 	// Expand environment variables
 	expanded := os.ExpandEnv(string(data))
 
-	// Parse YAML
+	// Parse YAML, then check it against the schema so every unknown key and wrongly
+	// typed value is reported by key path before decoding
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(expanded), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
 	var cfg Config
-	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
+	if doc.Kind == 0 {
+		return &cfg, nil // Empty file
+	}
+
+	if err := checkSchema(&doc); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(expanded))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
 	return &cfg, nil
 }
 
-// Validate checks that required configuration is present
+// Validate checks that required configuration is present and consistent
+// Every problem is reported at once (as a *ValidationError) rather than only the first.
 func (c *Config) Validate() error {
+	var problems []error
+
 	if len(c.Networks) == 0 {
-		return fmt.Errorf("at least one network must be configured")
+		problems = append(problems, errors.New("at least one network must be configured"))
 	}
 
-	for name, network := range c.Networks {
+	names := c.networkNames()
+	for _, name := range names {
+		network := c.Networks[name]
 		if err := network.Validate(); err != nil {
-			return fmt.Errorf("network %s: %w", name, err)
+			problems = append(problems, fmt.Errorf("network %s: %w", name, err))
+		}
+		if err := netguard.CheckURL(network.FacilitatorURL, c.AllowPrivateURLs); err != nil {
+			problems = append(problems, fmt.Errorf("network %s: facilitator_url: %w", name, err))
+		}
+		if err := netguard.CheckURL(network.RPCURL, c.AllowPrivateURLs); err != nil {
+			problems = append(problems, fmt.Errorf("network %s: rpc_url: %w", name, err))
 		}
 	}
 
-	if c.EIP712.DomainName == "" {
-		return fmt.Errorf("eip712.domain_name is required")
+	for _, name := range names {
+		if _, err := c.DomainParams(name); err != nil {
+			problems = append(problems, fmt.Errorf("network %s: %w", name, err))
+		}
 	}
 
-	if c.EIP712.DomainVersion == "" {
-		return fmt.Errorf("eip712.domain_version is required")
+	if c.Cache.SettlementTTLMinutes <= 0 {
+		problems = append(problems, errors.New("cache.settlement_ttl_minutes must be > 0"))
 	}
 
-	if c.Cache.SettlementTTLMinutes <= 0 {
-		return fmt.Errorf("cache.settlement_ttl_minutes must be > 0")
+	if c.Cache.PendingTTLSeconds < 0 {
+		problems = append(problems, errors.New("cache.pending_ttl_seconds must be >= 0"))
 	}
 
-	return nil
+	if c.Cache.GraceMs < 0 {
+		problems = append(problems, errors.New("cache.grace_ms must be >= 0"))
+	}
+
+	if c.Cache.SweepIntervalSeconds < 0 {
+		problems = append(problems, errors.New("cache.sweep_interval_seconds must be >= 0"))
+	}
+
+	if c.Cache.PendingTTL() > c.Cache.SettledTTL() {
+		problems = append(problems, errors.New("cache.pending_ttl_seconds must not exceed cache.settlement_ttl_minutes"))
+	}
+
+	if c.Verification.MaxAuthorizationAgeSeconds < 0 {
+		problems = append(problems, errors.New("verification.max_authorization_age_seconds must be >= 0"))
+	}
+
+	if !ValidProfile(c.Verification.Profile) {
+		problems = append(problems, fmt.Errorf("verification.profile must be 'lenient' or 'strict', got %s", c.Verification.Profile))
+	}
+
+	if !ValidAddressFormat(c.Verification.AddressFormat) {
+		problems = append(problems, fmt.Errorf("verification.address_format must be 'hex' or 'caip10', got %s", c.Verification.AddressFormat))
+	}
+
+	if !ValidOverpayment(c.Verification.Overpayment) {
+		problems = append(problems, fmt.Errorf("verification.overpayment must be 'reject', 'accept', or 'accept_and_refund_excess', got %s", c.Verification.Overpayment))
+	}
+	if c.Verification.Overpayment == OverpaymentAcceptAndRefundExcess && !c.Refunds.Enabled() {
+		problems = append(problems, errors.New("refunds.path is required when verification.overpayment is 'accept_and_refund_excess'"))
+	}
+
+	if !ValidRValueReuse(c.Verification.RValueReuse) {
+		problems = append(problems, fmt.Errorf("verification.r_value_reuse must be 'off', 'alert', or 'block', got %s", c.Verification.RValueReuse))
+	}
+
+	if !ValidEIP155V(c.Verification.EIP155V) {
+		problems = append(problems, fmt.Errorf("verification.eip155_v must be 'reject', 'accept', or 'match_chain', got %s", c.Verification.EIP155V))
+	}
+
+	if !ValidWeakNonce(c.Verification.WeakNonce) {
+		problems = append(problems, fmt.Errorf("verification.weak_nonce must be 'off', 'warn', or 'reject', got %s", c.Verification.WeakNonce))
+	}
+	if c.Verification.WeakNonceMinDistinctBytes < 0 || c.Verification.WeakNonceMinDistinctBytes > 32 {
+		problems = append(problems, errors.New("verification.weak_nonce_min_distinct_bytes must be between 0 and 32"))
+	}
+
+	if !ValidPayeePolicy(c.Verification.PayeePolicy) {
+		problems = append(problems, fmt.Errorf("verification.payee_policy must be 'reject' or 'warn', got %s", c.Verification.PayeePolicy))
+	}
+	for _, payee := range c.Verification.PayeeAllowlist {
+		if !addressPattern.MatchString(payee) {
+			problems = append(problems, fmt.Errorf("verification.payee_allowlist: invalid address %s", payee))
+		}
+	}
+
+	for _, scheme := range c.Verification.AcceptedSchemes {
+		if !x402.IsSupportedScheme(scheme) {
+			problems = append(problems, fmt.Errorf("verification.accepted_schemes: unsupported scheme %s (supported: %s)", scheme, strings.Join(x402.SupportedSchemes, ", ")))
+		}
+	}
+
+	if !ValidAmountFormat(c.Display.AmountFormat) {
+		problems = append(problems, fmt.Errorf("display.amount_format must be 'plain' or 'grouped', got %s", c.Display.AmountFormat))
+	}
+
+	if !ValidByteEncoding(c.Display.ByteEncoding) {
+		problems = append(problems, fmt.Errorf("display.byte_encoding must be 'hex' or 'base64', got %s", c.Display.ByteEncoding))
+	}
+
+	for _, wallet := range c.Verification.Multisig {
+		if err := wallet.Validate(); err != nil {
+			problems = append(problems, fmt.Errorf("verification.multisig %s: %w", wallet.Wallet, err))
+		}
+	}
+
+	if c.Reconciliation.IntervalSeconds < 0 {
+		problems = append(problems, errors.New("reconciliation.interval_seconds must be >= 0"))
+	}
+	if c.Reconciliation.BatchSize < 0 {
+		problems = append(problems, errors.New("reconciliation.batch_size must be >= 0"))
+	}
+	if c.Reconciliation.UnattestedMaxAgeSeconds < 0 {
+		problems = append(problems, errors.New("reconciliation.unattested_max_age_seconds must be >= 0"))
+	}
+
+	if c.Audit.WarmCacheMinutes < 0 || c.Audit.WarmCacheMaxEntries < 0 {
+		problems = append(problems, errors.New("audit.warm_cache_minutes and audit.warm_cache_max_entries must be >= 0"))
+	}
+	if c.Audit.WarmsCache() && c.Audit.Path == "" {
+		// An in-memory trail is empty at startup, leaving nothing to warm from
+		problems = append(problems, errors.New("audit.warm_cache_minutes requires audit.path"))
+	}
+
+	if c.ContractChecks.IntervalSeconds < 0 {
+		problems = append(problems, errors.New("contract_checks.interval_seconds must be >= 0"))
+	}
+
+	if c.Readiness.WarmupTimeoutSeconds < 0 {
+		problems = append(problems, errors.New("readiness.warmup_timeout_seconds must be >= 0"))
+	}
+
+	if c.Webhook.Enabled() {
+		if c.Webhook.SecretEnv == "" {
+			problems = append(problems, errors.New("webhook.secret_env is required when webhook.listen_addr is set"))
+		}
+		if !strings.HasPrefix(c.Webhook.CallbackPath(), "/") {
+			problems = append(problems, errors.New("webhook.path must start with '/'"))
+		}
+	}
+
+	if c.Metrics.Port < 0 || c.Metrics.Port > 65535 {
+		problems = append(problems, fmt.Errorf("metrics.port must be between 0 and 65535, got %d", c.Metrics.Port))
+	}
+
+	for _, disabled := range c.Tools.Disabled {
+		for _, enabled := range c.Tools.Enabled {
+			if disabled == enabled {
+				problems = append(problems, fmt.Errorf("tool %s is listed in both tools.enabled and tools.disabled", disabled))
+			}
+		}
+	}
+	if !ValidDuplicateToolPolicy(c.Tools.OnDuplicate) {
+		problems = append(problems, fmt.Errorf("tools.on_duplicate must be 'error', 'replace', or 'skip', got %s", c.Tools.OnDuplicate))
+	}
+
+	switch c.Settlement.Mode {
+	case "", SettlementModeFacilitator:
+	case SettlementModeOnChain:
+		if c.Settlement.RelayerKeyEnv == "" {
+			problems = append(problems, errors.New("settlement.relayer_key_env is required for onchain mode"))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("settlement.mode must be 'facilitator' or 'onchain', got %s", c.Settlement.Mode))
+	}
+
+	if c.Settlement.RequireAttestation && !c.Settlement.IsOnChain() {
+		for _, name := range sortedMapKeys(c.Networks) {
+			if c.Networks[name].FacilitatorPublicKey == "" {
+				problems = append(problems, fmt.Errorf("networks.%s.facilitator_public_key is required when settlement.require_attestation is set", name))
+			}
+		}
+	}
+
+	// Per-request facilitator overrides are a staging aid and never enabled in production
+	if c.Settlement.AllowFacilitatorOverride && !c.TestMode() {
+		problems = append(problems, errors.New("settlement.allow_facilitator_override requires environment: test"))
+	}
+
+	inFlightNetworks := make([]string, 0, len(c.Settlement.MaxInFlight))
+	for network := range c.Settlement.MaxInFlight {
+		inFlightNetworks = append(inFlightNetworks, network)
+	}
+	sort.Strings(inFlightNetworks)
+	for _, network := range inFlightNetworks {
+		limit := c.Settlement.MaxInFlight[network]
+		if _, exists := c.Networks[network]; !exists {
+			problems = append(problems, fmt.Errorf("settlement.max_in_flight references unknown network %s", network))
+		}
+		if limit < 0 {
+			problems = append(problems, fmt.Errorf("settlement.max_in_flight[%s] must be >= 0", network))
+		}
+	}
+
+	if c.Settlement.QueueTimeoutMs < 0 {
+		problems = append(problems, errors.New("settlement.queue_timeout_ms must be >= 0"))
+	}
+
+	if c.Retry.MaxRetries < 0 || c.Retry.BaseDelayMs < 0 || c.Retry.MaxDelayMs < 0 {
+		problems = append(problems, errors.New("retry.max_retries, retry.base_delay_ms, and retry.max_delay_ms must be >= 0"))
+	}
+
+	if c.Redirects.MaxRedirects < 0 {
+		problems = append(problems, errors.New("redirects.max_redirects must be >= 0"))
+	}
+
+	switch c.Events.Publisher {
+	case "", EventPublisherNone:
+	case EventPublisherNATS:
+		if !strings.HasPrefix(c.Events.NATSURL, "nats://") {
+			problems = append(problems, errors.New("events.nats_url must be a nats:// URL for the nats publisher"))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("events.publisher must be 'none' or 'nats', got %s", c.Events.Publisher))
+	}
+
+	if c.Events.PublishTimeoutMs < 0 {
+		problems = append(problems, errors.New("events.publish_timeout_ms must be >= 0"))
+	}
+
+	if c.Estimates.SubmitLatencyMs < 0 {
+		problems = append(problems, errors.New("estimates.submit_latency_ms must be >= 0"))
+	}
+
+	if c.RPC.MaxConcurrent < 0 {
+		problems = append(problems, errors.New("rpc.max_concurrent must be >= 0"))
+	}
+	if c.RPC.QueueTimeoutMs < 0 {
+		problems = append(problems, errors.New("rpc.queue_timeout_ms must be >= 0"))
+	}
+
+	if c.Limits.DefaultToolTimeoutMs < 0 {
+		problems = append(problems, errors.New("limits.default_tool_timeout_ms must be >= 0"))
+	}
+	for _, tool := range sortedMapKeys(c.Limits.ToolTimeouts) {
+		if c.Limits.ToolTimeouts[tool] <= 0 {
+			problems = append(problems, fmt.Errorf("limits.tool_timeouts.%s must be > 0", tool))
+		}
+	}
+
+	if c.Requirements.ValiditySeconds < 0 {
+		problems = append(problems, errors.New("requirements.validity_seconds must be >= 0"))
+	}
+
+	if name := c.Requirements.DefaultOutputSchema; name != "" {
+		if _, known := x402.OutputSchemaTemplate(name); !known {
+			problems = append(problems, fmt.Errorf("requirements.default_output_schema must be one of %s, got %s",
+				strings.Join(x402.OutputSchemaTemplateNames(), ", "), name))
+		}
+	}
+
+	switch c.Pricing.Oracle {
+	case "", PriceOracleStatic:
+	case PriceOracleHTTP:
+		if err := netguard.CheckURL(c.Pricing.OracleURL, c.AllowPrivateURLs); err != nil || !urlPattern.MatchString(c.Pricing.OracleURL) {
+			problems = append(problems, errors.New("pricing.oracle_url must be a public HTTP/HTTPS URL for the http oracle"))
+		}
+	default:
+		problems = append(problems, fmt.Errorf("pricing.oracle must be 'static' or 'http', got %s", c.Pricing.Oracle))
+	}
+	if c.Pricing.TimeoutMs < 0 {
+		problems = append(problems, errors.New("pricing.timeout_ms must be >= 0"))
+	}
+	for _, asset := range sortedMapKeys(c.Pricing.StaticPrices) {
+		if c.Pricing.StaticPrices[asset] <= 0 {
+			problems = append(problems, fmt.Errorf("pricing.static_prices.%s must be > 0", asset))
+		}
+	}
+	for _, currency := range sortedMapKeys(c.Pricing.USDPerFiat) {
+		if c.Pricing.USDPerFiat[currency] <= 0 {
+			problems = append(problems, fmt.Errorf("pricing.usd_per_fiat.%s must be > 0", currency))
+		}
+	}
+
+	if !ValidEnvironment(c.Environment) {
+		problems = append(problems, fmt.Errorf("environment must be 'production' or 'test', got %s", c.Environment))
+	}
+
+	if !ValidJitter(c.Retry.Jitter) {
+		problems = append(problems, fmt.Errorf("retry.jitter must be 'full' or 'none', got %s", c.Retry.Jitter))
+	}
+
+	return problemsError(problems)
+}
+
+// networkNames returns the configured network names in sorted order
+func (c *Config) networkNames() []string {
+	names := make([]string, 0, len(c.Networks))
+	for name := range c.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedMapKeys returns a map's keys in sorted order, so problems are reported deterministically
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CanonicalNetwork returns the configured network name matching name, ignoring case and
+// surrounding whitespace, so "Base" and "BASE" resolve to "base". An exact match wins; ok is
+// false when no network matches or the match is ambiguous.
+func (c *Config) CanonicalNetwork(name string) (string, bool) {
+	if _, exists := c.Networks[name]; exists {
+		return name, true
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	match := ""
+	for configured := range c.Networks {
+		if strings.ToLower(configured) != normalized {
+			continue
+		}
+		if match != "" {
+			return "", false
+		}
+		match = configured
+	}
+
+	return match, match != ""
+}
+
+// ResolveByAsset returns the configured network whose chain ID and USDC contract match
+// Asset comparison is case-insensitive. ok is false when no network or more than one matches.
+func (c *Config) ResolveByAsset(chainID uint64, asset string) (string, bool) {
+	match := ""
+	for name, network := range c.Networks {
+		if network.ChainID != chainID || !strings.EqualFold(network.USDCContract, asset) {
+			continue
+		}
+		if match != "" {
+			return "", false
+		}
+		match = name
+	}
+
+	return match, match != ""
+}
+
+// DomainChangedNetworks lists networks whose EIP-712 domain differs between two configs
+// A network is affected when it was added or removed, or when its chain ID, USDC contract,
+// or resolved domain name/version changed. The result is sorted by network name.
+func DomainChangedNetworks(oldCfg, newCfg *Config) []string {
+	names := make(map[string]bool)
+	for name := range oldCfg.Networks {
+		names[name] = true
+	}
+	for name := range newCfg.Networks {
+		names[name] = true
+	}
+
+	changed := make([]string, 0)
+	for name := range names {
+		oldNet, inOld := oldCfg.Networks[name]
+		newNet, inNew := newCfg.Networks[name]
+
+		oldParams, oldErr := oldCfg.DomainParams(name)
+		newParams, newErr := newCfg.DomainParams(name)
+
+		if inOld != inNew || oldErr != nil || newErr != nil || oldParams != newParams ||
+			oldNet.ChainID != newNet.ChainID ||
+			!strings.EqualFold(oldNet.USDCContract, newNet.USDCContract) {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// RPCChangedNetworks lists networks whose RPC connection differs between two configs, so
+// clients dialed under the old config must be dropped: networks removed or whose rpc_url
+// changed, or every old network when allow_private_urls changed. The result is sorted.
+func RPCChangedNetworks(oldCfg, newCfg *Config) []string {
+	changed := make([]string, 0)
+	for name, oldNet := range oldCfg.Networks {
+		newNet, exists := newCfg.Networks[name]
+		if !exists || oldNet.RPCURL != newNet.RPCURL || oldCfg.AllowPrivateURLs != newCfg.AllowPrivateURLs {
+			changed = append(changed, name)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
 }
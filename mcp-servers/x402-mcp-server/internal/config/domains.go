@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DomainParams are the EIP-712 domain name and version of a USDC deployment
+type DomainParams struct {
+	Name    string
+	Version string
+}
+
+// knownUSDCDomains maps chain ID to the EIP-712 domain of Circle's native USDC there
+// Testnet deployments name the token "USDC" while most mainnets use "USD Coin"; signing
+// with the wrong name or version makes every signature recover to a different address.
+var knownUSDCDomains = map[uint64]DomainParams{
+	1:        {Name: "USD Coin", Version: "2"}, // Ethereum
+	10:       {Name: "USD Coin", Version: "2"}, // Optimism
+	137:      {Name: "USD Coin", Version: "2"}, // Polygon PoS
+	8453:     {Name: "USD Coin", Version: "2"}, // Base
+	42161:    {Name: "USD Coin", Version: "2"}, // Arbitrum One
+	43114:    {Name: "USD Coin", Version: "2"}, // Avalanche C-Chain
+	43113:    {Name: "USD Coin", Version: "2"}, // Avalanche Fuji
+	80002:    {Name: "USDC", Version: "2"},     // Polygon Amoy
+	84532:    {Name: "USDC", Version: "2"},     // Base Sepolia
+	421614:   {Name: "USDC", Version: "2"},     // Arbitrum Sepolia
+	11155111: {Name: "USDC", Version: "2"},     // Ethereum Sepolia
+}
+
+// DefaultUSDCDomain returns the built-in USDC EIP-712 domain parameters for a chain ID
+func DefaultUSDCDomain(chainID uint64) (DomainParams, bool) {
+	params, exists := knownUSDCDomains[chainID]
+	return params, exists
+}
+
+// DomainParams returns the EIP-712 domain name and version used to verify a network's
+// authorizations. Each is resolved separately: the network's domain_name/domain_version,
+// then the global eip712 section, then the built-in table for the network's chain ID.
+func (c *Config) DomainParams(network string) (DomainParams, error) {
+	networkCfg, exists := c.Networks[network]
+	if !exists {
+		return DomainParams{}, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	builtin, _ := DefaultUSDCDomain(networkCfg.ChainID)
+	params := DomainParams{
+		Name:    firstNonEmpty(networkCfg.DomainName, c.EIP712.DomainName, builtin.Name),
+		Version: firstNonEmpty(networkCfg.DomainVersion, c.EIP712.DomainVersion, builtin.Version),
+	}
+
+	switch {
+	case params.Name == "" && params.Version == "":
+		return DomainParams{}, fmt.Errorf("no EIP-712 domain known for chain_id %d: set domain_name and domain_version", networkCfg.ChainID)
+	case params.Name == "":
+		return DomainParams{}, fmt.Errorf("no EIP-712 domain name known for chain_id %d: set domain_name", networkCfg.ChainID)
+	case params.Version == "":
+		return DomainParams{}, fmt.Errorf("no EIP-712 domain version known for chain_id %d: set domain_version", networkCfg.ChainID)
+	}
+
+	return params, nil
+}
+
+// firstNonEmpty returns the first of values that is not empty, or ""
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// LegacyDomainParams returns the network's pre-migration EIP-712 domain while its migration
+// window is open at now. ok is false when no legacy domain is configured or the window closed.
+func (c *Config) LegacyDomainParams(network string, now time.Time) (DomainParams, bool) {
+	networkCfg, exists := c.Networks[network]
+	if !exists || networkCfg.LegacyDomainName == "" {
+		return DomainParams{}, false
+	}
+
+	until, err := time.Parse(time.RFC3339, networkCfg.LegacyDomainUntil)
+	if err != nil || !now.Before(until) {
+		return DomainParams{}, false
+	}
+
+	return DomainParams{Name: networkCfg.LegacyDomainName, Version: networkCfg.LegacyDomainVersion}, true
+}
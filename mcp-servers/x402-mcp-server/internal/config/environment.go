@@ -0,0 +1,54 @@
+package config
+
+import "sort"
+
+// Deployment environments
+const (
+	EnvironmentProduction = "production" // Settle on any configured network (default)
+	EnvironmentTest       = "test"       // Refuse mainnet settlement unless overridden per call
+)
+
+// ErrorCodeMainnetBlocked is returned when test mode refuses a mainnet settlement
+const ErrorCodeMainnetBlocked = "mainnet_blocked"
+
+// knownTestnets lists chain IDs whose tokens carry no real value
+// Every other chain counts as mainnet, so an unrecognized chain is protected in test mode.
+var knownTestnets = map[uint64]bool{
+	84532:    true, // Base Sepolia
+	421614:   true, // Arbitrum Sepolia
+	11155111: true, // Ethereum Sepolia
+	11155420: true, // Optimism Sepolia
+	80002:    true, // Polygon Amoy
+	43113:    true, // Avalanche Fuji
+}
+
+// ValidEnvironment reports whether env is a supported environment (empty means production)
+func ValidEnvironment(env string) bool {
+	switch env {
+	case "", EnvironmentProduction, EnvironmentTest:
+		return true
+	}
+	return false
+}
+
+// TestMode reports whether the server runs in the test environment
+func (c *Config) TestMode() bool {
+	return c.Environment == EnvironmentTest
+}
+
+// IsMainnet reports whether the network moves real funds (any chain not a known testnet)
+func (n *NetworkConfig) IsMainnet() bool {
+	return !knownTestnets[n.ChainID]
+}
+
+// MainnetNetworks returns the configured mainnet network names in sorted order
+func (c *Config) MainnetNetworks() []string {
+	var names []string
+	for name, network := range c.Networks {
+		if network.IsMainnet() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
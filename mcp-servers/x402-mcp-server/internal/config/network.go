@@ -3,6 +3,10 @@ package config
 import (
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
 )
 
 // NetworkConfig contains network-specific parameters for payment processing
@@ -12,13 +16,36 @@ type NetworkConfig struct {
 	FacilitatorURL string `yaml:"facilitator_url"` // x402 facilitator endpoint
 	RPCURL         string `yaml:"rpc_url"`         // Blockchain RPC for nonces
 	PayeeAddress   string `yaml:"payee_address"`   // Certification service payee
+
+	DomainName    string `yaml:"domain_name"`    // EIP-712 domain name override (default: eip712 section, then per-chain table)
+	DomainVersion string `yaml:"domain_version"` // EIP-712 domain version override (default: eip712 section, then per-chain table)
+
+	LegacyDomainName    string `yaml:"legacy_domain_name"`    // Pre-migration EIP-712 domain name, still accepted until legacy_domain_until
+	LegacyDomainVersion string `yaml:"legacy_domain_version"` // Pre-migration EIP-712 domain version, set together with legacy_domain_name
+	LegacyDomainUntil   string `yaml:"legacy_domain_until"`   // RFC 3339 end of the migration window (required with a legacy domain)
+
+	MaxGasPriceGwei          float64 `yaml:"max_gas_price_gwei"`         // On-chain settlement gas ceiling (0 = no ceiling)
+	Confirmations            uint64  `yaml:"confirmations"`              // Confirmations required before a settlement counts as settled (0 = facilitator default)
+	SettlementTimeoutSeconds int     `yaml:"settlement_timeout_seconds"` // Per-network settlement timeout (0 = server default)
+	BlockTimeSeconds         float64 `yaml:"block_time_seconds"`         // Average block time for timing estimates (0 = built-in per-chain value)
+
+	ExplorerURL string `yaml:"explorer_url"` // Block explorer base for tx links, e.g. https://basescan.org (empty = no links)
+
+	FacilitatorPublicKey string `yaml:"facilitator_public_key"` // secp256k1 key (0x hex, compressed or uncompressed) signing the facilitator's settlement receipts
+
+	AssetDecimals int `yaml:"asset_decimals"` // Decimals of the token at usdc_contract, e.g. 18 for DAI (0 = 6, native USDC)
 }
 
-// Allowed chain IDs per data-model.md validation rules
+// MaxAssetDecimals bounds asset_decimals; ERC-20 tokens in practice use at most 18
+const MaxAssetDecimals = 36
+
+// Allowed chain IDs per data-model.md validation rules, with each chain's native USDC contract
 var allowedChainIDs = map[uint64]bool{
-	8453:  true, // Base
-	84532: true, // Base Sepolia
-	42161: true, // Arbitrum
+	8453:   true, // Base: 0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913
+	84532:  true, // Base Sepolia: 0x036CbD53842c5426634e7929541eC2318f3dCF7e
+	42161:  true, // Arbitrum: 0xaf88d065e77c8cC2239327C5EDb3A432268e5831
+	421614: true, // Arbitrum Sepolia: 0x75faf114eafb1BDbe2F0316DF893fd58CE46AA4d
+	10:     true, // Optimism: 0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85
 }
 
 // Ethereum address pattern: 0x prefix + 40 hex characters
@@ -27,11 +54,14 @@ var addressPattern = regexp.MustCompile(`^0x[a-fA-F0-9]{40}$`)
 // URL pattern: http or https
 var urlPattern = regexp.MustCompile(`^https?://`)
 
+// secp256k1 public key pattern: compressed (33 bytes) or uncompressed (65 bytes) hex
+var publicKeyPattern = regexp.MustCompile(`^0x(0[23][a-fA-F0-9]{64}|04[a-fA-F0-9]{128})$`)
+
 // Validate checks that all required network config fields are valid
 func (n *NetworkConfig) Validate() error {
 	// Chain ID must be in allowlist
 	if !allowedChainIDs[n.ChainID] {
-		return fmt.Errorf("chain_id %d not in allowed list (8453, 84532, 42161)", n.ChainID)
+		return fmt.Errorf("chain_id %d not in allowed list (8453, 84532, 42161, 421614, 10)", n.ChainID)
 	}
 
 	// USDC contract must be valid Ethereum address
@@ -54,5 +84,80 @@ func (n *NetworkConfig) Validate() error {
 		return fmt.Errorf("facilitator_url must be valid HTTP/HTTPS URL")
 	}
 
+	// A legacy domain is accepted only within an explicit migration window
+	if (n.LegacyDomainName == "") != (n.LegacyDomainVersion == "") {
+		return fmt.Errorf("legacy_domain_name and legacy_domain_version must be set together")
+	}
+	if n.LegacyDomainName != "" {
+		if _, err := time.Parse(time.RFC3339, n.LegacyDomainUntil); err != nil {
+			return fmt.Errorf("legacy_domain_until must be an RFC 3339 timestamp when a legacy domain is set")
+		}
+	} else if n.LegacyDomainUntil != "" {
+		return fmt.Errorf("legacy_domain_until requires legacy_domain_name and legacy_domain_version")
+	}
+
+	// Explorer base is optional but must be HTTP/HTTPS when set
+	if n.ExplorerURL != "" && !urlPattern.MatchString(n.ExplorerURL) {
+		return fmt.Errorf("explorer_url must be valid HTTP/HTTPS URL")
+	}
+
+	// Receipt attestation key is optional but must be a secp256k1 public key when set
+	if n.FacilitatorPublicKey != "" && !publicKeyPattern.MatchString(n.FacilitatorPublicKey) {
+		return fmt.Errorf("facilitator_public_key must be a 0x-prefixed compressed or uncompressed secp256k1 public key")
+	}
+
+	// Gas ceiling cannot be negative
+	if n.MaxGasPriceGwei < 0 {
+		return fmt.Errorf("max_gas_price_gwei must be >= 0")
+	}
+
+	// Block time cannot be negative
+	if n.BlockTimeSeconds < 0 {
+		return fmt.Errorf("block_time_seconds must be >= 0")
+	}
+
+	// Asset decimals drive amount conversion; 0 keeps the USDC default
+	if n.AssetDecimals < 0 || n.AssetDecimals > MaxAssetDecimals {
+		return fmt.Errorf("asset_decimals must be between 0 and %d", MaxAssetDecimals)
+	}
+
+	// Settlement timeout cannot be negative
+	if n.SettlementTimeoutSeconds < 0 {
+		return fmt.Errorf("settlement_timeout_seconds must be >= 0")
+	}
+
 	return nil
 }
+
+// Decimals returns the decimals of the network's payment asset
+func (n *NetworkConfig) Decimals() int {
+	if n.AssetDecimals == 0 {
+		return units.USDCDecimals
+	}
+	return n.AssetDecimals
+}
+
+// AssetDecimals returns the payment asset decimals of a network, or the USDC default when it is not configured
+func (c *Config) AssetDecimals(network string) int {
+	networkCfg, exists := c.Networks[network]
+	if !exists {
+		return units.USDCDecimals
+	}
+	return networkCfg.Decimals()
+}
+
+// SettlementTimeout returns the network's settlement timeout, or fallback if unset
+func (n *NetworkConfig) SettlementTimeout(fallback time.Duration) time.Duration {
+	if n.SettlementTimeoutSeconds <= 0 {
+		return fallback
+	}
+	return time.Duration(n.SettlementTimeoutSeconds) * time.Second
+}
+
+// TxExplorerURL returns the block explorer link for txHash, or "" when no explorer is configured
+func (n *NetworkConfig) TxExplorerURL(txHash string) string {
+	if n.ExplorerURL == "" || txHash == "" {
+		return ""
+	}
+	return strings.TrimRight(n.ExplorerURL, "/") + "/tx/" + txHash
+}
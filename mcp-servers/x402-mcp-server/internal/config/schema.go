@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []error
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return e.Problems[0].Error()
+	}
+
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Error()
+	}
+	return fmt.Sprintf("%d config problems:\n  - %s", len(e.Problems), strings.Join(messages, "\n  - "))
+}
+
+// Unwrap returns the individual problems so errors.Is and errors.As see through the list
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// problemsError returns a *ValidationError for the problems, or nil when there are none
+func problemsError(problems []error) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// checkSchema checks a parsed YAML document against the Config type before decoding,
+// reporting every unknown key and wrongly typed value by its dotted key path and line
+func checkSchema(doc *yaml.Node) error {
+	var problems []error
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		checkNode(doc.Content[0], reflect.TypeOf(Config{}), "", &problems)
+	}
+	return problemsError(problems)
+}
+
+// checkNode checks a YAML node against the Go type it will be decoded into
+func checkNode(node *yaml.Node, t reflect.Type, path string, problems *[]error) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+
+	mismatch := func(expected string) {
+		*problems = append(*problems, fmt.Errorf("%s (line %d): expected %s, got %s",
+			displayPath(path), node.Line, expected, describeNode(node)))
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			mismatch("a mapping")
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, known := fields[key.Value]
+			if !known {
+				*problems = append(*problems, fmt.Errorf("%s (line %d): unknown key (valid keys: %s)",
+					joinPath(path, key.Value), key.Line, strings.Join(sortedKeys(fields), ", ")))
+				continue
+			}
+			checkNode(value, field, joinPath(path, key.Value), problems)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			mismatch("a mapping")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkNode(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value), problems)
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			mismatch("a list")
+			return
+		}
+		for i, item := range node.Content {
+			checkNode(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), problems)
+		}
+
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			mismatch("a string")
+		}
+
+	case reflect.Bool:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+			mismatch("true or false")
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+			mismatch("an integer")
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if node.Kind != yaml.ScalarNode || node.Tag != "!!int" || strings.HasPrefix(node.Value, "-") {
+			mismatch("a non-negative integer")
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+			mismatch("a number")
+		}
+	}
+}
+
+// yamlFields maps a struct's YAML keys to their field types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// sortedKeys returns a field map's keys in sorted order
+func sortedKeys(fields map[string]reflect.Type) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// describeNode names a YAML node's type and value for error messages
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+
+	switch node.Tag {
+	case "!!int":
+		return fmt.Sprintf("integer %s", node.Value)
+	case "!!float":
+		return fmt.Sprintf("number %s", node.Value)
+	case "!!bool":
+		return fmt.Sprintf("boolean %s", node.Value)
+	}
+	return fmt.Sprintf("string %q", node.Value)
+}
+
+// joinPath appends a key to a dotted key path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// displayPath names the document root when the path is empty
+func displayPath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RequiredSecret is an environment variable the enabled configuration needs at runtime
+type RequiredSecret struct {
+	Env    string // Environment variable name
+	Option string // Config option naming the variable, e.g. settlement.relayer_key_env
+	Reason string // Feature that needs it
+}
+
+// RequiredSecrets lists the env secrets needed by the enabled modes:
+// the relayer key for on-chain settlement, the facilitator API key when
+// facilitator auth is configured, and the webhook HMAC secret when callbacks are accepted
+func (c *Config) RequiredSecrets() []RequiredSecret {
+	var secrets []RequiredSecret
+	if c.Settlement.IsOnChain() && c.Settlement.RelayerKeyEnv != "" {
+		secrets = append(secrets, RequiredSecret{
+			Env:    c.Settlement.RelayerKeyEnv,
+			Option: "settlement.relayer_key_env",
+			Reason: "onchain settlement",
+		})
+	}
+	if c.Settlement.FacilitatorAPIKeyEnv != "" {
+		secrets = append(secrets, RequiredSecret{
+			Env:    c.Settlement.FacilitatorAPIKeyEnv,
+			Option: "settlement.facilitator_api_key_env",
+			Reason: "facilitator auth",
+		})
+	}
+	if c.Webhook.Enabled() && c.Webhook.SecretEnv != "" {
+		secrets = append(secrets, RequiredSecret{
+			Env:    c.Webhook.SecretEnv,
+			Option: "webhook.secret_env",
+			Reason: "settlement webhook",
+		})
+	}
+	return secrets
+}
+
+// CheckSecrets fails when any required secret is unset or empty, naming every missing variable
+// lookup reads the environment (nil = os.LookupEnv), so tests can supply their own.
+func (c *Config) CheckSecrets(lookup func(string) (string, bool)) error {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	var missing []string
+	for _, secret := range c.RequiredSecrets() {
+		if value, ok := lookup(secret.Env); !ok || value == "" {
+			missing = append(missing, fmt.Sprintf("%s (%s, required for %s)", secret.Env, secret.Option, secret.Reason))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required secrets: %s", strings.Join(missing, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Watcher re-reads a config file on SIGHUP and hands each valid result to an apply
+// function, typically Server.ReloadConfig. A file that fails to load or validate is
+// reported to the error handler and never applied, so the running config is kept.
+type Watcher struct {
+	path    string
+	apply   func(*Config) error
+	onError func(error)
+
+	signals  chan os.Signal
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher creates a watcher for the config file at path
+func NewWatcher(path string, apply func(*Config) error) *Watcher {
+	return &Watcher{
+		path:    path,
+		apply:   apply,
+		onError: func(error) {},
+		signals: make(chan os.Signal, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// OnError registers a handler for reloads that failed to load, validate, or apply
+// Must be called before Start.
+func (w *Watcher) OnError(handler func(error)) {
+	w.onError = handler
+}
+
+// Start begins reloading on SIGHUP until Stop is called
+func (w *Watcher) Start() {
+	signal.Notify(w.signals, syscall.SIGHUP)
+
+	go func() {
+		defer close(w.done)
+		for {
+			select {
+			case <-w.signals:
+				if err := w.Reload(); err != nil {
+					w.onError(err)
+				}
+			case <-w.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops watching for SIGHUP and waits for an in-progress reload to finish
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		signal.Stop(w.signals)
+		close(w.stop)
+		<-w.done
+	})
+}
+
+// Reload re-reads and validates the config file, then applies it
+func (w *Watcher) Reload() error {
+	cfg, err := LoadConfig(w.path)
+	if err != nil {
+		return fmt.Errorf("reload %s: %w", w.path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("reload %s: invalid config: %w", w.path, err)
+	}
+
+	if err := w.apply(cfg); err != nil {
+		return fmt.Errorf("reload %s: %w", w.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package deadletter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+// MetricAppendFailures counts permanently failed settlements that could not be dead-lettered
+const MetricAppendFailures = "x402_dead_letter_append_failures_total"
+
+// Entry is a permanently failed settlement kept for inspection or replay
+type Entry struct {
+	Timestamp     time.Time                     `json:"timestamp"`
+	Network       string                        `json:"network"`
+	Authorization *eip3009.EIP3009Authorization `json:"authorization"` // Signed authorization as submitted
+	Error         string                        `json:"error"`
+	ErrorCode     string                        `json:"error_code,omitempty"`
+}
+
+// Store persists dead-lettered settlements
+type Store interface {
+	Append(entry Entry) error
+	Entries() ([]Entry, error)
+}
+
+// NopStore discards every entry (the default when no dead-letter path is configured)
+type NopStore struct{}
+
+// Append implements Store
+func (NopStore) Append(entry Entry) error {
+	return nil
+}
+
+// Entries implements Store
+func (NopStore) Entries() ([]Entry, error) {
+	return nil, nil
+}
+
+// FileStore appends entries to a JSON-lines file
+// The file holds signed authorizations, so it is created readable by the owner only.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileStore creates a store appending to path, which is created on first append
+func NewFileStore(path string) *FileStore {
+	return &FileStore{
+		path: path,
+	}
+}
+
+// Append writes entry as one JSON line, stamping it with the current time if unset
+func (f *FileStore) Append(entry Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+
+	return file.Sync()
+}
+
+// Entries reads all entries in append order; a missing file holds none
+func (f *FileStore) Entries() ([]Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead letter file: %w", err)
+	}
+	defer file.Close()
+
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("dead letter file line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dead letter file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// NewStore builds the store selected by the dead-letter configuration
+func NewStore(cfg *config.DeadLetterConfig) Store {
+	if !cfg.Enabled() {
+		return NopStore{}
+	}
+	return NewFileStore(cfg.Path)
+}
@@ -2,11 +2,14 @@ package eip3009
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"regexp"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // EIP3009Authorization represents the payment authorization data structure
@@ -21,13 +24,65 @@ type EIP3009Authorization struct {
 	V           uint8  `json:"v"`           // Signature parameter (27 or 28)
 	R           string `json:"r"`           // Signature parameter (bytes32 hex)
 	S           string `json:"s"`           // Signature parameter (bytes32 hex)
+
+	EIP155ChainID uint64 `json:"-"` // Chain ID embedded in an EIP-155 encoded input v (0 = plain 27/28)
+}
+
+// Signature holds the v/r/s components of a secp256k1 signature
+type Signature struct {
+	V uint8  `json:"v"` // 27 or 28
+	R string `json:"r"` // bytes32 hex
+	S string `json:"s"` // bytes32 hex
+
+	EIP155ChainID uint64 `json:"-"` // Chain ID embedded in an EIP-155 encoded input v (0 = plain 27/28)
+}
+
+// Verification error codes
+const (
+	ErrorCodeTooOld         = "too_old"          // validAfter is older than the configured maximum age
+	ErrorCodeAmountMismatch = "amount_mismatch"  // value differs from the caller's expected amount
+	ErrorCodePayeeMismatch  = "payee_mismatch"   // to differs from the payment requirement's payTo
+	ErrorCodeBadChecksum    = "invalid_checksum" // mixed-case address fails EIP-55 checksum
+	ErrorCodeNotVerified    = "not_verified"     // settlement requires a prior successful verify_payment
+	ErrorCodeStrictProfile  = "strict_profile"   // a field left at its default under verification.profile strict
+
+	ErrorCodeInvertedWindow = "inverted_window" // validAfter >= validBefore: invalid at any time, not a clock issue
+	ErrorCodeNotYetValid    = "not_yet_valid"   // current time is before validAfter
+	ErrorCodeExpired        = "expired"         // current time is at or after validBefore
+
+	ErrorCodeMultisigNotConfigured  = "multisig_not_configured" // payer has no configured owner set
+	ErrorCodeDuplicateSigner        = "duplicate_signer"        // the same owner signed more than once
+	ErrorCodeInsufficientSignatures = "insufficient_signatures" // fewer distinct owners than the threshold
+)
+
+// ErrInvertedWindow is wrapped by validation errors for authorizations whose validAfter is not
+// before validBefore; no clock can ever fall inside such a window
+var ErrInvertedWindow = errors.New("validAfter must be less than validBefore")
+
+// MaxTimestamp is the latest plausible validAfter/validBefore (2200-01-01T00:00:00Z)
+// Larger values indicate a malformed or hostile authorization rather than a real deadline.
+const MaxTimestamp uint64 = 7258118400
+
+// ValidationError reports a malformed authorization input field
+type ValidationError struct {
+	Field  string // Input field name (e.g., "validAfter")
+	Reason string
+}
+
+// Error implements the error interface
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
 }
 
 // VerifyPaymentOutput represents the verification result
 type VerifyPaymentOutput struct {
-	IsValid       bool   `json:"is_valid"`
-	SignerAddress string `json:"signer_address,omitempty"` // Recovered from signature
-	Error         string `json:"error,omitempty"`
+	IsValid       bool     `json:"is_valid"`
+	SignerAddress string   `json:"signer_address,omitempty"` // Recovered from signature
+	Error         string   `json:"error,omitempty"`
+	ErrorCode     string   `json:"error_code,omitempty"`    // Machine-readable failure reason
+	Signers       []string `json:"signers,omitempty"`       // Distinct authorized owners (multisig only)
+	LegacyDomain  bool     `json:"legacy_domain,omitempty"` // Signed under the pre-migration domain during its window
+	SignerType    string   `json:"signer_type,omitempty"`   // eoa | contract (EIP-1271) for single-signer verifications
 }
 
 var (
@@ -43,6 +98,15 @@ var (
 
 // Validate performs input validation on the authorization
 func (a *EIP3009Authorization) Validate() error {
+	if err := a.ValidateMessage(); err != nil {
+		return err
+	}
+
+	return (&Signature{V: a.V, R: a.R, S: a.S}).Validate()
+}
+
+// ValidateMessage validates the signed message fields, ignoring v/r/s
+func (a *EIP3009Authorization) ValidateMessage() error {
 	// Validate From address
 	if !addressPatternAuth.MatchString(a.From) {
 		return fmt.Errorf("invalid from address format: %s", a.From)
@@ -59,28 +123,61 @@ func (a *EIP3009Authorization) Validate() error {
 	}
 
 	// Validate Nonce format
+	if err := ValidateHex("nonce", a.Nonce); err != nil {
+		return err
+	}
 	if !bytes32Pattern.MatchString(a.Nonce) {
 		return fmt.Errorf("invalid nonce format: must be 32-byte hex string")
 	}
 
+	// Validate time bounds. A zero validAfter means valid immediately and is accepted here;
+	// only the strict profile (ValidateStrict) requires an explicit timestamp
+	if a.ValidAfter >= a.ValidBefore {
+		return fmt.Errorf("%w (validAfter=%d, validBefore=%d)", ErrInvertedWindow, a.ValidAfter, a.ValidBefore)
+	}
+
+	return nil
+}
+
+// ValidateStrict rejects fields a well-formed client always sets explicitly: a zero validAfter or
+// validBefore, or an all-zero nonce. Each is accepted on-chain, so this only runs under the strict profile.
+func (a *EIP3009Authorization) ValidateStrict() error {
+	if a.ValidAfter == 0 {
+		return fmt.Errorf("strict profile: validAfter must be an explicit non-zero timestamp")
+	}
+
+	if a.ValidBefore == 0 {
+		return fmt.Errorf("strict profile: validBefore must be an explicit non-zero timestamp")
+	}
+
+	if common.HexToHash(a.Nonce) == (common.Hash{}) {
+		return fmt.Errorf("strict profile: nonce must be a random 32-byte value, got all zeros")
+	}
+
+	return nil
+}
+
+// Validate checks the signature component formats
+func (sig *Signature) Validate() error {
 	// Validate V parameter
-	if a.V != 27 && a.V != 28 {
-		return fmt.Errorf("invalid v parameter: must be 27 or 28, got %d", a.V)
+	if sig.V != 27 && sig.V != 28 {
+		return fmt.Errorf("invalid v parameter: must be 27 or 28, got %d", sig.V)
 	}
 
 	// Validate R parameter
-	if !bytes32Pattern.MatchString(a.R) {
+	if err := ValidateHex("r", sig.R); err != nil {
+		return err
+	}
+	if !bytes32Pattern.MatchString(sig.R) {
 		return fmt.Errorf("invalid r parameter: must be 32-byte hex string")
 	}
 
 	// Validate S parameter
-	if !bytes32Pattern.MatchString(a.S) {
-		return fmt.Errorf("invalid s parameter: must be 32-byte hex string")
+	if err := ValidateHex("s", sig.S); err != nil {
+		return err
 	}
-
-	// Validate time bounds
-	if a.ValidAfter >= a.ValidBefore {
-		return fmt.Errorf("validAfter must be less than validBefore")
+	if !bytes32Pattern.MatchString(sig.S) {
+		return fmt.Errorf("invalid s parameter: must be 32-byte hex string")
 	}
 
 	return nil
@@ -103,6 +200,9 @@ func (a *EIP3009Authorization) ToMessage() (*ReceiveWithAuthorizationMessage, er
 	validBefore := new(big.Int).SetUint64(a.ValidBefore)
 
 	// Convert nonce
+	if err := ValidateHex("nonce", a.Nonce); err != nil {
+		return nil, err
+	}
 	nonceBytes := common.FromHex(a.Nonce)
 	if len(nonceBytes) != 32 {
 		return nil, fmt.Errorf("nonce must be 32 bytes, got %d", len(nonceBytes))
@@ -121,11 +221,42 @@ func (a *EIP3009Authorization) ToMessage() (*ReceiveWithAuthorizationMessage, er
 	}, nil
 }
 
+// ValidateChecksum checks the EIP-55 checksum of a mixed-case address
+// All-lowercase and all-uppercase addresses carry no checksum and are accepted
+func ValidateChecksum(address string) error {
+	mixed, err := common.NewMixedcaseAddressFromString(address)
+	if err != nil {
+		return fmt.Errorf("invalid address: %s", address)
+	}
+
+	digits := strings.TrimPrefix(address, "0x")
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+
+	if !mixed.ValidChecksum() {
+		return fmt.Errorf("invalid EIP-55 checksum for address %s (expected %s)", address, mixed.Address().Hex())
+	}
+
+	return nil
+}
+
 // GetSignature returns the signature components in the format expected by crypto.Sign
 func (a *EIP3009Authorization) GetSignature() ([]byte, error) {
+	return (&Signature{V: a.V, R: a.R, S: a.S}).Bytes()
+}
+
+// Bytes returns the 65-byte R || S || V signature in the format expected by crypto.SigToPub
+func (sig *Signature) Bytes() ([]byte, error) {
 	// Parse R and S
-	rBytes := common.FromHex(a.R)
-	sBytes := common.FromHex(a.S)
+	if err := ValidateHex("r", sig.R); err != nil {
+		return nil, err
+	}
+	if err := ValidateHex("s", sig.S); err != nil {
+		return nil, err
+	}
+	rBytes := common.FromHex(sig.R)
+	sBytes := common.FromHex(sig.S)
 
 	if len(rBytes) != 32 {
 		return nil, fmt.Errorf("R must be 32 bytes, got %d", len(rBytes))
@@ -142,17 +273,32 @@ func (a *EIP3009Authorization) GetSignature() ([]byte, error) {
 	copy(signature[32:64], sBytes)
 
 	// Convert v from 27/28 to 0/1 for go-ethereum
-	if a.V == 27 {
+	if sig.V == 27 {
 		signature[64] = 0
-	} else if a.V == 28 {
+	} else if sig.V == 28 {
 		signature[64] = 1
 	} else {
-		return nil, fmt.Errorf("invalid v value: %d", a.V)
+		return nil, fmt.Errorf("invalid v value: %d", sig.V)
 	}
 
 	return signature, nil
 }
 
+// secp256k1HalfN is half the secp256k1 curve order, the largest canonical (low-s) s value
+var secp256k1HalfN = new(big.Int).Rsh(crypto.S256().Params().N, 1)
+
+// IsLowS reports whether the signature's s is in the lower half of the curve order
+// Every ECDSA signature has a high-s twin (N - s, flipped v) that recovers the same signer;
+// EIP-2 and OpenZeppelin's ECDSA reject the high-s form. Malformed s reports false.
+func (sig *Signature) IsLowS() bool {
+	if !bytes32Pattern.MatchString(sig.S) {
+		return false
+	}
+
+	s := new(big.Int).SetBytes(common.FromHex(sig.S))
+	return s.Sign() > 0 && s.Cmp(secp256k1HalfN) <= 0
+}
+
 // ToJSON converts the authorization to JSON
 func (a *EIP3009Authorization) ToJSON() ([]byte, error) {
 	return json.Marshal(a)
@@ -172,5 +318,21 @@ func (v *VerifyPaymentOutput) ToMap() map[string]interface{} {
 		result["error"] = v.Error
 	}
 
+	if v.ErrorCode != "" {
+		result["error_code"] = v.ErrorCode
+	}
+
+	if len(v.Signers) > 0 {
+		result["signers"] = v.Signers
+	}
+
+	if v.LegacyDomain {
+		result["legacy_domain"] = true
+	}
+
+	if v.SignerType != "" {
+		result["signer_type"] = v.SignerType
+	}
+
 	return result
 }
@@ -0,0 +1,129 @@
+package eip3009
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ReceiveWithAuthorizationSelector is the 4-byte function selector of
+// receiveWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)
+var ReceiveWithAuthorizationSelector = []byte{0xef, 0x55, 0xbe, 0xc6}
+
+// receiveWithAuthorizationABI is the USDC (FiatTokenV2) receiveWithAuthorization method
+const receiveWithAuthorizationABI = `[{"name":"receiveWithAuthorization","type":"function","stateMutability":"nonpayable","inputs":[` +
+	`{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},` +
+	`{"name":"validAfter","type":"uint256"},{"name":"validBefore","type":"uint256"},{"name":"nonce","type":"bytes32"},` +
+	`{"name":"v","type":"uint8"},{"name":"r","type":"bytes32"},{"name":"s","type":"bytes32"}],"outputs":[]}]`
+
+// parsedReceiveABI is the parsed receiveWithAuthorization ABI
+var parsedReceiveABI = mustParseABI(receiveWithAuthorizationABI)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI definition: %v", err))
+	}
+	return parsed
+}
+
+// EncodeReceiveWithAuthorization produces the ABI-encoded receiveWithAuthorization calldata
+// for the authorization, suitable for submission to the USDC contract by a relayer
+func EncodeReceiveWithAuthorization(auth *EIP3009Authorization) ([]byte, error) {
+	if err := auth.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid authorization: %w", err)
+	}
+
+	message, err := auth.ToMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	calldata, err := parsedReceiveABI.Pack(
+		"receiveWithAuthorization",
+		message.From,
+		message.To,
+		message.Value,
+		message.ValidAfter,
+		message.ValidBefore,
+		message.Nonce,
+		auth.V,
+		common.HexToHash(auth.R),
+		common.HexToHash(auth.S),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode calldata: %w", err)
+	}
+
+	// Guard against ABI drift: the encoded selector must match the known USDC selector
+	if !bytes.Equal(calldata[:4], ReceiveWithAuthorizationSelector) {
+		return nil, fmt.Errorf("unexpected function selector: 0x%x", calldata[:4])
+	}
+
+	return calldata, nil
+}
+
+// DecodeReceiveWithAuthorization parses receiveWithAuthorization calldata back into an authorization
+func DecodeReceiveWithAuthorization(calldata []byte) (*EIP3009Authorization, error) {
+	if len(calldata) < 4 || !bytes.Equal(calldata[:4], ReceiveWithAuthorizationSelector) {
+		return nil, fmt.Errorf("calldata is not a receiveWithAuthorization call")
+	}
+
+	values, err := parsedReceiveABI.Methods["receiveWithAuthorization"].Inputs.Unpack(calldata[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode calldata: %w", err)
+	}
+
+	nonce := values[5].([32]byte)
+	r := values[7].([32]byte)
+	s := values[8].([32]byte)
+
+	return &EIP3009Authorization{
+		From:        values[0].(common.Address).Hex(),
+		To:          values[1].(common.Address).Hex(),
+		Value:       values[2].(*big.Int).String(),
+		ValidAfter:  values[3].(*big.Int).Uint64(),
+		ValidBefore: values[4].(*big.Int).Uint64(),
+		Nonce:       common.BytesToHash(nonce[:]).Hex(),
+		V:           values[6].(uint8),
+		R:           common.BytesToHash(r[:]).Hex(),
+		S:           common.BytesToHash(s[:]).Hex(),
+	}, nil
+}
+
+// authorizationStateABI is the USDC (FiatTokenV2) authorizationState view
+const authorizationStateABI = `[{"name":"authorizationState","type":"function","stateMutability":"view","inputs":[` +
+	`{"name":"authorizer","type":"address"},{"name":"nonce","type":"bytes32"}],` +
+	`"outputs":[{"name":"","type":"bool"}]}]`
+
+// parsedStateABI is the parsed authorizationState ABI
+var parsedStateABI = mustParseABI(authorizationStateABI)
+
+// EncodeAuthorizationState produces authorizationState(authorizer, nonce) calldata
+func EncodeAuthorizationState(authorizer common.Address, nonce [32]byte) ([]byte, error) {
+	calldata, err := parsedStateABI.Pack("authorizationState", authorizer, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode calldata: %w", err)
+	}
+	return calldata, nil
+}
+
+// DecodeAuthorizationState parses the authorizationState return value
+// true means the nonce was used (or canceled) and can no longer be settled
+func DecodeAuthorizationState(output []byte) (bool, error) {
+	values, err := parsedStateABI.Unpack("authorizationState", output)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode authorizationState result: %w", err)
+	}
+
+	used, ok := values[0].(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected authorizationState result type %T", values[0])
+	}
+
+	return used, nil
+}
@@ -0,0 +1,97 @@
+package eip3009
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CompactProofVersion is the layout version written as the first byte of a compact proof
+const CompactProofVersion byte = 1
+
+// Compact proof layout (big-endian, 187 bytes plus the network name):
+//
+//	version(1) from(20) to(20) value(32) validAfter(8) validBefore(8) nonce(32) v(1) r(32) s(32) networkLen(1) network
+const compactProofFixedSize = 1 + 20 + 20 + 32 + 8 + 8 + 32 + 1 + 32 + 32 + 1
+
+// EncodeCompact encodes a signed authorization and its network as a fixed binary layout
+// Under half the size of the JSON form. Addresses decode EIP-55 checksummed and
+// nonce/r/s as lowercase hex; an EIP-155 chain ID carried by the input v is not encoded.
+func EncodeCompact(auth *EIP3009Authorization, network string) ([]byte, error) {
+	if auth == nil {
+		return nil, fmt.Errorf("authorization is required")
+	}
+	if err := auth.Validate(); err != nil {
+		return nil, err
+	}
+	if network == "" || len(network) > 255 {
+		return nil, fmt.Errorf("network name must be 1-255 bytes")
+	}
+
+	value, ok := new(big.Int).SetString(auth.Value, 10)
+	if !ok || value.BitLen() > 256 {
+		return nil, fmt.Errorf("value does not fit in uint256: %s", auth.Value)
+	}
+
+	buf := make([]byte, 0, compactProofFixedSize+len(network))
+	buf = append(buf, CompactProofVersion)
+	buf = append(buf, common.HexToAddress(auth.From).Bytes()...)
+	buf = append(buf, common.HexToAddress(auth.To).Bytes()...)
+	buf = append(buf, common.BigToHash(value).Bytes()...)
+	buf = binary.BigEndian.AppendUint64(buf, auth.ValidAfter)
+	buf = binary.BigEndian.AppendUint64(buf, auth.ValidBefore)
+	buf = append(buf, common.FromHex(auth.Nonce)...)
+	buf = append(buf, auth.V)
+	buf = append(buf, common.FromHex(auth.R)...)
+	buf = append(buf, common.FromHex(auth.S)...)
+	buf = append(buf, byte(len(network)))
+	buf = append(buf, network...)
+
+	return buf, nil
+}
+
+// DecodeCompact decodes a proof written by EncodeCompact into the authorization and network
+func DecodeCompact(data []byte) (*EIP3009Authorization, string, error) {
+	if len(data) < compactProofFixedSize {
+		return nil, "", fmt.Errorf("compact proof too short: %d bytes", len(data))
+	}
+	if data[0] != CompactProofVersion {
+		return nil, "", fmt.Errorf("unsupported compact proof version %d", data[0])
+	}
+
+	offset := 1
+	next := func(n int) []byte {
+		field := data[offset : offset+n]
+		offset += n
+		return field
+	}
+
+	auth := &EIP3009Authorization{
+		From:        common.BytesToAddress(next(20)).Hex(),
+		To:          common.BytesToAddress(next(20)).Hex(),
+		Value:       new(big.Int).SetBytes(next(32)).String(),
+		ValidAfter:  binary.BigEndian.Uint64(next(8)),
+		ValidBefore: binary.BigEndian.Uint64(next(8)),
+		Nonce:       common.BytesToHash(next(32)).Hex(),
+		V:           next(1)[0],
+		R:           common.BytesToHash(next(32)).Hex(),
+		S:           common.BytesToHash(next(32)).Hex(),
+	}
+
+	networkLen := int(next(1)[0])
+	if len(data)-offset != networkLen {
+		return nil, "", fmt.Errorf("compact proof network length %d does not match remaining %d bytes", networkLen, len(data)-offset)
+	}
+	network := string(next(networkLen))
+	if network == "" {
+		return nil, "", fmt.Errorf("compact proof has no network")
+	}
+
+	if err := auth.Validate(); err != nil {
+		return nil, "", fmt.Errorf("invalid compact proof: %w", err)
+	}
+
+	return auth, network, nil
+}
@@ -0,0 +1,138 @@
+package eip3009
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+)
+
+// Signer types reported in VerifyPaymentOutput.SignerType
+const (
+	SignerTypeEOA      = "eoa"      // Signature recovered to from via ECDSA
+	SignerTypeContract = "contract" // from is a contract wallet that accepted the signature via EIP-1271
+)
+
+// ErrorCodeContractSignature is returned when a contract wallet payer rejects the signature
+const ErrorCodeContractSignature = "invalid_contract_signature"
+
+// EIP1271MagicValue is what isValidSignature(bytes32,bytes) returns for a valid signature
+var EIP1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// ContractCallTimeout bounds the code lookups and isValidSignature call of one verification
+const ContractCallTimeout = 10 * time.Second
+
+// isValidSignatureABI is the EIP-1271 isValidSignature method
+const isValidSignatureABI = `[{"name":"isValidSignature","type":"function","stateMutability":"view","inputs":[` +
+	`{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],` +
+	`"outputs":[{"name":"magicValue","type":"bytes4"}]}]`
+
+// parsedIsValidSignatureABI is the parsed isValidSignature ABI
+var parsedIsValidSignatureABI = mustParseABI(isValidSignatureABI)
+
+// ContractReader is the subset of the Ethereum RPC client used for EIP-1271 verification
+type ContractReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// SetContractReader overrides the RPC backend used for EIP-1271 checks on a network
+func (v *SignatureVerifier) SetContractReader(network string, reader ContractReader) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.contracts[network] = reader
+}
+
+// contractReader returns the RPC backend for a network, dialing its rpc_url on first use
+func (v *SignatureVerifier) contractReader(network string) (ContractReader, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if reader, exists := v.contracts[network]; exists {
+		return reader, nil
+	}
+
+	networkCfg, exists := v.config.Networks[network]
+	if !exists {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	client, err := rpc.DialEthClient(context.Background(), networkCfg.RPCURL, v.config.AllowPrivateURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	v.contracts[network] = client
+	return client, nil
+}
+
+// verifyContractSignature checks the authorization against from's EIP-1271 isValidSignature
+// when from has contract code. It returns nil, nil for a payer without code (an EOA), a
+// result for a contract wallet, and an error when the chain could not be queried.
+func (v *SignatureVerifier) verifyContractSignature(network string, typedDataHash common.Hash, auth *EIP3009Authorization) (*VerifyPaymentOutput, error) {
+	reader, err := v.contractReader(network)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ContractCallTimeout)
+	defer cancel()
+
+	from := common.HexToAddress(auth.From)
+	code, err := reader.CodeAt(ctx, from, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read code of %s: %w", from.Hex(), err)
+	}
+	if len(code) == 0 {
+		return nil, nil
+	}
+
+	signature, err := auth.GetSignature()
+	if err != nil {
+		return &VerifyPaymentOutput{
+			IsValid: false,
+			Error:   fmt.Sprintf("failed to parse signature: %v", err),
+		}, nil
+	}
+
+	// Contract wallets expect the Ethereum r || s || v layout with v as 27/28
+	signature[64] += 27
+	calldata, err := parsedIsValidSignatureABI.Pack("isValidSignature", typedDataHash, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode isValidSignature: %w", err)
+	}
+
+	output, err := reader.CallContract(ctx, ethereum.CallMsg{To: &from, Data: calldata}, nil)
+	if err != nil && !isExecutionReverted(err) {
+		return nil, fmt.Errorf("isValidSignature call failed: %w", err)
+	}
+
+	// A revert, a short return, or any value but the magic value rejects the signature
+	if err != nil || len(output) < 4 || [4]byte(output[:4]) != EIP1271MagicValue {
+		return &VerifyPaymentOutput{
+			IsValid:       false,
+			SignerAddress: from.Hex(),
+			SignerType:    SignerTypeContract,
+			Error:         fmt.Sprintf("contract wallet %s rejected the signature (EIP-1271)", from.Hex()),
+			ErrorCode:     ErrorCodeContractSignature,
+		}, nil
+	}
+
+	return &VerifyPaymentOutput{
+		IsValid:       true,
+		SignerAddress: from.Hex(),
+		SignerType:    SignerTypeContract,
+	}, nil
+}
+
+// isExecutionReverted reports whether an eth_call failed because the call reverted, as
+// opposed to the node being unreachable; nodes report reverts as "execution reverted"
+func isExecutionReverted(err error) bool {
+	return strings.Contains(err.Error(), "execution reverted")
+}
@@ -0,0 +1,55 @@
+package eip3009
+
+import (
+	"fmt"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+)
+
+// EIP-155 v error codes
+const (
+	ErrorCodeEIP155VRejected = "eip155_v_rejected" // v embeds a chain ID but verification.eip155_v is "reject"
+	ErrorCodeChainMismatch   = "chain_mismatch"    // v embeds a chain ID other than the network's
+)
+
+// DecodeV returns v in the 27/28 convention and, for an EIP-155 encoded v
+// (chainId*2 + 35 or 36), the chain ID it embeds; chainID is 0 for a plain 27/28
+func DecodeV(v uint64) (uint8, uint64, error) {
+	switch {
+	case v == 27 || v == 28:
+		return uint8(v), 0, nil
+	case v >= 37:
+		return uint8(27 + (v-35)%2), (v - 35) / 2, nil
+	default:
+		return 0, 0, fmt.Errorf("v must be 27, 28, or EIP-155 encoded (chainId*2 + 35/36), got %d", v)
+	}
+}
+
+// checkEIP155 applies verification.eip155_v to a signature whose v embedded chainID
+// (0 = plain 27/28); a non-nil output reports a rejection
+func (v *SignatureVerifier) checkEIP155(chainID uint64, network string) *VerifyPaymentOutput {
+	if chainID == 0 {
+		return nil
+	}
+
+	cfg := v.currentConfig()
+	switch cfg.Verification.EIP155V {
+	case config.EIP155VAccept:
+		return nil
+	case config.EIP155VMatchChain:
+		if networkCfg, exists := cfg.Networks[network]; exists && networkCfg.ChainID != chainID {
+			return &VerifyPaymentOutput{
+				IsValid:   false,
+				Error:     fmt.Sprintf("v encodes chain_id %d but network %s is chain_id %d", chainID, network, networkCfg.ChainID),
+				ErrorCode: ErrorCodeChainMismatch,
+			}
+		}
+		return nil
+	default:
+		return &VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     fmt.Sprintf("EIP-155 encoded v (chain_id %d) is not accepted: v must be 27 or 28", chainID),
+			ErrorCode: ErrorCodeEIP155VRejected,
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package eip3009
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+)
+
+// DecodeBytes32 decodes a nonce or signature component given as 0x-prefixed hex or
+// standard padded base64
+func DecodeBytes32(encoded string) ([32]byte, error) {
+	var value [32]byte
+
+	var decoded []byte
+	var err error
+	if strings.HasPrefix(encoded, "0x") || strings.HasPrefix(encoded, "0X") {
+		decoded, err = hexutil.Decode("0x" + encoded[2:])
+	} else {
+		decoded, err = base64.StdEncoding.Strict().DecodeString(encoded)
+	}
+	if err != nil || len(decoded) != len(value) {
+		return value, fmt.Errorf("must be 0x-prefixed 32-byte hex or base64")
+	}
+
+	copy(value[:], decoded)
+	return value, nil
+}
+
+// NormalizeBytes32 returns a nonce or signature component as 0x-prefixed hex
+// Hex input is returned unchanged so format validation reports it as given; base64 input is
+// converted, and rejected unless it decodes to exactly 32 bytes.
+func NormalizeBytes32(encoded string) (string, error) {
+	if strings.HasPrefix(encoded, "0x") {
+		return encoded, nil
+	}
+
+	value, err := DecodeBytes32(encoded)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(value[:]), nil
+}
+
+// ValidateHex checks that a nonce or signature field is 0x-prefixed and holds only hex digits
+// common.FromHex silently decodes malformed input to partial bytes, which otherwise surfaces as
+// a misleading length error; this names the field and the offending character instead.
+func ValidateHex(field, value string) error {
+	if !strings.HasPrefix(value, "0x") && !strings.HasPrefix(value, "0X") {
+		return &ValidationError{Field: field, Reason: "invalid hex: must be 0x-prefixed"}
+	}
+
+	for i, c := range value[2:] {
+		if !isHexDigit(c) {
+			return &ValidationError{Field: field, Reason: fmt.Sprintf("invalid hex: character %q at position %d", c, i+2)}
+		}
+	}
+
+	return nil
+}
+
+// isHexDigit reports whether c is 0-9, a-f, or A-F
+func isHexDigit(c rune) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// EncodeBytes32 renders a nonce or signature component in a display.byte_encoding ("" means hex)
+func EncodeBytes32(value [32]byte, encoding string) string {
+	if encoding == config.ByteEncodingBase64 {
+		return base64.StdEncoding.EncodeToString(value[:])
+	}
+	return hexutil.Encode(value[:])
+}
@@ -0,0 +1,70 @@
+package eip3009
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// recoverLegacySigner recovers the authorization's signer and typed data hash under the network's
+// pre-migration EIP-712 domain; ok is false when no migration window is open or recovery fails
+func (v *SignatureVerifier) recoverLegacySigner(auth *EIP3009Authorization, network string) (common.Address, common.Hash, bool) {
+	legacy, open := v.currentConfig().LegacyDomainParams(network, time.Now())
+	if !open {
+		return common.Address{}, common.Hash{}, false
+	}
+
+	typedDataHash, failure := v.prepare(auth, network, &legacy)
+	if failure != nil {
+		return common.Address{}, common.Hash{}, false
+	}
+
+	signer, failure := recoverSigner(typedDataHash, auth)
+	if failure != nil {
+		return common.Address{}, common.Hash{}, false
+	}
+
+	return signer, typedDataHash, true
+}
+
+// UpgradeAuthorization re-binds a legacy authorization to the network's current EIP-712 domain
+// The original message (from, to, value, time bounds, nonce) is kept and signature replaces its
+// v/r/s; the payer re-signs that same message under the new domain. The upgraded authorization
+// is returned only if the new signature recovers to the payer under the current domain.
+func (v *SignatureVerifier) UpgradeAuthorization(
+	original *EIP3009Authorization,
+	signature Signature,
+	network string,
+) (*EIP3009Authorization, error) {
+	if original == nil {
+		return nil, fmt.Errorf("original authorization is required")
+	}
+	if err := original.ValidateMessage(); err != nil {
+		return nil, fmt.Errorf("invalid original authorization: %w", err)
+	}
+	if err := signature.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	upgraded := *original
+	upgraded.V = signature.V
+	upgraded.R = signature.R
+	upgraded.S = signature.S
+
+	typedDataHash, failure := v.prepare(&upgraded, network, nil)
+	if failure != nil {
+		return nil, fmt.Errorf("cannot upgrade authorization: %s", failure.Error)
+	}
+
+	signer, failure := recoverSigner(typedDataHash, &upgraded)
+	if failure != nil {
+		return nil, fmt.Errorf("cannot upgrade authorization: %s", failure.Error)
+	}
+
+	if expected := common.HexToAddress(upgraded.From); signer != expected {
+		return nil, fmt.Errorf("new signature is not from %s under the current domain (recovered %s)", expected.Hex(), signer.Hex())
+	}
+
+	return &upgraded, nil
+}
@@ -0,0 +1,127 @@
+package eip3009
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+)
+
+// VerifyMultisigAuthorization verifies M-of-N owner signatures over the authorization's typed data
+// The payer ("from") must have a configured multisig policy; the authorization's own v/r/s are
+// ignored. Signatures from non-owners are disregarded, a repeated owner is rejected, and the
+// result is valid only when the number of distinct owners meets the policy threshold.
+// EIP-1271 contract-wallet delegation is not consulted.
+func (v *SignatureVerifier) VerifyMultisigAuthorization(
+	auth *EIP3009Authorization,
+	signatures []Signature,
+	network string,
+) (*VerifyPaymentOutput, error) {
+	return v.VerifyMultisigAuthorizationWithDomain(auth, signatures, network, nil)
+}
+
+// VerifyMultisigAuthorizationWithDomain verifies like VerifyMultisigAuthorization, but against
+// the given EIP-712 name/version instead of the configured domain (nil uses the configured one)
+func (v *SignatureVerifier) VerifyMultisigAuthorizationWithDomain(
+	auth *EIP3009Authorization,
+	signatures []Signature,
+	network string,
+	params *config.DomainParams,
+) (*VerifyPaymentOutput, error) {
+	// Step 1: Input validation
+	if err := auth.ValidateMessage(); err != nil {
+		return validationFailure(err), nil
+	}
+
+	// Step 2: Resolve the payer's multisig policy
+	policy, exists := v.currentConfig().Verification.MultisigPolicy(auth.From)
+	if !exists {
+		return &VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     fmt.Sprintf("no multisig policy configured for %s", auth.From),
+			ErrorCode: ErrorCodeMultisigNotConfigured,
+		}, nil
+	}
+
+	owners := make(map[string]bool, len(policy.Owners))
+	for _, owner := range policy.Owners {
+		owners[strings.ToLower(owner)] = true
+	}
+
+	// Step 3: Checksum, domain, and time bound checks; compute typed data hash
+	typedDataHash, failure := v.prepare(auth, network, params)
+	if failure != nil {
+		return failure, nil
+	}
+
+	// Step 4: Recover each signer and count distinct authorized owners
+	signers := make([]string, 0, len(signatures))
+	seen := make(map[string]bool, len(signatures))
+	for i := range signatures {
+		if err := signatures[i].Validate(); err != nil {
+			return &VerifyPaymentOutput{
+				IsValid: false,
+				Error:   fmt.Sprintf("signatures[%d]: %v", i, err),
+			}, nil
+		}
+
+		if failure := v.checkEIP155(signatures[i].EIP155ChainID, network); failure != nil {
+			failure.Error = fmt.Sprintf("signatures[%d]: %s", i, failure.Error)
+			return failure, nil
+		}
+
+		signature, err := signatures[i].Bytes()
+		if err != nil {
+			return &VerifyPaymentOutput{
+				IsValid: false,
+				Error:   fmt.Sprintf("signatures[%d]: failed to parse signature: %v", i, err),
+			}, nil
+		}
+
+		recoveredPubKey, err := crypto.SigToPub(typedDataHash.Bytes(), signature)
+		if err != nil {
+			return &VerifyPaymentOutput{
+				IsValid: false,
+				Error:   fmt.Sprintf("signatures[%d]: failed to recover public key: %v", i, err),
+			}, nil
+		}
+
+		signerAddress := crypto.PubkeyToAddress(*recoveredPubKey)
+		signer := signerAddress.Hex()
+		key := strings.ToLower(signer)
+
+		if seen[key] {
+			return &VerifyPaymentOutput{
+				IsValid:   false,
+				Error:     fmt.Sprintf("duplicate signature from %s", signer),
+				ErrorCode: ErrorCodeDuplicateSigner,
+			}, nil
+		}
+		seen[key] = true
+
+		if failure := v.checkRValue(network, signerAddress, signatures[i].R, typedDataHash); failure != nil {
+			return failure, nil
+		}
+
+		if owners[key] {
+			signers = append(signers, signer)
+		}
+	}
+
+	// Step 5: Enforce threshold
+	if len(signers) < policy.Threshold {
+		return &VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     fmt.Sprintf("insufficient signatures: %d of %d required owners signed", len(signers), policy.Threshold),
+			ErrorCode: ErrorCodeInsufficientSignatures,
+			Signers:   signers,
+		}, nil
+	}
+
+	return &VerifyPaymentOutput{
+		IsValid:       true,
+		SignerAddress: auth.From,
+		Signers:       signers,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package eip3009
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrorCodePayeeNotAllowlisted marks a contract payee missing from verification.payee_allowlist
+// It is a policy failure: the authorization itself may be perfectly valid.
+const ErrorCodePayeeNotAllowlisted = "payee_not_allowlisted"
+
+// PayeeNotAllowlisted reports why the authorization's payee violates verification.payee_allowlist,
+// or "" when the allowlist is disabled, the payee is listed, or the payee has no contract code.
+// Only contract payees are checked, so an EOA payee costs one code lookup and is always accepted.
+func (v *SignatureVerifier) PayeeNotAllowlisted(network string, to string) (string, error) {
+	verification := &v.currentConfig().Verification
+	if !verification.ChecksPayeeAllowlist() || verification.AllowlistsPayee(to) {
+		return "", nil
+	}
+
+	reader, err := v.contractReader(network)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ContractCallTimeout)
+	defer cancel()
+
+	payee := common.HexToAddress(to)
+	code, err := reader.CodeAt(ctx, payee, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to read code of %s: %w", payee.Hex(), err)
+	}
+	if len(code) == 0 {
+		return "", nil
+	}
+
+	return fmt.Sprintf("payee %s is a contract not on verification.payee_allowlist", payee.Hex()), nil
+}
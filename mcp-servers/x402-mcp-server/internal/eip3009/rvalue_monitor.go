@@ -0,0 +1,84 @@
+package eip3009
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/cache"
+)
+
+// ErrorCodeRValueReused is returned when verification.r_value_reuse is "block" and a signer
+// reuses an r value; the key must be considered compromised
+const ErrorCodeRValueReused = "r_value_reused"
+
+// MetricRValueReuse counts detected r value reuse, labelled by network
+const MetricRValueReuse = "x402_signature_r_reuse_total"
+
+// RValueWindow bounds how long observed (signer, r) pairs are remembered
+const RValueWindow = 24 * time.Hour
+
+// RValueReuse describes two different messages signed by one key with the same r value
+type RValueReuse struct {
+	Network       string
+	Signer        string
+	R             string
+	FirstMessage  common.Hash // Typed data hash signed first
+	SecondMessage common.Hash // Typed data hash that reused r
+}
+
+// RValueReuseHook is notified of each detected reuse
+type RValueReuseHook func(reuse RValueReuse)
+
+// RValueMonitor records (signer, r) pairs of verified signatures and detects a signer
+// reusing r for a different message, which leaks the signer's private key
+type RValueMonitor struct {
+	mu   sync.Mutex
+	seen *cache.TTLCache // signer:r -> first typed data hash
+	hook RValueReuseHook
+}
+
+// NewRValueMonitor creates a monitor remembering pairs for window
+func NewRValueMonitor(window time.Duration) *RValueMonitor {
+	return &RValueMonitor{seen: cache.NewTTLCache(window)}
+}
+
+// OnReuse installs a hook called for every detected reuse
+func (m *RValueMonitor) OnReuse(hook RValueReuseHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hook = hook
+}
+
+// Observe records that signer produced r over typedDataHash and returns the reuse, or nil
+// A repeat of the same message (e.g. verify then settle) is not a reuse.
+func (m *RValueMonitor) Observe(network string, signer common.Address, r string, typedDataHash common.Hash) *RValueReuse {
+	key := strings.ToLower(signer.Hex()) + ":" + strings.ToLower(r)
+
+	m.mu.Lock()
+	first, found := m.seen.Get(key)
+	if !found {
+		m.seen.Set(key, typedDataHash)
+	}
+	hook := m.hook
+	m.mu.Unlock()
+
+	if !found || first.(common.Hash) == typedDataHash {
+		return nil
+	}
+
+	reuse := &RValueReuse{
+		Network:       network,
+		Signer:        signer.Hex(),
+		R:             r,
+		FirstMessage:  first.(common.Hash),
+		SecondMessage: typedDataHash,
+	}
+	if hook != nil {
+		hook(*reuse)
+	}
+
+	return reuse
+}
@@ -1,149 +1,507 @@
 package eip3009
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/cache"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
 )
 
+// VerificationResultTTL bounds how long successful verification results are reused
+const VerificationResultTTL = 10 * time.Minute
+
 // SignatureVerifier handles EIP-3009 signature verification
 type SignatureVerifier struct {
-	config *config.Config
+	mu      sync.RWMutex
+	config  *config.Config
+	domains map[string]*EIP712Domain // Per-network EIP-712 domains
+	results cache.Store              // Successful verifications keyed by network:hash:signature
+	rValues *RValueMonitor           // Shared r value reuse monitor (nil = not monitored)
+
+	contracts map[string]ContractReader // Per-network RPC backends for EIP-1271 checks, dialed on first use
 }
 
 // NewSignatureVerifier creates a new signature verifier
 func NewSignatureVerifier(cfg *config.Config) *SignatureVerifier {
 	return &SignatureVerifier{
-		config: cfg,
+		config:    cfg,
+		domains:   make(map[string]*EIP712Domain),
+		results:   cache.NewMemoryStore(VerificationResultTTL),
+		contracts: make(map[string]ContractReader),
+	}
+}
+
+// OnCacheEvict installs a hook observing the age of verification results as they leave the cache
+// Only the in-process store reports evictions; a shared store is observed by its own backend.
+func (v *SignatureVerifier) OnCacheEvict(hook cache.EvictionHook) {
+	if memory, ok := v.resultStore().(*cache.MemoryStore); ok {
+		memory.OnEvict(hook)
+	}
+}
+
+// UseResultStore keeps successful verification results in store instead of in process
+// Replicas sharing one store reuse each other's verifications.
+func (v *SignatureVerifier) UseResultStore(store cache.Store) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.results = store
+}
+
+// resultStore returns the store holding successful verification results
+func (v *SignatureVerifier) resultStore() cache.Store {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.results
+}
+
+// cachedResult returns a previously stored successful verification
+func (v *SignatureVerifier) cachedResult(key string) (*VerifyPaymentOutput, bool) {
+	data, found := v.resultStore().Get(key)
+	if !found {
+		return nil, false
+	}
+
+	var result VerifyPaymentOutput
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+// storeResult records a successful verification for reuse
+func (v *SignatureVerifier) storeResult(key string, result *VerifyPaymentOutput) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	v.resultStore().Set(key, data, VerificationResultTTL)
+}
+
+// PreviouslyVerified reports whether this authorization (same typed data and signature) was
+// successfully verified within the result TTL, by this verifier or any sharing its store
+func (v *SignatureVerifier) PreviouslyVerified(auth *EIP3009Authorization, network string, params *config.DomainParams) bool {
+	typedDataHash, failure := v.prepare(auth, network, params)
+	if failure != nil {
+		return false
+	}
+
+	_, found := v.cachedResult(resultCacheKey(network, typedDataHash, auth))
+	return found
+}
+
+// UseRValueMonitor records verified signatures in m for r value reuse detection
+// Monitoring is active only while verification.r_value_reuse is "alert" or "block".
+func (v *SignatureVerifier) UseRValueMonitor(m *RValueMonitor) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.rValues = m
+}
+
+// checkRValue records a verified signature's r value; a non-nil output reports a
+// reuse that verification.r_value_reuse "block" rejects
+func (v *SignatureVerifier) checkRValue(network string, signer common.Address, r string, typedDataHash common.Hash) *VerifyPaymentOutput {
+	v.mu.RLock()
+	monitor, verification := v.rValues, v.config.Verification
+	v.mu.RUnlock()
+
+	if monitor == nil || !verification.MonitorsRValues() {
+		return nil
+	}
+
+	reuse := monitor.Observe(network, signer, r, typedDataHash)
+	if reuse == nil || verification.RValueReuse != config.RValueReuseBlock {
+		return nil
+	}
+
+	return &VerifyPaymentOutput{
+		IsValid:       false,
+		SignerAddress: signer.Hex(),
+		Error:         fmt.Sprintf("signature r value reused by %s for a different message: signing key is compromised", signer.Hex()),
+		ErrorCode:     ErrorCodeRValueReused,
 	}
 }
 
+// UpdateConfig swaps the verifier configuration after a config reload
+// Cached domains and verification results are flushed for every network whose
+// EIP-712 domain changed, so later verifications recompute against new parameters
+func (v *SignatureVerifier) UpdateConfig(cfg *config.Config) []string {
+	v.mu.Lock()
+	changed := config.DomainChangedNetworks(v.config, cfg)
+	v.config = cfg
+	for _, network := range changed {
+		delete(v.domains, network)
+	}
+	v.mu.Unlock()
+
+	for _, network := range changed {
+		v.resultStore().DeletePrefix(network + ":")
+	}
+
+	return changed
+}
+
+// currentConfig returns the configuration currently in effect
+func (v *SignatureVerifier) currentConfig() *config.Config {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.config
+}
+
+// domain returns the EIP-712 domain for a network, building and caching it on first use
+func (v *SignatureVerifier) domain(network string) (*EIP712Domain, error) {
+	v.mu.RLock()
+	cached, exists := v.domains[network]
+	cfg := v.config
+	v.mu.RUnlock()
+
+	if exists {
+		return cached, nil
+	}
+
+	params, err := cfg.DomainParams(network)
+	if err != nil {
+		return nil, err
+	}
+	networkCfg := cfg.Networks[network]
+
+	domain := &EIP712Domain{
+		Name:              params.Name,
+		Version:           params.Version,
+		ChainID:           new(big.Int).SetUint64(networkCfg.ChainID),
+		VerifyingContract: common.HexToAddress(networkCfg.USDCContract),
+	}
+
+	// Only cache if the config was not swapped while building the domain
+	v.mu.Lock()
+	if v.config == cfg {
+		v.domains[network] = domain
+	}
+	v.mu.Unlock()
+
+	return domain, nil
+}
+
+// domainFor returns the network's EIP-712 domain, or when params is non-nil a domain built
+// from those name/version values with the network's chain ID and verifying contract
+// Overridden domains come from caller-supplied requirements and are never cached.
+func (v *SignatureVerifier) domainFor(network string, params *config.DomainParams) (*EIP712Domain, error) {
+	if params == nil {
+		return v.domain(network)
+	}
+
+	networkCfg, exists := v.currentConfig().Networks[network]
+	if !exists {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	return &EIP712Domain{
+		Name:              params.Name,
+		Version:           params.Version,
+		ChainID:           new(big.Int).SetUint64(networkCfg.ChainID),
+		VerifyingContract: common.HexToAddress(networkCfg.USDCContract),
+	}, nil
+}
+
+// resultCacheKey identifies a verification by network, typed data hash, and signature
+func resultCacheKey(network string, typedDataHash common.Hash, auth *EIP3009Authorization) string {
+	return fmt.Sprintf("%s:%s:%s:%s:%d", network, typedDataHash.Hex(),
+		strings.ToLower(auth.R), strings.ToLower(auth.S), auth.V)
+}
+
 // VerifyAuthorization performs complete signature verification including:
 // - Input validation
 // - EIP-712 domain matching
 // - Signature recovery via secp256k1 ECDSA
-// - Time bound validation
-// - Signer address verification
+// - Time bound and maximum age validation
+// - Signer address verification, via EIP-1271 for contract wallets when verification.eip1271 is set
 func (v *SignatureVerifier) VerifyAuthorization(
 	auth *EIP3009Authorization,
 	network string,
+) (*VerifyPaymentOutput, error) {
+	return v.VerifyAuthorizationWithDomain(auth, network, nil)
+}
+
+// VerifyAuthorizationWithDomain verifies like VerifyAuthorization, but against the given
+// EIP-712 name/version (e.g. a requirement's extra fields) instead of the configured domain
+// A nil params uses the configured domain.
+func (v *SignatureVerifier) VerifyAuthorizationWithDomain(
+	auth *EIP3009Authorization,
+	network string,
+	params *config.DomainParams,
 ) (*VerifyPaymentOutput, error) {
 	// Step 1: Input validation
 	if err := auth.Validate(); err != nil {
-		return &VerifyPaymentOutput{
-			IsValid: false,
-			Error:   fmt.Sprintf("validation failed: %v", err),
-		}, nil
+		return validationFailure(err), nil
 	}
 
-	// Step 2: Get network configuration
-	networkCfg, exists := v.config.Networks[network]
-	if !exists {
-		return &VerifyPaymentOutput{
-			IsValid: false,
-			Error:   fmt.Sprintf("unsupported network: %s", network),
-		}, nil
+	// Step 2: Checksum, domain, and time bound checks; compute typed data hash
+	typedDataHash, failure := v.prepare(auth, network, params)
+	if failure != nil {
+		return failure, nil
 	}
 
-	// Step 3: Time bound validation
-	currentTime := time.Now().Unix()
-	if currentTime < int64(auth.ValidAfter) {
-		return &VerifyPaymentOutput{
-			IsValid: false,
-			Error:   fmt.Sprintf("authorization not yet valid: current=%d, validAfter=%d", currentTime, auth.ValidAfter),
-		}, nil
+	// Step 3: Reuse a previous successful verification of the same typed data and signature
+	cacheKey := resultCacheKey(network, typedDataHash, auth)
+	if cached, found := v.cachedResult(cacheKey); found {
+		return cached, nil
 	}
-	if currentTime >= int64(auth.ValidBefore) {
-		return &VerifyPaymentOutput{
-			IsValid: false,
-			Error:   fmt.Sprintf("authorization expired: current=%d, validBefore=%d", currentTime, auth.ValidBefore),
-		}, nil
+
+	// Step 4: Recover the signer address from the signature
+	signerAddress, failure := recoverSigner(typedDataHash, auth)
+	if failure != nil {
+		return failure, nil
 	}
 
-	// Step 4: Create EIP-712 domain separator
-	domain := &EIP712Domain{
-		Name:              v.config.EIP712.DomainName,
-		Version:           v.config.EIP712.DomainVersion,
-		ChainID:           big.NewInt(int64(networkCfg.ChainID)),
-		VerifyingContract: common.HexToAddress(networkCfg.USDCContract),
+	// Step 5: While a domain migration window is open, accept a signature under the legacy domain
+	expectedFrom := common.HexToAddress(auth.From)
+	if signerAddress != expectedFrom && params == nil {
+		if legacyAddress, legacyHash, ok := v.recoverLegacySigner(auth, network); ok && legacyAddress == expectedFrom {
+			if failure := v.checkRValue(network, legacyAddress, auth.R, legacyHash); failure != nil {
+				return failure, nil
+			}
+
+			// Not cached: acceptance must end as soon as the migration window closes
+			return &VerifyPaymentOutput{
+				IsValid:       true,
+				SignerAddress: legacyAddress.Hex(),
+				LegacyDomain:  true,
+				SignerType:    SignerTypeEOA,
+			}, nil
+		}
 	}
 
-	// Step 5: Convert authorization to message
-	message, err := auth.ToMessage()
-	if err != nil {
+	// Step 6: A 'from' with contract code is a smart contract wallet; it validates the
+	// signature itself via EIP-1271 (checked only after ECDSA recovery fails to match, so
+	// EOA payers cost no RPC call)
+	if signerAddress != expectedFrom && v.currentConfig().Verification.EIP1271 {
+		result, err := v.verifyContractSignature(network, typedDataHash, auth)
+		if err != nil {
+			return nil, fmt.Errorf("EIP-1271 verification: %w", err)
+		}
+		if result != nil {
+			if result.IsValid {
+				v.storeResult(cacheKey, result)
+			}
+			return result, nil
+		}
+	}
+
+	// Step 7: Verify signer matches 'from' address
+	if signerAddress != expectedFrom {
 		return &VerifyPaymentOutput{
-			IsValid: false,
-			Error:   fmt.Sprintf("failed to convert authorization: %v", err),
+			IsValid:       false,
+			SignerAddress: signerAddress.Hex(),
+			SignerType:    SignerTypeEOA,
+			Error:         fmt.Sprintf("signer mismatch: expected %s, got %s", expectedFrom.Hex(), signerAddress.Hex()),
 		}, nil
 	}
 
-	// Step 6: Compute EIP-712 typed data hash
-	typedDataHash, err := TypedDataHash(domain, message)
-	if err != nil {
+	// Step 8: Detect the signer reusing this r value for a different message
+	if failure := v.checkRValue(network, signerAddress, auth.R, typedDataHash); failure != nil {
+		return failure, nil
+	}
+
+	// All checks passed
+	result := &VerifyPaymentOutput{
+		IsValid:       true,
+		SignerAddress: signerAddress.Hex(),
+		SignerType:    SignerTypeEOA,
+	}
+
+	v.storeResult(cacheKey, result)
+
+	return result, nil
+}
+
+// validationFailure reports an authorization that failed input validation
+// An inverted validity window gets its own error code: it is invalid at any time, so unlike
+// not_yet_valid or expired it cannot be caused by clock skew between signer and server.
+func validationFailure(err error) *VerifyPaymentOutput {
+	if errors.Is(err, ErrInvertedWindow) {
 		return &VerifyPaymentOutput{
-			IsValid: false,
-			Error:   fmt.Sprintf("failed to compute typed data hash: %v", err),
-		}, nil
+			IsValid:   false,
+			Error:     fmt.Sprintf("authorization can never be valid: %v", err),
+			ErrorCode: ErrorCodeInvertedWindow,
+		}
+	}
+
+	return &VerifyPaymentOutput{
+		IsValid: false,
+		Error:   fmt.Sprintf("validation failed: %v", err),
 	}
+}
 
-	// Step 7: Get signature bytes
+// recoverSigner recovers the address that signed typedDataHash with the authorization's v/r/s;
+// a non-nil output reports a failure
+func recoverSigner(typedDataHash common.Hash, auth *EIP3009Authorization) (common.Address, *VerifyPaymentOutput) {
 	signature, err := auth.GetSignature()
 	if err != nil {
-		return &VerifyPaymentOutput{
+		return common.Address{}, &VerifyPaymentOutput{
 			IsValid: false,
 			Error:   fmt.Sprintf("failed to parse signature: %v", err),
-		}, nil
+		}
 	}
 
-	// Step 8: Recover public key from signature
 	recoveredPubKey, err := crypto.SigToPub(typedDataHash.Bytes(), signature)
 	if err != nil {
-		return &VerifyPaymentOutput{
+		return common.Address{}, &VerifyPaymentOutput{
 			IsValid: false,
 			Error:   fmt.Sprintf("failed to recover public key: %v", err),
-		}, nil
+		}
 	}
 
-	// Step 9: Derive signer address from recovered public key
-	signerAddress := crypto.PubkeyToAddress(*recoveredPubKey)
+	return crypto.PubkeyToAddress(*recoveredPubKey), nil
+}
 
-	// Step 10: Verify signer matches 'from' address
-	expectedFrom := common.HexToAddress(auth.From)
-	if signerAddress != expectedFrom {
-		return &VerifyPaymentOutput{
-			IsValid:       false,
-			SignerAddress: signerAddress.Hex(),
-			Error:         fmt.Sprintf("signer mismatch: expected %s, got %s", expectedFrom.Hex(), signerAddress.Hex()),
-		}, nil
+// prepare runs the checks shared by single and multisig verification and returns
+// the authorization's EIP-712 typed data hash; a non-nil output reports a failure
+func (v *SignatureVerifier) prepare(
+	auth *EIP3009Authorization,
+	network string,
+	params *config.DomainParams,
+) (common.Hash, *VerifyPaymentOutput) {
+	// Step 1: Optional EIP-55 checksum enforcement for mixed-case addresses
+	if v.currentConfig().Verification.RequireChecksum {
+		for _, address := range []string{auth.From, auth.To} {
+			if err := ValidateChecksum(address); err != nil {
+				return common.Hash{}, &VerifyPaymentOutput{
+					IsValid:   false,
+					Error:     err.Error(),
+					ErrorCode: ErrorCodeBadChecksum,
+				}
+			}
+		}
 	}
 
-	// All checks passed
-	return &VerifyPaymentOutput{
-		IsValid:       true,
-		SignerAddress: signerAddress.Hex(),
-	}, nil
+	// Step 2: Optional strict profile for fields that are optional on-chain
+	if v.currentConfig().Verification.Strict() {
+		if err := auth.ValidateStrict(); err != nil {
+			return common.Hash{}, &VerifyPaymentOutput{
+				IsValid:   false,
+				Error:     err.Error(),
+				ErrorCode: ErrorCodeStrictProfile,
+			}
+		}
+	}
+
+	// Step 3: Optional rejection of predictable nonces (warn mode is reported by the tools)
+	if verification := &v.currentConfig().Verification; verification.WeakNonce == config.WeakNonceReject {
+		if reason := WeakNonceReason(auth.Nonce, verification.WeakNonceThreshold()); reason != "" {
+			return common.Hash{}, &VerifyPaymentOutput{
+				IsValid:   false,
+				Error:     "weak nonce: " + reason,
+				ErrorCode: ErrorCodeWeakNonce,
+			}
+		}
+	}
+
+	// Step 4: Apply verification.eip155_v to a chain ID embedded in v
+	if failure := v.checkEIP155(auth.EIP155ChainID, network); failure != nil {
+		return common.Hash{}, failure
+	}
+
+	// Step 5: Resolve the network's (or the overriding) EIP-712 domain
+	domain, err := v.domainFor(network, params)
+	if err != nil {
+		return common.Hash{}, &VerifyPaymentOutput{
+			IsValid: false,
+			Error:   err.Error(),
+		}
+	}
+
+	// Step 6: Time bound validation (the window itself is well-formed; see validationFailure)
+	// A zero validAfter is always in the past, so it is valid immediately; note that it also
+	// always exceeds max_authorization_age_seconds when that limit is set
+	currentTime := time.Now().Unix()
+	if currentTime < int64(auth.ValidAfter) {
+		return common.Hash{}, &VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     fmt.Sprintf("authorization not yet valid: current=%d is %ds before validAfter=%d", currentTime, int64(auth.ValidAfter)-currentTime, auth.ValidAfter),
+			ErrorCode: ErrorCodeNotYetValid,
+		}
+	}
+	if currentTime >= int64(auth.ValidBefore) {
+		return common.Hash{}, &VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     fmt.Sprintf("authorization expired: current=%d is %ds past validBefore=%d", currentTime, currentTime-int64(auth.ValidBefore), auth.ValidBefore),
+			ErrorCode: ErrorCodeExpired,
+		}
+	}
+	if maxAge := v.currentConfig().Verification.MaxAuthorizationAgeSeconds; maxAge > 0 && currentTime-int64(auth.ValidAfter) > maxAge {
+		return common.Hash{}, &VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     fmt.Sprintf("authorization too old: validAfter=%d is more than %ds before current=%d", auth.ValidAfter, maxAge, currentTime),
+			ErrorCode: ErrorCodeTooOld,
+		}
+	}
+
+	// Step 7: Convert authorization to message
+	message, err := auth.ToMessage()
+	if err != nil {
+		return common.Hash{}, &VerifyPaymentOutput{
+			IsValid: false,
+			Error:   fmt.Sprintf("failed to convert authorization: %v", err),
+		}
+	}
+
+	// Step 8: Compute EIP-712 typed data hash
+	typedDataHash, err := TypedDataHash(domain, message)
+	if err != nil {
+		return common.Hash{}, &VerifyPaymentOutput{
+			IsValid: false,
+			Error:   fmt.Sprintf("failed to compute typed data hash: %v", err),
+		}
+	}
+
+	return typedDataHash, nil
 }
 
 // VerifyDomain checks if the domain separator matches the network configuration
 // This is a helper function for domain matching validation
 func (v *SignatureVerifier) VerifyDomain(network string) (*EIP712Domain, error) {
-	networkCfg, exists := v.config.Networks[network]
-	if !exists {
-		return nil, fmt.Errorf("unsupported network: %s", network)
+	domain, err := v.domain(network)
+	if err != nil {
+		return nil, err
 	}
 
-	domain := &EIP712Domain{
-		Name:              v.config.EIP712.DomainName,
-		Version:           v.config.EIP712.DomainVersion,
-		ChainID:           big.NewInt(int64(networkCfg.ChainID)),
-		VerifyingContract: common.HexToAddress(networkCfg.USDCContract),
+	// Return a copy so callers cannot mutate the cached domain
+	copied := *domain
+	return &copied, nil
+}
+
+// SigningDomain returns the domain an authorization for the network must be signed under:
+// the configured one, or with params' name/version when params is non-nil (see domainFor)
+func (v *SignatureVerifier) SigningDomain(network string, params *config.DomainParams) (*EIP712Domain, error) {
+	domain, err := v.domainFor(network, params)
+	if err != nil {
+		return nil, err
 	}
 
-	return domain, nil
+	copied := *domain
+	return &copied, nil
+}
+
+// PrewarmDomains builds and caches the EIP-712 domain for every configured network
+func (v *SignatureVerifier) PrewarmDomains() error {
+	for network := range v.currentConfig().Networks {
+		if _, err := v.domain(network); err != nil {
+			return fmt.Errorf("network %s: %w", network, err)
+		}
+	}
+
+	return nil
 }
 
 // RecoverSigner is a helper function to recover the signer address from a signature
@@ -152,18 +510,10 @@ func (v *SignatureVerifier) RecoverSigner(
 	auth *EIP3009Authorization,
 	network string,
 ) (common.Address, error) {
-	// Get network configuration
-	networkCfg, exists := v.config.Networks[network]
-	if !exists {
-		return common.Address{}, fmt.Errorf("unsupported network: %s", network)
-	}
-
-	// Create domain
-	domain := &EIP712Domain{
-		Name:              v.config.EIP712.DomainName,
-		Version:           v.config.EIP712.DomainVersion,
-		ChainID:           big.NewInt(int64(networkCfg.ChainID)),
-		VerifyingContract: common.HexToAddress(networkCfg.USDCContract),
+	// Get network domain
+	domain, err := v.domain(network)
+	if err != nil {
+		return common.Address{}, err
 	}
 
 	// Convert to message
@@ -194,7 +544,13 @@ func (v *SignatureVerifier) RecoverSigner(
 }
 
 // ValidateTimeBounds checks if the authorization is within valid time bounds
+// An inverted window (validAfter >= validBefore) is reported as ErrInvertedWindow regardless of
+// the current time.
 func ValidateTimeBounds(validAfter, validBefore uint64) error {
+	if validAfter >= validBefore {
+		return fmt.Errorf("%w (validAfter=%d, validBefore=%d)", ErrInvertedWindow, validAfter, validBefore)
+	}
+
 	currentTime := uint64(time.Now().Unix())
 
 	if currentTime < validAfter {
@@ -0,0 +1,53 @@
+package eip3009
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignAuthorization signs a receiveWithAuthorization message under the domain with an
+// in-process key, returning the authorization with v in the 27/28 convention.
+// Intended for tests and local development; production payers sign in their own wallet.
+// The message's From must be the key's address, otherwise the result could never verify.
+func SignAuthorization(msg *ReceiveWithAuthorizationMessage, domain *EIP712Domain, priv *ecdsa.PrivateKey) (*EIP3009Authorization, error) {
+	if msg == nil || domain == nil || priv == nil {
+		return nil, fmt.Errorf("message, domain, and key are required")
+	}
+
+	signer := crypto.PubkeyToAddress(priv.PublicKey)
+	if msg.From != signer {
+		return nil, fmt.Errorf("message from %s does not match signing key address %s", msg.From.Hex(), signer.Hex())
+	}
+
+	if msg.Value == nil || msg.ValidAfter == nil || msg.ValidBefore == nil {
+		return nil, fmt.Errorf("value, validAfter, and validBefore are required")
+	}
+	if !msg.ValidAfter.IsUint64() || !msg.ValidBefore.IsUint64() {
+		return nil, fmt.Errorf("validAfter and validBefore must fit in uint64")
+	}
+
+	typedDataHash, err := TypedDataHash(domain, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute typed data hash: %w", err)
+	}
+
+	signature, err := crypto.Sign(typedDataHash.Bytes(), priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign authorization: %w", err)
+	}
+
+	return &EIP3009Authorization{
+		From:        msg.From.Hex(),
+		To:          msg.To.Hex(),
+		Value:       msg.Value.String(),
+		ValidAfter:  msg.ValidAfter.Uint64(),
+		ValidBefore: msg.ValidBefore.Uint64(),
+		Nonce:       common.BytesToHash(msg.Nonce[:]).Hex(),
+		V:           signature[64] + 27, // Ethereum uses 27/28 for v
+		R:           common.BytesToHash(signature[0:32]).Hex(),
+		S:           common.BytesToHash(signature[32:64]).Hex(),
+	}, nil
+}
@@ -0,0 +1,47 @@
+package eip3009
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrorCodeWeakNonce marks an authorization whose nonce is trivially predictable
+const ErrorCodeWeakNonce = "weak_nonce"
+
+// WeakNonceReason reports why a nonce looks predictable, or "" when it passes
+// This is a best-effort heuristic: random 32-byte nonces almost never have fewer than
+// minDistinctBytes distinct byte values, while counters, zero-padded integers, and short
+// ASCII strings do. Nonces whose bytes step by a constant (0x0102...20) are flagged too.
+func WeakNonceReason(nonce string, minDistinctBytes int) string {
+	value := common.HexToHash(nonce)
+	if value == (common.Hash{}) {
+		return "nonce is all zeros"
+	}
+
+	stride := value[1] - value[0]
+	sequential := true
+	for i := 2; i < len(value); i++ {
+		if value[i]-value[i-1] != stride {
+			sequential = false
+			break
+		}
+	}
+	if sequential {
+		return fmt.Sprintf("nonce bytes step by a constant %d", stride)
+	}
+
+	var seen [256]bool
+	distinct := 0
+	for _, b := range value {
+		if !seen[b] {
+			seen[b] = true
+			distinct++
+		}
+	}
+	if distinct < minDistinctBytes {
+		return fmt.Sprintf("nonce has only %d distinct byte values (minimum %d)", distinct, minDistinctBytes)
+	}
+
+	return ""
+}
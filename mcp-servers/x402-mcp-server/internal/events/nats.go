@@ -0,0 +1,99 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NATSPublisher publishes events to a NATS subject using the core text protocol
+// Each publish opens a short-lived connection (INFO, CONNECT, PUB, PING/PONG), so there is
+// no background state to supervise; the PONG confirms the server accepted the message.
+// Authentication and TLS are not supported.
+type NATSPublisher struct {
+	address string        // host:port
+	subject string        // Subject events are published to
+	timeout time.Duration // Bound on a whole publish round trip
+}
+
+// NewNATSPublisher creates a publisher for a nats://host[:port] URL
+func NewNATSPublisher(rawURL, subject string, timeout time.Duration) (*NATSPublisher, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme != "nats" || parsed.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q: expected nats://host[:port]", rawURL)
+	}
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, fmt.Errorf("invalid NATS subject %q", subject)
+	}
+
+	address := parsed.Host
+	if parsed.Port() == "" {
+		address = net.JoinHostPort(parsed.Hostname(), "4222")
+	}
+
+	return &NATSPublisher{
+		address: address,
+		subject: subject,
+		timeout: timeout,
+	}, nil
+}
+
+// Publish implements Publisher
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.address)
+	if err != nil {
+		return fmt.Errorf("NATS connect failed: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("NATS handshake failed: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("NATS handshake failed: unexpected %q", strings.TrimSpace(line))
+	}
+
+	var request strings.Builder
+	request.WriteString(`CONNECT {"verbose":false,"pedantic":false,"name":"x402-mcp-server"}` + "\r\n")
+	fmt.Fprintf(&request, "PUB %s %d\r\n", p.subject, len(payload))
+	request.Write(payload)
+	request.WriteString("\r\nPING\r\n")
+
+	if _, err := conn.Write([]byte(request.String())); err != nil {
+		return fmt.Errorf("NATS publish failed: %w", err)
+	}
+
+	// The server answers PING with PONG only after processing everything before it
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("NATS publish unconfirmed: %w", err)
+		}
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS publish rejected: %s", line)
+		}
+	}
+}
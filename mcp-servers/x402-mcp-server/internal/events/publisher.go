@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+)
+
+// MetricPublishFailures counts settlement events that could not be published
+const MetricPublishFailures = "x402_event_publish_failures_total"
+
+// Settlement event types
+const (
+	EventSettlementSettled = "settlement_settled" // A settlement reached its final settled status
+	EventSettlementFailed  = "settlement_failed"  // A submitted settlement failed
+)
+
+// Event is a settlement outcome published to the configured message bus
+type Event struct {
+	Type      string                 `json:"type"`
+	Timestamp time.Time              `json:"timestamp"`
+	Network   string                 `json:"network"`
+	Nonce     string                 `json:"nonce"`
+	From      string                 `json:"from"`
+	To        string                 `json:"to"`
+	Value     string                 `json:"value"`
+	Receipt   map[string]interface{} `json:"receipt"` // settle_payment result (status, tx_hash, ...)
+}
+
+// Publisher delivers settlement events to a message bus
+// Publish errors are reported to the caller, which must not fail the settlement over them.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// NopPublisher discards every event (the default when no publisher is configured)
+type NopPublisher struct{}
+
+// Publish implements Publisher
+func (NopPublisher) Publish(ctx context.Context, event Event) error {
+	return nil
+}
+
+// MemoryPublisher is an in-process publisher that retains events in order
+type MemoryPublisher struct {
+	mu     sync.RWMutex
+	events []Event
+}
+
+// NewMemoryPublisher creates an empty in-memory publisher
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{
+		events: make([]Event, 0),
+	}
+}
+
+// Publish implements Publisher
+func (m *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = append(m.events, event)
+	return nil
+}
+
+// Events returns a copy of all published events in order
+func (m *MemoryPublisher) Events() []Event {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return append([]Event(nil), m.events...)
+}
+
+// NewPublisher builds the publisher selected by the events configuration
+func NewPublisher(cfg *config.EventsConfig) (Publisher, error) {
+	switch cfg.Publisher {
+	case "", config.EventPublisherNone:
+		return NopPublisher{}, nil
+	case config.EventPublisherNATS:
+		return NewNATSPublisher(cfg.NATSURL, cfg.EventSubject(), cfg.PublishTimeout())
+	default:
+		return nil, fmt.Errorf("unsupported event publisher: %s", cfg.Publisher)
+	}
+}
@@ -0,0 +1,142 @@
+package facilitator
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/canonical"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+)
+
+// ErrorCodeAttestationInvalid marks a facilitator response whose receipt signature is missing or invalid
+const ErrorCodeAttestationInvalid = "attestation_invalid"
+
+// ErrorCodeAttestationExpired marks a settlement failed by reconciliation after staying
+// unattested past reconciliation.unattested_max_age_seconds
+const ErrorCodeAttestationExpired = "attestation_expired"
+
+// ErrAttestation is wrapped by errors for unsigned or incorrectly signed facilitator responses
+var ErrAttestation = errors.New("facilitator attestation failed")
+
+// Receipt is the settlement outcome a facilitator signs
+// It binds the reported status and transaction to the network and authorization nonce, so
+// a signed receipt for one settlement cannot be replayed for another.
+type Receipt struct {
+	Network       string `json:"network"`
+	Nonce         string `json:"nonce"` // Lowercase hex
+	Status        string `json:"status"`
+	TxHash        string `json:"tx_hash"`
+	BlockNumber   uint64 `json:"block_number"`
+	Confirmations uint64 `json:"confirmations"`
+}
+
+// NewReceipt builds the receipt a facilitator response attests to
+func NewReceipt(network, nonce string, response *FacilitatorResponse) *Receipt {
+	return &Receipt{
+		Network:       network,
+		Nonce:         strings.ToLower(nonce),
+		Status:        response.Status,
+		TxHash:        response.TxHash,
+		BlockNumber:   response.BlockNumber,
+		Confirmations: response.Confirmations,
+	}
+}
+
+// Hash returns the Keccak-256 hash of the receipt's canonical JSON, the signed digest
+func (r *Receipt) Hash() (common.Hash, error) {
+	return canonical.Hash(r)
+}
+
+// SignReceipt signs the receipt for a response as a facilitator would, returning the
+// 0x-prefixed 65-byte r || s || v attestation with v in the 27/28 convention.
+// Intended for tests and local facilitator stubs.
+func SignReceipt(priv *ecdsa.PrivateKey, network, nonce string, response *FacilitatorResponse) (string, error) {
+	hash, err := NewReceipt(network, nonce, response).Hash()
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := crypto.Sign(hash.Bytes(), priv)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign receipt: %w", err)
+	}
+	signature[64] += 27
+
+	return hexutil.Encode(signature), nil
+}
+
+// verifyAttestation checks the response's receipt signature against the network's
+// facilitator_public_key. Networks without a key are not checked unless attestation is
+// required; once a key is configured a present signature must always be valid.
+func verifyAttestation(cfg *config.Config, network, nonce string, response *FacilitatorResponse) error {
+	networkCfg := cfg.Networks[network]
+	if networkCfg.FacilitatorPublicKey == "" {
+		if cfg.Settlement.RequireAttestation {
+			return fmt.Errorf("%w: no facilitator_public_key configured for %s", ErrAttestation, network)
+		}
+		return nil
+	}
+
+	if response.Attestation == "" {
+		if cfg.Settlement.RequireAttestation {
+			return fmt.Errorf("%w: response is not signed", ErrAttestation)
+		}
+		return nil
+	}
+
+	publicKey, err := parsePublicKey(networkCfg.FacilitatorPublicKey)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrAttestation, err)
+	}
+
+	signature, err := hexutil.Decode(response.Attestation)
+	if err != nil || len(signature) != 65 {
+		return fmt.Errorf("%w: attestation must be a 65-byte hex signature", ErrAttestation)
+	}
+	if signature[64] >= 27 {
+		signature[64] -= 27
+	}
+
+	hash, err := NewReceipt(network, nonce, response).Hash()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrAttestation, err)
+	}
+
+	recovered, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrAttestation, err)
+	}
+	if crypto.PubkeyToAddress(*recovered) != crypto.PubkeyToAddress(*publicKey) {
+		return fmt.Errorf("%w: receipt was not signed by the configured facilitator key", ErrAttestation)
+	}
+
+	response.Attested = true
+	return nil
+}
+
+// parsePublicKey decodes a compressed or uncompressed secp256k1 public key
+func parsePublicKey(key string) (*ecdsa.PublicKey, error) {
+	raw, err := hexutil.Decode(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid facilitator_public_key: %w", err)
+	}
+
+	if len(raw) == 33 {
+		publicKey, err := crypto.DecompressPubkey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid facilitator_public_key: %w", err)
+		}
+		return publicKey, nil
+	}
+
+	publicKey, err := crypto.UnmarshalPubkey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid facilitator_public_key: %w", err)
+	}
+	return publicKey, nil
+}
@@ -2,48 +2,183 @@ package facilitator
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"maps"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/cache"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/netguard"
 )
 
 // Client handles interaction with the x402 facilitator API
 type Client struct {
+	settings atomic.Pointer[clientSettings] // Swapped by UpdateConfig on config reload
+	timeout  time.Duration                  // Default request timeout, overridable per network
+	cache    *settlementCache
+
+	submits submitGroup // Submissions in flight
+}
+
+// clientSettings is the configuration a request runs under, together with the HTTP client
+// and backoff derived from it; each request reads one snapshot, so a reload never mixes them
+type clientSettings struct {
 	config     *config.Config
 	httpClient *http.Client
-	cache      *settlementCache
+	backoff    *Backoff // Delays between retries of transient failures
+}
+
+// newClientSettings derives the HTTP client (SSRF and redirect policy) and backoff from cfg
+func newClientSettings(cfg *config.Config) *clientSettings {
+	httpClient := netguard.HTTPClient(cfg.AllowPrivateURLs)
+	httpClient.CheckRedirect = netguard.RedirectPolicy(cfg.Redirects.MaxRedirects, cfg.Redirects.AllowCrossHost)
+
+	return &clientSettings{
+		config:     cfg,
+		httpClient: httpClient,
+		backoff:    NewBackoff(&cfg.Retry),
+	}
 }
 
+// confirmationRetryAfterSeconds is the retry hint when a settlement lacks required confirmations
+const confirmationRetryAfterSeconds = 5
+
+// attestationRetryAfterSeconds is the retry hint when a settlement response fails attestation
+const attestationRetryAfterSeconds = 30
+
 // settlementCache provides idempotency via nonce-based caching
+// Entries are keyed by network + ":" + nonce so that the same nonce used on two
+// networks settles independently, while repeats within a network still dedupe
 type settlementCache struct {
-	mu      sync.RWMutex
-	entries map[string]*cacheEntry
-	ttl     time.Duration
+	mu       sync.RWMutex
+	entries  map[string]*cacheEntry
+	pending  map[string]*PendingSettlement // Pending settlements awaiting reconciliation
+	ttl      time.Duration                 // Lifetime of settled results (final, safe to keep long)
+	shortTTL time.Duration                 // Lifetime of pending/failed results (0 = not cached)
+	grace    time.Duration                 // Expired entries are still served this long while refreshed
+	onEvict  cache.EvictionHook            // Observes the age of expired entries
+
+	onResolved ResolvedHook // Observes pending settlements resolved by UpdateSettlement
+
+	refreshing map[string]bool  // Keys with a background refresh in flight
+	now        func() time.Time // Time source (replaceable in tests)
+
+	hits      atomic.Uint64 // get calls finding a live result
+	misses    atomic.Uint64 // get calls finding no live result
+	evictions atomic.Uint64 // Expired results removed by cleanup
+
+	sweeping  bool // A background sweeper removes expired results instead of set
+	closeOnce sync.Once
+	stop      chan struct{} // Closed by Close to end the sweeper
+	done      chan struct{} // Closed when the sweeper has exited (nil = no sweeper)
 }
 
 type cacheEntry struct {
 	response  *FacilitatorResponse
+	nonce     string // Authorization nonce the result is for (keys may be idempotency tokens)
 	timestamp time.Time
+	ttl       time.Duration
+}
+
+// PendingSettlement is a settlement the facilitator reported as pending
+type PendingSettlement struct {
+	Network  string
+	Nonce    string
+	Since    time.Time
+	Response *FacilitatorResponse
+}
+
+// ResolvedHook observes a tracked pending settlement reaching a final (settled or failed) status
+type ResolvedHook func(network, nonce string, response *FacilitatorResponse)
+
+// Before orders pending settlements oldest first, breaking ties by network and nonce
+func (p PendingSettlement) Before(other PendingSettlement) bool {
+	if !p.Since.Equal(other.Since) {
+		return p.Since.Before(other.Since)
+	}
+	if p.Network != other.Network {
+		return p.Network < other.Network
+	}
+	return p.Nonce < other.Nonce
 }
 
 // NewClient creates a new facilitator client
+// With cache.sweep_interval_seconds set, expired settlement results are removed by a
+// background sweeper (stopped by Close) rather than by a scan on every cache write.
 func NewClient(cfg *config.Config, timeout time.Duration) *Client {
-	return &Client{
-		config: cfg,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+	client := &Client{
+		timeout: timeout,
 		cache: &settlementCache{
-			entries: make(map[string]*cacheEntry),
-			ttl:     time.Duration(cfg.Cache.SettlementTTLMinutes) * time.Minute,
+			entries:  make(map[string]*cacheEntry),
+			pending:  make(map[string]*PendingSettlement),
+			ttl:      cfg.Cache.SettledTTL(),
+			shortTTL: cfg.Cache.PendingTTL(),
+			grace:    cfg.Cache.Grace(),
+
+			refreshing: make(map[string]bool),
+			now:        time.Now,
+			stop:       make(chan struct{}),
 		},
 	}
+
+	client.settings.Store(newClientSettings(cfg))
+
+	if interval := cfg.Cache.SweepInterval(); interval > 0 {
+		client.cache.sweeping = true
+		client.cache.done = make(chan struct{})
+		go client.cache.sweep(interval)
+	}
+
+	return client
+}
+
+// UpdateConfig swaps the client configuration after a config reload: networks, facilitator
+// URLs, retry, redirect, and cache TTL settings apply to the next request. Cached results
+// are kept; the sweep interval is fixed when the client is created.
+func (c *Client) UpdateConfig(cfg *config.Config) {
+	c.settings.Store(newClientSettings(cfg))
+	c.cache.setTTLs(&cfg.Cache)
+}
+
+// Close stops the settlement cache's background sweeper, if any, and waits for it to exit
+func (c *Client) Close() {
+	c.cache.close()
+}
+
+// SetClock replaces the settlement cache's time source, letting tests control entry ages
+func (c *Client) SetClock(now func() time.Time) {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+
+	c.cache.now = now
+}
+
+// OnCacheEvict installs a hook observing the age of settlement cache entries as they expire
+func (c *Client) OnCacheEvict(hook cache.EvictionHook) {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+
+	c.cache.onEvict = hook
+}
+
+// OnPendingResolved installs a hook called when UpdateSettlement (reconciliation or a
+// settlement callback) moves a tracked pending settlement to a final status. It runs once
+// per settlement, after the cache is updated.
+func (c *Client) OnPendingResolved(hook ResolvedHook) {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+
+	c.cache.onResolved = hook
 }
 
 // BuildSettlementRequest constructs the JSON request body for facilitator submission
@@ -66,21 +201,81 @@ func (c *Client) BuildSettlementRequest(auth *eip3009.EIP3009Authorization, netw
 		"s":           auth.S,
 	}
 
+	// Ask the facilitator to wait for the network's required confirmations
+	if networkCfg, exists := c.settings.Load().config.Networks[network]; exists && networkCfg.Confirmations > 0 {
+		requestBody["confirmations"] = networkCfg.Confirmations
+	}
+
 	return json.Marshal(requestBody)
 }
 
 // SubmitSettlement submits a payment authorization to the x402 facilitator
 func (c *Client) SubmitSettlement(auth *eip3009.EIP3009Authorization, network string) (*FacilitatorResponse, error) {
+	return c.SubmitSettlementWithToken(auth, network, "")
+}
+
+// SubmitSettlementWithToken submits a payment authorization, deduplicating on the caller's
+// idempotency token (e.g., an order ID) instead of the nonce when one is given, so retries
+// that re-sign with a fresh nonce still settle once. The token is forwarded to the
+// facilitator as the Idempotency-Key header.
+func (c *Client) SubmitSettlementWithToken(auth *eip3009.EIP3009Authorization, network, token string) (*FacilitatorResponse, error) {
 	// Check cache for idempotency
-	if cached := c.cache.get(auth.Nonce); cached != nil {
-		return cached, nil
+	cacheKey := settlementCacheKey(network, auth.Nonce)
+	if token != "" {
+		cacheKey = idempotencyTokenKey(network, token)
 	}
+	lookup := func() *FacilitatorResponse {
+		cached, refreshNonce := c.cache.lookup(cacheKey)
+		if refreshNonce != "" {
+			go c.refresh(cacheKey, network, refreshNonce)
+		}
+		return cached
+	}
+
+	return c.exclusive(cacheKey, lookup, func() (*FacilitatorResponse, error) {
+		return c.submit(cacheKey, auth, network, token, "")
+	})
+}
+
+// SubmitSettlementVia submits a settlement to facilitatorURL instead of the network's
+// configured facilitator, e.g. to test against a staging facilitator. The URL is held to
+// the same SSRF policy. Results are neither cached nor tracked for reconciliation, which
+// always queries the configured facilitator.
+func (c *Client) SubmitSettlementVia(auth *eip3009.EIP3009Authorization, network, token, facilitatorURL string) (*FacilitatorResponse, error) {
+	return c.submit("", auth, network, token, facilitatorURL)
+}
+
+// refresh re-checks a pending or failed settlement whose cached result is within its grace
+// window, replacing the entry; readers keep getting the stale result until it completes.
+// It queries the facilitator's status endpoint rather than re-submitting, so the signed
+// authorization is never posted twice; on error the entry is left to expire.
+func (c *Client) refresh(cacheKey, network, nonce string) {
+	defer c.cache.endRefresh(cacheKey)
+
+	response, err := c.GetSettlementStatus(network, nonce)
+	if err != nil {
+		return
+	}
+	c.cache.record(cacheKey, network, nonce, response)
+}
+
+// submit sends a settlement to the facilitator and records the result under cacheKey
+// A non-empty facilitatorURL replaces the configured one; an empty cacheKey skips recording.
+func (c *Client) submit(cacheKey string, auth *eip3009.EIP3009Authorization, network, token, facilitatorURL string) (*FacilitatorResponse, error) {
+	settings := c.settings.Load()
 
 	// Get network configuration
-	networkCfg, exists := c.config.Networks[network]
+	networkCfg, exists := settings.config.Networks[network]
 	if !exists {
 		return nil, fmt.Errorf("unsupported network: %s", network)
 	}
+	if facilitatorURL == "" {
+		facilitatorURL = networkCfg.FacilitatorURL
+	}
+
+	if err := netguard.CheckURL(facilitatorURL, settings.config.AllowPrivateURLs); err != nil {
+		return nil, fmt.Errorf("facilitator URL rejected: %w", err)
+	}
 
 	// Build request body
 	requestBody, err := c.BuildSettlementRequest(auth, network)
@@ -88,42 +283,192 @@ func (c *Client) SubmitSettlement(auth *eip3009.EIP3009Authorization, network st
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
 
-	// Create HTTP POST request
-	req, err := http.NewRequest(http.MethodPost, networkCfg.FacilitatorURL, bytes.NewReader(requestBody))
+	// Apply the network's settlement timeout (covering all retries)
+	ctx, cancel := context.WithTimeout(context.Background(), networkCfg.SettlementTimeout(c.timeout))
+	defer cancel()
+
+	// Submit HTTP POST request, retrying transient failures
+	// Resubmitting is safe: the EIP-3009 nonce can be consumed on-chain at most once
+	statusCode, body, err := c.do(ctx, settings, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, facilitatorURL, bytes.NewReader(requestBody))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		if token != "" {
+			req.Header.Set("Idempotency-Key", token)
+		}
+		return req, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
+	// Parse response
+	result, err := c.parseResponse(statusCode, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// An unattested response says nothing trustworthy about the outcome: the facilitator may
+	// have settled. Report it as pending, tracked for reconciliation, so it is re-checked
+	// instead of treated as a permanent failure.
+	if err := verifyAttestation(settings.config, network, auth.Nonce, result); err != nil {
+		unattested := &FacilitatorResponse{
+			Status:     "pending",
+			Error:      err.Error(),
+			ErrorCode:  ErrorCodeAttestationInvalid,
+			RetryAfter: attestationRetryAfterSeconds,
+		}
+		if cacheKey != "" {
+			c.cache.record(cacheKey, network, auth.Nonce, unattested)
+		}
+		return unattested, nil
+	}
+
+	// Not yet settled until the network's required confirmations are reached
+	if result.Status == "settled" && result.Confirmations < networkCfg.Confirmations {
+		result.Status = "pending"
+		result.RetryAfter = confirmationRetryAfterSeconds
+	}
 
-	// Submit request
-	resp, err := c.httpClient.Do(req)
+	// Cache successful settlements; track pending ones for reconciliation
+	if cacheKey != "" {
+		c.cache.record(cacheKey, network, auth.Nonce, result)
+	}
+
+	return result, nil
+}
+
+// SubmitSettlementRaw submits like SubmitSettlement and also returns the facilitator's decoded
+// response body, for advanced callers needing facilitator-specific fields the typed response
+// does not model. The raw map is nil when the body was not a JSON object. It reflects the
+// facilitator's reply as received, before confirmation policy is applied to the typed status.
+func (c *Client) SubmitSettlementRaw(auth *eip3009.EIP3009Authorization, network string) (*FacilitatorResponse, map[string]interface{}, error) {
+	result, err := c.SubmitSettlementWithToken(auth, network, "")
 	if err != nil {
-		return nil, fmt.Errorf("facilitator request failed: %w", err)
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
 
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	return result, maps.Clone(result.raw), nil
+}
+
+// GetSettlementStatus queries the facilitator for the current status of a settlement by nonce
+func (c *Client) GetSettlementStatus(network, nonce string) (*FacilitatorResponse, error) {
+	settings := c.settings.Load()
+
+	networkCfg, exists := settings.config.Networks[network]
+	if !exists {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), networkCfg.SettlementTimeout(c.timeout))
+	defer cancel()
+
+	if err := netguard.CheckURL(networkCfg.FacilitatorURL, settings.config.AllowPrivateURLs); err != nil {
+		return nil, fmt.Errorf("facilitator URL rejected: %w", err)
+	}
+
+	statusURL := strings.TrimSuffix(networkCfg.FacilitatorURL, "/") + "/status/" + url.PathEscape(nonce)
+	statusCode, body, err := c.do(ctx, settings, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		return req, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
-	// Parse response
-	result, err := c.parseResponse(resp.StatusCode, body)
+	result, err := c.parseResponse(statusCode, body)
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache successful settlements
-	if result.Status == "settled" {
-		c.cache.set(auth.Nonce, result)
+	if err := verifyAttestation(settings.config, network, nonce, result); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
+// Ping checks that the network's facilitator is reachable
+// Any HTTP response (including a refused redirect) counts as reachable; only transport errors are reported
+func (c *Client) Ping(ctx context.Context, network string) error {
+	settings := c.settings.Load()
+
+	networkCfg, exists := settings.config.Networks[network]
+	if !exists {
+		return fmt.Errorf("unsupported network: %s", network)
+	}
+
+	if err := netguard.CheckURL(networkCfg.FacilitatorURL, settings.config.AllowPrivateURLs); err != nil {
+		return fmt.Errorf("facilitator URL rejected: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, networkCfg.FacilitatorURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := settings.httpClient.Do(req)
+	if errors.Is(err, netguard.ErrRedirectNotAllowed) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("facilitator unreachable: %w", err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// SeedSettlement caches a settlement known to have settled at settledAt (e.g., from the
+// audit trail after a restart), so resubmissions are idempotent hits until it would have
+// expired had it been cached then. Returns false when it has already expired or a result
+// is cached for the nonce.
+func (c *Client) SeedSettlement(network, nonce string, response *FacilitatorResponse, settledAt time.Time) bool {
+	return c.cache.seed(settlementCacheKey(network, nonce), response, settledAt)
+}
+
+// CacheStats reports how effective the settlement idempotency cache has been
+func (c *Client) CacheStats() cache.Stats {
+	return c.cache.Stats()
+}
+
+// PendingSettlements returns settlements still awaiting a final status, oldest first
+func (c *Client) PendingSettlements() []PendingSettlement {
+	return c.cache.pendingList()
+}
+
+// CachedSettlement returns the cached settled or pending response for a nonce, or nil
+func (c *Client) CachedSettlement(network, nonce string) *FacilitatorResponse {
+	key := settlementCacheKey(network, nonce)
+	if settled := c.cache.get(key); settled != nil {
+		return settled
+	}
+	return c.cache.getPending(key)
+}
+
+// UpdateSettlement records a newer status for a settlement (e.g., from reconciliation)
+// Settled results become idempotency cache hits; failed results stop being tracked
+func (c *Client) UpdateSettlement(network, nonce string, response *FacilitatorResponse) {
+	if !c.cache.record(settlementCacheKey(network, nonce), network, nonce, response) {
+		return
+	}
+
+	c.cache.mu.RLock()
+	hook := c.cache.onResolved
+	c.cache.mu.RUnlock()
+
+	if hook != nil {
+		hook(network, nonce, response)
+	}
+}
+
 // parseResponse parses the facilitator HTTP response
 func (c *Client) parseResponse(statusCode int, body []byte) (*FacilitatorResponse, error) {
 	var response FacilitatorResponse
@@ -135,6 +480,7 @@ func (c *Client) parseResponse(statusCode int, body []byte) (*FacilitatorRespons
 		if err := json.Unmarshal(body, &response); err != nil {
 			return nil, fmt.Errorf("failed to parse response: %w", err)
 		}
+		response.raw = decodeRaw(body)
 		return &response, nil
 
 	case statusCode == http.StatusBadRequest:
@@ -144,6 +490,7 @@ func (c *Client) parseResponse(statusCode int, body []byte) (*FacilitatorRespons
 			return nil, fmt.Errorf("facilitator returned 400 Bad Request: %s", string(body))
 		}
 		// Return parsed error response
+		response.raw = decodeRaw(body)
 		if response.Status == "" {
 			response.Status = "failed"
 		}
@@ -159,45 +506,336 @@ func (c *Client) parseResponse(statusCode int, body []byte) (*FacilitatorRespons
 	}
 }
 
-// get retrieves a cached settlement result by nonce
-func (sc *settlementCache) get(nonce string) *FacilitatorResponse {
+// decodeRaw decodes a response body as a generic JSON object, or returns nil
+func decodeRaw(body []byte) map[string]interface{} {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil
+	}
+	return raw
+}
+
+// settlementCacheKey scopes a nonce to its network
+// Nonces are normalized to lowercase so hex casing differences still dedupe
+func settlementCacheKey(network, nonce string) string {
+	return network + ":" + strings.ToLower(nonce)
+}
+
+// idempotencyTokenKey scopes a caller idempotency token to its network
+// Tokens are opaque and compared exactly; the prefix keeps them apart from nonce keys
+func idempotencyTokenKey(network, token string) string {
+	return network + ":token:" + token
+}
+
+// get retrieves a cached settlement result by network-scoped key
+func (sc *settlementCache) get(key string) *FacilitatorResponse {
 	sc.mu.RLock()
 	defer sc.mu.RUnlock()
 
-	entry, exists := sc.entries[nonce]
+	entry, exists := sc.entries[key]
 	if !exists {
+		sc.misses.Add(1)
 		return nil
 	}
 
 	// Check if entry has expired
-	if time.Since(entry.timestamp) > sc.ttl {
+	if sc.now().Sub(entry.timestamp) > entry.ttl {
 		// Entry expired, will be cleaned up later
+		sc.misses.Add(1)
 		return nil
 	}
 
+	sc.hits.Add(1)
 	return entry.response
 }
 
-// set stores a settlement result in cache
-func (sc *settlementCache) set(nonce string, response *FacilitatorResponse) {
+// lookup retrieves a cached result for submission, honouring the grace window: an entry
+// that expired less than grace ago is still returned. A settled result is final, so its
+// TTL is simply extended; for other results exactly one caller gets the result's nonce as
+// refreshNonce, and should re-check its status in the background and then call endRefresh
+func (sc *settlementCache) lookup(key string) (response *FacilitatorResponse, refreshNonce string) {
+	if fresh := sc.get(key); fresh != nil || sc.lifetimes().grace <= 0 {
+		return fresh, ""
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	entry, exists := sc.entries[key]
+	if !exists {
+		return nil, ""
+	}
+
+	now := sc.now()
+	age := now.Sub(entry.timestamp)
+	if age <= entry.ttl {
+		// Refreshed while we waited for the lock
+		return entry.response, ""
+	}
+	if age > entry.ttl+sc.grace {
+		return nil, ""
+	}
+
+	if entry.response.Status == "settled" {
+		entry.timestamp = now
+		return entry.response, ""
+	}
+
+	if sc.refreshing[key] || entry.nonce == "" {
+		return entry.response, ""
+	}
+	sc.refreshing[key] = true
+	return entry.response, entry.nonce
+}
+
+// cacheLifetimes are the settlement cache's configured TTLs
+type cacheLifetimes struct {
+	ttl      time.Duration
+	shortTTL time.Duration
+	grace    time.Duration
+}
+
+// lifetimes returns the current TTLs, which a config reload may change
+func (sc *settlementCache) lifetimes() cacheLifetimes {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	return cacheLifetimes{ttl: sc.ttl, shortTTL: sc.shortTTL, grace: sc.grace}
+}
+
+// setTTLs applies reloaded cache lifetimes to results stored from now on
+func (sc *settlementCache) setTTLs(cfg *config.CacheConfig) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.ttl = cfg.SettledTTL()
+	sc.shortTTL = cfg.PendingTTL()
+	sc.grace = cfg.Grace()
+}
+
+// endRefresh marks a background refresh as finished
+func (sc *settlementCache) endRefresh(key string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	delete(sc.refreshing, key)
+}
+
+// set stores the settlement result for nonce in cache for ttl
+func (sc *settlementCache) set(key, nonce string, response *FacilitatorResponse, ttl time.Duration) {
+	sc.mu.Lock()
+	sc.entries[key] = &cacheEntry{
+		response:  response,
+		nonce:     nonce,
+		timestamp: sc.now(),
+		ttl:       ttl,
+	}
+
+	// Cleanup expired entries inline unless the background sweeper does
+	var expiredAges []time.Duration
+	if !sc.sweeping {
+		expiredAges = sc.cleanup()
+	}
+	hook := sc.onEvict
+	sc.mu.Unlock()
+
+	notifyExpired(hook, expiredAges)
+}
+
+// sweep removes expired entries every interval until close is called
+func (sc *settlementCache) sweep(interval time.Duration) {
+	defer close(sc.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sc.mu.Lock()
+			expiredAges := sc.cleanup()
+			hook := sc.onEvict
+			sc.mu.Unlock()
+
+			notifyExpired(hook, expiredAges)
+		case <-sc.stop:
+			return
+		}
+	}
+}
+
+// close stops the background sweeper, if any, and waits for it to exit
+func (sc *settlementCache) close() {
+	sc.closeOnce.Do(func() {
+		close(sc.stop)
+	})
+	if sc.done != nil {
+		<-sc.done
+	}
+}
+
+// notifyExpired reports the ages of expired entries to the eviction hook, if installed
+func notifyExpired(hook cache.EvictionHook, ages []time.Duration) {
+	if hook == nil {
+		return
+	}
+	for _, age := range ages {
+		hook(age, cache.EvictionExpired)
+	}
+}
+
+// seed stores a settled result recorded at settledAt for the settled TTL remaining, unless
+// it has already expired or key is cached
+func (sc *settlementCache) seed(key string, response *FacilitatorResponse, settledAt time.Time) bool {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
-	sc.entries[nonce] = &cacheEntry{
+	if _, exists := sc.entries[key]; exists || sc.now().Sub(settledAt) > sc.ttl {
+		return false
+	}
+
+	sc.entries[key] = &cacheEntry{
 		response:  response,
-		timestamp: time.Now(),
+		timestamp: settledAt,
+		ttl:       sc.ttl,
+	}
+	return true
+}
+
+// record stores a result under key by status: settled results are cached for the settled
+// TTL, pending results are tracked (by network and nonce) for reconciliation, and anything
+// else clears pending tracking. Pending and failed results are also cached for the shorter
+// TTL when set, so repeats within it reuse the result while later calls refresh it promptly.
+// Returns true when the result resolved a tracked pending settlement.
+func (sc *settlementCache) record(key, network, nonce string, response *FacilitatorResponse) bool {
+	pendingKey := settlementCacheKey(network, nonce)
+	resolved := false
+	switch response.Status {
+	case "settled":
+		resolved = sc.deletePending(pendingKey)
+		sc.set(key, nonce, response, sc.lifetimes().ttl)
+		return resolved
+	case "pending":
+		sc.setPending(pendingKey, network, nonce, response)
+	default:
+		resolved = sc.deletePending(pendingKey)
+	}
+
+	if shortTTL := sc.lifetimes().shortTTL; shortTTL > 0 {
+		sc.set(key, nonce, response, shortTTL)
+	} else {
+		sc.delete(key)
+	}
+	return resolved
+}
+
+// delete removes a cached result
+func (sc *settlementCache) delete(key string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	delete(sc.entries, key)
+}
+
+// setPending tracks a pending settlement, preserving the original pending time
+func (sc *settlementCache) setPending(key, network, nonce string, response *FacilitatorResponse) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if existing, exists := sc.pending[key]; exists {
+		existing.Response = response
+		return
+	}
+
+	sc.pending[key] = &PendingSettlement{
+		Network:  network,
+		Nonce:    nonce,
+		Since:    sc.now(),
+		Response: response,
+	}
+}
+
+// getPending returns the tracked pending response for a key, or nil
+func (sc *settlementCache) getPending(key string) *FacilitatorResponse {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	if entry, exists := sc.pending[key]; exists {
+		return entry.Response
 	}
+	return nil
+}
+
+// deletePending stops tracking a pending settlement, returning whether it was tracked
+func (sc *settlementCache) deletePending(key string) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	_, tracked := sc.pending[key]
+	delete(sc.pending, key)
+	return tracked
+}
+
+// pendingList returns copies of tracked pending settlements, oldest first (see PendingSettlement.Before)
+func (sc *settlementCache) pendingList() []PendingSettlement {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	list := make([]PendingSettlement, 0, len(sc.pending))
+	for _, entry := range sc.pending {
+		list = append(list, *entry)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Before(list[j])
+	})
 
-	// Cleanup expired entries (simple inline cleanup)
-	sc.cleanup()
+	return list
 }
 
-// cleanup removes expired entries from cache
-func (sc *settlementCache) cleanup() {
-	now := time.Now()
-	for nonce, entry := range sc.entries {
-		if now.Sub(entry.timestamp) > sc.ttl {
-			delete(sc.entries, nonce)
+// cleanup removes entries expired beyond the grace window, returning their ages (caller holds mu)
+func (sc *settlementCache) cleanup() []time.Duration {
+	now := sc.now()
+	var ages []time.Duration
+	for key, entry := range sc.entries {
+		if age := now.Sub(entry.timestamp); age > entry.ttl+sc.grace {
+			delete(sc.entries, key)
+			ages = append(ages, age)
 		}
 	}
+	sc.evictions.Add(uint64(len(ages)))
+	return ages
+}
+
+// Hits returns the number of lookups that found a live settlement result
+func (sc *settlementCache) Hits() uint64 {
+	return sc.hits.Load()
+}
+
+// Misses returns the number of lookups that found no live settlement result
+func (sc *settlementCache) Misses() uint64 {
+	return sc.misses.Load()
+}
+
+// Evictions returns the number of expired settlement results removed by cleanup
+func (sc *settlementCache) Evictions() uint64 {
+	return sc.evictions.Load()
+}
+
+// Size returns the number of cached settlement results (including expired, not yet removed)
+func (sc *settlementCache) Size() int {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	return len(sc.entries)
+}
+
+// Stats returns the hit, miss, and eviction counts with the current size
+func (sc *settlementCache) Stats() cache.Stats {
+	return cache.Stats{
+		Hits:      sc.Hits(),
+		Misses:    sc.Misses(),
+		Evictions: sc.Evictions(),
+		Size:      sc.Size(),
+	}
 }
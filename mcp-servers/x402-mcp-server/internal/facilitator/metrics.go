@@ -0,0 +1,11 @@
+package facilitator
+
+// MetricSettlementLatency is the histogram of settlement submission latency (seconds) per network
+// Only settled submissions are observed; its mean feeds estimate_settlement_time.
+const MetricSettlementLatency = "x402_settlement_latency_seconds"
+
+// SettlementLatencyBuckets are the histogram upper bounds (seconds) for MetricSettlementLatency
+var SettlementLatencyBuckets = []float64{0.25, 0.5, 1, 2, 5, 10, 20, 30, 60}
+
+// MetricErrors counts settlements the facilitator failed, per network and error code
+const MetricErrors = "x402_facilitator_errors_total"
@@ -1,12 +1,25 @@
 package facilitator
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
 // FacilitatorResponse represents the result of a payment settlement attempt
 type FacilitatorResponse struct {
-	Status      string `json:"status"`                 // settled | pending | failed
-	TxHash      string `json:"tx_hash,omitempty"`      // Transaction hash (if settled)
-	BlockNumber uint64 `json:"block_number,omitempty"` // Block number (if settled)
-	Error       string `json:"error,omitempty"`        // Error message (if failed)
-	RetryAfter  int    `json:"retry_after,omitempty"`  // Seconds until retry (if pending)
+	Status        string `json:"status"`                  // settled | pending | failed
+	TxHash        string `json:"tx_hash,omitempty"`       // Transaction hash (if settled)
+	BlockNumber   uint64 `json:"block_number,omitempty"`  // Block number (if settled)
+	Confirmations uint64 `json:"confirmations,omitempty"` // Confirmations observed by the facilitator
+	Error         string `json:"error,omitempty"`         // Error message (if failed)
+	ErrorCode     string `json:"error_code,omitempty"`    // Machine-readable failure reason (if failed)
+	RetryAfter    int    `json:"retry_after,omitempty"`   // Seconds until retry (if pending)
+	Attestation   string `json:"attestation,omitempty"`   // Facilitator signature over the canonical Receipt (0x hex, 65 bytes)
+	Attested      bool   `json:"-"`                       // Attestation verified against the network's facilitator_public_key
+
+	raw map[string]interface{} // Decoded facilitator body, including fields not modeled above
 }
 
 // ToMap converts the response to a map for MCP tool output
@@ -23,13 +36,94 @@ func (r *FacilitatorResponse) ToMap() map[string]interface{} {
 		result["block_number"] = r.BlockNumber
 	}
 
+	if r.Confirmations > 0 {
+		result["confirmations"] = r.Confirmations
+	}
+
 	if r.Error != "" {
 		result["error"] = r.Error
 	}
 
+	if r.ErrorCode != "" {
+		result["error_code"] = r.ErrorCode
+	}
+
 	if r.RetryAfter > 0 {
 		result["retry_after"] = r.RetryAfter
 	}
 
+	if r.Attested {
+		result["attested"] = true
+	}
+
 	return result
 }
+
+// UnmarshalJSON decodes a facilitator body, accepting block_number and retry_after either as
+// JSON numbers or as decimal strings ("12345678"), which some facilitators send instead
+func (r *FacilitatorResponse) UnmarshalJSON(data []byte) error {
+	type plain FacilitatorResponse
+	aux := struct {
+		*plain
+		BlockNumber lenientNumber `json:"block_number"`
+		RetryAfter  lenientNumber `json:"retry_after"`
+	}{plain: (*plain)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	blockNumber, err := aux.BlockNumber.uint64()
+	if err != nil {
+		return fmt.Errorf("block_number: %w", err)
+	}
+	retryAfter, err := aux.RetryAfter.uint64()
+	if err != nil || retryAfter > 1<<31-1 {
+		return fmt.Errorf("retry_after: must be a non-negative integer, got %s", aux.RetryAfter)
+	}
+
+	r.BlockNumber = blockNumber
+	r.RetryAfter = int(retryAfter)
+	return nil
+}
+
+// lenientNumber holds a non-negative integer given as a JSON number or a decimal string
+// Absent, null, and empty-string values decode as zero.
+type lenientNumber string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *lenientNumber) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*n = ""
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*n = lenientNumber(s)
+		return nil
+	}
+
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	*n = lenientNumber(number)
+	return nil
+}
+
+// uint64 parses the value as a base-10 unsigned integer
+func (n lenientNumber) uint64() (uint64, error) {
+	if n == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseUint(string(n), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("must be a non-negative integer, got %s", string(n))
+	}
+	return value, nil
+}
@@ -0,0 +1,105 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/netguard"
+)
+
+// Backoff computes exponential retry delays, optionally spread with full jitter
+// Without jitter, clients that failed together retry together; full jitter draws each
+// delay uniformly from [0, backoff] so a recovering facilitator isn't hit in lockstep.
+type Backoff struct {
+	Base   time.Duration // Delay before the first retry
+	Max    time.Duration // Cap on any single delay
+	Jitter string        // config.JitterFull (default) or config.JitterNone
+}
+
+// NewBackoff builds a backoff from the retry configuration
+func NewBackoff(cfg *config.RetryConfig) *Backoff {
+	return &Backoff{
+		Base:   cfg.BaseDelay(),
+		Max:    cfg.MaxDelay(),
+		Jitter: cfg.Jitter,
+	}
+}
+
+// Delay returns the wait before retry number attempt (0-based)
+func (b *Backoff) Delay(attempt int) time.Duration {
+	ceiling := b.Max
+	if attempt < 32 {
+		if d := b.Base << attempt; d > 0 && d < b.Max {
+			ceiling = d
+		}
+	}
+
+	if b.Jitter == config.JitterNone || ceiling <= 0 {
+		return ceiling
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// retryableStatus reports whether a facilitator HTTP status is worth retrying
+// Only errors a later attempt can plausibly clear are retried; 501 and other 5xx responses
+// describe the facilitator itself, and 4xx responses (including 400) the request.
+func retryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+// do sends the request built by newRequest, retrying transport errors and 500/502/503
+// responses up to the configured retry count. Retries stop when ctx is done; the final
+// status and body (or transport error) are returned for the caller to interpret.
+func (c *Client) do(ctx context.Context, settings *clientSettings, newRequest func(ctx context.Context) (*http.Request, error)) (int, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest(ctx)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if env := settings.config.Settlement.FacilitatorAPIKeyEnv; env != "" {
+			req.Header.Set("Authorization", "Bearer "+os.Getenv(env))
+		}
+
+		statusCode, body, err := doOnce(settings.httpClient, req)
+		// Refused redirects are policy, not transient; retrying would only repeat them
+		retryable := (err != nil && ctx.Err() == nil && !errors.Is(err, netguard.ErrRedirectNotAllowed)) || retryableStatus(statusCode)
+		if !retryable || attempt >= settings.config.Retry.MaxRetries {
+			return statusCode, body, err
+		}
+
+		timer := time.NewTimer(settings.backoff.Delay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return statusCode, body, err
+		case <-timer.C:
+		}
+	}
+}
+
+// doOnce performs a single request and reads the full response body
+func doOnce(httpClient *http.Client, req *http.Request) (int, []byte, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("facilitator request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp.StatusCode, body, nil
+}
@@ -0,0 +1,85 @@
+package facilitator
+
+import (
+	"strings"
+	"sync"
+)
+
+// submitGroup tracks the submissions in flight, by settlement key
+type submitGroup struct {
+	mu    sync.Mutex
+	calls map[string]chan struct{} // Closed when the call for a key ends
+}
+
+// SubmitOnce runs submit, e.g. an on-chain broadcast, at most once per network, payer, and
+// nonce: concurrent calls wait for the one in flight, and a result carrying a tx hash is
+// cached for the settled TTL so retries return it rather than broadcasting a second
+// transaction that would revert. Results without a tx hash (e.g. gas_too_high) are not
+// cached, so a retry submits again.
+func (c *Client) SubmitOnce(network, from, nonce string, submit func() (*FacilitatorResponse, error)) (*FacilitatorResponse, error) {
+	key := onchainSettlementKey(network, from, nonce)
+	return c.exclusive(key, func() *FacilitatorResponse {
+		return c.cache.get(key)
+	}, func() (*FacilitatorResponse, error) {
+		response, err := submit()
+		if err != nil {
+			return nil, err
+		}
+		if response.TxHash != "" {
+			// No nonce: a broadcast is never refreshed through the facilitator's status endpoint
+			c.cache.set(key, "", response, c.cache.lifetimes().ttl)
+		}
+		return response, nil
+	})
+}
+
+// exclusive returns the result lookup finds for key, or else runs submit, which must record
+// its result for lookup. Calls for a key take turns, so a retry made while a submission is
+// still running (e.g. after a tool timeout) waits for it and returns its result instead of
+// submitting the same authorization a second time.
+func (c *Client) exclusive(key string, lookup func() *FacilitatorResponse, submit func() (*FacilitatorResponse, error)) (*FacilitatorResponse, error) {
+	for {
+		wait, claimed := c.submits.claim(key)
+		if claimed {
+			break
+		}
+		<-wait
+	}
+	defer c.submits.end(key)
+
+	if cached := lookup(); cached != nil {
+		return cached, nil
+	}
+	return submit()
+}
+
+// onchainSettlementKey scopes an on-chain settlement to its network, payer, and nonce
+// (EIP-3009 nonces are unique per payer), normalized to lowercase
+func onchainSettlementKey(network, from, nonce string) string {
+	return network + ":onchain:" + strings.ToLower(from) + ":" + strings.ToLower(nonce)
+}
+
+// claim claims key for a submission, or returns a channel closed when the call
+// already in flight for it ends
+func (g *submitGroup) claim(key string) (<-chan struct{}, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if wait, exists := g.calls[key]; exists {
+		return wait, false
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]chan struct{})
+	}
+	g.calls[key] = make(chan struct{})
+	return nil, true
+}
+
+// end releases a key claimed by claim, waking its waiters
+func (g *submitGroup) end(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	close(g.calls[key])
+	delete(g.calls, key)
+}
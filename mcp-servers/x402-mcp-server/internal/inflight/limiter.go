@@ -0,0 +1,83 @@
+package inflight
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrorCodeQueueFull is returned when no in-flight slot frees up before the deadline
+const ErrorCodeQueueFull = "settlement_queue_full"
+
+// ErrQueueFull indicates a network's in-flight limit stayed saturated for the whole wait
+var ErrQueueFull = errors.New("settlement queue full")
+
+// Limiter bounds concurrent settlement submissions per network using one semaphore each
+type Limiter struct {
+	wait time.Duration
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// NewLimiter creates a limiter from per-network limits. Networks without a positive
+// limit are unbounded. Acquire waits at most wait for a free slot.
+func NewLimiter(limits map[string]int, wait time.Duration) *Limiter {
+	l := &Limiter{
+		wait:  wait,
+		slots: make(map[string]chan struct{}),
+	}
+
+	for network, limit := range limits {
+		if limit > 0 {
+			l.slots[network] = make(chan struct{}, limit)
+		}
+	}
+
+	return l
+}
+
+// Acquire reserves an in-flight slot for the network, returning a release func that
+// must be called once the submission finishes. Returns ErrQueueFull after the wait.
+func (l *Limiter) Acquire(network string) (func(), error) {
+	l.mu.Lock()
+	slots, limited := l.slots[network]
+	l.mu.Unlock()
+
+	if !limited {
+		return func() {}, nil
+	}
+
+	// Fast path avoids allocating a timer when a slot is free
+	select {
+	case slots <- struct{}{}:
+		return releaser(slots), nil
+	default:
+	}
+
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+		return releaser(slots), nil
+	case <-timer.C:
+		return nil, ErrQueueFull
+	}
+}
+
+// InFlight returns the number of occupied slots for a network (0 when unbounded)
+func (l *Limiter) InFlight(network string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.slots[network])
+}
+
+// releaser frees one slot exactly once, however many times it is called
+func releaser(slots chan struct{}) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-slots })
+	}
+}
@@ -0,0 +1,65 @@
+package logger
+
+import "fmt"
+
+// RedactedPrefixLength is how many leading characters of a redacted value are kept,
+// enough to correlate "0x1234…" across log lines without exposing the full value
+const RedactedPrefixLength = 6
+
+// redactedSuffix marks a value truncated by redaction
+const redactedSuffix = "…"
+
+// SetRedactFields masks the values of the given field keys in every subsequent entry,
+// e.g. "r", "s", and "nonce" when logs leave the host. Keys match exactly; nil disables redaction.
+// Safe to call concurrently with logging.
+func (l *Logger) SetRedactFields(keys []string) {
+	redact := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		redact[key] = true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.redact = redact
+}
+
+// redactFields returns fields with redacted keys reduced to a prefix
+// The caller's map is never modified; it is returned as is when nothing needs masking.
+func (l *Logger) redactFields(fields map[string]interface{}) map[string]interface{} {
+	l.mu.RLock()
+	redact := l.redact
+	l.mu.RUnlock()
+
+	if len(redact) == 0 {
+		return fields
+	}
+
+	var redacted map[string]interface{}
+	for key, value := range fields {
+		if !redact[key] || value == nil {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(map[string]interface{}, len(fields))
+			for k, v := range fields {
+				redacted[k] = v
+			}
+		}
+		redacted[key] = Redact(fmt.Sprint(value))
+	}
+
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
+
+// Redact truncates a value to its first RedactedPrefixLength characters plus "…"
+func Redact(value string) string {
+	runes := []rune(value)
+	if len(runes) <= RedactedPrefixLength {
+		return redactedSuffix
+	}
+	return string(runes[:RedactedPrefixLength]) + redactedSuffix
+}
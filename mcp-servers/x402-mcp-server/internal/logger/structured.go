@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -16,11 +17,16 @@ const (
 	INFO  Level = "INFO"
 	WARN  Level = "WARN"
 	ERROR Level = "ERROR"
+
+	// CRITICAL marks events needing immediate operator action (e.g. a leaked payer key)
+	CRITICAL Level = "CRITICAL"
 )
 
 // Logger provides structured JSON logging
 type Logger struct {
+	mu     sync.RWMutex
 	level  Level
+	redact map[string]bool // Field keys masked before marshaling (see SetRedactFields)
 	output io.Writer
 }
 
@@ -35,6 +41,33 @@ func New(level Level, output io.Writer) *Logger {
 	}
 }
 
+// ParseLevel returns the level named by a logging.level setting; unknown names are INFO
+func ParseLevel(name string) Level {
+	switch Level(name) {
+	case DEBUG, WARN, ERROR:
+		return Level(name)
+	default:
+		return INFO
+	}
+}
+
+// SetLevel changes the minimum logged level, e.g. after a config reload
+// Safe to call concurrently with logging.
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.level = level
+}
+
+// Level returns the minimum logged level
+func (l *Logger) Level() Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.level
+}
+
 // Entry represents a structured log entry
 type Entry struct {
 	Level   string                 `json:"level"`
@@ -46,12 +79,13 @@ type Entry struct {
 // shouldLog determines if a message at the given level should be logged
 func (l *Logger) shouldLog(level Level) bool {
 	levels := map[Level]int{
-		DEBUG: 0,
-		INFO:  1,
-		WARN:  2,
-		ERROR: 3,
+		DEBUG:    0,
+		INFO:     1,
+		WARN:     2,
+		ERROR:    3,
+		CRITICAL: 4,
 	}
-	return levels[level] >= levels[l.level]
+	return levels[level] >= levels[l.Level()]
 }
 
 // log writes a structured log entry
@@ -64,7 +98,7 @@ func (l *Logger) log(level Level, msg string, fields map[string]interface{}) {
 		Level:   string(level),
 		Time:    time.Now().UTC().Format(time.RFC3339),
 		Message: msg,
-		Fields:  fields,
+		Fields:  l.redactFields(fields),
 	}
 
 	data, err := json.Marshal(entry)
@@ -98,6 +132,11 @@ func (l *Logger) Error(msg string, fields map[string]interface{}) {
 	l.log(ERROR, msg, fields)
 }
 
+// Critical logs a critical message with optional fields
+func (l *Logger) Critical(msg string, fields map[string]interface{}) {
+	l.log(CRITICAL, msg, fields)
+}
+
 // WithFields returns a new logger with additional context fields
 func (l *Logger) WithFields(fields map[string]interface{}) *ContextLogger {
 	return &ContextLogger{
@@ -143,3 +182,8 @@ func (cl *ContextLogger) Warn(msg string, fields map[string]interface{}) {
 func (cl *ContextLogger) Error(msg string, fields map[string]interface{}) {
 	cl.logger.Error(msg, cl.mergeFields(fields))
 }
+
+// Critical logs with context fields
+func (cl *ContextLogger) Critical(msg string, fields map[string]interface{}) {
+	cl.logger.Critical(msg, cl.mergeFields(fields))
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the registry for Prometheus scrapes through promhttp, which negotiates
+// the exposition format with the scraper. Histogram exemplars are only representable in,
+// and so only served with, the OpenMetrics format.
+func Handler(registry *Registry) http.Handler {
+	gatherer := prometheus.NewRegistry()
+	gatherer.MustRegister(registry)
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
+
+// Describe implements prometheus.Collector. Series are created on first use, so nothing is
+// described up front and the registry is collected unchecked.
+func (r *Registry) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector, exporting every series as a constant metric
+// Histogram buckets are exported cumulatively, as Prometheus expects.
+func (r *Registry) Collect(ch chan<- prometheus.Metric) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	r.collectScalars(ch, prometheus.CounterValue, r.counters)
+	r.collectScalars(ch, prometheus.GaugeValue, r.gauges)
+
+	for name, series := range r.histograms {
+		for key, h := range series {
+			buckets := make(map[float64]uint64, len(h.buckets))
+			cumulative := uint64(0)
+			for i, bound := range h.buckets {
+				cumulative += h.counts[i]
+				buckets[bound] = cumulative
+			}
+			metric := prometheus.MustNewConstHistogram(r.desc(name, key), h.count, h.sum, buckets)
+			if exemplars := h.promExemplars(); len(exemplars) > 0 {
+				metric = prometheus.MustNewMetricWithExemplars(metric, exemplars...)
+			}
+			ch <- metric
+		}
+	}
+}
+
+// promExemplars returns the histogram's exemplars, at most one per bucket
+func (h *histogram) promExemplars() []prometheus.Exemplar {
+	var exemplars []prometheus.Exemplar
+	for _, exemplar := range h.exemplars {
+		if exemplar != nil {
+			exemplars = append(exemplars, prometheus.Exemplar{
+				Value:     exemplar.Value,
+				Labels:    prometheus.Labels(exemplar.Labels),
+				Timestamp: exemplar.Timestamp,
+			})
+		}
+	}
+	return exemplars
+}
+
+// collectScalars exports counter or gauge metrics, one sample per series
+func (r *Registry) collectScalars(ch chan<- prometheus.Metric, kind prometheus.ValueType, metrics map[string]map[string]float64) {
+	for name, series := range metrics {
+		for key, value := range series {
+			ch <- prometheus.MustNewConstMetric(r.desc(name, key), kind, value)
+		}
+	}
+}
+
+// desc describes one series, carrying its labels as constant labels
+func (r *Registry) desc(name, key string) *prometheus.Desc {
+	return prometheus.NewDesc(name, name, nil, prometheus.Labels(r.labels[key]))
+}
@@ -0,0 +1,229 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Labels identifies a series within a metric
+type Labels map[string]string
+
+// Registry holds in-process counters, gauges, and histograms keyed by metric name and labels
+type Registry struct {
+	mu         sync.RWMutex
+	counters   map[string]map[string]float64    // name -> series key -> value
+	gauges     map[string]map[string]float64    // name -> series key -> value
+	histograms map[string]map[string]*histogram // name -> series key -> histogram
+	labels     map[string]Labels                // series key -> labels, for exposition
+}
+
+// NewRegistry creates an empty metrics registry
+func NewRegistry() *Registry {
+	return &Registry{
+		counters:   make(map[string]map[string]float64),
+		gauges:     make(map[string]map[string]float64),
+		histograms: make(map[string]map[string]*histogram),
+		labels:     make(map[string]Labels),
+	}
+}
+
+// IncCounter increments a counter series by one
+func (r *Registry) IncCounter(name string, labels Labels) {
+	r.AddCounter(name, labels, 1)
+}
+
+// AddCounter increments a counter series by delta
+func (r *Registry) AddCounter(name string, labels Labels, delta float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	series, exists := r.counters[name]
+	if !exists {
+		series = make(map[string]float64)
+		r.counters[name] = series
+	}
+
+	series[r.seriesKey(labels)] += delta
+}
+
+// CounterValue returns the current value of a counter series (0 if never incremented)
+func (r *Registry) CounterValue(name string, labels Labels) float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.counters[name][seriesKey(labels)]
+}
+
+// SetGauge sets a gauge series to value
+func (r *Registry) SetGauge(name string, labels Labels, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	series, exists := r.gauges[name]
+	if !exists {
+		series = make(map[string]float64)
+		r.gauges[name] = series
+	}
+
+	series[r.seriesKey(labels)] = value
+}
+
+// GaugeValue returns the current value of a gauge series (0 if never set)
+func (r *Registry) GaugeValue(name string, labels Labels) float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.gauges[name][seriesKey(labels)]
+}
+
+// seriesKey returns the key of a series being written, remembering its labels
+// Callers must hold the write lock.
+func (r *Registry) seriesKey(labels Labels) string {
+	key := seriesKey(labels)
+	if _, exists := r.labels[key]; !exists {
+		r.labels[key] = copyLabels(labels)
+	}
+	return key
+}
+
+// seriesKey renders labels in Prometheus form with sorted keys, e.g. {a="1",b="2"}
+func seriesKey(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+`="`+labels[key]+`"`)
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// histogram accumulates observations into fixed upper-bound buckets
+type histogram struct {
+	buckets   []float64
+	counts    []uint64    // counts[i] observations <= buckets[i]; last slot is +Inf
+	exemplars []*Exemplar // Latest exemplar per bucket slot (nil = none)
+	count     uint64
+	sum       float64
+}
+
+// MaxExemplarRunes is the OpenMetrics limit on an exemplar's combined label names and values
+const MaxExemplarRunes = 128
+
+// Exemplar links a histogram observation to the trace that produced it (OpenMetrics exemplar)
+type Exemplar struct {
+	Labels    Labels // e.g. {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"}
+	Value     float64
+	Timestamp time.Time
+}
+
+// HistogramSnapshot is a point-in-time copy of a histogram series
+// Counts are per bucket (not cumulative); Counts[len(Buckets)] holds observations above the last bound.
+type HistogramSnapshot struct {
+	Buckets   []float64
+	Counts    []uint64
+	Exemplars []*Exemplar // Parallel to Counts; nil where the bucket has no exemplar
+	Count     uint64
+	Sum       float64
+}
+
+// ObserveHistogram records value in a histogram series
+// The bucket bounds (ascending) are fixed by the first observation of a series.
+func (r *Registry) ObserveHistogram(name string, buckets []float64, labels Labels, value float64) {
+	r.ObserveHistogramWithExemplar(name, buckets, labels, value, nil)
+}
+
+// ObserveHistogramWithExemplar records value like ObserveHistogram and attaches exemplar
+// (e.g. the observation's trace ID) to the bucket it falls in, replacing that bucket's
+// previous exemplar. An empty exemplar, or one over MaxExemplarRunes, is not attached.
+func (r *Registry) ObserveHistogramWithExemplar(name string, buckets []float64, labels Labels, value float64, exemplar Labels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	series, exists := r.histograms[name]
+	if !exists {
+		series = make(map[string]*histogram)
+		r.histograms[name] = series
+	}
+
+	key := r.seriesKey(labels)
+	h, exists := series[key]
+	if !exists {
+		h = &histogram{
+			buckets:   append([]float64(nil), buckets...),
+			counts:    make([]uint64, len(buckets)+1),
+			exemplars: make([]*Exemplar, len(buckets)+1),
+		}
+		series[key] = h
+	}
+
+	slot := sort.SearchFloat64s(h.buckets, value)
+	h.counts[slot]++
+	h.count++
+	h.sum += value
+
+	if len(exemplar) > 0 && exemplarRunes(exemplar) <= MaxExemplarRunes {
+		h.exemplars[slot] = &Exemplar{
+			Labels:    copyLabels(exemplar),
+			Value:     value,
+			Timestamp: time.Now(),
+		}
+	}
+}
+
+// exemplarRunes counts the runes of an exemplar's label names and values
+func exemplarRunes(labels Labels) int {
+	total := 0
+	for key, value := range labels {
+		total += utf8.RuneCountInString(key) + utf8.RuneCountInString(value)
+	}
+	return total
+}
+
+// copyLabels returns a copy of labels so callers cannot mutate stored exemplars
+func copyLabels(labels Labels) Labels {
+	copied := make(Labels, len(labels))
+	for key, value := range labels {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Histogram returns a snapshot of a histogram series (zero value if never observed)
+func (r *Registry) Histogram(name string, labels Labels) HistogramSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	h, exists := r.histograms[name][seriesKey(labels)]
+	if !exists {
+		return HistogramSnapshot{}
+	}
+
+	exemplars := make([]*Exemplar, len(h.exemplars))
+	for i, exemplar := range h.exemplars {
+		if exemplar != nil {
+			copied := *exemplar
+			copied.Labels = copyLabels(exemplar.Labels)
+			exemplars[i] = &copied
+		}
+	}
+
+	return HistogramSnapshot{
+		Buckets:   append([]float64(nil), h.buckets...),
+		Counts:    append([]uint64(nil), h.counts...),
+		Exemplars: exemplars,
+		Count:     h.count,
+		Sum:       h.sum,
+	}
+}
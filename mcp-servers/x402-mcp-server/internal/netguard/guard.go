@@ -0,0 +1,126 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrPrivateAddress is returned when a URL targets a private, loopback, or link-local address
+var ErrPrivateAddress = errors.New("private, loopback, or link-local address not allowed")
+
+// ErrRedirectNotAllowed is returned when a redirect exceeds the limit or leaves the original host
+var ErrRedirectNotAllowed = errors.New("redirect not allowed")
+
+// IsPrivateIP reports whether ip is loopback, private (RFC 1918 / RFC 4193),
+// link-local, or unspecified
+func IsPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsUnspecified()
+}
+
+// CheckURL rejects URLs whose host is a private IP literal or a localhost name
+// Hostnames are not resolved here; resolved addresses are enforced at connect time
+// by the dialer used in HTTPClient, which also defeats DNS rebinding.
+func CheckURL(rawURL string, allowPrivate bool) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		return fmt.Errorf("URL has no host: %s", rawURL)
+	}
+
+	if allowPrivate {
+		return nil
+	}
+
+	lower := strings.ToLower(strings.TrimSuffix(host, "."))
+	if lower == "localhost" || strings.HasSuffix(lower, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && IsPrivateIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+
+	return nil
+}
+
+// dialer returns a dialer that refuses connections to private addresses unless allowed
+func dialer(allowPrivate bool) *net.Dialer {
+	d := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	if !allowPrivate {
+		d.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || IsPrivateIP(ip) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+			}
+			return nil
+		}
+	}
+
+	return d
+}
+
+// HTTPClient returns an HTTP client whose connections are checked against private ranges
+// after DNS resolution, so every request is guarded, not only config load
+func HTTPClient(allowPrivate bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer(allowPrivate).DialContext
+
+	return &http.Client{Transport: transport}
+}
+
+// RedirectPolicy returns an http.Client CheckRedirect that follows at most maxRedirects
+// redirects, refusing any that leave the original host (host:port) unless allowCrossHost.
+// A refused redirect fails the request with ErrRedirectNotAllowed instead of re-sending
+// the request, and possibly its body, to a location the operator never configured.
+func RedirectPolicy(maxRedirects int, allowCrossHost bool) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w: more than %d redirect(s) to %s", ErrRedirectNotAllowed, maxRedirects, req.URL.Redacted())
+		}
+		if !allowCrossHost && !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+			return fmt.Errorf("%w: cross-host redirect from %s to %s", ErrRedirectNotAllowed, via[0].URL.Host, req.URL.Host)
+		}
+		return nil
+	}
+}
+
+// DialEthClient connects to an HTTP(S) or WebSocket RPC endpoint after checking its URL,
+// routing HTTP traffic through the guarded client
+func DialEthClient(ctx context.Context, rpcURL string, allowPrivate bool) (*ethclient.Client, error) {
+	if err := CheckURL(rpcURL, allowPrivate); err != nil {
+		return nil, err
+	}
+
+	client, err := gethrpc.DialOptions(ctx, rpcURL, gethrpc.WithHTTPClient(HTTPClient(allowPrivate)))
+	if err != nil {
+		return nil, err
+	}
+
+	return ethclient.NewClient(client), nil
+}
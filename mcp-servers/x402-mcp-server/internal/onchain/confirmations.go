@@ -0,0 +1,190 @@
+package onchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+)
+
+// Settlement statuses reported from live confirmation counts
+const (
+	StatusSettled = "settled"
+	StatusPending = "pending"
+	StatusFailed  = "failed"
+)
+
+// BlockReader is the subset of the Ethereum RPC client used to count confirmations
+type BlockReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+}
+
+// ConfirmationStatus is the live confirmation state of a settlement transaction
+type ConfirmationStatus struct {
+	TxHash        string `json:"tx_hash"`
+	Status        string `json:"status"`
+	Mined         bool   `json:"mined"`
+	BlockNumber   uint64 `json:"block_number,omitempty"`
+	CurrentBlock  uint64 `json:"current_block"`
+	Confirmations uint64 `json:"confirmations"`
+	Required      uint64 `json:"required_confirmations"`
+}
+
+// ToMap converts the confirmation status to a map for MCP tool responses
+func (s *ConfirmationStatus) ToMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"tx_hash":                s.TxHash,
+		"status":                 s.Status,
+		"mined":                  s.Mined,
+		"current_block":          s.CurrentBlock,
+		"confirmations":          s.Confirmations,
+		"required_confirmations": s.Required,
+	}
+
+	if s.Mined {
+		result["block_number"] = s.BlockNumber
+	}
+
+	return result
+}
+
+// ConfirmationCounter counts confirmations of settlement transactions against each
+// network's configured minimum
+type ConfirmationCounter struct {
+	config  *config.Config
+	timeout time.Duration
+
+	mu      sync.Mutex
+	readers map[string]BlockReader
+}
+
+// NewConfirmationCounter creates a new confirmation counter
+func NewConfirmationCounter(cfg *config.Config, timeout time.Duration) *ConfirmationCounter {
+	return &ConfirmationCounter{
+		config:  cfg,
+		timeout: timeout,
+		readers: make(map[string]BlockReader),
+	}
+}
+
+// SetBackend overrides the RPC backend used for a network
+func (c *ConfirmationCounter) SetBackend(network string, reader BlockReader) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readers[network] = reader
+}
+
+// UpdateConfig swaps the counter configuration after a config reload
+// Readers of networks whose RPC connection changed are closed and redialed on next use.
+func (c *ConfirmationCounter) UpdateConfig(cfg *config.Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropClients(c.readers, c.config, cfg)
+	c.config = cfg
+}
+
+// currentConfig returns the configuration currently in effect
+func (c *ConfirmationCounter) currentConfig() *config.Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.config
+}
+
+// reader returns the RPC backend for a network, dialing the configured RPC URL on first use
+func (c *ConfirmationCounter) reader(network string, networkCfg config.NetworkConfig) (BlockReader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r, exists := c.readers[network]; exists {
+		return r, nil
+	}
+
+	client, err := rpc.DialEthClient(context.Background(), networkCfg.RPCURL, c.config.AllowPrivateURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	c.readers[network] = client
+	return client, nil
+}
+
+// Status fetches the transaction receipt and the current block number, computing
+// confirmations as current block minus the transaction's block. The transaction is
+// reported settled only once that count reaches the network's configured confirmations;
+// an unmined transaction is pending and a reverted one has failed.
+func (c *ConfirmationCounter) Status(network string, txHash string) (*ConfirmationStatus, error) {
+	networkCfg, exists := c.currentConfig().Networks[network]
+	if !exists {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	hashBytes, err := hexutil.Decode(txHash)
+	if err != nil || len(hashBytes) != common.HashLength {
+		return nil, fmt.Errorf("invalid tx_hash: must be 0x-prefixed 32-byte hex")
+	}
+	hash := common.BytesToHash(hashBytes)
+
+	reader, err := c.reader(network, networkCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), networkCfg.SettlementTimeout(c.timeout))
+	defer cancel()
+
+	result := &ConfirmationStatus{
+		TxHash:   hash.Hex(),
+		Status:   StatusPending,
+		Required: networkCfg.Confirmations,
+	}
+
+	// Step 1: Read the chain head
+	head, err := reader.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch block number: %w", err)
+	}
+	result.CurrentBlock = head
+
+	// Step 2: Locate the transaction's block; no receipt yet means it is unmined
+	receipt, err := reader.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction receipt: %w", err)
+	}
+	if receipt.BlockNumber == nil {
+		return result, nil
+	}
+
+	result.Mined = true
+	result.BlockNumber = receipt.BlockNumber.Uint64()
+
+	// Step 3: Count confirmations, treating a head behind the receipt (lagging node) as zero
+	if head > result.BlockNumber {
+		result.Confirmations = head - result.BlockNumber
+	}
+
+	if receipt.Status == types.ReceiptStatusFailed {
+		result.Status = StatusFailed
+		return result, nil
+	}
+
+	if result.Confirmations >= result.Required {
+		result.Status = StatusSettled
+	}
+
+	return result, nil
+}
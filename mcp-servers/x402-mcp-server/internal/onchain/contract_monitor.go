@@ -0,0 +1,297 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+)
+
+// MetricContractCheckFailures counts failed USDC contract health checks per network
+const MetricContractCheckFailures = "x402_usdc_contract_check_failures_total"
+
+// tokenViewsABI holds the ERC-20 views used as a cheap liveness probe
+const tokenViewsABI = `[` +
+	`{"name":"name","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},` +
+	`{"name":"decimals","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]}]`
+
+var parsedTokenViewsABI = mustParseTokenABI(tokenViewsABI)
+
+func mustParseTokenABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI definition: %v", err))
+	}
+	return parsed
+}
+
+// ContractHealth is the latest check of a network's USDC contract
+type ContractHealth struct {
+	Network   string    `json:"network"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ContractMonitor periodically calls name() and decimals() on each configured USDC contract
+// A contract that is unreachable (e.g. paused behind a reverting proxy) or reports a name
+// other than its EIP-712 domain name or decimals other than 6 marks its network degraded
+// until a later check passes again.
+type ContractMonitor struct {
+	config   *config.Config
+	metrics  *metrics.Registry
+	logger   *logger.Logger
+	timeout  time.Duration
+	interval time.Duration
+
+	mu      sync.RWMutex
+	readers map[string]StateReader
+	health  map[string]ContractHealth
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewContractMonitor creates a monitor checking every interval, bounding each check by timeout
+func NewContractMonitor(cfg *config.Config, registry *metrics.Registry, log *logger.Logger, interval, timeout time.Duration) *ContractMonitor {
+	return &ContractMonitor{
+		config:   cfg,
+		metrics:  registry,
+		logger:   log,
+		timeout:  timeout,
+		interval: interval,
+		readers:  make(map[string]StateReader),
+		health:   make(map[string]ContractHealth),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// SetBackend overrides the RPC backend used for a network
+func (m *ContractMonitor) SetBackend(network string, reader StateReader) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.readers[network] = reader
+}
+
+// UpdateConfig swaps the monitor configuration after a config reload
+// Readers of networks whose RPC connection changed are closed and redialed on the next
+// pass, and removed networks drop out of the reported health.
+func (m *ContractMonitor) UpdateConfig(cfg *config.Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dropClients(m.readers, m.config, cfg)
+	for network := range m.health {
+		if _, exists := cfg.Networks[network]; !exists {
+			delete(m.health, network)
+		}
+	}
+	m.config = cfg
+}
+
+// currentConfig returns the configuration currently in effect
+func (m *ContractMonitor) currentConfig() *config.Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.config
+}
+
+// reader returns the RPC backend for a network, dialing the configured RPC URL on first use
+func (m *ContractMonitor) reader(network string, networkCfg config.NetworkConfig) (StateReader, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r, exists := m.readers[network]; exists {
+		return r, nil
+	}
+
+	client, err := rpc.DialEthClient(context.Background(), networkCfg.RPCURL, m.config.AllowPrivateURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	m.readers[network] = client
+	return client, nil
+}
+
+// Start runs a check immediately and then every interval until Stop is called
+func (m *ContractMonitor) Start() {
+	go func() {
+		defer close(m.done)
+
+		m.RunOnce()
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				m.RunOnce()
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the background loop and waits for an in-flight pass to finish
+func (m *ContractMonitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	<-m.done
+}
+
+// RunOnce checks every configured network's USDC contract and returns the number degraded
+func (m *ContractMonitor) RunOnce() int {
+	degraded := 0
+	cfg := m.currentConfig()
+
+	for _, network := range networkNames(cfg) {
+		err := m.check(cfg, network)
+
+		m.mu.Lock()
+		previous, checked := m.health[network]
+		m.health[network] = ContractHealth{
+			Network:   network,
+			Healthy:   err == nil,
+			Error:     errorString(err),
+			CheckedAt: time.Now(),
+		}
+		m.mu.Unlock()
+
+		if err != nil {
+			degraded++
+			m.metrics.IncCounter(MetricContractCheckFailures, metrics.Labels{"network": network})
+			if !checked || previous.Healthy {
+				m.logger.Error("USDC contract check failed; network degraded", map[string]interface{}{
+					"network":  network,
+					"contract": cfg.Networks[network].USDCContract,
+					"error":    err.Error(),
+				})
+			}
+			continue
+		}
+
+		if checked && !previous.Healthy {
+			m.logger.Info("USDC contract check recovered", map[string]interface{}{
+				"network":  network,
+				"contract": cfg.Networks[network].USDCContract,
+			})
+		}
+	}
+
+	return degraded
+}
+
+// Degraded reports whether the network's most recent contract check failed
+// Networks not yet checked are not degraded.
+func (m *ContractMonitor) Degraded(network string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	health, checked := m.health[network]
+	return checked && !health.Healthy
+}
+
+// Health returns the latest check of every checked network, sorted by network
+func (m *ContractMonitor) Health() []ContractHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]ContractHealth, 0, len(m.health))
+	for _, health := range m.health {
+		result = append(result, health)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Network < result[j].Network
+	})
+	return result
+}
+
+// networkNames returns the configured network names in a stable order
+func networkNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Networks))
+	for name := range cfg.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// check calls name() and decimals() on the network's USDC contract and compares the results
+func (m *ContractMonitor) check(cfg *config.Config, network string) error {
+	networkCfg := cfg.Networks[network]
+	reader, err := m.reader(network, networkCfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	usdc := common.HexToAddress(networkCfg.USDCContract)
+
+	var name string
+	if err := callView(ctx, reader, usdc, "name", &name); err != nil {
+		return err
+	}
+	if params, err := cfg.DomainParams(network); err == nil && name != params.Name {
+		return fmt.Errorf("name() returned %q, expected %q", name, params.Name)
+	}
+
+	var decimals uint8
+	if err := callView(ctx, reader, usdc, "decimals", &decimals); err != nil {
+		return err
+	}
+	if int(decimals) != networkCfg.Decimals() {
+		return fmt.Errorf("decimals() returned %d, expected %d", decimals, networkCfg.Decimals())
+	}
+
+	return nil
+}
+
+// callView calls a no-argument view of the token ABI and decodes its single output into out
+func callView(ctx context.Context, reader StateReader, contract common.Address, method string, out interface{}) error {
+	calldata, err := parsedTokenViewsABI.Pack(method)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s(): %w", method, err)
+	}
+
+	output, err := reader.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: calldata}, nil)
+	if err != nil {
+		return fmt.Errorf("%s() call failed: %w", method, err)
+	}
+	if len(output) == 0 {
+		return fmt.Errorf("%s() returned no data (no contract at address?)", method)
+	}
+
+	if err := parsedTokenViewsABI.UnpackIntoInterface(out, method, output); err != nil {
+		return fmt.Errorf("failed to decode %s(): %w", method, err)
+	}
+
+	return nil
+}
+
+// errorString returns err's message, or "" for nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
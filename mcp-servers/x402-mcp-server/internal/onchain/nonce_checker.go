@@ -0,0 +1,187 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+)
+
+// StateReader is the subset of the Ethereum RPC client used to read USDC authorization state
+type StateReader interface {
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// NonceQuery identifies an authorization nonce of a payer
+type NonceQuery struct {
+	From  string `json:"from"`
+	Nonce string `json:"nonce"`
+}
+
+// NonceStatus is the on-chain state of one queried nonce
+// Error is set (and Used meaningless) when the nonce could not be checked.
+type NonceStatus struct {
+	From  string `json:"from"`
+	Nonce string `json:"nonce"`
+	Used  bool   `json:"used"`
+	Error string `json:"error,omitempty"`
+}
+
+// ToMap converts the status to a map for MCP tool responses
+func (s *NonceStatus) ToMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"from":  s.From,
+		"nonce": s.Nonce,
+		"used":  s.Used,
+	}
+
+	if s.Error != "" {
+		result["error"] = s.Error
+	}
+
+	return result
+}
+
+// NonceChecker reads USDC authorizationState for many (from, nonce) pairs concurrently
+// All reads for a network share one RPC client, and at most concurrency run at once.
+type NonceChecker struct {
+	config      *config.Config
+	timeout     time.Duration
+	concurrency int
+
+	mu      sync.Mutex
+	readers map[string]StateReader
+}
+
+// NewNonceChecker creates a nonce checker bounded to concurrency parallel reads per call
+func NewNonceChecker(cfg *config.Config, timeout time.Duration, concurrency int) *NonceChecker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &NonceChecker{
+		config:      cfg,
+		timeout:     timeout,
+		concurrency: concurrency,
+		readers:     make(map[string]StateReader),
+	}
+}
+
+// SetBackend overrides the RPC backend used for a network
+func (c *NonceChecker) SetBackend(network string, reader StateReader) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readers[network] = reader
+}
+
+// UpdateConfig swaps the checker configuration after a config reload
+// Readers of networks whose RPC connection changed are closed and redialed on next use.
+func (c *NonceChecker) UpdateConfig(cfg *config.Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropClients(c.readers, c.config, cfg)
+	c.config = cfg
+}
+
+// currentConfig returns the configuration currently in effect
+func (c *NonceChecker) currentConfig() *config.Config {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.config
+}
+
+// reader returns the RPC backend for a network, dialing the configured RPC URL on first use
+func (c *NonceChecker) reader(network string, networkCfg config.NetworkConfig) (StateReader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r, exists := c.readers[network]; exists {
+		return r, nil
+	}
+
+	client, err := rpc.DialEthClient(context.Background(), networkCfg.RPCURL, c.config.AllowPrivateURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	c.readers[network] = client
+	return client, nil
+}
+
+// Check returns the used/unused state of every query, in query order
+// Malformed queries and failed reads are reported per entry rather than failing the batch.
+func (c *NonceChecker) Check(network string, queries []NonceQuery) ([]NonceStatus, error) {
+	networkCfg, exists := c.currentConfig().Networks[network]
+	if !exists {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	reader, err := c.reader(network, networkCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	usdc := common.HexToAddress(networkCfg.USDCContract)
+	results := make([]NonceStatus, len(queries))
+	slots := make(chan struct{}, c.concurrency)
+
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		results[i] = NonceStatus{From: query.From, Nonce: query.Nonce}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			used, err := c.checkOne(ctx, reader, usdc, query)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Used = used
+		}()
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// checkOne reads authorizationState for a single (from, nonce) pair
+func (c *NonceChecker) checkOne(ctx context.Context, reader StateReader, usdc common.Address, query NonceQuery) (bool, error) {
+	if !common.IsHexAddress(query.From) {
+		return false, fmt.Errorf("invalid from address")
+	}
+
+	nonceBytes, err := hexutil.Decode(query.Nonce)
+	if err != nil || len(nonceBytes) != common.HashLength {
+		return false, fmt.Errorf("invalid nonce: must be 0x-prefixed 32-byte hex")
+	}
+
+	calldata, err := eip3009.EncodeAuthorizationState(common.HexToAddress(query.From), common.BytesToHash(nonceBytes))
+	if err != nil {
+		return false, err
+	}
+
+	output, err := reader.CallContract(ctx, ethereum.CallMsg{To: &usdc, Data: calldata}, nil)
+	if err != nil {
+		return false, fmt.Errorf("authorizationState call failed: %w", err)
+	}
+
+	return eip3009.DecodeAuthorizationState(output)
+}
@@ -0,0 +1,19 @@
+package onchain
+
+import "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+
+// dropClients removes and closes the clients of networks whose RPC connection changed
+// between two configs, so the next call for them dials under the new config.
+// Callers must hold the lock guarding clients.
+func dropClients[T any](clients map[string]T, oldCfg, newCfg *config.Config) {
+	for _, network := range config.RPCChangedNetworks(oldCfg, newCfg) {
+		client, exists := clients[network]
+		if !exists {
+			continue
+		}
+		delete(clients, network)
+		if closer, ok := any(client).(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+}
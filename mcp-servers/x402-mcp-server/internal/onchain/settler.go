@@ -0,0 +1,206 @@
+package onchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+)
+
+// ErrorCodeGasTooHigh is returned when the network gas price exceeds the configured ceiling
+const ErrorCodeGasTooHigh = "gas_too_high"
+
+// gasRetryAfterSeconds is the retry hint returned when settlement is aborted due to gas price
+const gasRetryAfterSeconds = 60
+
+// Backend is the subset of the Ethereum RPC client used for on-chain settlement
+type Backend interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// Settler submits receiveWithAuthorization transactions directly from a relayer account
+type Settler struct {
+	config     *config.Config
+	relayerKey *ecdsa.PrivateKey
+	timeout    time.Duration
+
+	mu       sync.Mutex
+	backends map[string]Backend
+}
+
+// NewSettler creates a new on-chain settler using the given relayer key
+func NewSettler(cfg *config.Config, relayerKey *ecdsa.PrivateKey, timeout time.Duration) *Settler {
+	return &Settler{
+		config:     cfg,
+		relayerKey: relayerKey,
+		timeout:    timeout,
+		backends:   make(map[string]Backend),
+	}
+}
+
+// LoadRelayerKey reads a hex-encoded relayer private key from the named environment variable
+func LoadRelayerKey(envVar string) (*ecdsa.PrivateKey, error) {
+	raw := os.Getenv(envVar)
+	if raw == "" {
+		return nil, fmt.Errorf("relayer key env var %s is not set", envVar)
+	}
+
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(raw, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid relayer key in %s: %w", envVar, err)
+	}
+
+	return key, nil
+}
+
+// SetBackend overrides the RPC backend used for a network
+func (s *Settler) SetBackend(network string, backend Backend) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.backends[network] = backend
+}
+
+// UpdateConfig swaps the settler configuration after a config reload
+// Backends of networks whose RPC connection changed are closed and redialed on next use.
+func (s *Settler) UpdateConfig(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dropClients(s.backends, s.config, cfg)
+	s.config = cfg
+}
+
+// currentConfig returns the configuration currently in effect
+func (s *Settler) currentConfig() *config.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.config
+}
+
+// backend returns the RPC backend for a network, dialing the configured RPC URL on first use
+func (s *Settler) backend(network string, networkCfg config.NetworkConfig) (Backend, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, exists := s.backends[network]; exists {
+		return b, nil
+	}
+
+	client, err := rpc.DialEthClient(context.Background(), networkCfg.RPCURL, s.config.AllowPrivateURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	s.backends[network] = client
+	return client, nil
+}
+
+// Settle submits the authorization on-chain
+// Returns a failed response with error_code "gas_too_high" if the current gas price
+// exceeds the network's max_gas_price_gwei, otherwise a pending response with the tx hash
+func (s *Settler) Settle(auth *eip3009.EIP3009Authorization, network string) (*facilitator.FacilitatorResponse, error) {
+	networkCfg, exists := s.currentConfig().Networks[network]
+	if !exists {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	backend, err := s.backend(network, networkCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), networkCfg.SettlementTimeout(s.timeout))
+	defer cancel()
+
+	// Step 1: Enforce gas price ceiling before spending relayer funds
+	gasPrice, err := backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	if ceiling := GweiToWei(networkCfg.MaxGasPriceGwei); ceiling != nil && gasPrice.Cmp(ceiling) > 0 {
+		return &facilitator.FacilitatorResponse{
+			Status:     "failed",
+			ErrorCode:  ErrorCodeGasTooHigh,
+			Error:      fmt.Sprintf("gas price %s wei exceeds ceiling %s wei", gasPrice.String(), ceiling.String()),
+			RetryAfter: gasRetryAfterSeconds,
+		}, nil
+	}
+
+	// Step 2: Build receiveWithAuthorization calldata
+	calldata, err := eip3009.EncodeReceiveWithAuthorization(auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build calldata: %w", err)
+	}
+
+	// Step 3: Prepare and sign the transaction
+	relayer := crypto.PubkeyToAddress(s.relayerKey.PublicKey)
+	usdc := common.HexToAddress(networkCfg.USDCContract)
+
+	txNonce, err := backend.PendingNonceAt(ctx, relayer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relayer nonce: %w", err)
+	}
+
+	gasLimit, err := backend.EstimateGas(ctx, ethereum.CallMsg{
+		From:     relayer,
+		To:       &usdc,
+		GasPrice: gasPrice,
+		Data:     calldata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas: %w", err)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    txNonce,
+		To:       &usdc,
+		Gas:      gasLimit,
+		GasPrice: gasPrice,
+		Data:     calldata,
+	})
+
+	chainID := new(big.Int).SetUint64(networkCfg.ChainID)
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), s.relayerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	// Step 4: Broadcast
+	if err := backend.SendTransaction(ctx, signedTx); err != nil {
+		return nil, fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	return &facilitator.FacilitatorResponse{
+		Status: "pending",
+		TxHash: signedTx.Hash().Hex(),
+	}, nil
+}
+
+// GweiToWei converts a gwei amount to wei, returning nil for non-positive values
+func GweiToWei(gwei float64) *big.Int {
+	if gwei <= 0 {
+		return nil
+	}
+
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(1e9)).Int(nil)
+	return wei
+}
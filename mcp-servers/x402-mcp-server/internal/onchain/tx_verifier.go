@@ -0,0 +1,200 @@
+package onchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+)
+
+// Error codes returned when a settlement transaction does not match the authorization
+const (
+	ErrorCodeTxMismatch      = "tx_mismatch"
+	ErrorCodeNotSettlementTx = "not_settlement_tx"
+)
+
+// TxReader is the subset of the Ethereum RPC client used to fetch settlement transactions
+type TxReader interface {
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+}
+
+// TxVerification is the result of comparing an on-chain transaction against an authorization
+type TxVerification struct {
+	TxHash     string   `json:"tx_hash"`
+	Match      bool     `json:"match"`
+	Pending    bool     `json:"pending"`
+	Mismatches []string `json:"mismatches,omitempty"`
+	ErrorCode  string   `json:"error_code,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// ToMap converts the verification result to a map for MCP tool responses
+func (v *TxVerification) ToMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"tx_hash": v.TxHash,
+		"match":   v.Match,
+		"pending": v.Pending,
+	}
+
+	if len(v.Mismatches) > 0 {
+		result["mismatches"] = v.Mismatches
+	}
+	if v.ErrorCode != "" {
+		result["error_code"] = v.ErrorCode
+	}
+	if v.Error != "" {
+		result["error"] = v.Error
+	}
+
+	return result
+}
+
+// TxVerifier checks that a facilitator-reported transaction settles exactly what was authorized
+type TxVerifier struct {
+	config  *config.Config
+	timeout time.Duration
+
+	mu      sync.Mutex
+	readers map[string]TxReader
+}
+
+// NewTxVerifier creates a new settlement transaction verifier
+func NewTxVerifier(cfg *config.Config, timeout time.Duration) *TxVerifier {
+	return &TxVerifier{
+		config:  cfg,
+		timeout: timeout,
+		readers: make(map[string]TxReader),
+	}
+}
+
+// SetBackend overrides the RPC backend used for a network
+func (v *TxVerifier) SetBackend(network string, reader TxReader) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.readers[network] = reader
+}
+
+// UpdateConfig swaps the verifier configuration after a config reload
+// Readers of networks whose RPC connection changed are closed and redialed on next use.
+func (v *TxVerifier) UpdateConfig(cfg *config.Config) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	dropClients(v.readers, v.config, cfg)
+	v.config = cfg
+}
+
+// currentConfig returns the configuration currently in effect
+func (v *TxVerifier) currentConfig() *config.Config {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.config
+}
+
+// reader returns the RPC backend for a network, dialing the configured RPC URL on first use
+func (v *TxVerifier) reader(network string, networkCfg config.NetworkConfig) (TxReader, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if r, exists := v.readers[network]; exists {
+		return r, nil
+	}
+
+	client, err := rpc.DialEthClient(context.Background(), networkCfg.RPCURL, v.config.AllowPrivateURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	v.readers[network] = client
+	return client, nil
+}
+
+// Verify fetches the transaction, decodes its receiveWithAuthorization calldata and
+// compares from/to/value/nonce against the authorization. A substituted parameter
+// yields Match=false with error_code "tx_mismatch" and the differing fields listed.
+func (v *TxVerifier) Verify(auth *eip3009.EIP3009Authorization, network string, txHash string) (*TxVerification, error) {
+	networkCfg, exists := v.currentConfig().Networks[network]
+	if !exists {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	hashBytes, err := hexutil.Decode(txHash)
+	if err != nil || len(hashBytes) != common.HashLength {
+		return nil, fmt.Errorf("invalid tx_hash: must be 0x-prefixed 32-byte hex")
+	}
+	hash := common.BytesToHash(hashBytes)
+
+	expected, err := auth.ToMessage()
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization: %w", err)
+	}
+
+	reader, err := v.reader(network, networkCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), networkCfg.SettlementTimeout(v.timeout))
+	defer cancel()
+
+	// Step 1: Fetch the transaction
+	tx, pending, err := reader.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transaction: %w", err)
+	}
+
+	result := &TxVerification{
+		TxHash:  hash.Hex(),
+		Pending: pending,
+	}
+
+	// Step 2: The transaction must target the configured USDC contract
+	usdc := common.HexToAddress(networkCfg.USDCContract)
+	if tx.To() == nil || *tx.To() != usdc {
+		result.ErrorCode = ErrorCodeNotSettlementTx
+		result.Error = fmt.Sprintf("transaction does not call the USDC contract %s", usdc.Hex())
+		return result, nil
+	}
+
+	// Step 3: Decode receiveWithAuthorization parameters
+	decoded, err := eip3009.DecodeReceiveWithAuthorization(tx.Data())
+	if err != nil {
+		result.ErrorCode = ErrorCodeNotSettlementTx
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	// Step 4: Compare against what was authorized
+	if common.HexToAddress(decoded.From) != expected.From {
+		result.Mismatches = append(result.Mismatches, "from")
+	}
+	if common.HexToAddress(decoded.To) != expected.To {
+		result.Mismatches = append(result.Mismatches, "to")
+	}
+	if value, ok := new(big.Int).SetString(decoded.Value, 10); !ok || value.Cmp(expected.Value) != 0 {
+		result.Mismatches = append(result.Mismatches, "value")
+	}
+	if common.HexToHash(decoded.Nonce) != common.Hash(expected.Nonce) {
+		result.Mismatches = append(result.Mismatches, "nonce")
+	}
+
+	if len(result.Mismatches) > 0 {
+		result.ErrorCode = ErrorCodeTxMismatch
+		result.Error = fmt.Sprintf("on-chain parameters differ from authorization: %s", strings.Join(result.Mismatches, ", "))
+		return result, nil
+	}
+
+	result.Match = true
+	return result, nil
+}
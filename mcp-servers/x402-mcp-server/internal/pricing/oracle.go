@@ -0,0 +1,178 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/netguard"
+)
+
+// FiatUSD is the fiat currency priced directly by oracles
+const FiatUSD = "USD"
+
+// fiatAmountPattern validates plain decimal fiat amounts (e.g., "4.99")
+var fiatAmountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// PriceOracle reports the USD price of one whole token of an asset
+type PriceOracle interface {
+	USDPerToken(asset string) (float64, error)
+}
+
+// StaticOracle prices assets from configuration
+// Assets without an entry are priced at 1.0 USD, the peg of the USDC assets the server accepts.
+type StaticOracle struct {
+	prices map[string]float64
+}
+
+// NewStaticOracle creates an oracle from USD prices keyed by asset address (any case)
+func NewStaticOracle(prices map[string]float64) *StaticOracle {
+	normalized := make(map[string]float64, len(prices))
+	for asset, price := range prices {
+		normalized[strings.ToLower(asset)] = price
+	}
+	return &StaticOracle{prices: normalized}
+}
+
+// USDPerToken implements PriceOracle
+func (o *StaticOracle) USDPerToken(asset string) (float64, error) {
+	if price, exists := o.prices[strings.ToLower(asset)]; exists {
+		return price, nil
+	}
+	return 1.0, nil
+}
+
+// HTTPOracle fetches prices from GET <url>?asset=<address>, expecting {"usd_per_token": <price>}
+type HTTPOracle struct {
+	url     string
+	client  *http.Client
+	timeout time.Duration
+}
+
+// NewHTTPOracle creates an oracle querying baseURL; private addresses are refused unless allowPrivate
+func NewHTTPOracle(baseURL string, timeout time.Duration, allowPrivate bool) *HTTPOracle {
+	return &HTTPOracle{
+		url:     baseURL,
+		client:  netguard.HTTPClient(allowPrivate),
+		timeout: timeout,
+	}
+}
+
+// USDPerToken implements PriceOracle
+func (o *HTTPOracle) USDPerToken(asset string) (float64, error) {
+	endpoint, err := url.Parse(o.url)
+	if err != nil {
+		return 0, fmt.Errorf("invalid oracle URL: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("asset", asset)
+	endpoint.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build oracle request: %w", err)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("price oracle request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("price oracle returned HTTP %d", resp.StatusCode)
+	}
+
+	var body struct {
+		USDPerToken *float64 `json:"usd_per_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("invalid price oracle response: %w", err)
+	}
+	if body.USDPerToken == nil {
+		return 0, fmt.Errorf("price oracle response has no usd_per_token")
+	}
+
+	return *body.USDPerToken, nil
+}
+
+// NewOracle builds the oracle selected by the pricing configuration
+func NewOracle(cfg *config.PricingConfig, allowPrivate bool) (PriceOracle, error) {
+	switch cfg.Oracle {
+	case "", config.PriceOracleStatic:
+		return NewStaticOracle(cfg.StaticPrices), nil
+	case config.PriceOracleHTTP:
+		return NewHTTPOracle(cfg.OracleURL, cfg.Timeout(), allowPrivate), nil
+	default:
+		return nil, fmt.Errorf("unsupported price oracle: %s", cfg.Oracle)
+	}
+}
+
+// FiatToAtomic converts amountFiat (a decimal string) in currency to the asset's atomic units
+// Non-USD currencies use usdPerFiat. The result is rounded up so a fiat price is never undercharged.
+func FiatToAtomic(oracle PriceOracle, asset, amountFiat, currency string, usdPerFiat map[string]float64, decimals int) (*big.Int, error) {
+	if !fiatAmountPattern.MatchString(amountFiat) {
+		return nil, fmt.Errorf("amount_fiat must be a positive decimal, got %q", amountFiat)
+	}
+	amount, ok := new(big.Rat).SetString(amountFiat)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("amount_fiat must be a positive decimal, got %q", amountFiat)
+	}
+
+	// Convert the fiat amount to USD
+	currency = strings.ToUpper(currency)
+	if currency != FiatUSD {
+		rate, exists := usdPerFiat[currency]
+		if !exists {
+			return nil, fmt.Errorf("no exchange rate configured for fiat_currency %s (pricing.usd_per_fiat)", currency)
+		}
+		if !validPrice(rate) {
+			return nil, fmt.Errorf("invalid exchange rate %v for fiat_currency %s", rate, currency)
+		}
+		amount.Mul(amount, decimalRat(rate))
+	}
+
+	price, err := oracle.USDPerToken(asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to price %s: %w", asset, err)
+	}
+	if !validPrice(price) {
+		return nil, fmt.Errorf("price oracle returned invalid price %v for %s", price, asset)
+	}
+
+	// tokens = usd / price; atomic = ceil(tokens * 10^decimals)
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	atomic := amount.Quo(amount, decimalRat(price))
+	atomic.Mul(atomic, new(big.Rat).SetInt(scale))
+
+	quotient, remainder := new(big.Int).QuoRem(atomic.Num(), atomic.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+
+	return quotient, nil
+}
+
+// validPrice reports whether a price or rate is a positive finite number
+func validPrice(f float64) bool {
+	return f > 0 && !math.IsInf(f, 0)
+}
+
+// decimalRat converts f to its shortest decimal representation (1.08, not its binary
+// approximation), so configured rates do not skew rounded-up amounts
+func decimalRat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
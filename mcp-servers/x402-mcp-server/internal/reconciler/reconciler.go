@@ -0,0 +1,216 @@
+package reconciler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+)
+
+const (
+	// MetricTransitions counts pending settlements resolved by reconciliation
+	MetricTransitions = "x402_settlement_reconciliations_total"
+
+	// MetricBacklog gauges the pending settlements the latest pass left for later passes
+	MetricBacklog = "x402_settlement_reconciliation_backlog"
+)
+
+// Reconciler periodically re-checks pending settlements with the facilitator
+type Reconciler struct {
+	client   *facilitator.Client
+	audit    audit.Store
+	metrics  *metrics.Registry
+	logger   *logger.Logger
+	interval time.Duration
+	batch    int // Maximum settlements checked per pass (0 = all)
+
+	unattestedMaxAge time.Duration // Unattested settlements pending longer are failed (0 = never)
+
+	mu     sync.Mutex                     // Serializes passes
+	cursor *facilitator.PendingSettlement // Last settlement checked by an unfinished round (nil = start from the oldest)
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a reconciler for the client's pending settlements
+// Each pass checks at most batchSize settlements (0 = all); the rest carry over to later passes.
+func New(
+	client *facilitator.Client,
+	auditStore audit.Store,
+	registry *metrics.Registry,
+	log *logger.Logger,
+	interval time.Duration,
+	batchSize int,
+) *Reconciler {
+	return &Reconciler{
+		client:   client,
+		audit:    auditStore,
+		metrics:  registry,
+		logger:   log,
+		interval: interval,
+		batch:    batchSize,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// ExpireUnattestedAfter fails settlements still pending on a missing or invalid
+// attestation once they have been pending longer than maxAge (0 = never), so an
+// unsigned facilitator response cannot stay pending indefinitely
+func (r *Reconciler) ExpireUnattestedAfter(maxAge time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unattestedMaxAge = maxAge
+}
+
+// Start runs reconciliation every interval until Stop is called
+func (r *Reconciler) Start() {
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.RunOnce()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the background loop and waits for an in-flight pass to finish
+func (r *Reconciler) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	<-r.done
+}
+
+// RunOnce re-checks the next batch of pending settlements and returns the number that transitioned
+func (r *Reconciler) RunOnce() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	transitions := 0
+
+	for _, pending := range r.nextBatch(r.client.PendingSettlements()) {
+		response, err := r.client.GetSettlementStatus(pending.Network, pending.Nonce)
+		if err != nil {
+			r.logger.Warn("Settlement status check failed", map[string]interface{}{
+				"network": pending.Network,
+				"nonce":   pending.Nonce,
+				"error":   err.Error(),
+			})
+			if response = r.expireUnattested(pending); response == nil {
+				continue
+			}
+		}
+
+		r.client.UpdateSettlement(pending.Network, pending.Nonce, response)
+
+		if response.Status == "pending" {
+			continue
+		}
+
+		transitions++
+		r.recordTransition(pending, response)
+	}
+
+	return transitions
+}
+
+// expireUnattested returns a failed response for a settlement that is still unattested
+// (its status check failed attestation again) past the unattested max age, or nil
+func (r *Reconciler) expireUnattested(pending facilitator.PendingSettlement) *facilitator.FacilitatorResponse {
+	if r.unattestedMaxAge <= 0 || pending.Response.ErrorCode != facilitator.ErrorCodeAttestationInvalid {
+		return nil
+	}
+	age := time.Since(pending.Since)
+	if age < r.unattestedMaxAge {
+		return nil
+	}
+
+	return &facilitator.FacilitatorResponse{
+		Status:    "failed",
+		Error:     fmt.Sprintf("facilitator response still unattested after %s pending", age.Truncate(time.Second)),
+		ErrorCode: facilitator.ErrorCodeAttestationExpired,
+	}
+}
+
+// nextBatch selects the settlements to check this pass from the oldest-first pending list
+// A round walks the list in batches, resuming after the last settlement checked, so
+// settlements that stay pending cannot starve newer ones; the next round starts again
+// from the oldest. The number left unchecked is reported as the backlog.
+func (r *Reconciler) nextBatch(pending []facilitator.PendingSettlement) []facilitator.PendingSettlement {
+	if r.batch <= 0 || len(pending) <= r.batch {
+		r.cursor = nil
+		r.metrics.SetGauge(MetricBacklog, nil, 0)
+		return pending
+	}
+
+	start := 0
+	if r.cursor != nil {
+		cursor := *r.cursor
+		start = sort.Search(len(pending), func(i int) bool {
+			return cursor.Before(pending[i])
+		})
+		if start == len(pending) {
+			start = 0
+		}
+	}
+
+	end := min(start+r.batch, len(pending))
+	batch := pending[start:end]
+	if end == len(pending) {
+		r.cursor = nil
+	} else {
+		last := batch[len(batch)-1]
+		r.cursor = &last
+	}
+
+	r.metrics.SetGauge(MetricBacklog, nil, float64(len(pending)-len(batch)))
+	return batch
+}
+
+// recordTransition emits the audit record, metric, and log for a resolved settlement
+func (r *Reconciler) recordTransition(pending facilitator.PendingSettlement, response *facilitator.FacilitatorResponse) {
+	if err := r.audit.Append(audit.Record{
+		Event:          audit.EventSettlementReconciled,
+		Network:        pending.Network,
+		Nonce:          pending.Nonce,
+		PreviousStatus: pending.Response.Status,
+		Status:         response.Status,
+		TxHash:         response.TxHash,
+		Error:          response.Error,
+	}); err != nil {
+		r.logger.Error("Failed to write audit record", map[string]interface{}{
+			"error": err.Error(),
+			"nonce": pending.Nonce,
+		})
+	}
+
+	r.metrics.IncCounter(MetricTransitions, metrics.Labels{
+		"network": pending.Network,
+		"status":  response.Status,
+	})
+
+	r.logger.Info("Pending settlement reconciled", map[string]interface{}{
+		"network":     pending.Network,
+		"nonce":       pending.Nonce,
+		"status":      response.Status,
+		"tx_hash":     response.TxHash,
+		"pending_for": time.Since(pending.Since).String(),
+	})
+}
@@ -0,0 +1,130 @@
+package refunds
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+)
+
+// MetricEnqueueFailures counts refunds due that could not be queued
+const MetricEnqueueFailures = "x402_refund_enqueue_failures_total"
+
+// Entry is the excess of an accepted overpayment owed back to the payer
+// The server holds no payee key, so the payee's operator sends the refund from this record.
+type Entry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Network     string    `json:"network"`
+	Asset       string    `json:"asset"`        // Token contract the overpayment was made in
+	From        string    `json:"from"`         // Payee that received the overpayment and owes the refund
+	To          string    `json:"to"`           // Payer the refund is owed to
+	Amount      string    `json:"amount"`       // Excess in atomic units
+	AmountHuman string    `json:"amount_human"` // Excess in token units
+	Nonce       string    `json:"nonce"`        // Nonce of the settled authorization
+	TxHash      string    `json:"tx_hash,omitempty"`
+}
+
+// Queue holds refunds due for the operator to send
+type Queue interface {
+	Enqueue(entry Entry) error
+	Entries() ([]Entry, error)
+}
+
+// FileQueue appends refunds due to a JSON-lines file
+type FileQueue struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileQueue creates a queue appending to path, which is created on first enqueue
+func NewFileQueue(path string) *FileQueue {
+	return &FileQueue{
+		path: path,
+	}
+}
+
+// Enqueue writes entry as one JSON line, stamping it with the current time if unset
+func (f *FileQueue) Enqueue(entry Entry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode refund: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open refund queue: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write refund: %w", err)
+	}
+
+	return file.Sync()
+}
+
+// Entries reads all queued refunds in enqueue order; a missing file holds none
+func (f *FileQueue) Entries() ([]Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open refund queue: %w", err)
+	}
+	defer file.Close()
+
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("refund queue line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read refund queue: %w", err)
+	}
+
+	return entries, nil
+}
+
+// NopQueue drops every refund (used when no refund path is configured)
+type NopQueue struct{}
+
+// Enqueue implements Queue
+func (NopQueue) Enqueue(entry Entry) error {
+	return nil
+}
+
+// Entries implements Queue
+func (NopQueue) Entries() ([]Entry, error) {
+	return nil, nil
+}
+
+// NewQueue builds the queue selected by the refunds configuration
+func NewQueue(cfg *config.RefundsConfig) Queue {
+	if !cfg.Enabled() {
+		return NopQueue{}
+	}
+	return NewFileQueue(cfg.Path)
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// balanceOfABI is the ERC-20 balanceOf view
+const balanceOfABI = `[{"name":"balanceOf","type":"function","stateMutability":"view",` +
+	`"inputs":[{"name":"account","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}]`
+
+var parsedBalanceOfABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(balanceOfABI))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI definition: %v", err))
+	}
+	return parsed
+}()
+
+// ContractCaller is the subset of the Ethereum RPC client used to read token balances
+type ContractCaller interface {
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// BalanceFetcher reads ERC-20 (USDC) token balances from an Ethereum RPC endpoint
+type BalanceFetcher struct {
+	caller  ContractCaller
+	timeout time.Duration
+}
+
+// NewBalanceFetcher creates a balance fetcher for the RPC URL, bounded by the shared limiter
+// Private/loopback endpoints are refused unless allowPrivate is set
+func NewBalanceFetcher(rpcURL string, allowPrivate bool) (*BalanceFetcher, error) {
+	client, err := DialEthClient(context.Background(), rpcURL, allowPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	return NewBalanceFetcherWithBackend(client), nil
+}
+
+// NewBalanceFetcherWithBackend creates a balance fetcher reading through caller
+func NewBalanceFetcherWithBackend(caller ContractCaller) *BalanceFetcher {
+	return &BalanceFetcher{
+		caller:  caller,
+		timeout: 10 * time.Second,
+	}
+}
+
+// Close closes the RPC client connection, if the backend holds one
+func (bf *BalanceFetcher) Close() {
+	if closer, ok := bf.caller.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// BalanceOf returns account's balance of token in atomic units at the latest block
+func (bf *BalanceFetcher) BalanceOf(token, account common.Address) (*big.Int, error) {
+	calldata, err := parsedBalanceOfABI.Pack("balanceOf", account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode balanceOf: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bf.timeout)
+	defer cancel()
+
+	output, err := bf.caller.CallContract(ctx, ethereum.CallMsg{To: &token, Data: calldata}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("balanceOf call failed: %w", err)
+	}
+
+	values, err := parsedBalanceOfABI.Unpack("balanceOf", output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode balanceOf result from %s: %w", token.Hex(), err)
+	}
+
+	balance, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected balanceOf result type %T", values[0])
+	}
+
+	return balance, nil
+}
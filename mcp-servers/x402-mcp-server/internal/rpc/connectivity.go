@@ -0,0 +1,27 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+)
+
+// CheckChainID verifies the RPC endpoint is reachable and serves the expected chain
+// Private/loopback endpoints are refused unless allowPrivate is set
+func CheckChainID(ctx context.Context, rpcURL string, expected uint64, allowPrivate bool) error {
+	client, err := DialEthClient(ctx, rpcURL, allowPrivate)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	if !chainID.IsUint64() || chainID.Uint64() != expected {
+		return fmt.Errorf("RPC chain ID %s does not match configured chain ID %d", chainID.String(), expected)
+	}
+
+	return nil
+}
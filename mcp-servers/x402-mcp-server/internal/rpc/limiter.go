@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	gethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/netguard"
+)
+
+// ErrTooManyCalls is returned when no RPC slot frees up within the limiter's queue timeout
+var ErrTooManyCalls = errors.New("too many concurrent RPC calls")
+
+// DefaultQueueTimeout is how long a call waits for a free slot when rpc.queue_timeout_ms is unset
+const DefaultQueueTimeout = 5 * time.Second
+
+// Limiter caps concurrent RPC requests with a counting semaphore
+// Calls beyond the cap queue until a slot frees up or the queue timeout passes.
+type Limiter struct {
+	slots        chan struct{} // nil = unlimited
+	queueTimeout time.Duration
+}
+
+// NewLimiter creates a limiter allowing max concurrent calls (0 or less = unlimited)
+func NewLimiter(max int, queueTimeout time.Duration) *Limiter {
+	if queueTimeout <= 0 {
+		queueTimeout = DefaultQueueTimeout
+	}
+
+	l := &Limiter{queueTimeout: queueTimeout}
+	if max > 0 {
+		l.slots = make(chan struct{}, max)
+	}
+	return l
+}
+
+// Max returns the concurrent call cap, or 0 when unlimited
+func (l *Limiter) Max() int {
+	return cap(l.slots)
+}
+
+// QueueTimeout returns how long a call waits for a free slot
+func (l *Limiter) QueueTimeout() time.Duration {
+	return l.queueTimeout
+}
+
+// Acquire takes a slot, waiting up to the queue timeout; each successful Acquire must be
+// paired with Release
+func (l *Limiter) Acquire(ctx context.Context) error {
+	if l.slots == nil {
+		return nil
+	}
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w: no slot of %d free after %s", ErrTooManyCalls, cap(l.slots), l.queueTimeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *Limiter) Release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// shared is the process-wide limiter used by every client from DialEthClient
+var shared atomic.Pointer[Limiter]
+
+func init() {
+	shared.Store(NewLimiter(0, 0))
+}
+
+// SetShared replaces the process-wide limiter, e.g. from rpc.max_concurrent at startup and
+// on config reload. Calls already holding a slot release it to the limiter they took it from.
+func SetShared(l *Limiter) {
+	shared.Store(l)
+}
+
+// Shared returns the process-wide limiter
+func Shared() *Limiter {
+	return shared.Load()
+}
+
+// limitedTransport holds a shared limiter slot for the duration of each HTTP round trip
+type limitedTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := Shared()
+	if err := limiter.Acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	defer limiter.Release()
+
+	return t.base.RoundTrip(req)
+}
+
+// DialEthClient connects to an RPC endpoint like netguard.DialEthClient, additionally
+// bounding its HTTP requests by the shared limiter so nonce, balance, code, and state reads
+// across all features stay within rpc.max_concurrent
+func DialEthClient(ctx context.Context, rpcURL string, allowPrivate bool) (*ethclient.Client, error) {
+	if err := netguard.CheckURL(rpcURL, allowPrivate); err != nil {
+		return nil, err
+	}
+
+	return dialLimited(ctx, rpcURL, netguard.HTTPClient(allowPrivate).Transport)
+}
+
+// dialLimited connects to an RPC endpoint, routing HTTP requests through base under the shared limiter
+func dialLimited(ctx context.Context, rpcURL string, base http.RoundTripper) (*ethclient.Client, error) {
+	httpClient := &http.Client{Transport: &limitedTransport{base: base}}
+
+	client, err := gethrpc.DialOptions(ctx, rpcURL, gethrpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+
+	return ethclient.NewClient(client), nil
+}
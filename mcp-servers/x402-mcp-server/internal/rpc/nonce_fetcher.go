@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"net/http"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -20,7 +21,7 @@ type NonceFetcher struct {
 
 // NewNonceFetcher creates a new nonce fetcher with the specified RPC URL
 func NewNonceFetcher(rpcURL string) (*NonceFetcher, error) {
-	client, err := ethclient.Dial(rpcURL)
+	client, err := dialLimited(context.Background(), rpcURL, http.DefaultTransport)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
 	}
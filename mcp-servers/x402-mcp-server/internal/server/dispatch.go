@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ErrorCodeToolDisabled is returned for calls to a tool disabled by tools.disabled or tools.enabled
+const ErrorCodeToolDisabled = "tool_disabled"
+
+// registerExecutable registers an MCP handler that dispatches calls through ExecuteTool,
+// so client calls get the same tools.disabled, readiness and timeout handling
+func (s *Server) registerExecutable(mcpServer *server.MCPServer, tool ExecutableTool) error {
+	schema, err := json.Marshal(tool.Schema())
+	if err != nil {
+		return fmt.Errorf("invalid input schema: %w", err)
+	}
+
+	name := tool.Name()
+	mcpServer.AddTool(mcp.NewToolWithRawSchema(name, tool.Description(), schema),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := s.executeTool(name, request.GetArguments(), traceIDFromMeta(request.Params.Meta))
+			if err != nil {
+				return toolErrorResult(err), nil
+			}
+			return toolResult(result), nil
+		})
+
+	return nil
+}
+
+// toolResult converts a tool's return value into an MCP result; maps and structs are
+// returned as structured content with a JSON text fallback
+func toolResult(result interface{}) *mcp.CallToolResult {
+	if text, ok := result.(string); ok {
+		return mcp.NewToolResultText(text)
+	}
+	return mcp.NewToolResultStructuredOnly(result)
+}
+
+// toolErrorResult reports a failed call inside the result, as MCP expects for tool errors
+func toolErrorResult(err error) *mcp.CallToolResult {
+	if !errors.Is(err, ErrToolDisabled) {
+		return mcp.NewToolResultError(err.Error())
+	}
+
+	result := mcp.NewToolResultStructuredOnly(map[string]interface{}{
+		"error":      err.Error(),
+		"error_code": ErrorCodeToolDisabled,
+	})
+	result.IsError = true
+	return result
+}
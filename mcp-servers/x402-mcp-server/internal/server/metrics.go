@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+)
+
+// MetricToolExecutions counts tool calls per tool and status (success, error, timeout)
+const MetricToolExecutions = "x402_tool_executions_total"
+
+// MetricToolDuration is the histogram of tool call duration (seconds) per tool
+const MetricToolDuration = "x402_tool_duration_seconds"
+
+// ToolDurationBuckets are the histogram upper bounds (seconds) for MetricToolDuration
+var ToolDurationBuckets = []float64{0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Tool call statuses recorded in MetricToolExecutions
+const (
+	ToolStatusSuccess = "success"
+	ToolStatusError   = "error"
+	ToolStatusTimeout = "timeout"
+)
+
+// recordToolExecution counts a tool call and observes how long it took. The observation
+// carries the call's trace ID as an exemplar when tracing and metrics are both enabled.
+func (s *Server) recordToolExecution(name, status string, duration time.Duration, traceID string) {
+	s.metrics.IncCounter(MetricToolExecutions, metrics.Labels{"tool": name, "status": status})
+
+	var exemplar metrics.Labels
+	if traceID != "" && s.GetConfig().ExemplarsEnabled() {
+		exemplar = metrics.Labels{"trace_id": traceID}
+	}
+	s.metrics.ObserveHistogramWithExemplar(MetricToolDuration, ToolDurationBuckets, metrics.Labels{"tool": name}, duration.Seconds(), exemplar)
+}
+
+// StartMetricsListener serves the metrics registry at /metrics on metrics.port when
+// metrics.enabled is set; scrapers asking for OpenMetrics also receive exemplars. It returns once the port is bound; requests are served in the
+// background for the life of the process. Returns nil without listening when disabled.
+func (s *Server) StartMetricsListener() error {
+	cfg := s.GetConfig().Metrics
+	if !cfg.Enabled {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", cfg.ListenAddr())
+	if err != nil {
+		return fmt.Errorf("metrics listener: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(config.MetricsPath, metrics.Handler(s.metrics))
+
+	s.logger.Info("Serving metrics", map[string]interface{}{
+		"addr": listener.Addr().String(),
+		"path": config.MetricsPath,
+	})
+
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			s.logger.Error("Metrics listener stopped", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	return nil
+}
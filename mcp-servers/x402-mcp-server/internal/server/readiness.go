@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ErrorCodeNotReady is returned for tool calls made before startup warmup completes
+const ErrorCodeNotReady = "not_ready"
+
+// Readiness states
+const (
+	ReadinessWarmingUp = "warming_up" // Warmup running; tool calls are rejected
+	ReadinessReady     = "ready"      // All warmup checks passed
+	ReadinessDegraded  = "degraded"   // Warmup timed out; serving with failed checks
+)
+
+// warmupRetryInterval is the delay between attempts of a failing warmup check
+const warmupRetryInterval = 500 * time.Millisecond
+
+// WarmupCheck is run before the server accepts tool calls (cache pre-warm, connectivity)
+type WarmupCheck func(ctx context.Context) error
+
+// namedWarmupCheck pairs a warmup check with its name for logging
+type namedWarmupCheck struct {
+	name  string
+	check WarmupCheck
+}
+
+// readiness tracks warmup progress; a server without warmup is ready immediately
+type readiness struct {
+	mu     sync.RWMutex
+	state  string
+	failed []string
+	done   chan struct{}
+	checks []namedWarmupCheck
+}
+
+func newReadiness() *readiness {
+	done := make(chan struct{})
+	close(done)
+
+	return &readiness{
+		state: ReadinessReady,
+		done:  done,
+	}
+}
+
+// AddWarmupCheck registers a check to run when StartWarmup is called
+func (s *Server) AddWarmupCheck(name string, check WarmupCheck) {
+	s.readiness.mu.Lock()
+	defer s.readiness.mu.Unlock()
+
+	s.readiness.checks = append(s.readiness.checks, namedWarmupCheck{name: name, check: check})
+}
+
+// StartWarmup gates tool execution until every warmup check succeeds. Failing checks are
+// retried until readiness.warmup_timeout_seconds elapses, after which the server serves
+// degraded. Returns a channel closed once tool calls are accepted.
+func (s *Server) StartWarmup() <-chan struct{} {
+	r := s.readiness
+
+	r.mu.Lock()
+	if r.state == ReadinessWarmingUp {
+		r.mu.Unlock()
+		return r.done
+	}
+	checks := append([]namedWarmupCheck(nil), r.checks...)
+	done := make(chan struct{})
+	r.state = ReadinessWarmingUp
+	r.failed = nil
+	r.done = done
+	r.mu.Unlock()
+
+	timeout := s.GetConfig().Readiness.WarmupTimeout()
+	s.logger.Info("Starting warmup", map[string]interface{}{
+		"checks":     len(checks),
+		"timeout_ms": timeout.Milliseconds(),
+	})
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		var failedMu sync.Mutex
+		failed := make([]string, 0)
+
+		for _, c := range checks {
+			wg.Add(1)
+			go func(c namedWarmupCheck) {
+				defer wg.Done()
+				if err := runWarmupCheck(ctx, c.check); err != nil {
+					s.logger.Warn("Warmup check did not pass", map[string]interface{}{
+						"check": c.name,
+						"error": err.Error(),
+					})
+					failedMu.Lock()
+					failed = append(failed, c.name)
+					failedMu.Unlock()
+				}
+			}(c)
+		}
+		wg.Wait()
+
+		r.mu.Lock()
+		if len(failed) > 0 {
+			r.state = ReadinessDegraded
+			r.failed = failed
+		} else {
+			r.state = ReadinessReady
+		}
+		state := r.state
+		r.mu.Unlock()
+		close(done)
+
+		s.logger.Info("Warmup finished", map[string]interface{}{
+			"state":         state,
+			"failed_checks": failed,
+		})
+	}()
+
+	return done
+}
+
+// runWarmupCheck retries a check until it succeeds or the warmup deadline passes
+func runWarmupCheck(ctx context.Context, check WarmupCheck) error {
+	for {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(warmupRetryInterval):
+		}
+	}
+}
+
+// Readiness returns the current readiness state and the names of failed warmup checks
+func (s *Server) Readiness() (string, []string) {
+	s.readiness.mu.RLock()
+	defer s.readiness.mu.RUnlock()
+
+	return s.readiness.state, append([]string(nil), s.readiness.failed...)
+}
+
+// accepting reports whether tool calls are served (ready or degraded)
+func (s *Server) accepting() bool {
+	state, _ := s.Readiness()
+	return state != ReadinessWarmingUp
+}
+
+// notReadyResult is returned in place of a tool result while warming up
+func notReadyResult() map[string]interface{} {
+	return map[string]interface{}{
+		"error":       "server is warming up",
+		"error_code":  ErrorCodeNotReady,
+		"retry_after": 1,
+	}
+}
@@ -1,23 +1,51 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/cache"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/deadletter"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/events"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/pricing"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/refunds"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 // Server represents the x402 MCP server instance
 type Server struct {
-	config *config.Config
-	logger *logger.Logger
-	cache  *cache.TTLCache
-	tools  []Tool
+	configMu      sync.RWMutex
+	config        *config.Config
+	reloadHandler []ReloadHandler
+	logger        *logger.Logger
+	cache         *cache.TTLCache
+	metrics       *metrics.Registry
+	audit         audit.Store
+	deadLetters   deadletter.Store
+	refunds       refunds.Queue
+	events        events.Publisher
+	prices        pricing.PriceOracle
+	rValues       *eip3009.RValueMonitor
+	verifications cache.Store
+	seenNonces    cache.Store // Nonces verified or settled, behind verify_payment's already_seen
+	contracts     *onchain.ContractMonitor
+	readiness     *readiness
+	tools         []Tool
 }
 
+// ReloadHandler is notified after the server configuration has been replaced
+type ReloadHandler func(oldCfg, newCfg *config.Config)
+
 // Tool represents an MCP tool handler
 type Tool interface {
 	Name() string
@@ -26,6 +54,18 @@ type Tool interface {
 	Register(s *server.MCPServer) error
 }
 
+// ExecutableTool is a tool that can be invoked through Server.ExecuteTool
+type ExecutableTool interface {
+	Tool
+	Execute(args map[string]interface{}) (interface{}, error)
+}
+
+// ErrToolDisabled is returned when invoking a tool disabled by configuration
+var ErrToolDisabled = errors.New("tool disabled")
+
+// contractCheckTimeout bounds each network's USDC contract health check
+const contractCheckTimeout = 10 * time.Second
+
 // NewServer creates a new x402 server instance
 func NewServer(cfg *config.Config, log *logger.Logger) (*Server, error) {
 	if cfg == nil {
@@ -35,16 +75,54 @@ func NewServer(cfg *config.Config, log *logger.Logger) (*Server, error) {
 		return nil, fmt.Errorf("logger cannot be nil")
 	}
 
+	// Fail fast: every tool needs at least one usable network
+	if len(cfg.Networks) == 0 {
+		return nil, fmt.Errorf("no networks configured: at least one network must be defined under 'networks'")
+	}
+	for name, network := range cfg.Networks {
+		if err := network.Validate(); err != nil {
+			return nil, fmt.Errorf("network %s: %w", name, err)
+		}
+	}
+
+	allowConfiguredAssets(cfg)
+	limitRPC(cfg)
+
+	// Settlement events go nowhere unless a publisher is configured
+	publisher, err := events.NewPublisher(&cfg.Events)
+	if err != nil {
+		return nil, fmt.Errorf("events: %w", err)
+	}
+
+	// Fiat-priced requirements are converted with the configured oracle
+	oracle, err := pricing.NewOracle(&cfg.Pricing, cfg.AllowPrivateURLs)
+	if err != nil {
+		return nil, fmt.Errorf("pricing: %w", err)
+	}
+
 	// Initialize cache with configured TTL
-	cacheTTL := time.Duration(cfg.Cache.SettlementTTLMinutes) * time.Minute
+	cacheTTL := cfg.Cache.SettledTTL()
 	settlementCache := cache.NewTTLCache(cacheTTL)
 
 	srv := &Server{
-		config: cfg,
-		logger: log,
-		cache:  settlementCache,
-		tools:  make([]Tool, 0),
+		config:        cfg,
+		logger:        log,
+		cache:         settlementCache,
+		metrics:       metrics.NewRegistry(),
+		audit:         audit.NewStore(&cfg.Audit),
+		deadLetters:   deadletter.NewStore(&cfg.DeadLetter),
+		refunds:       refunds.NewQueue(&cfg.Refunds),
+		events:        publisher,
+		prices:        oracle,
+		rValues:       eip3009.NewRValueMonitor(eip3009.RValueWindow),
+		verifications: cache.NewMemoryStore(eip3009.VerificationResultTTL),
+		seenNonces:    cache.NewMemoryStore(eip3009.VerificationResultTTL),
+		readiness:     newReadiness(),
+		tools:         make([]Tool, 0),
 	}
+	srv.rValues.OnReuse(srv.alertRValueReuse)
+	srv.contracts = onchain.NewContractMonitor(cfg, srv.metrics, log,
+		time.Duration(cfg.ContractChecks.IntervalSeconds)*time.Second, contractCheckTimeout)
 
 	// Initialize tools (will be added in subsequent phases)
 	if err := srv.initializeTools(); err != nil {
@@ -54,6 +132,23 @@ func NewServer(cfg *config.Config, log *logger.Logger) (*Server, error) {
 	return srv, nil
 }
 
+// allowConfiguredAssets permits each network's configured USDC contract in payment requirements
+func allowConfiguredAssets(cfg *config.Config) {
+	for name, network := range cfg.Networks {
+		x402.AllowAsset(name, network.USDCContract)
+	}
+}
+
+// limitRPC applies rpc.max_concurrent to the limiter shared by all RPC clients
+// The limiter is only replaced when its settings change, so a reload keeps queued calls in order.
+func limitRPC(cfg *config.Config) {
+	next := rpc.NewLimiter(cfg.RPC.MaxConcurrent, cfg.RPC.QueueTimeout())
+	if current := rpc.Shared(); current.Max() == next.Max() && current.QueueTimeout() == next.QueueTimeout() {
+		return
+	}
+	rpc.SetShared(next)
+}
+
 // initializeTools sets up all available MCP tools
 func (s *Server) initializeTools() error {
 	s.logger.Debug("Initializing MCP tools", nil)
@@ -84,7 +179,14 @@ func (s *Server) RegisterTools(mcpServer *server.MCPServer) error {
 	})
 
 	for _, tool := range s.tools {
-		if err := tool.Register(mcpServer); err != nil {
+		// Executable tools are served through ExecuteTool; others register their own handler
+		var err error
+		if executable, ok := tool.(ExecutableTool); ok {
+			err = s.registerExecutable(mcpServer, executable)
+		} else {
+			err = tool.Register(mcpServer)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to register tool %s: %w", tool.Name(), err)
 		}
 
@@ -101,11 +203,67 @@ func (s *Server) ToolCount() int {
 	return len(s.tools)
 }
 
+// ToolNames returns the names of registered tools enabled by the current configuration,
+// in registration order
+func (s *Server) ToolNames() []string {
+	cfg := s.GetConfig()
+	names := make([]string, 0, len(s.tools))
+	for _, tool := range s.tools {
+		if cfg.Tools.IsEnabled(tool.Name()) {
+			names = append(names, tool.Name())
+		}
+	}
+	return names
+}
+
 // GetConfig returns the server configuration
+// Safe to call concurrently with ReloadConfig
 func (s *Server) GetConfig() *config.Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
 	return s.config
 }
 
+// OnConfigReload registers a handler invoked after each successful config reload
+func (s *Server) OnConfigReload(handler ReloadHandler) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	s.reloadHandler = append(s.reloadHandler, handler)
+}
+
+// ReloadConfig validates and swaps in a new configuration, then notifies reload handlers
+// An invalid configuration is rejected and the running configuration is left untouched
+func (s *Server) ReloadConfig(cfg *config.Config) error {
+	if cfg == nil {
+		return fmt.Errorf("config cannot be nil")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	allowConfiguredAssets(cfg)
+	limitRPC(cfg)
+
+	s.configMu.Lock()
+	oldCfg := s.config
+	s.config = cfg
+	handlers := append([]ReloadHandler(nil), s.reloadHandler...)
+	s.configMu.Unlock()
+
+	s.contracts.UpdateConfig(cfg)
+	for _, handler := range handlers {
+		handler(oldCfg, cfg)
+	}
+
+	s.logger.Info("Configuration reloaded", map[string]interface{}{
+		"networks_changed": config.DomainChangedNetworks(oldCfg, cfg),
+	})
+
+	return nil
+}
+
 // GetLogger returns the server logger
 func (s *Server) GetLogger() *logger.Logger {
 	return s.logger
@@ -116,15 +274,128 @@ func (s *Server) GetCache() *cache.TTLCache {
 	return s.cache
 }
 
+// GetMetrics returns the metrics registry
+func (s *Server) GetMetrics() *metrics.Registry {
+	return s.metrics
+}
+
+// GetAuditStore returns the audit record store
+func (s *Server) GetAuditStore() audit.Store {
+	return s.audit
+}
+
+// GetDeadLetterStore returns the store for permanently failed settlements
+func (s *Server) GetDeadLetterStore() deadletter.Store {
+	return s.deadLetters
+}
+
+// GetRefundQueue returns the queue of refunds due on accepted overpayments
+func (s *Server) GetRefundQueue() refunds.Queue {
+	return s.refunds
+}
+
+// GetEventPublisher returns the settlement event publisher
+func (s *Server) GetEventPublisher() events.Publisher {
+	return s.events
+}
+
+// SetEventPublisher replaces the settlement event publisher (e.g., with an in-memory one in tests)
+// Call during setup, before settlements run.
+func (s *Server) SetEventPublisher(publisher events.Publisher) {
+	s.events = publisher
+}
+
+// GetPriceOracle returns the oracle converting fiat amounts to token amounts
+func (s *Server) GetPriceOracle() pricing.PriceOracle {
+	return s.prices
+}
+
+// SetPriceOracle replaces the price oracle (e.g., with a mock in tests)
+// Call during setup, before requirements are created.
+func (s *Server) SetPriceOracle(oracle pricing.PriceOracle) {
+	s.prices = oracle
+}
+
+// GetContractMonitor returns the USDC contract health monitor
+// It only runs once started (see contract_checks.interval_seconds).
+func (s *Server) GetContractMonitor() *onchain.ContractMonitor {
+	return s.contracts
+}
+
+// NetworkDegraded reports whether the network's USDC contract failed its latest health check
+func (s *Server) NetworkDegraded(network string) bool {
+	return s.contracts.Degraded(network)
+}
+
+// GetVerificationStore returns the store of successful verification results shared by all verifiers
+func (s *Server) GetVerificationStore() cache.Store {
+	return s.verifications
+}
+
+// SetVerificationStore replaces the verification result store (e.g., with one shared by
+// several replicas). Call during setup, before creating tools.
+func (s *Server) SetVerificationStore(store cache.Store) {
+	s.verifications = store
+}
+
+// GetSeenNonceStore returns the store of authorization nonces already verified or settled,
+// shared by verify_payment and settle_payment
+func (s *Server) GetSeenNonceStore() cache.Store {
+	return s.seenNonces
+}
+
+// GetRValueMonitor returns the signature r value reuse monitor shared by all verifiers
+func (s *Server) GetRValueMonitor() *eip3009.RValueMonitor {
+	return s.rValues
+}
+
+// alertRValueReuse raises a CRITICAL alert: the signer's private key can be computed from
+// the two signatures, so the payer must stop using it
+func (s *Server) alertRValueReuse(reuse eip3009.RValueReuse) {
+	s.metrics.IncCounter(eip3009.MetricRValueReuse, metrics.Labels{"network": reuse.Network})
+	s.logger.Critical("Signature r value reused: signer private key is exposed", map[string]interface{}{
+		"network":        reuse.Network,
+		"signer":         reuse.Signer,
+		"r":              reuse.R,
+		"first_message":  reuse.FirstMessage.Hex(),
+		"second_message": reuse.SecondMessage.Hex(),
+		"mode":           s.GetConfig().Verification.RValueReuse,
+	})
+}
+
 // AddTool adds a tool to the server's tool registry
 func (s *Server) AddTool(tool Tool) error {
 	if tool == nil {
 		return fmt.Errorf("tool cannot be nil")
 	}
 
-	// Check for duplicate tool names
-	for _, existingTool := range s.tools {
-		if existingTool.Name() == tool.Name() {
+	// Disabled tools are skipped so they are never registered with the MCP server
+	if !s.GetConfig().Tools.IsEnabled(tool.Name()) {
+		s.logger.Info("Tool disabled by configuration", map[string]interface{}{
+			"tool": tool.Name(),
+		})
+		return nil
+	}
+
+	// Resolve duplicate tool names according to tools.on_duplicate
+	for i, existingTool := range s.tools {
+		if existingTool.Name() != tool.Name() {
+			continue
+		}
+
+		switch s.GetConfig().Tools.OnDuplicate {
+		case config.DuplicateToolReplace:
+			s.tools[i] = tool
+			s.logger.Warn("Replaced duplicate tool", map[string]interface{}{
+				"tool": tool.Name(),
+			})
+			return nil
+		case config.DuplicateToolSkip:
+			s.logger.Warn("Skipped duplicate tool", map[string]interface{}{
+				"tool": tool.Name(),
+			})
+			return nil
+		default:
 			return fmt.Errorf("tool with name %s already registered", tool.Name())
 		}
 	}
@@ -138,3 +409,37 @@ func (s *Server) AddTool(tool Tool) error {
 
 	return nil
 }
+
+// ExecuteTool invokes a registered tool by name; MCP tools/call requests are dispatched here
+// Returns ErrToolDisabled if the tool is disabled by the current configuration, and a
+// result with error_code "not_ready" while startup warmup is still running, or
+// error_code "tool_timeout" when the call exceeds its limits.tool_timeouts budget
+func (s *Server) ExecuteTool(name string, args map[string]interface{}) (interface{}, error) {
+	return s.executeTool(name, args, "")
+}
+
+// executeTool runs ExecuteTool for a call made under traceID ("" = untraced)
+func (s *Server) executeTool(name string, args map[string]interface{}, traceID string) (interface{}, error) {
+	if !s.GetConfig().Tools.IsEnabled(name) {
+		return nil, fmt.Errorf("%w: %s", ErrToolDisabled, name)
+	}
+
+	if !s.accepting() {
+		return notReadyResult(), nil
+	}
+
+	for _, tool := range s.tools {
+		if tool.Name() != name {
+			continue
+		}
+
+		executable, ok := tool.(ExecutableTool)
+		if !ok {
+			return nil, fmt.Errorf("tool %s is not executable", name)
+		}
+
+		return s.executeWithTimeout(executable, name, args, traceID)
+	}
+
+	return nil, fmt.Errorf("unknown tool: %s", name)
+}
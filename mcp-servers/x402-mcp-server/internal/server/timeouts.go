@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ErrorCodeToolTimeout is returned when a tool call exceeds its limits.tool_timeouts budget
+const ErrorCodeToolTimeout = "tool_timeout"
+
+// TimeoutReporter is implemented by tools whose calls have side effects that outlive a
+// timeout, e.g. a settlement submission. Its result replaces the generic tool_timeout error,
+// so the caller learns the call is still running rather than that it failed.
+type TimeoutReporter interface {
+	TimeoutResult(args map[string]interface{}, timeoutMs int64) map[string]interface{}
+}
+
+// toolOutcome carries the result of a tool call back from its goroutine
+type toolOutcome struct {
+	result interface{}
+	err    error
+}
+
+// executeWithTimeout runs the tool under its configured deadline. Tools do not take a
+// context, so a call that overruns keeps running in the background and its result is
+// discarded; tools implementing TimeoutReporter describe that in-flight call to the caller.
+// Every call's status and duration is recorded in the metrics registry.
+func (s *Server) executeWithTimeout(tool ExecutableTool, name string, args map[string]interface{}, traceID string) (interface{}, error) {
+	timeout := s.GetConfig().Limits.ToolTimeout(name)
+	startTime := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan toolOutcome, 1)
+	go func() {
+		result, err := tool.Execute(args)
+		done <- toolOutcome{result: result, err: err}
+	}()
+
+	select {
+	case outcome := <-done:
+		status := ToolStatusSuccess
+		if outcome.err != nil {
+			status = ToolStatusError
+		}
+		s.recordToolExecution(name, status, time.Since(startTime), traceID)
+		return outcome.result, outcome.err
+	case <-ctx.Done():
+		s.recordToolExecution(name, ToolStatusTimeout, time.Since(startTime), traceID)
+		s.GetLogger().Warn("Tool call exceeded its timeout", map[string]interface{}{
+			"tool":       name,
+			"timeout_ms": timeout.Milliseconds(),
+		})
+		if reporter, ok := tool.(TimeoutReporter); ok {
+			return reporter.TimeoutResult(args, timeout.Milliseconds()), nil
+		}
+		return toolTimeoutResult(name, timeout.Milliseconds()), nil
+	}
+}
+
+func toolTimeoutResult(name string, timeoutMs int64) map[string]interface{} {
+	return map[string]interface{}{
+		"error":      fmt.Sprintf("tool %s did not complete within %dms", name, timeoutMs),
+		"error_code": ErrorCodeToolTimeout,
+		"timeout_ms": timeoutMs,
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TraceparentMetaKey is the tools/call _meta field carrying the caller's W3C trace context
+const TraceparentMetaKey = "traceparent"
+
+// traceIDFromMeta returns the trace ID of the W3C traceparent
+// ("00-<32 hex trace-id>-<16 hex parent-id>-<2 hex flags>") in a request's _meta, or ""
+// when it is absent or malformed
+func traceIDFromMeta(meta *mcp.Meta) string {
+	if meta == nil {
+		return ""
+	}
+	traceparent, ok := meta.AdditionalFields[TraceparentMetaKey].(string)
+	if !ok {
+		return ""
+	}
+
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ""
+	}
+	for _, part := range parts[:4] {
+		if !isLowerHex(part) {
+			return ""
+		}
+	}
+	// Version 00 has exactly four fields; all-zero IDs are invalid
+	if (parts[0] == "00" && len(parts) != 4) ||
+		parts[1] == strings.Repeat("0", 32) || parts[2] == strings.Repeat("0", 16) {
+		return ""
+	}
+
+	return parts[1]
+}
+
+// isLowerHex reports whether s is lowercase hexadecimal, as traceparent fields must be
+func isLowerHex(s string) bool {
+	if _, err := hex.DecodeString(s); err != nil {
+		return false
+	}
+	return strings.ToLower(s) == s
+}
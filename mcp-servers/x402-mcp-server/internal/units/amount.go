@@ -0,0 +1,82 @@
+package units
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// USDCDecimals is the number of decimals used by USDC on all supported networks
+const USDCDecimals = 6
+
+// humanAmountPattern validates non-negative decimal amounts (e.g., "0.05", "12", "1.500000")
+var humanAmountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ToAtomic converts a human-readable amount to atomic units with the given decimals
+// Returns an error if the amount has more fractional digits than the asset supports
+func ToAtomic(human string, decimals int) (*big.Int, error) {
+	if !humanAmountPattern.MatchString(human) {
+		return nil, fmt.Errorf("invalid amount format: %s", human)
+	}
+
+	whole, fraction, _ := strings.Cut(human, ".")
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("amount %s has more than %d decimal places", human, decimals)
+	}
+
+	// Right-pad the fraction so whole+fraction is the atomic integer
+	digits := whole + fraction + strings.Repeat("0", decimals-len(fraction))
+
+	atomic, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount: %s", human)
+	}
+
+	return atomic, nil
+}
+
+// ToHuman converts atomic units to a human-readable amount with the given decimals
+// Trailing fractional zeros are trimmed (e.g., 50000 with 6 decimals becomes "0.05")
+func ToHuman(atomic *big.Int, decimals int) string {
+	if decimals == 0 {
+		return atomic.String()
+	}
+
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	whole, fraction := new(big.Int).QuoRem(atomic, divisor, new(big.Int))
+
+	fractionStr := fraction.String()
+	fractionStr = strings.Repeat("0", decimals-len(fractionStr)) + fractionStr
+	fractionStr = strings.TrimRight(fractionStr, "0")
+	if fractionStr == "" {
+		return whole.String()
+	}
+
+	return whole.String() + "." + fractionStr
+}
+
+// ToHumanGrouped is ToHuman with the whole part grouped in thousands (e.g., "1,000.5")
+// For display only; grouped amounts are not accepted by ToAtomic.
+func ToHumanGrouped(atomic *big.Int, decimals int) string {
+	human := ToHuman(atomic, decimals)
+
+	whole, fraction, hasFraction := strings.Cut(human, ".")
+	sign := ""
+	if strings.HasPrefix(whole, "-") {
+		sign, whole = "-", whole[1:]
+	}
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	if hasFraction {
+		return sign + grouped.String() + "." + fraction
+	}
+	return sign + grouped.String()
+}
@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the raw request body as "sha256=<hex>"
+const SignatureHeader = "X-X402-Signature"
+
+// MetricCallbacks counts accepted settlement callbacks
+const MetricCallbacks = "x402_settlement_callbacks_total"
+
+// maxBodyBytes bounds the size of a callback payload
+const maxBodyBytes = 1 << 20
+
+// Listener timeouts, so slow or stalled clients cannot hold callback connections open
+const (
+	readHeaderTimeout = 5 * time.Second
+	readTimeout       = 10 * time.Second
+	writeTimeout      = 10 * time.Second
+)
+
+// Callback is the settlement status a facilitator posts once a settlement resolves
+type Callback struct {
+	Network       string `json:"network"`
+	Nonce         string `json:"nonce"`
+	Status        string `json:"status"` // settled | pending | failed
+	TxHash        string `json:"tx_hash,omitempty"`
+	BlockNumber   uint64 `json:"block_number,omitempty"`
+	Confirmations uint64 `json:"confirmations,omitempty"`
+	Error         string `json:"error,omitempty"`
+	ErrorCode     string `json:"error_code,omitempty"`
+}
+
+// Validate checks the callback identifies a settlement and carries a known status
+func (c *Callback) Validate() error {
+	if c.Network == "" {
+		return fmt.Errorf("network is required")
+	}
+	if c.Nonce == "" {
+		return fmt.Errorf("nonce is required")
+	}
+
+	switch c.Status {
+	case "settled", "pending", "failed":
+		return nil
+	default:
+		return fmt.Errorf("invalid status: %s", c.Status)
+	}
+}
+
+// Response converts the callback into the facilitator response cached for the settlement
+func (c *Callback) Response() *facilitator.FacilitatorResponse {
+	return &facilitator.FacilitatorResponse{
+		Status:        c.Status,
+		TxHash:        c.TxHash,
+		BlockNumber:   c.BlockNumber,
+		Confirmations: c.Confirmations,
+		Error:         c.Error,
+		ErrorCode:     c.ErrorCode,
+	}
+}
+
+// Sign returns the SignatureHeader value for body under secret
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Handler receives facilitator settlement callbacks as an alternative to polling
+// Authenticated callbacks update the settlement cache (moving the linked pending
+// settlement to its final status) and emit an audit record and metric.
+type Handler struct {
+	client  *facilitator.Client
+	secret  []byte
+	audit   audit.Store
+	metrics *metrics.Registry
+	logger  *logger.Logger
+}
+
+// NewHandler creates a webhook handler verifying callbacks with the shared secret
+func NewHandler(
+	client *facilitator.Client,
+	secret []byte,
+	auditStore audit.Store,
+	registry *metrics.Registry,
+	log *logger.Logger,
+) *Handler {
+	return &Handler{
+		client:  client,
+		secret:  secret,
+		audit:   auditStore,
+		metrics: registry,
+		logger:  log,
+	}
+}
+
+// NewServer returns the HTTP server for the callback listener on addr, serving handler at
+// path with read and write timeouts
+func NewServer(addr, path string, handler http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"error": "method not allowed"})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, map[string]interface{}{"error": "body too large"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "unreadable body"})
+		return
+	}
+
+	// Step 1: Authenticate before parsing anything
+	if !h.validSignature(r.Header.Get(SignatureHeader), body) {
+		h.logger.Warn("Rejected settlement callback with invalid signature", map[string]interface{}{
+			"remote_addr": r.RemoteAddr,
+		})
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid signature"})
+		return
+	}
+
+	// Step 2: Parse and validate the callback
+	var callback Callback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid JSON"})
+		return
+	}
+	if err := callback.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	// Step 3: Transition the cached settlement
+	previousStatus := ""
+	if previous := h.client.CachedSettlement(callback.Network, callback.Nonce); previous != nil {
+		previousStatus = previous.Status
+	}
+	h.client.UpdateSettlement(callback.Network, callback.Nonce, callback.Response())
+
+	// Step 4: Record the transition
+	if err := h.audit.Append(audit.Record{
+		Event:          audit.EventSettlementCallback,
+		Network:        callback.Network,
+		Nonce:          callback.Nonce,
+		PreviousStatus: previousStatus,
+		Status:         callback.Status,
+		TxHash:         callback.TxHash,
+		Error:          callback.Error,
+	}); err != nil {
+		h.logger.Error("Failed to write audit record", map[string]interface{}{
+			"error": err.Error(),
+			"nonce": callback.Nonce,
+		})
+	}
+
+	h.metrics.IncCounter(MetricCallbacks, metrics.Labels{
+		"network": callback.Network,
+		"status":  callback.Status,
+	})
+
+	h.logger.Info("Settlement callback received", map[string]interface{}{
+		"network":         callback.Network,
+		"nonce":           callback.Nonce,
+		"status":          callback.Status,
+		"previous_status": previousStatus,
+		"tx_hash":         callback.TxHash,
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"received": true})
+}
+
+// validSignature compares the header against the expected HMAC in constant time
+func (h *Handler) validSignature(header string, body []byte) bool {
+	if len(h.secret) == 0 || !strings.HasPrefix(header, "sha256=") {
+		return false
+	}
+
+	return hmac.Equal([]byte(header), []byte(Sign(h.secret, body)))
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
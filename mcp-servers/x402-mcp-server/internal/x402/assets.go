@@ -0,0 +1,71 @@
+package x402
+
+import (
+	"strings"
+	"sync"
+)
+
+// usdcContracts maps each supported network to its native USDC contract
+var usdcContracts = map[string]string{
+	"base":             "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+	"base-sepolia":     "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+	"arbitrum":         "0xaf88d065e77c8cC2239327C5EDb3A432268e5831",
+	"arbitrum-sepolia": "0x75faf114eafb1BDbe2F0316DF893fd58CE46AA4d",
+	"optimism":         "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85",
+}
+
+// networkDisplayNames maps each supported network to its name in human-readable text
+var networkDisplayNames = map[string]string{
+	"base":             "Base",
+	"base-sepolia":     "Base Sepolia",
+	"arbitrum":         "Arbitrum",
+	"arbitrum-sepolia": "Arbitrum Sepolia",
+	"optimism":         "Optimism",
+}
+
+// NetworkDisplayName returns the network's human-readable name, or the network itself when unknown
+func NetworkDisplayName(network string) string {
+	if name, known := networkDisplayNames[network]; known {
+		return name
+	}
+	return network
+}
+
+// AssetSymbol returns "USDC" for the network's native USDC, or "" for any other asset
+func AssetSymbol(network, asset string) string {
+	if usdc, known := usdcContracts[network]; known && strings.EqualFold(usdc, asset) {
+		return "USDC"
+	}
+	return ""
+}
+
+// allowedAssets holds additional per-network assets registered with AllowAsset
+var (
+	allowedAssetsMu sync.RWMutex
+	allowedAssets   = make(map[string]map[string]bool)
+)
+
+// AllowAsset permits asset as a payment asset on network in addition to its native USDC
+// The server registers each network's configured usdc_contract at startup and on reload.
+func AllowAsset(network, asset string) {
+	allowedAssetsMu.Lock()
+	defer allowedAssetsMu.Unlock()
+
+	if allowedAssets[network] == nil {
+		allowedAssets[network] = make(map[string]bool)
+	}
+	allowedAssets[network][strings.ToLower(asset)] = true
+}
+
+// IsAllowedAsset reports whether asset is the network's native USDC or an allowlisted asset
+// Addresses are compared case-insensitively.
+func IsAllowedAsset(network, asset string) bool {
+	if usdc, known := usdcContracts[network]; known && strings.EqualFold(usdc, asset) {
+		return true
+	}
+
+	allowedAssetsMu.RLock()
+	defer allowedAssetsMu.RUnlock()
+
+	return allowedAssets[network][strings.ToLower(asset)]
+}
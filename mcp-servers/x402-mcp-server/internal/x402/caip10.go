@@ -0,0 +1,16 @@
+package x402
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CAIP2Namespace is the CAIP-2 chain namespace for EVM networks
+const CAIP2Namespace = "eip155"
+
+// FormatCAIP10 renders an EVM address as a CAIP-10 account ID (eip155:<chainId>:<address>)
+// The address is emitted in EIP-55 checksum form as recommended for the eip155 namespace
+func FormatCAIP10(chainID uint64, address string) string {
+	return fmt.Sprintf("%s:%d:%s", CAIP2Namespace, chainID, common.HexToAddress(address).Hex())
+}
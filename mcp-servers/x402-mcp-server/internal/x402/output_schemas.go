@@ -0,0 +1,95 @@
+package x402
+
+import "sort"
+
+// Output schema template names selectable for a requirement's outputSchema
+const (
+	OutputSchemaCertificationReceipt = "certification-receipt" // Notarization receipt for a certified document
+	OutputSchemaJSONResource         = "json-resource"         // Generic JSON body with data and metadata
+	OutputSchemaFileDownload         = "file-download"         // Link to a downloadable file with its digest
+)
+
+// outputSchemaTemplates builds each template's JSON schema; every call returns a fresh copy
+// so callers can extend the schema without affecting other requirements
+var outputSchemaTemplates = map[string]func() map[string]interface{}{
+	OutputSchemaCertificationReceipt: func() map[string]interface{} {
+		return objectSchema(
+			[]string{"certificate_id", "content_hash", "issued_at", "signature"},
+			map[string]interface{}{
+				"certificate_id": stringSchema("Unique identifier of the issued certificate"),
+				"content_hash":   stringSchema("0x-prefixed SHA-256 hash of the certified content"),
+				"issued_at":      dateTimeSchema("When the certificate was issued"),
+				"signature":      stringSchema("Notary signature over the certificate"),
+				"tx_hash":        stringSchema("Settlement transaction hash of the payment"),
+			},
+		)
+	},
+	OutputSchemaJSONResource: func() map[string]interface{} {
+		return objectSchema(
+			[]string{"data"},
+			map[string]interface{}{
+				"data": map[string]interface{}{
+					"description": "Resource payload",
+				},
+				"metadata": map[string]interface{}{
+					"type":        "object",
+					"description": "Optional information about the payload",
+				},
+			},
+		)
+	},
+	OutputSchemaFileDownload: func() map[string]interface{} {
+		return objectSchema(
+			[]string{"url", "sha256", "expires_at"},
+			map[string]interface{}{
+				"url":          stringSchema("Download URL for the paid file"),
+				"sha256":       stringSchema("Hex SHA-256 digest of the file"),
+				"size_bytes":   map[string]interface{}{"type": "integer", "minimum": 0},
+				"content_type": stringSchema("MIME type of the file"),
+				"expires_at":   dateTimeSchema("When the download URL stops working"),
+			},
+		)
+	},
+}
+
+// OutputSchemaTemplate returns a copy of the named output schema template
+func OutputSchemaTemplate(name string) (map[string]interface{}, bool) {
+	build, exists := outputSchemaTemplates[name]
+	if !exists {
+		return nil, false
+	}
+	return build(), true
+}
+
+// OutputSchemaTemplateNames returns the available template names in sorted order
+func OutputSchemaTemplateNames() []string {
+	names := make([]string, 0, len(outputSchemaTemplates))
+	for name := range outputSchemaTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func objectSchema(required []string, properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func stringSchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": description,
+	}
+}
+
+func dateTimeSchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"format":      "date-time",
+		"description": description,
+	}
+}
@@ -0,0 +1,116 @@
+package x402
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PaymentPayload is the object an x402 client sends (base64-encoded) in the X-PAYMENT header
+// per official Coinbase x402 specification
+type PaymentPayload struct {
+	X402Version int             `json:"x402Version"`
+	Scheme      string          `json:"scheme"`
+	Network     string          `json:"network"`
+	Payload     ExactEVMPayload `json:"payload"`
+}
+
+// ExactEVMPayload is the "exact" scheme payload for EVM networks: an EIP-3009
+// authorization and its 65-byte signature
+type ExactEVMPayload struct {
+	Signature     string                `json:"signature"` // 0x-prefixed r || s || v
+	Authorization ExactEVMAuthorization `json:"authorization"`
+}
+
+// ExactEVMAuthorization holds the signed EIP-3009 message fields
+// Timestamps are decimal strings in the x402 wire format; numbers are accepted too.
+type ExactEVMAuthorization struct {
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Value       string      `json:"value"`
+	ValidAfter  json.Number `json:"validAfter"`
+	ValidBefore json.Number `json:"validBefore"`
+	Nonce       string      `json:"nonce"`
+}
+
+// signaturePattern validates a 65-byte hex signature
+var signaturePattern = regexp.MustCompile(`^0x[a-fA-F0-9]{130}$`)
+
+// ParsePaymentPayload decodes a payment payload given as JSON or as the base64-encoded
+// JSON carried in the X-PAYMENT header, and validates it
+func ParsePaymentPayload(data string) (*PaymentPayload, error) {
+	trimmed := strings.TrimSpace(data)
+	raw := []byte(trimmed)
+	if !strings.HasPrefix(trimmed, "{") {
+		decoded, err := base64.StdEncoding.DecodeString(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("payment payload is neither JSON nor base64: %w", err)
+		}
+		raw = decoded
+	}
+
+	// Accept the version under either wire name, as a number or a string
+	raw, err := normalizeVersion(raw, VersionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse payment payload: %w", err)
+	}
+
+	var payload PaymentPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse payment payload: %w", err)
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid payment payload: %w", err)
+	}
+
+	return &payload, nil
+}
+
+// Validate checks the payload envelope and signature format
+// The authorization fields are validated when mapped to an EIP-3009 authorization.
+func (p *PaymentPayload) Validate() error {
+	if _, err := ParseVersion(p.X402Version); err != nil {
+		return fmt.Errorf("invalid x402Version: %w", err)
+	}
+
+	if !IsSupportedScheme(p.Scheme) {
+		return &UnsupportedSchemeError{Scheme: p.Scheme}
+	}
+
+	if p.Network == "" {
+		return fmt.Errorf("network is required")
+	}
+
+	if !signaturePattern.MatchString(p.Payload.Signature) {
+		return fmt.Errorf("invalid signature: must be a 65-byte hex string")
+	}
+
+	return nil
+}
+
+// SplitSignature returns the signature's v/r/s components, normalizing v to 27 or 28
+func (p *ExactEVMPayload) SplitSignature() (uint8, string, string, error) {
+	if !signaturePattern.MatchString(p.Signature) {
+		return 0, "", "", fmt.Errorf("invalid signature: must be a 65-byte hex string")
+	}
+
+	digits := p.Signature[2:]
+	r := "0x" + digits[0:64]
+	s := "0x" + digits[64:128]
+
+	var v uint8
+	if _, err := fmt.Sscanf(digits[128:130], "%02x", &v); err != nil {
+		return 0, "", "", fmt.Errorf("invalid signature v: %w", err)
+	}
+	if v < 27 {
+		v += 27
+	}
+	if v != 27 && v != 28 {
+		return 0, "", "", fmt.Errorf("invalid signature v: must be 27 or 28, got %d", v)
+	}
+
+	return v, r, s, nil
+}
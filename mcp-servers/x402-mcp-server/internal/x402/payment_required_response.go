@@ -0,0 +1,59 @@
+package x402
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultPaymentRequiredError is the error message returned with a 402 when no payment was sent
+const DefaultPaymentRequiredError = "X-PAYMENT header is required"
+
+// PaymentRequiredResponse is the JSON body a resource server returns with HTTP 402 Payment Required
+// per official Coinbase x402 specification
+type PaymentRequiredResponse struct {
+	X402Version int                   `json:"x402Version"`
+	Error       string                `json:"error"`
+	Accepts     []*PaymentRequirement `json:"accepts"`
+}
+
+// NewPaymentRequiredResponse wraps one or more payment requirements in a 402 response body
+func NewPaymentRequiredResponse(errorMessage string, accepts ...*PaymentRequirement) (*PaymentRequiredResponse, error) {
+	if len(accepts) == 0 {
+		return nil, fmt.Errorf("at least one payment requirement is required")
+	}
+
+	for i, requirement := range accepts {
+		if err := requirement.Validate(); err != nil {
+			return nil, fmt.Errorf("accepts[%d]: %w", i, err)
+		}
+	}
+
+	if errorMessage == "" {
+		errorMessage = DefaultPaymentRequiredError
+	}
+
+	return &PaymentRequiredResponse{
+		X402Version: Version,
+		Error:       errorMessage,
+		Accepts:     accepts,
+	}, nil
+}
+
+// ToJSON converts the response to the JSON body served with HTTP 402
+func (r *PaymentRequiredResponse) ToJSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// ToMap converts the response to a map for MCP tool output
+func (r *PaymentRequiredResponse) ToMap() map[string]interface{} {
+	accepts := make([]interface{}, 0, len(r.Accepts))
+	for _, requirement := range r.Accepts {
+		accepts = append(accepts, requirement.ToMap())
+	}
+
+	return map[string]interface{}{
+		"x402Version": r.X402Version,
+		"error":       r.Error,
+		"accepts":     accepts,
+	}
+}
@@ -5,11 +5,17 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"math/big"
 	"regexp"
 	"time"
 )
 
+// Requirement error codes
+const (
+	ErrorCodeInvalidRequirement = "invalid_requirement" // requirement fails Validate
+	ErrorCodeRequirementExpired = "expired"             // requirement's valid_until has passed
+	ErrorCodeTimeoutExceeded    = "timeout_exceeded"    // settled later than maxTimeoutSeconds after issuance
+)
+
 // PaymentRequirement represents an x402-compliant payment requirement
 // per official Coinbase x402 specification
 type PaymentRequirement struct {
@@ -48,12 +54,20 @@ var (
 
 	// Supported networks
 	supportedNetworks = map[string]bool{
-		"base":         true,
-		"base-sepolia": true,
-		"arbitrum":     true,
+		"base":             true,
+		"base-sepolia":     true,
+		"arbitrum":         true,
+		"arbitrum-sepolia": true,
+		"optimism":         true,
 	}
 )
 
+// SupportedNetworks returns the network names accepted in payment requirements, in the
+// order tool schemas advertise them
+func SupportedNetworks() []string {
+	return []string{"base", "base-sepolia", "arbitrum", "arbitrum-sepolia", "optimism"}
+}
+
 // NewPaymentRequirement creates a new x402-compliant payment requirement
 // per official Coinbase x402 specification
 func NewPaymentRequirement(
@@ -86,6 +100,11 @@ func NewPaymentRequirement(
 		return nil, fmt.Errorf("invalid asset address format")
 	}
 
+	// Reject tokens other than the network's USDC (or an allowlisted asset)
+	if !IsAllowedAsset(network, asset) {
+		return nil, fmt.Errorf("asset %s is not an allowed asset on %s", asset, network)
+	}
+
 	// Validate required fields
 	if resource == "" {
 		return nil, fmt.Errorf("resource URL is required")
@@ -124,31 +143,26 @@ func NewPaymentRequirement(
 		},
 
 		// Extension fields
-		X402Version: 1,
+		X402Version: Version,
 		ValidUntil:  validUntil.Format(time.RFC3339),
 		Nonce:       nonce,
 	}, nil
 }
 
+// nonceBytes is the nonce length: 256 bits, matching an EIP-3009 bytes32 nonce
+const nonceBytes = 32
+
 // generateNonce creates a cryptographically secure random nonce
-// Combines timestamp with random bytes for uniqueness
+// The nonce is drawn entirely from crypto/rand, which is safe for concurrent use; a
+// timestamp component would add predictability without adding meaningful uniqueness.
 func generateNonce() (string, error) {
-	// Use 16 bytes of randomness (128 bits)
-	randomBytes := make([]byte, 16)
-	_, err := rand.Read(randomBytes)
-	if err != nil {
+	randomBytes := make([]byte, nonceBytes)
+	if _, err := rand.Read(randomBytes); err != nil {
 		return "", fmt.Errorf("failed to generate random bytes: %w", err)
 	}
 
-	// Add timestamp component for additional uniqueness
-	timestamp := time.Now().UnixNano()
-	timestampBig := big.NewInt(timestamp)
-
-	// Combine random bytes with timestamp
-	combined := append(randomBytes, timestampBig.Bytes()...)
-
 	// Return as 0x-prefixed hex string
-	return "0x" + hex.EncodeToString(combined), nil
+	return "0x" + hex.EncodeToString(randomBytes), nil
 }
 
 // ToJSON converts the payment requirement to JSON
@@ -188,10 +202,59 @@ func (pr *PaymentRequirement) ToMap() map[string]interface{} {
 	return result
 }
 
+// ParsePaymentRequirement decodes and validates a JSON-encoded payment requirement
+func ParsePaymentRequirement(data []byte) (*PaymentRequirement, error) {
+	// Accept the version under either wire name, as a number or a string
+	data, err := normalizeVersion(data, VersionKeySnake)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse payment requirement: %w", err)
+	}
+
+	var pr PaymentRequirement
+	if err := json.Unmarshal(data, &pr); err != nil {
+		return nil, fmt.Errorf("failed to parse payment requirement: %w", err)
+	}
+
+	if err := pr.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid payment requirement: %w", err)
+	}
+
+	return &pr, nil
+}
+
+// IsExpired reports whether the requirement's valid_until is at or before now
+// A requirement with an unparseable valid_until is treated as expired
+func (pr *PaymentRequirement) IsExpired(now time.Time) bool {
+	return pr.TimeUntilExpiry(now) <= 0
+}
+
+// TimeUntilExpiry returns the time remaining until valid_until, negative once expired
+// Returns 0 if valid_until cannot be parsed
+func (pr *PaymentRequirement) TimeUntilExpiry(now time.Time) time.Duration {
+	validUntil, err := time.Parse(time.RFC3339, pr.ValidUntil)
+	if err != nil {
+		return 0
+	}
+
+	return validUntil.Sub(now)
+}
+
+// SettlementDeadline returns the latest time the requirement may be settled: its issuance
+// (valid_until minus the validity it was generated with) plus maxTimeoutSeconds
+func (pr *PaymentRequirement) SettlementDeadline(validity time.Duration) (time.Time, error) {
+	validUntil, err := time.Parse(time.RFC3339, pr.ValidUntil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid valid_until format: %w", err)
+	}
+
+	issuedAt := validUntil.Add(-validity)
+	return issuedAt.Add(time.Duration(pr.MaxTimeoutSeconds) * time.Second), nil
+}
+
 // Validate checks if the payment requirement is valid
 func (pr *PaymentRequirement) Validate() error {
-	if pr.X402Version != 1 {
-		return fmt.Errorf("invalid x402_version: expected 1, got %d", pr.X402Version)
+	if _, err := ParseVersion(pr.X402Version); err != nil {
+		return fmt.Errorf("invalid x402_version: %w", err)
 	}
 
 	if pr.Scheme != "exact" {
@@ -0,0 +1,33 @@
+package x402
+
+import "fmt"
+
+// SchemeExact transfers exactly the required amount via an EIP-3009 authorization
+const SchemeExact = "exact"
+
+// ErrorCodeUnsupportedScheme marks a payment whose scheme the server does not accept
+const ErrorCodeUnsupportedScheme = "unsupported_scheme"
+
+// SupportedSchemes lists the x402 schemes this server can process
+// verification.accepted_schemes may narrow this set but not extend it.
+var SupportedSchemes = []string{SchemeExact}
+
+// IsSupportedScheme reports whether the server can process scheme
+func IsSupportedScheme(scheme string) bool {
+	for _, supported := range SupportedSchemes {
+		if scheme == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// UnsupportedSchemeError reports a payment scheme that is not accepted
+type UnsupportedSchemeError struct {
+	Scheme string
+}
+
+// Error implements the error interface
+func (e *UnsupportedSchemeError) Error() string {
+	return fmt.Sprintf("unsupported scheme: %s", e.Scheme)
+}
@@ -0,0 +1,106 @@
+package x402
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Version is the x402 protocol version this server implements
+const Version = 1
+
+// Wire names of the x402 version field: x402Version in payloads and 402 responses,
+// x402_version in this server's payment requirements
+const (
+	VersionKey      = "x402Version"
+	VersionKeySnake = "x402_version"
+)
+
+// ParseVersion parses an x402 version given as an int, an integral float64 (as decoded from
+// JSON), a json.Number, or a decimal string, and rejects versions this server does not support
+func ParseVersion(v interface{}) (int, error) {
+	var version int
+	switch value := v.(type) {
+	case int:
+		version = value
+	case int64:
+		if value < math.MinInt32 || value > math.MaxInt32 {
+			return 0, fmt.Errorf("x402 version %d is out of range", value)
+		}
+		version = int(value)
+	case float64:
+		if value != math.Trunc(value) || math.Abs(value) > math.MaxInt32 {
+			return 0, fmt.Errorf("x402 version must be an integer, got %v", value)
+		}
+		version = int(value)
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return ParseVersion(integer)
+		}
+		float, err := value.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("x402 version must be an integer, got %s", value)
+		}
+		return ParseVersion(float)
+	case string:
+		parsed, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("x402 version must be an integer, got %q", value)
+		}
+		version = parsed
+	case nil:
+		return 0, fmt.Errorf("x402 version is required")
+	default:
+		return 0, fmt.Errorf("x402 version must be a number or string, got %T", v)
+	}
+
+	if version != Version {
+		return 0, fmt.Errorf("unsupported x402 version %d: only version %d is supported", version, Version)
+	}
+
+	return version, nil
+}
+
+// normalizeVersion rewrites the x402 version of a JSON object, given under either wire name
+// in any form ParseVersion accepts, as an integer under key. Objects without a version are
+// returned unchanged so their own validation reports it.
+func normalizeVersion(data []byte, key string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var found []json.RawMessage
+	for _, name := range []string{VersionKey, VersionKeySnake} {
+		if raw, exists := fields[name]; exists {
+			found = append(found, raw)
+			delete(fields, name)
+		}
+	}
+	if len(found) == 0 {
+		return data, nil
+	}
+
+	versions := make([]int, len(found))
+	for i, raw := range found {
+		decoder := json.NewDecoder(strings.NewReader(string(raw)))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("invalid x402 version: %w", err)
+		}
+		version, err := ParseVersion(value)
+		if err != nil {
+			return nil, err
+		}
+		versions[i] = version
+	}
+	if len(versions) == 2 && versions[0] != versions[1] {
+		return nil, fmt.Errorf("conflicting x402 versions: %s=%d, %s=%d", VersionKey, versions[0], VersionKeySnake, versions[1])
+	}
+
+	fields[key] = json.RawMessage(strconv.Itoa(versions[0]))
+	return json.Marshal(fields)
+}
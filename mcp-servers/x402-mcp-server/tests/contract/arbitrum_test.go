@@ -0,0 +1,200 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// createTestConfigForArbitrum creates an arbitrum-only config with its own confirmation/timeout knobs
+func createTestConfigForArbitrum(facilitatorURL string) *config.Config {
+	return &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"arbitrum": {
+				ChainID:                  42161,
+				USDCContract:             "0xaf88d065e77c8cC2239327C5EDb3A432268e5831",
+				FacilitatorURL:           facilitatorURL,
+				RPCURL:                   "https://arb1.arbitrum.io/rpc",
+				PayeeAddress:             "0x2222222222222222222222222222222222222222",
+				Confirmations:            3,
+				SettlementTimeoutSeconds: 1,
+			},
+		},
+		EIP712: config.EIP712Config{
+			DomainName:    "USD Coin",
+			DomainVersion: "2",
+		},
+		Cache: config.CacheConfig{
+			SettlementTTLMinutes: 10,
+		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+}
+
+// buildArbitrumAuthorization signs an authorization against the arbitrum USDC domain
+func buildArbitrumAuthorization(t *testing.T, cfg *config.Config, nonceByte byte) map[string]interface{} {
+	t.Helper()
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("arbitrum")
+	if err != nil {
+		t.Fatalf("Failed to build arbitrum domain: %v", err)
+	}
+
+	var nonce [32]byte
+	nonce[31] = nonceByte
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	return authInput
+}
+
+// TestArbitrum_RequirementVerifySettle exercises the full flow with arbitrum's own config values
+func TestArbitrum_RequirementVerifySettle(t *testing.T) {
+	var received map[string]interface{}
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":        "settled",
+			"tx_hash":       "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number":  250000000,
+			"confirmations": 3,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForArbitrum(facilitator.URL)
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// Requirement advertises arbitrum's settlement timeout
+	requirement, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "arbitrum",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+
+	reqMap := requirement.(map[string]interface{})
+	if reqMap["maxTimeoutSeconds"] != 1 {
+		t.Errorf("Expected maxTimeoutSeconds 1, got %v", reqMap["maxTimeoutSeconds"])
+	}
+	if reqMap["asset"] != cfg.Networks["arbitrum"].USDCContract {
+		t.Errorf("Expected arbitrum USDC asset, got %v", reqMap["asset"])
+	}
+
+	authInput := buildArbitrumAuthorization(t, cfg, 0x01)
+	input := map[string]interface{}{
+		"authorization": authInput,
+		"network":       "arbitrum",
+	}
+
+	// Verify against the arbitrum domain
+	verifyResult, err := tools.NewVerifyPaymentTool(srv).Execute(input)
+	if err != nil {
+		t.Fatalf("verify_payment failed: %v", err)
+	}
+	if verifyResult.(map[string]interface{})["is_valid"] != true {
+		t.Fatalf("Expected valid arbitrum signature, got %+v", verifyResult)
+	}
+
+	// Settle requests arbitrum's confirmation count
+	settleResult, err := tools.NewSettlePaymentTool(srv).Execute(input)
+	if err != nil {
+		t.Fatalf("settle_payment failed: %v", err)
+	}
+
+	if received["confirmations"] != float64(3) {
+		t.Errorf("Expected facilitator request confirmations=3, got %v", received["confirmations"])
+	}
+
+	settleMap := settleResult.(map[string]interface{})
+	if settleMap["status"] != "settled" {
+		t.Errorf("Expected status 'settled', got %v", settleMap["status"])
+	}
+}
+
+// TestArbitrum_InsufficientConfirmations tests that under-confirmed settlements are reported pending
+func TestArbitrum_InsufficientConfirmations(t *testing.T) {
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":        "settled",
+			"tx_hash":       "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"confirmations": 1,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForArbitrum(facilitator.URL)
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	result, err := tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+		"authorization": buildArbitrumAuthorization(t, cfg, 0x02),
+		"network":       "arbitrum",
+	})
+	if err != nil {
+		t.Fatalf("settle_payment failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["status"] != "pending" {
+		t.Errorf("Expected status 'pending' below required confirmations, got %v", resultMap["status"])
+	}
+	if _, exists := resultMap["retry_after"]; !exists {
+		t.Error("Expected retry_after hint for pending settlement")
+	}
+}
+
+// TestArbitrum_SettlementTimeout tests that arbitrum's shorter timeout is honored
+func TestArbitrum_SettlementTimeout(t *testing.T) {
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Second)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForArbitrum(facilitator.URL)
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	start := time.Now()
+	_, err = tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+		"authorization": buildArbitrumAuthorization(t, cfg, 0x03),
+		"network":       "arbitrum",
+	})
+	if err == nil {
+		t.Fatal("Expected timeout error")
+	}
+
+	if elapsed := time.Since(start); elapsed >= 2*time.Second {
+		t.Errorf("Expected arbitrum 1s timeout to apply, took %v", elapsed)
+	}
+}
@@ -0,0 +1,98 @@
+package contract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+func createTestCalldataAuthorization() map[string]interface{} {
+	return map[string]interface{}{
+		"from":        "0x1111111111111111111111111111111111111111",
+		"to":          "0x2222222222222222222222222222222222222222",
+		"value":       "50000",
+		"validAfter":  float64(1700000000),
+		"validBefore": float64(1700003600),
+		"nonce":       "0x0000000000000000000000000000000000000000000000000000000000000001",
+		"v":           float64(27),
+		"r":           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		"s":           "0x3edcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+}
+
+// TestBuildSettlementCalldata_Execute tests calldata output for a valid authorization
+func TestBuildSettlementCalldata_Execute(t *testing.T) {
+	cfg := createTestConfigForSettlement()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewBuildSettlementCalldataTool(srv)
+	if tool.Name() != "build_settlement_calldata" {
+		t.Errorf("Expected tool name build_settlement_calldata, got %s", tool.Name())
+	}
+
+	result, err := tool.Execute(map[string]interface{}{
+		"authorization": createTestCalldataAuthorization(),
+		"network":       "base-sepolia",
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		t.Fatal("Result should be a map")
+	}
+
+	if resultMap["selector"] != "0xef55bec6" {
+		t.Errorf("Expected selector 0xef55bec6, got %v", resultMap["selector"])
+	}
+
+	calldataHex, _ := resultMap["calldata"].(string)
+	if !strings.HasPrefix(calldataHex, "0xef55bec6") {
+		t.Errorf("Calldata should start with selector, got %s", calldataHex)
+	}
+
+	if resultMap["to"] != cfg.Networks["base-sepolia"].USDCContract {
+		t.Errorf("Expected to=%s, got %v", cfg.Networks["base-sepolia"].USDCContract, resultMap["to"])
+	}
+
+	calldata, err := hexutil.Decode(calldataHex)
+	if err != nil {
+		t.Fatalf("Calldata is not valid hex: %v", err)
+	}
+
+	decoded, err := eip3009.DecodeReceiveWithAuthorization(calldata)
+	if err != nil {
+		t.Fatalf("Failed to decode calldata: %v", err)
+	}
+
+	if decoded.Nonce != createTestCalldataAuthorization()["nonce"] {
+		t.Errorf("Decoded nonce mismatch: %s", decoded.Nonce)
+	}
+}
+
+// TestBuildSettlementCalldata_UnsupportedNetwork tests rejection of unknown networks
+func TestBuildSettlementCalldata_UnsupportedNetwork(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForSettlement(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewBuildSettlementCalldataTool(srv)
+	_, err = tool.Execute(map[string]interface{}{
+		"authorization": createTestCalldataAuthorization(),
+		"network":       "polygon",
+	})
+	if err == nil {
+		t.Fatal("Expected error for unsupported network")
+	}
+}
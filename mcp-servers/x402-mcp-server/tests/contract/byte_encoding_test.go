@@ -0,0 +1,127 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestByteEncoding_VerifyAcceptsBothEncodings tests that nonce and r/s verify as hex or base64
+func TestByteEncoding_VerifyAcceptsBothEncodings(t *testing.T) {
+	cfg := createTestConfigForPayment()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	toBase64 := func(hex string) string {
+		return base64.StdEncoding.EncodeToString(common.FromHex(hex))
+	}
+
+	for _, encoding := range []string{config.ByteEncodingHex, config.ByteEncodingBase64} {
+		t.Run(encoding, func(t *testing.T) {
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), [32]byte{0xb6, byte(len(encoding))})
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+			if encoding == config.ByteEncodingBase64 {
+				for _, field := range []string{"nonce", "r", "s"} {
+					authInput[field] = toBase64(authInput[field].(string))
+				}
+			}
+
+			result, err := tool.Execute(map[string]interface{}{"authorization": authInput, "network": "base"})
+			if err != nil {
+				t.Fatalf("verify_payment failed: %v", err)
+			}
+			if output := result.(map[string]interface{}); output["is_valid"] != true {
+				t.Errorf("Expected %s-encoded authorization to verify, got %v", encoding, output)
+			}
+		})
+	}
+
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), [32]byte{0xb7})
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+	authInput["r"] = base64.StdEncoding.EncodeToString([]byte("too short"))
+	if _, err := tool.Execute(map[string]interface{}{"authorization": authInput, "network": "base"}); err == nil {
+		t.Error("Expected error for a base64 r that is not 32 bytes")
+	}
+}
+
+// TestByteEncoding_PrepareDigestsOutput tests that prepared nonces follow display.byte_encoding
+// and that a base64 nonce given back as input round-trips unchanged
+func TestByteEncoding_PrepareDigestsOutput(t *testing.T) {
+	nonceHex := "0x" + strings.Repeat("cd", 32)
+	nonceBase64 := base64.StdEncoding.EncodeToString(common.FromHex(nonceHex))
+
+	tests := []struct {
+		encoding string
+		input    string
+		expected string
+	}{
+		{"", nonceHex, nonceHex},
+		{config.ByteEncodingHex, nonceBase64, nonceHex},
+		{config.ByteEncodingBase64, nonceHex, nonceBase64},
+		{config.ByteEncodingBase64, nonceBase64, nonceBase64},
+	}
+
+	for _, tt := range tests {
+		cfg := createTestConfigForPayment()
+		cfg.Display.ByteEncoding = tt.encoding
+		srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+
+		requirement, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+			"amount":  "1000",
+			"network": "base",
+		})
+		if err != nil {
+			t.Fatalf("create_payment_requirement failed: %v", err)
+		}
+
+		result, err := tools.NewPrepareAuthorizationDigestsTool(srv).Execute(map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{
+				"requirement": requirement,
+				"from":        "0x1111111111111111111111111111111111111111",
+				"nonce":       tt.input,
+			}},
+		})
+		if err != nil {
+			t.Fatalf("prepare_authorization_digests failed: %v", err)
+		}
+
+		item := result.(map[string]interface{})["results"].([]interface{})[0].(map[string]interface{})
+		authorization, ok := item["authorization"].(map[string]interface{})
+		if !ok {
+			t.Fatalf("encoding %q: expected a prepared authorization, got %v", tt.encoding, item)
+		}
+		if authorization["nonce"] != tt.expected {
+			t.Errorf("encoding %q, input %s: expected nonce %s, got %v", tt.encoding, tt.input, tt.expected, authorization["nonce"])
+		}
+	}
+}
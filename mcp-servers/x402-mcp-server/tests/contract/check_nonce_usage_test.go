@@ -0,0 +1,105 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// stubStateReader reports nonces in used as consumed and every other nonce as unused
+type stubStateReader struct {
+	used map[common.Hash]bool
+}
+
+func (s *stubStateReader) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	output := make([]byte, 32)
+	if s.used[common.BytesToHash(call.Data[36:68])] {
+		output[31] = 1
+	}
+	return output, nil
+}
+
+// TestCheckNonceUsage_Execute tests the tool reports a mix of used and unused nonces
+func TestCheckNonceUsage_Execute(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	usedNonce := common.HexToHash("0x01")
+	unusedNonce := common.HexToHash("0x02")
+	payer := "0x1111111111111111111111111111111111111111"
+
+	tool := tools.NewCheckNonceUsageTool(srv)
+	tool.NonceChecker().SetBackend("base", &stubStateReader{used: map[common.Hash]bool{usedNonce: true}})
+
+	result, err := tool.Execute(map[string]interface{}{
+		"network": "base",
+		"authorizations": []interface{}{
+			map[string]interface{}{"from": payer, "nonce": usedNonce.Hex()},
+			map[string]interface{}{"from": payer, "nonce": unusedNonce.Hex()},
+			map[string]interface{}{"from": "not-an-address", "nonce": unusedNonce.Hex()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["used_count"] != 1 || resultMap["unused_count"] != 1 || resultMap["error_count"] != 1 {
+		t.Errorf("Expected 1 used, 1 unused, 1 error; got %v/%v/%v",
+			resultMap["used_count"], resultMap["unused_count"], resultMap["error_count"])
+	}
+
+	results := resultMap["results"].([]interface{})
+	if first := results[0].(map[string]interface{}); first["used"] != true || first["nonce"] != usedNonce.Hex() {
+		t.Errorf("Expected first result used, got %v", first)
+	}
+	if second := results[1].(map[string]interface{}); second["used"] != false {
+		t.Errorf("Expected second result unused, got %v", second)
+	}
+	if third := results[2].(map[string]interface{}); third["error"] == nil {
+		t.Errorf("Expected error for invalid address, got %v", third)
+	}
+}
+
+// TestCheckNonceUsage_InvalidInput tests batch-level input validation
+func TestCheckNonceUsage_InvalidInput(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewCheckNonceUsageTool(srv)
+	tool.NonceChecker().SetBackend("base", &stubStateReader{})
+
+	oversized := make([]interface{}, 101)
+	for i := range oversized {
+		oversized[i] = map[string]interface{}{"from": "0x1111111111111111111111111111111111111111", "nonce": common.HexToHash("0x01").Hex()}
+	}
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"empty batch", map[string]interface{}{"network": "base", "authorizations": []interface{}{}}},
+		{"oversized batch", map[string]interface{}{"network": "base", "authorizations": oversized}},
+		{"not an array", map[string]interface{}{"network": "base", "authorizations": "0x01"}},
+		{"unknown network", map[string]interface{}{"network": "polygon", "authorizations": oversized[:1]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tool.Execute(tt.args); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
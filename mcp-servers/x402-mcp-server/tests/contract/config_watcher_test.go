@@ -0,0 +1,96 @@
+package contract
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+)
+
+// watcherConfigYAML renders a minimal valid config paying payee on base
+func watcherConfigYAML(payee string) string {
+	return fmt.Sprintf(`networks:
+  base:
+    chain_id: 8453
+    usdc_contract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+    facilitator_url: "https://api.cdp.coinbase.com"
+    rpc_url: "https://mainnet.base.org"
+    payee_address: "%s"
+logging:
+  level: "INFO"
+  format: "json"
+cache:
+  settlement_ttl_minutes: 10
+`, payee)
+}
+
+// TestConfigWatcher_SIGHUPReload tests that SIGHUP swaps in an edited config file and that an
+// invalid edit is reported without disturbing the running config
+func TestConfigWatcher_SIGHUPReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(watcherConfigYAML("0x1234567890123456789012345678901234567890")), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	reloadErrors := make(chan error, 1)
+	watcher := config.NewWatcher(path, srv.ReloadConfig)
+	watcher.OnError(func(err error) { reloadErrors <- err })
+	watcher.Start()
+	defer watcher.Stop()
+
+	payee := func() string { return srv.GetConfig().Networks["base"].PayeeAddress }
+
+	// A valid edit is picked up on SIGHUP
+	newPayee := "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd"
+	if err := os.WriteFile(path, []byte(watcherConfigYAML(newPayee)), 0o600); err != nil {
+		t.Fatalf("Failed to rewrite config: %v", err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for payee() != newPayee && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if payee() != newPayee {
+		t.Fatalf("Expected payee %s after SIGHUP, got %s", newPayee, payee())
+	}
+
+	// An invalid edit is rejected and the running config kept
+	running := srv.GetConfig()
+	if err := os.WriteFile(path, []byte(watcherConfigYAML("not-an-address")), 0o600); err != nil {
+		t.Fatalf("Failed to rewrite config: %v", err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+
+	select {
+	case err := <-reloadErrors:
+		if err == nil {
+			t.Error("Expected a reload error for the invalid config")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the invalid config to be rejected")
+	}
+	if srv.GetConfig() != running {
+		t.Error("Running config should be unchanged after a rejected reload")
+	}
+}
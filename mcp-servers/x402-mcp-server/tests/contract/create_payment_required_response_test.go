@@ -0,0 +1,132 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestCreatePaymentRequiredResponse_SpecShape validates the 402 body matches the x402 spec shape
+func TestCreatePaymentRequiredResponse_SpecShape(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewCreatePaymentRequiredResponseTool(srv)
+	if tool.Name() != "create_payment_required_response" {
+		t.Errorf("Expected tool name create_payment_required_response, got %s", tool.Name())
+	}
+
+	result, err := tool.Execute(map[string]interface{}{
+		"amount":   "50000",
+		"network":  "base",
+		"resource": "https://api.example.com/certify",
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["status_code"] != 402 {
+		t.Errorf("Expected status_code 402, got %v", resultMap["status_code"])
+	}
+
+	// The served JSON body must decode to exactly the spec's top-level fields
+	bodyJSON, ok := resultMap["body_json"].(string)
+	if !ok {
+		t.Fatal("Expected body_json string")
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(bodyJSON), &body); err != nil {
+		t.Fatalf("body_json is not valid JSON: %v", err)
+	}
+	for _, field := range []string{"x402Version", "error", "accepts"} {
+		if _, exists := body[field]; !exists {
+			t.Errorf("402 body missing %s", field)
+		}
+	}
+	if len(body) != 3 {
+		t.Errorf("Expected exactly 3 top-level fields, got %d", len(body))
+	}
+
+	var version int
+	json.Unmarshal(body["x402Version"], &version)
+	if version != 1 {
+		t.Errorf("Expected x402Version 1, got %d", version)
+	}
+
+	var errorMessage string
+	json.Unmarshal(body["error"], &errorMessage)
+	if errorMessage != x402.DefaultPaymentRequiredError {
+		t.Errorf("Expected default error message, got %q", errorMessage)
+	}
+
+	var accepts []json.RawMessage
+	if err := json.Unmarshal(body["accepts"], &accepts); err != nil || len(accepts) != 1 {
+		t.Fatalf("Expected accepts array with one requirement, got %s", body["accepts"])
+	}
+
+	// The advertised requirement must round-trip through the requirement parser
+	requirement, err := x402.ParsePaymentRequirement(accepts[0])
+	if err != nil {
+		t.Fatalf("accepts[0] is not a valid payment requirement: %v", err)
+	}
+
+	if requirement.MaxAmountRequired != "50000" || requirement.Network != "base" {
+		t.Errorf("Unexpected requirement amount/network: %s/%s", requirement.MaxAmountRequired, requirement.Network)
+	}
+	if requirement.Resource != "https://api.example.com/certify" {
+		t.Errorf("Expected resource to be preserved, got %s", requirement.Resource)
+	}
+	if requirement.PayTo != createTestConfigForPayment().Networks["base"].PayeeAddress {
+		t.Errorf("Expected payTo from network config, got %s", requirement.PayTo)
+	}
+
+	// The object form mirrors the JSON body
+	bodyMap, ok := resultMap["body"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected body object")
+	}
+	if acceptsList, _ := bodyMap["accepts"].([]interface{}); len(acceptsList) != 1 {
+		t.Errorf("Expected body.accepts with one requirement, got %v", bodyMap["accepts"])
+	}
+}
+
+// TestCreatePaymentRequiredResponse_CustomError tests the error message override and input validation
+func TestCreatePaymentRequiredResponse_CustomError(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewCreatePaymentRequiredResponseTool(srv)
+
+	result, err := tool.Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+		"error":   "Payment expired, please retry",
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	body := result.(map[string]interface{})["body"].(map[string]interface{})
+	if body["error"] != "Payment expired, please retry" {
+		t.Errorf("Expected custom error message, got %v", body["error"])
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{"amount": "0", "network": "base"}); err == nil {
+		t.Error("Expected error for zero amount")
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{"amount": "50000", "network": "polygon"}); err == nil {
+		t.Error("Expected error for unsupported network")
+	}
+}
@@ -3,11 +3,15 @@ package contract
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
 	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
 )
 
@@ -84,7 +88,7 @@ func TestCreatePaymentRequirement_ToolSchema(t *testing.T) {
 		t.Fatal("network should have enum values")
 	}
 
-	expectedNetworks := []string{"base", "base-sepolia", "arbitrum"}
+	expectedNetworks := []string{"base", "base-sepolia", "arbitrum", "arbitrum-sepolia", "optimism"}
 	if len(enum) != len(expectedNetworks) {
 		t.Errorf("Expected %d network options, got %d", len(expectedNetworks), len(enum))
 	}
@@ -95,8 +99,14 @@ func TestCreatePaymentRequirement_ToolSchema(t *testing.T) {
 		t.Fatal("Schema should have required fields")
 	}
 
-	if len(required) != 2 {
-		t.Errorf("Expected 2 required fields, got %d", len(required))
+	if len(required) != 1 || required[0] != "network" {
+		t.Errorf("Expected only network to be required, got %v", required)
+	}
+
+	// Either an atomic amount or a fiat amount with its currency must be given
+	anyOf, ok := schemaMap["anyOf"].([]interface{})
+	if !ok || len(anyOf) != 2 {
+		t.Fatalf("Expected anyOf with amount and amount_fiat alternatives, got %v", schemaMap["anyOf"])
 	}
 }
 
@@ -309,3 +319,219 @@ func createTestConfigForPayment() *config.Config {
 		},
 	}
 }
+
+// TestCreatePaymentRequirement_NetworkCaseInsensitive tests that network casing is normalized
+// to the configured name, and unknown networks are still rejected
+func TestCreatePaymentRequirement_NetworkCaseInsensitive(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewCreatePaymentRequirementTool(srv)
+
+	tests := map[string]string{
+		"Base":         "base",
+		"BASE":         "base",
+		"base-Sepolia": "base-sepolia",
+	}
+	for input, expected := range tests {
+		result, err := tool.Execute(map[string]interface{}{
+			"amount":  "50000",
+			"network": input,
+		})
+		if err != nil {
+			t.Errorf("Network %q: unexpected error: %v", input, err)
+			continue
+		}
+
+		resultMap := result.(map[string]interface{})
+		if resultMap["network"] != expected {
+			t.Errorf("Network %q: expected canonical %q, got %v", input, expected, resultMap["network"])
+		}
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{"amount": "50000", "network": "Ethereum"}); err == nil {
+		t.Error("Expected error for unknown network after normalization")
+	}
+}
+
+// TestCreatePaymentRequirement_OutputSchemaTemplate tests filling outputSchema from a named template
+func TestCreatePaymentRequirement_OutputSchemaTemplate(t *testing.T) {
+	cfg := createTestConfigForPayment()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewCreatePaymentRequirementTool(srv)
+
+	result, err := tool.Execute(map[string]interface{}{
+		"amount":        "50000",
+		"network":       "base",
+		"output_schema": x402.OutputSchemaCertificationReceipt,
+	})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+
+	expected, _ := x402.OutputSchemaTemplate(x402.OutputSchemaCertificationReceipt)
+	schema, ok := result.(map[string]interface{})["outputSchema"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected outputSchema in requirement, got %v", result)
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("Expected certification-receipt schema, got %v", schema)
+	}
+	required := schema["required"].([]string)
+	if len(required) == 0 || required[0] != "certificate_id" {
+		t.Errorf("Expected certificate_id to be required, got %v", required)
+	}
+
+	// No template selected and no default configured: outputSchema is omitted
+	result, err = tool.Execute(map[string]interface{}{"amount": "50000", "network": "base"})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+	if _, exists := result.(map[string]interface{})["outputSchema"]; exists {
+		t.Error("Expected no outputSchema without a template")
+	}
+
+	// The configured default applies when the caller names none
+	cfg.Requirements.DefaultOutputSchema = x402.OutputSchemaFileDownload
+	result, err = tool.Execute(map[string]interface{}{"amount": "50000", "network": "base"})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+	expected, _ = x402.OutputSchemaTemplate(x402.OutputSchemaFileDownload)
+	if !reflect.DeepEqual(result.(map[string]interface{})["outputSchema"], expected) {
+		t.Errorf("Expected default file-download schema, got %v", result.(map[string]interface{})["outputSchema"])
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{"amount": "50000", "network": "base", "output_schema": "invoice"}); err == nil {
+		t.Error("Expected error for unknown output_schema template")
+	}
+}
+
+// mockPriceOracle returns fixed USD prices per asset
+type mockPriceOracle map[string]float64
+
+func (m mockPriceOracle) USDPerToken(asset string) (float64, error) {
+	price, exists := m[strings.ToLower(asset)]
+	if !exists {
+		return 0, fmt.Errorf("no price for %s", asset)
+	}
+	return price, nil
+}
+
+// TestCreatePaymentRequirement_FiatAmount tests converting amount_fiat to atomic units via the price oracle
+func TestCreatePaymentRequirement_FiatAmount(t *testing.T) {
+	cfg := createTestConfigForPayment()
+	cfg.Pricing.USDPerFiat = map[string]float64{"EUR": 1.08}
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	asset := strings.ToLower(cfg.Networks["base"].USDCContract)
+	srv.SetPriceOracle(mockPriceOracle{asset: 0.9998})
+	tool := tools.NewCreatePaymentRequirementTool(srv)
+
+	tests := []struct {
+		name     string
+		fiat     string
+		currency string
+		expected string
+	}{
+		{"USD", "4.99", "USD", "4990999"},                 // 4.99 / 0.9998 = 4.990998..., rounded up
+		{"lowercase currency", "10", "usd", "10002001"},   // 10 / 0.9998 = 10.002000...
+		{"EUR via usd_per_fiat", "10", "EUR", "10802161"}, // 10.8 / 0.9998 = 10.802160...
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(map[string]interface{}{
+				"amount_fiat":   tt.fiat,
+				"fiat_currency": tt.currency,
+				"network":       "base",
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+			if amount := result.(map[string]interface{})["maxAmountRequired"]; amount != tt.expected {
+				t.Errorf("Expected maxAmountRequired %s, got %v", tt.expected, amount)
+			}
+		})
+	}
+
+	invalid := []map[string]interface{}{
+		{"amount_fiat": "10", "fiat_currency": "GBP", "network": "base"},                    // No exchange rate
+		{"amount_fiat": "10", "network": "base"},                                            // Missing currency
+		{"amount_fiat": "10", "fiat_currency": "USD", "amount": "50000", "network": "base"}, // Both amounts
+		{"amount_fiat": "-1", "fiat_currency": "USD", "network": "base"},                    // Not a positive decimal
+		{"amount_fiat": "10", "fiat_currency": "USD", "network": "arbitrum"},                // Oracle has no price
+	}
+	for _, args := range invalid {
+		if _, err := tool.Execute(args); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+
+	// Atomic amounts bypass the oracle
+	result, err := tool.Execute(map[string]interface{}{"amount": "50000", "network": "base"})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+	if result.(map[string]interface{})["maxAmountRequired"] != "50000" {
+		t.Errorf("Expected atomic amount to be unchanged, got %v", result.(map[string]interface{})["maxAmountRequired"])
+	}
+}
+
+// TestCreatePaymentRequirement_Explain tests the optional plain-language summary
+func TestCreatePaymentRequirement_Explain(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewCreatePaymentRequirementTool(srv)
+	args := map[string]interface{}{
+		"amount":      "50000",
+		"network":     "base-sepolia",
+		"description": "API access",
+	}
+
+	// Off by default
+	result, err := tool.Execute(args)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, exists := result.(map[string]interface{})["explain"]; exists {
+		t.Error("Expected no explain field unless requested")
+	}
+
+	args["explain"] = true
+	result, err = tool.Execute(args)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	explain, ok := resultMap["explain"].(string)
+	if !ok {
+		t.Fatalf("Expected explain string, got %v", resultMap["explain"])
+	}
+
+	for _, want := range []string{
+		"Pay 0.05 USDC",
+		"on Base Sepolia",
+		"to 0x1234567890123456789012345678901234567890",
+		"for API access",
+		"valid until " + resultMap["valid_until"].(string),
+	} {
+		if !strings.Contains(explain, want) {
+			t.Errorf("Expected explain to contain %q, got %q", want, explain)
+		}
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{"amount": "50000", "network": "base", "explain": "yes"}); err == nil {
+		t.Error("Expected error for non-boolean explain")
+	}
+}
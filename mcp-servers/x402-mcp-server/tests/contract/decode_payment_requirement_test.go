@@ -0,0 +1,128 @@
+package contract
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestDecodePaymentRequirement_Fresh tests decoding a freshly created requirement
+func TestDecodePaymentRequirement_Fresh(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	created, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+
+	tool := tools.NewDecodePaymentRequirementTool(srv)
+	result, err := tool.Execute(map[string]interface{}{"requirement": created})
+	if err != nil {
+		t.Fatalf("decode_payment_requirement failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["expired"] != false {
+		t.Errorf("Expected fresh requirement not expired, got %v", resultMap["expired"])
+	}
+
+	seconds, _ := resultMap["seconds_until_expiry"].(int64)
+	if seconds <= 0 || seconds > int64(24*time.Hour/time.Second) {
+		t.Errorf("Expected seconds_until_expiry within 24h, got %v", resultMap["seconds_until_expiry"])
+	}
+
+	if resultMap["amount_human"] != "0.05" {
+		t.Errorf("Expected amount_human '0.05', got %v", resultMap["amount_human"])
+	}
+}
+
+// TestDecodePaymentRequirement_Expired tests that a stale requirement is reported as expired
+func TestDecodePaymentRequirement_Expired(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	created, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+
+	stale := created.(map[string]interface{})
+	stale["valid_until"] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+	result, err := tools.NewDecodePaymentRequirementTool(srv).Execute(map[string]interface{}{"requirement": stale})
+	if err != nil {
+		t.Fatalf("decode_payment_requirement failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["expired"] != true {
+		t.Errorf("Expected stale requirement to be expired, got %v", resultMap["expired"])
+	}
+	if resultMap["seconds_until_expiry"] != int64(0) {
+		t.Errorf("Expected seconds_until_expiry 0, got %v", resultMap["seconds_until_expiry"])
+	}
+}
+
+// TestDecodePaymentRequirement_InvalidInput tests rejection of malformed requirements
+func TestDecodePaymentRequirement_InvalidInput(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewDecodePaymentRequirementTool(srv)
+	for _, input := range []interface{}{"{not json", 42, map[string]interface{}{"scheme": "exact"}} {
+		if _, err := tool.Execute(map[string]interface{}{"requirement": input}); err == nil {
+			t.Errorf("Expected error for input %v", input)
+		}
+	}
+}
+
+// TestDecodePaymentRequirement_GroupedAmount tests that display.amount_format only affects amount_human
+func TestDecodePaymentRequirement_GroupedAmount(t *testing.T) {
+	cfg := createTestConfigForPayment()
+	cfg.Display.AmountFormat = config.AmountFormatGrouped
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	created, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "1234567500000",
+		"network": "base",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+
+	result, err := tools.NewDecodePaymentRequirementTool(srv).Execute(map[string]interface{}{"requirement": created})
+	if err != nil {
+		t.Fatalf("decode_payment_requirement failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["amount_human"] != "1,234,567.5" {
+		t.Errorf("Expected amount_human '1,234,567.5', got %v", resultMap["amount_human"])
+	}
+
+	if resultMap["maxAmountRequired"] != "1234567500000" {
+		t.Errorf("Expected atomic maxAmountRequired untouched, got %v", resultMap["maxAmountRequired"])
+	}
+}
@@ -0,0 +1,118 @@
+package contract
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestEstimateSettlementTime_ConfiguredDefaults tests that estimates combine the default
+// submission latency with confirmations times the configured block time
+func TestEstimateSettlementTime_ConfiguredDefaults(t *testing.T) {
+	cfg := createTestConfigForSettlement()
+	base := cfg.Networks["base"]
+	base.Confirmations = 3
+	base.BlockTimeSeconds = 2
+	cfg.Networks["base"] = base
+	sepolia := cfg.Networks["base-sepolia"]
+	sepolia.BlockTimeSeconds = 0.5 // No confirmations configured: inclusion block only
+	cfg.Networks["base-sepolia"] = sepolia
+	cfg.Estimates = config.EstimatesConfig{SubmitLatencyMs: 1500}
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewEstimateSettlementTimeTool(srv)
+
+	tests := []struct {
+		network       string
+		confirmations uint64
+		confirmation  float64
+		estimate      float64
+	}{
+		{"base", 3, 6, 7.5},
+		{"base-sepolia", 1, 0.5, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			result, err := tool.Execute(map[string]interface{}{"network": tt.network})
+			if err != nil {
+				t.Fatalf("estimate_settlement_time failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["latency_source"] != "default" {
+				t.Errorf("Expected latency_source 'default', got %v", resultMap["latency_source"])
+			}
+			if resultMap["submit_latency_seconds"] != 1.5 {
+				t.Errorf("Expected submit_latency_seconds 1.5, got %v", resultMap["submit_latency_seconds"])
+			}
+			if resultMap["confirmations"] != tt.confirmations {
+				t.Errorf("Expected confirmations %d, got %v", tt.confirmations, resultMap["confirmations"])
+			}
+			if resultMap["confirmation_seconds"] != tt.confirmation {
+				t.Errorf("Expected confirmation_seconds %v, got %v", tt.confirmation, resultMap["confirmation_seconds"])
+			}
+			if resultMap["estimated_settlement_seconds"] != tt.estimate {
+				t.Errorf("Expected estimated_settlement_seconds %v, got %v", tt.estimate, resultMap["estimated_settlement_seconds"])
+			}
+		})
+	}
+}
+
+// TestEstimateSettlementTime_RecordedLatency tests that measured settlement latency
+// replaces the configured default once samples exist
+func TestEstimateSettlementTime_RecordedLatency(t *testing.T) {
+	cfg := createTestConfigForSettlement()
+	base := cfg.Networks["base"]
+	base.Confirmations = 2
+	base.BlockTimeSeconds = 2
+	cfg.Networks["base"] = base
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewEstimateSettlementTimeTool(srv)
+
+	labels := metrics.Labels{"network": "base"}
+	for _, seconds := range []float64{1, 3} {
+		srv.GetMetrics().ObserveHistogram(facilitator.MetricSettlementLatency, facilitator.SettlementLatencyBuckets, labels, seconds)
+	}
+
+	result, err := tool.Execute(map[string]interface{}{"network": "base"})
+	if err != nil {
+		t.Fatalf("estimate_settlement_time failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["latency_source"] != "metrics" {
+		t.Errorf("Expected latency_source 'metrics', got %v", resultMap["latency_source"])
+	}
+	if resultMap["latency_samples"] != uint64(2) {
+		t.Errorf("Expected latency_samples 2, got %v", resultMap["latency_samples"])
+	}
+	if resultMap["submit_latency_seconds"] != 2.0 {
+		t.Errorf("Expected submit_latency_seconds 2, got %v", resultMap["submit_latency_seconds"])
+	}
+	if resultMap["estimated_settlement_seconds"] != 6.0 {
+		t.Errorf("Expected estimated_settlement_seconds 6, got %v", resultMap["estimated_settlement_seconds"])
+	}
+
+	// Other networks have no samples and keep the default
+	result, err = tool.Execute(map[string]interface{}{"network": "base-sepolia"})
+	if err != nil {
+		t.Fatalf("estimate_settlement_time failed: %v", err)
+	}
+	if source := result.(map[string]interface{})["latency_source"]; source != "default" {
+		t.Errorf("Expected latency_source 'default' for base-sepolia, got %v", source)
+	}
+}
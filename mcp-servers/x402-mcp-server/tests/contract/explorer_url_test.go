@@ -0,0 +1,125 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+const explorerTestTxHash = "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
+
+// TestSettlePayment_ExplorerURL tests that settled results link to the network's configured explorer
+func TestSettlePayment_ExplorerURL(t *testing.T) {
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      explorerTestTxHash,
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: facilitator.URL,
+				RPCURL:         "https://mainnet.base.org",
+				PayeeAddress:   "0x2222222222222222222222222222222222222222",
+				ExplorerURL:    "https://basescan.org/", // Trailing slash is trimmed
+			},
+			"arbitrum": {
+				ChainID:        42161,
+				USDCContract:   "0xaf88d065e77c8cC2239327C5EDb3A432268e5831",
+				FacilitatorURL: facilitator.URL,
+				RPCURL:         "https://arb1.arbitrum.io/rpc",
+				PayeeAddress:   "0x2222222222222222222222222222222222222222",
+				ExplorerURL:    "https://arbiscan.io",
+			},
+			"base-sepolia": {
+				ChainID:        84532,
+				USDCContract:   "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+				FacilitatorURL: facilitator.URL,
+				RPCURL:         "https://sepolia.base.org",
+				PayeeAddress:   "0x2222222222222222222222222222222222222222",
+			},
+		},
+		Cache: config.CacheConfig{
+			SettlementTTLMinutes: 10,
+		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewSettlePaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	tests := []struct {
+		network  string
+		expected string
+	}{
+		{"base", "https://basescan.org/tx/" + explorerTestTxHash},
+		{"arbitrum", "https://arbiscan.io/tx/" + explorerTestTxHash},
+		{"base-sepolia", ""}, // No explorer configured
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain(tt.network)
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+
+			var nonce [32]byte
+			nonce[31] = byte(i + 1)
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization": authInput,
+				"network":       tt.network,
+			})
+			if err != nil {
+				t.Fatalf("settle_payment failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["status"] != "settled" {
+				t.Fatalf("Expected status 'settled', got %+v", resultMap)
+			}
+
+			link, exists := resultMap["explorer_url"]
+			if tt.expected == "" {
+				if exists {
+					t.Errorf("Expected explorer_url to be omitted, got %v", link)
+				}
+				return
+			}
+			if link != tt.expected {
+				t.Errorf("Expected explorer_url %s, got %v", tt.expected, link)
+			}
+		})
+	}
+}
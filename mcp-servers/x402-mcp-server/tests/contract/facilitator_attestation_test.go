@@ -0,0 +1,172 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestSettlePayment_FacilitatorAttestation tests verification of signed facilitator settlement receipts
+// A response failing attestation is indeterminate: pending for reconciliation, never dead-lettered
+func TestSettlePayment_FacilitatorAttestation(t *testing.T) {
+	facilitatorKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate facilitator key: %v", err)
+	}
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	const txHash = "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
+
+	tests := []struct {
+		name     string
+		require  bool
+		sign     func(t *testing.T, nonce string, response *facilitator.FacilitatorResponse) string
+		status   string
+		attested bool
+	}{
+		{
+			name: "signed receipt accepted",
+			sign: func(t *testing.T, nonce string, response *facilitator.FacilitatorResponse) string {
+				attestation, err := facilitator.SignReceipt(facilitatorKey, "base", nonce, response)
+				if err != nil {
+					t.Fatalf("SignReceipt failed: %v", err)
+				}
+				return attestation
+			},
+			status:   "settled",
+			attested: true,
+		},
+		{
+			name: "tampered tx hash rejected",
+			sign: func(t *testing.T, nonce string, response *facilitator.FacilitatorResponse) string {
+				genuine := *response
+				genuine.TxHash = "0x1111111111111111111111111111111111111111111111111111111111111111"
+				attestation, err := facilitator.SignReceipt(facilitatorKey, "base", nonce, &genuine)
+				if err != nil {
+					t.Fatalf("SignReceipt failed: %v", err)
+				}
+				return attestation
+			},
+			status: "pending",
+		},
+		{
+			name: "other key rejected",
+			sign: func(t *testing.T, nonce string, response *facilitator.FacilitatorResponse) string {
+				attestation, err := facilitator.SignReceipt(otherKey, "base", nonce, response)
+				if err != nil {
+					t.Fatalf("SignReceipt failed: %v", err)
+				}
+				return attestation
+			},
+			status: "pending",
+		},
+		{
+			name:   "unsigned accepted when not required",
+			status: "settled",
+		},
+		{
+			name:    "unsigned rejected when required",
+			require: true,
+			status:  "pending",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKey, _, err := createTestPrivateKeyAndAddress()
+			if err != nil {
+				t.Fatalf("Failed to create test private key: %v", err)
+			}
+
+			cfg := createTestConfigForSettlement()
+			cfg.Settlement.RequireAttestation = tt.require
+
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+			var nonce [32]byte
+			nonce[31] = byte(0x60 + i)
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := &facilitator.FacilitatorResponse{
+					Status:        "settled",
+					TxHash:        txHash,
+					BlockNumber:   12345678,
+					Confirmations: 1,
+				}
+				if tt.sign != nil {
+					response.Attestation = tt.sign(t, authInput["nonce"].(string), response)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(response)
+			}))
+			defer server.Close()
+
+			baseNet := cfg.Networks["base"]
+			baseNet.FacilitatorURL = server.URL
+			baseNet.FacilitatorPublicKey = hexutil.Encode(crypto.CompressPubkey(&facilitatorKey.PublicKey))
+			cfg.Networks["base"] = baseNet
+
+			cfg.DeadLetter.Path = filepath.Join(t.TempDir(), "dead-letters.jsonl")
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+
+			tool := tools.NewSettlePaymentTool(srv)
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization": authInput,
+				"network":       "base",
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["status"] != tt.status {
+				t.Fatalf("Expected status %s, got %v", tt.status, resultMap)
+			}
+			if tt.status == "pending" {
+				if resultMap["error_code"] != facilitator.ErrorCodeAttestationInvalid {
+					t.Errorf("Expected error_code %s, got %v", facilitator.ErrorCodeAttestationInvalid, resultMap["error_code"])
+				}
+				if pending := tool.FacilitatorClient().PendingSettlements(); len(pending) != 1 {
+					t.Errorf("Expected the unattested settlement tracked for reconciliation, got %d pending", len(pending))
+				}
+				letters, err := srv.GetDeadLetterStore().Entries()
+				if err != nil {
+					t.Fatalf("Failed to read dead letters: %v", err)
+				}
+				if len(letters) != 0 {
+					t.Errorf("Expected no dead letters, got %+v", letters)
+				}
+			}
+			if _, attested := resultMap["attested"]; attested != tt.attested {
+				t.Errorf("Expected attested=%v, got %v", tt.attested, resultMap)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package contract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestGenerateTestAuthorization_Verifies tests that the generated authorization verifies
+// against its own requirement and was signed by the returned key
+func TestGenerateTestAuthorization_Verifies(t *testing.T) {
+	cfg := createTestConfigForPayment()
+	cfg.Environment = config.EnvironmentTest
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	result, err := tools.NewGenerateTestAuthorizationTool(srv).Execute(map[string]interface{}{
+		"network": "base",
+		"amount":  "25000",
+	})
+	if err != nil {
+		t.Fatalf("generate_test_authorization failed: %v", err)
+	}
+	resultMap := result.(map[string]interface{})
+
+	requirement := resultMap["requirement"].(map[string]interface{})
+	authorization := resultMap["authorization"].(map[string]interface{})
+	if authorization["value"] != "25000" || requirement["maxAmountRequired"] != "25000" {
+		t.Errorf("Expected amount 25000, got authorization %v, requirement %v", authorization["value"], requirement["maxAmountRequired"])
+	}
+
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(resultMap["private_key"].(string), "0x"))
+	if err != nil {
+		t.Fatalf("Failed to decode private key: %v", err)
+	}
+	if address := crypto.PubkeyToAddress(privateKey.PublicKey).Hex(); !strings.EqualFold(address, authorization["from"].(string)) {
+		t.Errorf("Expected private key for %v, got %s", authorization["from"], address)
+	}
+
+	verified, err := tools.NewVerifyPaymentTool(srv).Execute(map[string]interface{}{
+		"network":       resultMap["network"],
+		"authorization": authorization,
+		"requirement":   requirement,
+	})
+	if err != nil {
+		t.Fatalf("verify_payment failed: %v", err)
+	}
+
+	verifiedMap := verified.(map[string]interface{})
+	if verifiedMap["is_valid"] != true {
+		t.Errorf("Expected generated authorization to verify, got %v (%v)", verifiedMap["error"], verifiedMap["error_code"])
+	}
+}
+
+// TestGenerateTestAuthorization_ProductionDisabled tests that the tool refuses to run outside the test environment
+func TestGenerateTestAuthorization_ProductionDisabled(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if _, err := tools.NewGenerateTestAuthorizationTool(srv).Execute(map[string]interface{}{"network": "base"}); err == nil {
+		t.Error("Expected generate_test_authorization to be refused in production")
+	}
+}
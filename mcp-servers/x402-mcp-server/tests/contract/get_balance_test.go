@@ -0,0 +1,93 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// fakeTokenBalances answers balanceOf(account) calls from a fixed map of balances
+type fakeTokenBalances struct {
+	token    common.Address
+	balances map[common.Address]*big.Int
+	err      error
+}
+
+func (f *fakeTokenBalances) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if call.To == nil || *call.To != f.token {
+		return nil, errors.New("unexpected contract")
+	}
+
+	// balanceOf(address): selector followed by the left-padded account
+	account := common.BytesToAddress(call.Data[4:36])
+	balance := f.balances[account]
+	if balance == nil {
+		balance = new(big.Int)
+	}
+	return common.LeftPadBytes(balance.Bytes(), 32), nil
+}
+
+// TestGetBalance_Execute tests reading and formatting a USDC balance
+func TestGetBalance_Execute(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	holder := common.HexToAddress("0xabcdefabcdefabcdefabcdefabcdefabcdefabcd")
+	backend := &fakeTokenBalances{
+		token:    common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+		balances: map[common.Address]*big.Int{holder: big.NewInt(1234567)},
+	}
+
+	tool := tools.NewGetBalanceTool(srv)
+	tool.SetBalanceFetcher("base", rpc.NewBalanceFetcherWithBackend(backend))
+
+	if tool.Name() != "get_balance" {
+		t.Errorf("Expected tool name get_balance, got %s", tool.Name())
+	}
+
+	result, err := tool.Execute(map[string]interface{}{
+		"address": holder.Hex(),
+		"network": "Base",
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["balance_atomic"] != "1234567" {
+		t.Errorf("Expected balance_atomic 1234567, got %v", resultMap["balance_atomic"])
+	}
+	if resultMap["balance_usdc"] != "1.234567" {
+		t.Errorf("Expected balance_usdc 1.234567, got %v", resultMap["balance_usdc"])
+	}
+	if resultMap["decimals"] != 6 || resultMap["network"] != "base" {
+		t.Errorf("Expected decimals 6 on base, got %v", resultMap)
+	}
+
+	// Unsupported networks and malformed addresses are rejected before any RPC call
+	if _, err := tool.Execute(map[string]interface{}{"address": holder.Hex(), "network": "solana"}); err == nil {
+		t.Error("Expected error for unsupported network")
+	}
+	if _, err := tool.Execute(map[string]interface{}{"address": "0x1234", "network": "base"}); err == nil {
+		t.Error("Expected error for malformed address")
+	}
+
+	backend.err = errors.New("connection refused")
+	if _, err := tool.Execute(map[string]interface{}{"address": holder.Hex(), "network": "base"}); err == nil {
+		t.Error("Expected RPC failure to surface as an error")
+	}
+}
@@ -0,0 +1,133 @@
+package contract
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestGetCapabilities_Defaults tests the document for a default configuration
+func TestGetCapabilities_Defaults(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if err := srv.AddTool(tools.NewCreatePaymentRequirementTool(srv)); err != nil {
+		t.Fatalf("Failed to add tool: %v", err)
+	}
+	capabilitiesTool := tools.NewGetCapabilitiesTool(srv, "1.2.3")
+	if err := srv.AddTool(capabilitiesTool); err != nil {
+		t.Fatalf("Failed to add tool: %v", err)
+	}
+
+	result, err := capabilitiesTool.Execute(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("get_capabilities failed: %v", err)
+	}
+	doc := result.(map[string]interface{})
+
+	if doc["capabilities_version"] != tools.CapabilitiesVersion {
+		t.Errorf("Expected capabilities_version %d, got %v", tools.CapabilitiesVersion, doc["capabilities_version"])
+	}
+	if version := doc["server"].(map[string]interface{})["version"]; version != "1.2.3" {
+		t.Errorf("Expected server version 1.2.3, got %v", version)
+	}
+	if !reflect.DeepEqual(doc["schemes"], []string{"exact"}) {
+		t.Errorf("Expected schemes [exact], got %v", doc["schemes"])
+	}
+
+	networks := doc["networks"].([]interface{})
+	var names []string
+	for _, raw := range networks {
+		network := raw.(map[string]interface{})
+		names = append(names, network["name"].(string))
+
+		assets := network["assets"].([]interface{})
+		asset := assets[0].(map[string]interface{})
+		if asset["decimals"] != 6 || asset["address"] == "" {
+			t.Errorf("Network %v: unexpected asset %v", network["name"], asset)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"arbitrum", "base", "base-sepolia"}) {
+		t.Errorf("Expected configured networks in sorted order, got %v", names)
+	}
+
+	settlement := doc["settlement"].(map[string]interface{})
+	if settlement["mode"] != config.SettlementModeFacilitator || settlement["onchain"] != false {
+		t.Errorf("Expected facilitator settlement by default, got %v", settlement)
+	}
+
+	features := doc["features"].(map[string]interface{})
+	if features["eip1271"] != false {
+		t.Error("Expected eip1271 to be reported unsupported")
+	}
+	if features["multisig"] != false {
+		t.Error("Expected multisig to be disabled without configured wallets")
+	}
+	if features["eip155_v"] != config.EIP155VReject {
+		t.Errorf("Expected eip155_v 'reject' by default, got %v", features["eip155_v"])
+	}
+
+	if !reflect.DeepEqual(doc["tools"], []string{"create_payment_requirement", "get_capabilities"}) {
+		t.Errorf("Expected registered tools, got %v", doc["tools"])
+	}
+}
+
+// TestGetCapabilities_EnabledFeatures tests that enabled features and disabled tools are reflected
+func TestGetCapabilities_EnabledFeatures(t *testing.T) {
+	cfg := createTestConfigForPayment()
+	delete(cfg.Networks, "arbitrum")
+	cfg.Settlement.Mode = config.SettlementModeOnChain
+	cfg.Settlement.EnforceRequirementTimeout = true
+	cfg.Verification.EIP155V = config.EIP155VMatchChain
+	cfg.Verification.Multisig = []config.MultisigWallet{{
+		Wallet:    "0x1111111111111111111111111111111111111111",
+		Threshold: 1,
+		Owners:    []string{"0x2222222222222222222222222222222222222222"},
+	}}
+	cfg.Tools.Disabled = []string{"create_payment_requirement"}
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := srv.AddTool(tools.NewCreatePaymentRequirementTool(srv)); err != nil {
+		t.Fatalf("Failed to add tool: %v", err)
+	}
+
+	result, err := tools.NewGetCapabilitiesTool(srv, "1.2.3").Execute(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("get_capabilities failed: %v", err)
+	}
+	doc := result.(map[string]interface{})
+
+	if networks := doc["networks"].([]interface{}); len(networks) != 2 {
+		t.Errorf("Expected 2 configured networks, got %d", len(networks))
+	}
+
+	settlement := doc["settlement"].(map[string]interface{})
+	if settlement["mode"] != config.SettlementModeOnChain || settlement["onchain"] != true {
+		t.Errorf("Expected on-chain settlement, got %v", settlement)
+	}
+	if settlement["enforce_requirement_timeout"] != true {
+		t.Error("Expected enforce_requirement_timeout to be reported")
+	}
+
+	features := doc["features"].(map[string]interface{})
+	if features["multisig"] != true {
+		t.Error("Expected multisig to be enabled with configured wallets")
+	}
+	if features["eip155_v"] != config.EIP155VMatchChain {
+		t.Errorf("Expected eip155_v 'match_chain', got %v", features["eip155_v"])
+	}
+
+	if names := doc["tools"].([]string); len(names) != 0 {
+		t.Errorf("Expected disabled tools to be omitted, got %v", names)
+	}
+}
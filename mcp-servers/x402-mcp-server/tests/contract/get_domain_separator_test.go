@@ -0,0 +1,83 @@
+package contract
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestGetDomainSeparator tests that the returned separator matches a direct EIP-712 computation
+func TestGetDomainSeparator(t *testing.T) {
+	cfg := createTestConfigForSettlement()
+	cfg.EIP712 = config.EIP712Config{} // Use the built-in per-chain USDC domains
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewGetDomainSeparatorTool(srv)
+
+	tests := []struct {
+		network  string
+		name     string
+		chainID  int64
+		contract string
+	}{
+		{"base", "USD Coin", 8453, "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"},
+		{"base-sepolia", "USDC", 84532, "0x036CbD53842c5426634e7929541eC2318f3dCF7e"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			result, err := tool.Execute(map[string]interface{}{"network": tt.network})
+			if err != nil {
+				t.Fatalf("get_domain_separator failed: %v", err)
+			}
+			resultMap := result.(map[string]interface{})
+
+			expected := (&eip3009.EIP712Domain{
+				Name:              tt.name,
+				Version:           "2",
+				ChainID:           big.NewInt(tt.chainID),
+				VerifyingContract: common.HexToAddress(tt.contract),
+			}).DomainSeparator()
+
+			// Independent encoding: keccak256(typeHash || keccak(name) || keccak(version) || chainId || contract)
+			typeHash := crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
+			manual := crypto.Keccak256Hash(
+				typeHash,
+				crypto.Keccak256([]byte(tt.name)),
+				crypto.Keccak256([]byte("2")),
+				common.LeftPadBytes(big.NewInt(tt.chainID).Bytes(), 32),
+				common.LeftPadBytes(common.HexToAddress(tt.contract).Bytes(), 32),
+			)
+			if expected != manual {
+				t.Fatalf("DomainSeparator %s differs from manual encoding %s", expected.Hex(), manual.Hex())
+			}
+
+			if resultMap["domain_separator"] != expected.Hex() {
+				t.Errorf("Expected domain_separator %s, got %v", expected.Hex(), resultMap["domain_separator"])
+			}
+			if resultMap["name"] != tt.name || resultMap["version"] != "2" {
+				t.Errorf("Expected name %q version 2, got %v / %v", tt.name, resultMap["name"], resultMap["version"])
+			}
+			if resultMap["chain_id"] != uint64(tt.chainID) {
+				t.Errorf("Expected chain_id %d, got %v", tt.chainID, resultMap["chain_id"])
+			}
+			if resultMap["verifying_contract"] != tt.contract {
+				t.Errorf("Expected verifying_contract %s, got %v", tt.contract, resultMap["verifying_contract"])
+			}
+		})
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{"network": "polygon"}); err == nil {
+		t.Error("Expected error for unconfigured network")
+	}
+}
@@ -0,0 +1,102 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestGetPaymentFields_MinimalSet tests that only the fields needed to pay are returned
+func TestGetPaymentFields_MinimalSet(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	created, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":      "50000",
+		"network":     "base",
+		"description": "Premium API access",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+	requirement := created.(map[string]interface{})
+
+	result, err := tools.NewGetPaymentFieldsTool(srv).Execute(map[string]interface{}{"requirement": requirement})
+	if err != nil {
+		t.Fatalf("get_payment_fields failed: %v", err)
+	}
+	resultMap := result.(map[string]interface{})
+
+	expected := []string{"scheme", "network", "maxAmountRequired", "payTo", "asset", "nonce", "validBefore_hint"}
+	if len(resultMap) != len(expected) {
+		t.Errorf("Expected exactly %d fields, got %d: %v", len(expected), len(resultMap), resultMap)
+	}
+	for _, key := range expected {
+		if _, ok := resultMap[key]; !ok {
+			t.Errorf("Missing field %q", key)
+		}
+	}
+
+	for _, key := range []string{"scheme", "network", "maxAmountRequired", "payTo", "asset", "nonce"} {
+		if resultMap[key] != requirement[key] {
+			t.Errorf("Expected %s %v, got %v", key, requirement[key], resultMap[key])
+		}
+	}
+
+	hint, _ := resultMap["validBefore_hint"].(int64)
+	now := time.Now().Unix()
+	if hint <= now || hint > now+int64(24*time.Hour/time.Second) {
+		t.Errorf("Expected validBefore_hint in the next 24h, got %v", resultMap["validBefore_hint"])
+	}
+}
+
+// TestGetPaymentFields_JSONString tests that a requirement given as a JSON string is accepted
+func TestGetPaymentFields_JSONString(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	created, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+	encoded, err := json.Marshal(created)
+	if err != nil {
+		t.Fatalf("Failed to encode requirement: %v", err)
+	}
+
+	result, err := tools.NewGetPaymentFieldsTool(srv).Execute(map[string]interface{}{"requirement": string(encoded)})
+	if err != nil {
+		t.Fatalf("get_payment_fields failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	for _, key := range []string{"description", "resource", "mimeType", "extra", "x402_version", "valid_until"} {
+		if _, ok := resultMap[key]; ok {
+			t.Errorf("Expected field %q to be trimmed", key)
+		}
+	}
+}
+
+// TestGetPaymentFields_InvalidRequirement tests rejection of a malformed requirement
+func TestGetPaymentFields_InvalidRequirement(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if _, err := tools.NewGetPaymentFieldsTool(srv).Execute(map[string]interface{}{"requirement": "{not json"}); err == nil {
+		t.Error("Expected error for malformed requirement")
+	}
+}
@@ -0,0 +1,146 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// countingStateReader wraps stubStateReader, counting authorizationState calls
+type countingStateReader struct {
+	mu    sync.Mutex
+	calls int
+	stub  stubStateReader
+}
+
+func (c *countingStateReader) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	return c.stub.CallContract(ctx, call, blockNumber)
+}
+
+func (c *countingStateReader) markUsed(nonce common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stub.used[nonce] = true
+}
+
+// TestGetPaymentStatus_ToolSchema tests that the schema requires an authorization and network
+func TestGetPaymentStatus_ToolSchema(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewGetPaymentStatusTool(srv)
+
+	if tool.Name() != "get_payment_status" {
+		t.Errorf("Expected name 'get_payment_status', got '%s'", tool.Name())
+	}
+
+	schema := tool.Schema().(map[string]interface{})
+	required := schema["required"].([]string)
+	if len(required) != 2 || required[0] != "authorization" || required[1] != "network" {
+		t.Errorf("Expected required [authorization network], got %v", required)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	authRequired := properties["authorization"].(map[string]interface{})["required"].([]string)
+	if len(authRequired) != 2 || authRequired[0] != "from" || authRequired[1] != "nonce" {
+		t.Errorf("Expected authorization to require only from and nonce, got %v", authRequired)
+	}
+}
+
+// TestGetPaymentStatus_Execute tests available and consumed nonces and the caching of consumed results
+func TestGetPaymentStatus_Execute(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	nonce := common.HexToHash("0x0abc")
+	reader := &countingStateReader{stub: stubStateReader{used: map[common.Hash]bool{}}}
+	tool := tools.NewGetPaymentStatusTool(srv)
+	tool.NonceChecker().SetBackend("base", reader)
+
+	check := func() map[string]interface{} {
+		result, err := tool.Execute(map[string]interface{}{
+			"network": "base",
+			"authorization": map[string]interface{}{
+				"from":  "0x1111111111111111111111111111111111111111",
+				"nonce": nonce.Hex(),
+			},
+		})
+		if err != nil {
+			t.Fatalf("get_payment_status failed: %v", err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	// Unused nonces are re-read every call (no pending TTL configured)
+	for i := 0; i < 2; i++ {
+		result := check()
+		if result["status"] != tools.PaymentStatusAvailable || result["nonce_used"] != false || result["cached"] != false {
+			t.Errorf("Expected uncached available status, got %v", result)
+		}
+	}
+	if reader.calls != 2 {
+		t.Errorf("Expected 2 RPC reads for available nonces, got %d", reader.calls)
+	}
+
+	reader.markUsed(nonce)
+	result := check()
+	if result["status"] != tools.PaymentStatusConsumed || result["nonce_used"] != true || result["cached"] != false {
+		t.Errorf("Expected consumed status, got %v", result)
+	}
+	if result["nonce"] != nonce.Hex() || result["network"] != "base" {
+		t.Errorf("Expected nonce and network echoed, got %v", result)
+	}
+
+	// Consumed is final: served from the server cache without another read
+	result = check()
+	if result["status"] != tools.PaymentStatusConsumed || result["cached"] != true {
+		t.Errorf("Expected cached consumed status, got %v", result)
+	}
+	if reader.calls != 3 {
+		t.Errorf("Expected consumed status to be cached after 3 RPC reads, got %d", reader.calls)
+	}
+}
+
+// TestGetPaymentStatus_InvalidInput tests input validation
+func TestGetPaymentStatus_InvalidInput(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewGetPaymentStatusTool(srv)
+	tool.NonceChecker().SetBackend("base", &stubStateReader{used: map[common.Hash]bool{}})
+
+	payer := "0x1111111111111111111111111111111111111111"
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"missing authorization", map[string]interface{}{"network": "base"}},
+		{"unsupported network", map[string]interface{}{"network": "optimism", "authorization": map[string]interface{}{"from": payer, "nonce": common.HexToHash("0x01").Hex()}}},
+		{"missing nonce", map[string]interface{}{"network": "base", "authorization": map[string]interface{}{"from": payer}}},
+		{"short nonce", map[string]interface{}{"network": "base", "authorization": map[string]interface{}{"from": payer, "nonce": "0x1234"}}},
+		{"invalid from", map[string]interface{}{"network": "base", "authorization": map[string]interface{}{"from": "not-an-address", "nonce": common.HexToHash("0x01").Hex()}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tool.Execute(tt.args); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+const (
+	volumePayeeA = "0xAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAa"
+	volumePayeeB = "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+)
+
+// seedVolumeServer creates a server recording receipts, seeded with settlements at t0 + offsets
+func seedVolumeServer(t *testing.T, t0 time.Time) *x402server.Server {
+	t.Helper()
+
+	cfg := createTestConfigForSettlement()
+	cfg.Audit.RecordReceipts = true
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	at := func(seconds int) time.Time { return t0.Add(time.Duration(seconds) * time.Second) }
+	for _, record := range []audit.Record{
+		// Settled immediately
+		{Timestamp: at(0), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x01", Status: "settled", Payee: volumePayeeA, Value: "1000000"},
+		{Timestamp: at(10), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x02", Status: "settled", Payee: volumePayeeA, Value: "250000"},
+		{Timestamp: at(20), Event: audit.EventSettlementReceipt, Network: "base-sepolia", Nonce: "0x01", Status: "settled", Payee: volumePayeeB, Value: "500000"},
+		// Pending, later settled by reconciliation
+		{Timestamp: at(30), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x03", Status: "pending", Payee: volumePayeeB, Value: "2000000"},
+		{Timestamp: at(100), Event: audit.EventSettlementReconciled, Network: "base", Nonce: "0x03", PreviousStatus: "pending", Status: "settled"},
+		// Pending, later failed: never counted
+		{Timestamp: at(40), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x04", Status: "pending", Payee: volumePayeeA, Value: "9000000"},
+		{Timestamp: at(50), Event: audit.EventSettlementCallback, Network: "base", Nonce: "0x04", PreviousStatus: "pending", Status: "failed"},
+		// A repeated receipt for an already counted settlement
+		{Timestamp: at(60), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x01", Status: "settled", Payee: volumePayeeA, Value: "1000000"},
+	} {
+		if err := srv.GetAuditStore().Append(record); err != nil {
+			t.Fatalf("Failed to seed audit record: %v", err)
+		}
+	}
+
+	return srv
+}
+
+// payeeTotals indexes a get_settled_volume result by payee
+func payeeTotals(t *testing.T, result map[string]interface{}) map[string]map[string]interface{} {
+	t.Helper()
+
+	totals := make(map[string]map[string]interface{})
+	for _, raw := range result["payees"].([]interface{}) {
+		payee := raw.(map[string]interface{})
+		totals[payee["payee"].(string)] = payee
+	}
+	return totals
+}
+
+// TestGetSettledVolume_Aggregation tests per-payee totals across all time
+func TestGetSettledVolume_Aggregation(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	tool := tools.NewGetSettledVolumeTool(seedVolumeServer(t, t0))
+
+	raw, err := tool.Execute(map[string]interface{}{"until": float64(t0.Unix() + 3600)})
+	if err != nil {
+		t.Fatalf("get_settled_volume failed: %v", err)
+	}
+	result := raw.(map[string]interface{})
+
+	totals := payeeTotals(t, result)
+	if len(totals) != 2 {
+		t.Fatalf("Expected 2 payees, got %v", result["payees"])
+	}
+
+	a := totals[common.HexToAddress(volumePayeeA).Hex()]
+	if a["total"] != "1250000" || a["total_human"] != "1.25" || a["settlements"] != 2 {
+		t.Errorf("Unexpected payee A volume: %v", a)
+	}
+	b := totals[common.HexToAddress(volumePayeeB).Hex()]
+	if b["total"] != "2500000" || b["total_human"] != "2.5" || b["settlements"] != 2 {
+		t.Errorf("Unexpected payee B volume: %v", b)
+	}
+
+	// Sorted by descending total
+	if first := result["payees"].([]interface{})[0].(map[string]interface{}); first["total"] != "2500000" {
+		t.Errorf("Expected largest payee first, got %v", first)
+	}
+	if result["total"] != "3750000" || result["settlements"] != 4 {
+		t.Errorf("Unexpected overall total: %v (%v settlements)", result["total"], result["settlements"])
+	}
+}
+
+// TestGetSettledVolume_TimeRange tests that settlements count at the time they became settled
+func TestGetSettledVolume_TimeRange(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	tool := tools.NewGetSettledVolumeTool(seedVolumeServer(t, t0))
+
+	tests := []struct {
+		name          string
+		args          map[string]interface{}
+		expectedTotal string
+	}{
+		{"first receipts only", map[string]interface{}{"since": float64(t0.Unix()), "until": float64(t0.Unix() + 10)}, "1000000"},
+		{"until is exclusive", map[string]interface{}{"since": float64(t0.Unix()), "until": float64(t0.Unix() + 20)}, "1250000"},
+		{"pending receipt before its settlement", map[string]interface{}{"since": float64(t0.Unix() + 30), "until": float64(t0.Unix() + 100)}, "0"},
+		{"pending receipt once settled", map[string]interface{}{"since": float64(t0.Unix() + 30), "until": float64(t0.Unix() + 101)}, "2000000"},
+		{"network filter", map[string]interface{}{"network": "base", "until": float64(t0.Unix() + 3600)}, "3250000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := tool.Execute(tt.args)
+			if err != nil {
+				t.Fatalf("get_settled_volume failed: %v", err)
+			}
+			if total := raw.(map[string]interface{})["total"]; total != tt.expectedTotal {
+				t.Errorf("Expected total %s, got %v", tt.expectedTotal, total)
+			}
+		})
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{"since": float64(t0.Unix()), "until": float64(t0.Unix())}); err == nil {
+		t.Error("Expected error for an empty time range")
+	}
+}
+
+// TestGetSettledVolume_Disabled tests that the tool refuses to report without receipt recording
+func TestGetSettledVolume_Disabled(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForSettlement(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if _, err := tools.NewGetSettledVolumeTool(srv).Execute(map[string]interface{}{}); err == nil {
+		t.Error("Expected error when audit.record_receipts is disabled")
+	}
+}
+
+// TestGetSettledVolume_RecordsSettlements tests that settle_payment records receipts the tool aggregates
+func TestGetSettledVolume_RecordsSettlements(t *testing.T) {
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	cfg.Audit.RecordReceipts = true
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	settleTool := tools.NewSettlePaymentTool(srv)
+	for _, nonce := range []byte{0xa1, 0xa2} {
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress(volumePayeeA), big.NewInt(50000), [32]byte{nonce})
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		if _, err := settleTool.Execute(map[string]interface{}{"authorization": authInput, "network": "base"}); err != nil {
+			t.Fatalf("settle_payment failed: %v", err)
+		}
+	}
+
+	raw, err := tools.NewGetSettledVolumeTool(srv).Execute(map[string]interface{}{"until": float64(time.Now().Unix() + 60)})
+	if err != nil {
+		t.Fatalf("get_settled_volume failed: %v", err)
+	}
+
+	a := payeeTotals(t, raw.(map[string]interface{}))[common.HexToAddress(volumePayeeA).Hex()]
+	if a == nil || a["total"] != "100000" || a["settlements"] != 2 {
+		t.Errorf("Expected 2 settlements totalling 100000 for payee A, got %v", a)
+	}
+}
@@ -2,12 +2,17 @@ package contract
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
 	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
 	"github.com/mark3labs/mcp-go/server"
 )
 
@@ -82,6 +87,67 @@ func TestMCPServer_AddToolDuplicate(t *testing.T) {
 	}
 }
 
+// DescribingTool is an executable tool that returns its own description
+type DescribingTool struct {
+	MockTool
+}
+
+func (d *DescribingTool) Execute(args map[string]interface{}) (interface{}, error) {
+	return d.description, nil
+}
+
+// TestMCPServer_AddToolDuplicatePolicy verifies tools.on_duplicate handling of a repeated tool name
+func TestMCPServer_AddToolDuplicatePolicy(t *testing.T) {
+	tests := []struct {
+		policy    string
+		expectErr bool
+		expected  string
+	}{
+		{"", true, "first"},
+		{config.DuplicateToolError, true, "first"},
+		{config.DuplicateToolReplace, false, "second"},
+		{config.DuplicateToolSkip, false, "first"},
+	}
+
+	for _, tt := range tests {
+		t.Run("policy_"+tt.policy, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Tools.OnDuplicate = tt.policy
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+
+			first := &DescribingTool{MockTool{name: "test_tool", description: "first"}}
+			second := &DescribingTool{MockTool{name: "test_tool", description: "second"}}
+
+			if err := srv.AddTool(first); err != nil {
+				t.Fatalf("First AddTool failed: %v", err)
+			}
+			err = srv.AddTool(second)
+			if tt.expectErr && err == nil {
+				t.Error("Expected error for duplicate tool, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected duplicate tool to be accepted, got %v", err)
+			}
+
+			if srv.ToolCount() != 1 {
+				t.Errorf("Expected 1 tool, got %d", srv.ToolCount())
+			}
+
+			result, err := srv.ExecuteTool("test_tool", map[string]interface{}{})
+			if err != nil {
+				t.Fatalf("ExecuteTool failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected the %s tool to be registered, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
 // TestMCPServer_AddToolNil verifies nil tool rejection
 func TestMCPServer_AddToolNil(t *testing.T) {
 	cfg := createTestConfig()
@@ -256,3 +322,262 @@ func createTestConfig() *config.Config {
 		},
 	}
 }
+
+// TestMCPServer_DisabledToolNotRegistered verifies disabled tools are skipped and not invocable
+func TestMCPServer_DisabledToolNotRegistered(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Tools.Disabled = []string{"settle_payment"}
+	log := logger.New(logger.DEBUG, &bytes.Buffer{})
+
+	srv, err := x402server.NewServer(cfg, log)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// Adding a disabled tool is a no-op
+	if err := srv.AddTool(tools.NewSettlePaymentTool(srv)); err != nil {
+		t.Fatalf("AddTool for disabled tool should not error: %v", err)
+	}
+
+	if err := srv.AddTool(tools.NewVerifyPaymentTool(srv)); err != nil {
+		t.Fatalf("AddTool failed: %v", err)
+	}
+
+	if srv.ToolCount() != 1 {
+		t.Errorf("Expected 1 registered tool, got %d", srv.ToolCount())
+	}
+
+	_, err = srv.ExecuteTool("settle_payment", map[string]interface{}{})
+	if !errors.Is(err, x402server.ErrToolDisabled) {
+		t.Errorf("Expected ErrToolDisabled, got %v", err)
+	}
+}
+
+// TestMCPServer_DispatchDisabledTool verifies MCP tools/call requests honour tools.disabled,
+// including for a tool disabled by a reload after registration
+func TestMCPServer_DispatchDisabledTool(t *testing.T) {
+	cfg := createTestConfig()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if err := srv.AddTool(tools.NewCreatePaymentRequirementTool(srv)); err != nil {
+		t.Fatalf("AddTool failed: %v", err)
+	}
+	mcpServer := server.NewMCPServer("test-server", "0.1.0")
+	if err := srv.RegisterTools(mcpServer); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+
+	args := map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	}
+
+	result, err := callTool(mcpServer, "create_payment_requirement", args)
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	if result.IsError || result.StructuredContent["error_code"] != nil {
+		t.Fatalf("Expected a payment requirement from the enabled tool, got %+v", result)
+	}
+
+	newCfg := createTestConfig()
+	newCfg.Tools.Disabled = []string{"create_payment_requirement"}
+	if err := srv.ReloadConfig(newCfg); err != nil {
+		t.Fatalf("Config reload failed: %v", err)
+	}
+
+	result, err = callTool(mcpServer, "create_payment_requirement", args)
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("Expected an error result for a disabled tool, got %+v", result)
+	}
+	if code := result.StructuredContent["error_code"]; code != x402server.ErrorCodeToolDisabled {
+		t.Errorf("Expected error_code '%s', got %v", x402server.ErrorCodeToolDisabled, code)
+	}
+}
+
+// TestMCPServer_EnabledToolsAllowlist verifies only allowlisted tools are registered
+func TestMCPServer_EnabledToolsAllowlist(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Tools.Enabled = []string{"verify_payment"}
+	log := logger.New(logger.DEBUG, &bytes.Buffer{})
+
+	srv, err := x402server.NewServer(cfg, log)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if err := srv.AddTool(tools.NewCreatePaymentRequirementTool(srv)); err != nil {
+		t.Fatalf("AddTool failed: %v", err)
+	}
+	if err := srv.AddTool(tools.NewVerifyPaymentTool(srv)); err != nil {
+		t.Fatalf("AddTool failed: %v", err)
+	}
+
+	if srv.ToolCount() != 1 {
+		t.Errorf("Expected 1 registered tool, got %d", srv.ToolCount())
+	}
+
+	_, err = srv.ExecuteTool("create_payment_requirement", map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if !errors.Is(err, x402server.ErrToolDisabled) {
+		t.Errorf("Expected ErrToolDisabled for non-allowlisted tool, got %v", err)
+	}
+
+	// Enabled tool remains invocable (bad input surfaces a tool error, not a disabled error)
+	_, err = srv.ExecuteTool("verify_payment", map[string]interface{}{})
+	if err == nil || errors.Is(err, x402server.ErrToolDisabled) {
+		t.Errorf("Expected input error from verify_payment, got %v", err)
+	}
+}
+
+// TestMCPServer_NoNetworksConfigured verifies server creation fails fast without networks
+func TestMCPServer_NoNetworksConfigured(t *testing.T) {
+	log := logger.New(logger.DEBUG, &bytes.Buffer{})
+
+	for name, networks := range map[string]map[string]config.NetworkConfig{
+		"nil map":   nil,
+		"empty map": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := createTestConfig()
+			cfg.Networks = networks
+
+			srv, err := x402server.NewServer(cfg, log)
+			if err == nil {
+				t.Fatal("Expected error when no networks are configured")
+			}
+			if srv != nil {
+				t.Error("Expected nil server on error")
+			}
+			if !strings.Contains(err.Error(), "no networks configured") {
+				t.Errorf("Expected clear 'no networks configured' message, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestMCPServer_InvalidNetworkConfigured verifies server creation fails for an invalid network
+func TestMCPServer_InvalidNetworkConfigured(t *testing.T) {
+	cfg := createTestConfig()
+	base := cfg.Networks["base"]
+	base.ChainID = 1
+	cfg.Networks["base"] = base
+
+	if _, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{})); err == nil {
+		t.Fatal("Expected error for invalid network config")
+	}
+}
+
+// TestMCPServer_ReadinessGate verifies tool calls are rejected until warmup completes, both
+// through ExecuteTool and through MCP tools/call dispatch
+func TestMCPServer_ReadinessGate(t *testing.T) {
+	cfg := createTestConfig()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	if err := srv.AddTool(tools.NewCreatePaymentRequirementTool(srv)); err != nil {
+		t.Fatalf("Failed to add tool: %v", err)
+	}
+	mcpServer := server.NewMCPServer("test-server", "0.1.0")
+	if err := srv.RegisterTools(mcpServer); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+
+	release := make(chan struct{})
+	srv.AddWarmupCheck("blocking", func(ctx context.Context) error {
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	args := map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	}
+
+	done := srv.StartWarmup()
+
+	result, err := srv.ExecuteTool("create_payment_requirement", args)
+	if err != nil {
+		t.Fatalf("ExecuteTool returned error: %v", err)
+	}
+	if code := result.(map[string]interface{})["error_code"]; code != x402server.ErrorCodeNotReady {
+		t.Errorf("Expected error_code '%s' before warmup completes, got %v", x402server.ErrorCodeNotReady, code)
+	}
+
+	dispatched, err := callTool(mcpServer, "create_payment_requirement", args)
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	if code := dispatched.StructuredContent["error_code"]; code != x402server.ErrorCodeNotReady {
+		t.Errorf("Expected tools/call error_code '%s' before warmup completes, got %v", x402server.ErrorCodeNotReady, code)
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Warmup did not complete")
+	}
+
+	if state, _ := srv.Readiness(); state != x402server.ReadinessReady {
+		t.Errorf("Expected state '%s', got '%s'", x402server.ReadinessReady, state)
+	}
+
+	result, err = srv.ExecuteTool("create_payment_requirement", args)
+	if err != nil {
+		t.Fatalf("ExecuteTool after warmup returned error: %v", err)
+	}
+	if _, notReady := result.(map[string]interface{})["error_code"]; notReady {
+		t.Errorf("Expected a payment requirement after warmup, got %v", result)
+	}
+
+	dispatched, err = callTool(mcpServer, "create_payment_requirement", args)
+	if err != nil {
+		t.Fatalf("tools/call after warmup failed: %v", err)
+	}
+	if _, notReady := dispatched.StructuredContent["error_code"]; notReady || dispatched.IsError {
+		t.Errorf("Expected a payment requirement from tools/call after warmup, got %+v", dispatched)
+	}
+}
+
+// TestMCPServer_WarmupTimeoutServesDegraded verifies a failing check only delays serving
+func TestMCPServer_WarmupTimeoutServesDegraded(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Readiness.WarmupTimeoutSeconds = 1
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	srv.AddWarmupCheck("unreachable", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	select {
+	case <-srv.StartWarmup():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Warmup did not time out")
+	}
+
+	state, failed := srv.Readiness()
+	if state != x402server.ReadinessDegraded {
+		t.Errorf("Expected state '%s', got '%s'", x402server.ReadinessDegraded, state)
+	}
+	if len(failed) != 1 || failed[0] != "unreachable" {
+		t.Errorf("Expected failed checks [unreachable], got %v", failed)
+	}
+}
@@ -0,0 +1,204 @@
+package contract
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+	"github.com/mark3labs/mcp-go/server"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// TestMetrics_ToolExecutions tests that MCP tool calls are counted by status and exposed for
+// scraping in a format Prometheus parses
+func TestMetrics_ToolExecutions(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := srv.AddTool(tools.NewCreatePaymentRequirementTool(srv)); err != nil {
+		t.Fatalf("AddTool failed: %v", err)
+	}
+	mcpServer := server.NewMCPServer("test-server", "0.1.0")
+	if err := srv.RegisterTools(mcpServer); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+
+	result, err := callTool(mcpServer, "create_payment_requirement", map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("create_payment_requirement failed: %v %+v", err, result)
+	}
+	result, err = callTool(mcpServer, "create_payment_requirement", map[string]interface{}{
+		"amount":  "not-a-number",
+		"network": "base",
+	})
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected invalid amount to fail, got %v %+v", err, result)
+	}
+
+	registry := srv.GetMetrics()
+	for status, want := range map[string]float64{x402server.ToolStatusSuccess: 1, x402server.ToolStatusError: 1} {
+		got := registry.CounterValue(x402server.MetricToolExecutions, metrics.Labels{"tool": "create_payment_requirement", "status": status})
+		if got != want {
+			t.Errorf("Expected %v %s executions, got %v", want, status, got)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler(registry).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if format := expfmt.ResponseFormat(recorder.Header()); format.FormatType() != expfmt.TypeTextPlain {
+		t.Errorf("Expected the Prometheus text format, got Content-Type %q", recorder.Header().Get("Content-Type"))
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(recorder.Body)
+	if err != nil {
+		t.Fatalf("Scrape is not valid Prometheus text format: %v", err)
+	}
+
+	executions := families[x402server.MetricToolExecutions]
+	if executions == nil || executions.GetType() != dto.MetricType_COUNTER {
+		t.Fatalf("Expected counter %s, got %v", x402server.MetricToolExecutions, executions)
+	}
+	found := false
+	for _, metric := range executions.GetMetric() {
+		labels := labelMap(metric)
+		if labels["tool"] == "create_payment_requirement" && labels["status"] == x402server.ToolStatusSuccess {
+			found = true
+			if value := metric.GetCounter().GetValue(); value != 1 {
+				t.Errorf("Expected 1 successful execution, got %v", value)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected a success series for create_payment_requirement, got %v", executions)
+	}
+
+	duration := families[x402server.MetricToolDuration]
+	if duration == nil || duration.GetType() != dto.MetricType_HISTOGRAM || len(duration.GetMetric()) != 1 {
+		t.Fatalf("Expected one %s histogram series, got %v", x402server.MetricToolDuration, duration)
+	}
+	histogram := duration.GetMetric()[0].GetHistogram()
+	if histogram.GetSampleCount() != 2 {
+		t.Errorf("Expected 2 observations, got %d", histogram.GetSampleCount())
+	}
+	buckets := histogram.GetBucket()
+	if len(buckets) != len(x402server.ToolDurationBuckets)+1 {
+		t.Fatalf("Expected %d buckets plus +Inf, got %d", len(x402server.ToolDurationBuckets), len(buckets))
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].GetCumulativeCount() < buckets[i-1].GetCumulativeCount() {
+			t.Errorf("Expected cumulative bucket counts, got %v", buckets)
+		}
+	}
+	if last := buckets[len(buckets)-1]; !math.IsInf(last.GetUpperBound(), 1) || last.GetCumulativeCount() != 2 {
+		t.Errorf("Expected the +Inf bucket to hold both observations, got %v", last)
+	}
+}
+
+// TestMetrics_TraceExemplars tests that a traced tool call's latency is linked to its trace
+// ID by an OpenMetrics exemplar only when both tracing and metrics are enabled
+func TestMetrics_TraceExemplars(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent := "00-" + traceID + "-00f067aa0ba902b7-01"
+
+	tests := []struct {
+		name           string
+		tracing        bool
+		metrics        bool
+		traceparent    string
+		expectExemplar bool
+	}{
+		{"tracing and metrics enabled", true, true, traceparent, true},
+		{"tracing disabled", false, true, traceparent, false},
+		{"metrics disabled", true, false, traceparent, false},
+		{"malformed traceparent", true, true, "00-" + strings.ToUpper(traceID) + "-00f067aa0ba902b7-01", false},
+		{"all-zero trace ID", true, true, "00-" + strings.Repeat("0", 32) + "-00f067aa0ba902b7-01", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfigForPayment()
+			cfg.Tracing.Enabled = tt.tracing
+			cfg.Metrics.Enabled = tt.metrics
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			if err := srv.AddTool(tools.NewCreatePaymentRequirementTool(srv)); err != nil {
+				t.Fatalf("AddTool failed: %v", err)
+			}
+			mcpServer := server.NewMCPServer("test-server", "0.1.0")
+			if err := srv.RegisterTools(mcpServer); err != nil {
+				t.Fatalf("RegisterTools failed: %v", err)
+			}
+
+			result, err := callToolWithMeta(mcpServer, "create_payment_requirement", map[string]interface{}{
+				"amount":  "50000",
+				"network": "base",
+			}, map[string]interface{}{x402server.TraceparentMetaKey: tt.traceparent})
+			if err != nil || result.IsError {
+				t.Fatalf("create_payment_requirement failed: %v %+v", err, result)
+			}
+
+			snapshot := srv.GetMetrics().Histogram(x402server.MetricToolDuration, metrics.Labels{"tool": "create_payment_requirement"})
+			var recorded *metrics.Exemplar
+			for _, exemplar := range snapshot.Exemplars {
+				if exemplar != nil {
+					recorded = exemplar
+				}
+			}
+			if tt.expectExemplar && (recorded == nil || recorded.Labels["trace_id"] != traceID) {
+				t.Fatalf("Expected an exemplar with trace_id %s, got %+v", traceID, recorded)
+			}
+			if !tt.expectExemplar && recorded != nil {
+				t.Fatalf("Expected no exemplar, got %+v", recorded)
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			request.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+			recorder := httptest.NewRecorder()
+			metrics.Handler(srv.GetMetrics()).ServeHTTP(recorder, request)
+			if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/openmetrics-text") {
+				t.Fatalf("Expected OpenMetrics, got Content-Type %q", contentType)
+			}
+
+			body := recorder.Body.String()
+			exemplarLine := false
+			for _, line := range strings.Split(body, "\n") {
+				if strings.HasPrefix(line, x402server.MetricToolDuration+"_bucket{") &&
+					strings.Contains(line, ` # {trace_id="`+traceID+`"} `) {
+					exemplarLine = true
+				}
+			}
+			if exemplarLine != tt.expectExemplar {
+				t.Errorf("Expected exemplar in scrape: %v, got:\n%s", tt.expectExemplar, body)
+			}
+			if !strings.HasSuffix(body, "# EOF\n") {
+				t.Errorf("Expected an OpenMetrics scrape terminated by # EOF, got:\n%s", body)
+			}
+		})
+	}
+}
+
+// labelMap returns a scraped metric's labels by name
+func labelMap(metric *dto.Metric) map[string]string {
+	labels := make(map[string]string, len(metric.GetLabel()))
+	for _, pair := range metric.GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+	return labels
+}
@@ -0,0 +1,230 @@
+package contract
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// countingSettlementBackend is an RPC node recording broadcast transactions
+type countingSettlementBackend struct {
+	mu   sync.Mutex
+	sent []*types.Transaction
+}
+
+func (b *countingSettlementBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1_000_000_000), nil
+}
+
+func (b *countingSettlementBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return uint64(len(b.sent)), nil
+}
+
+func (b *countingSettlementBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 90000, nil
+}
+
+func (b *countingSettlementBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *countingSettlementBackend) broadcasts() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.sent)
+}
+
+// TestSettlePayment_OnChainIdempotent tests that retried on-chain settlements of one
+// authorization broadcast a single transaction and all return its hash
+func TestSettlePayment_OnChainIdempotent(t *testing.T) {
+	relayerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate relayer key: %v", err)
+	}
+	t.Setenv("TEST_RELAYER_KEY", hex.EncodeToString(crypto.FromECDSA(relayerKey)))
+
+	cfg := createTestConfigForSettlement()
+	cfg.Settlement.Mode = config.SettlementModeOnChain
+	cfg.Settlement.RelayerKeyEnv = "TEST_RELAYER_KEY"
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewSettlePaymentTool(srv)
+	if tool.OnChainSettler() == nil {
+		t.Fatal("Expected an on-chain settler")
+	}
+	backend := &countingSettlementBackend{}
+	tool.OnChainSettler().SetBackend("base", backend)
+
+	privateKey, fromAddr, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	toAddr := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	now := time.Now().Unix()
+	validAfter := big.NewInt(now - 3600)
+	validBefore := big.NewInt(now + 3600)
+
+	sign := func(nonceByte byte) map[string]interface{} {
+		var nonce [32]byte
+		nonce[31] = nonceByte
+		v, r, s, err := generateValidSignature(privateKey, fromAddr, toAddr, big.NewInt(50000), validAfter, validBefore, nonce,
+			big.NewInt(8453), common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"))
+		if err != nil {
+			t.Fatalf("Failed to generate valid signature: %v", err)
+		}
+		return map[string]interface{}{
+			"from":        fromAddr.Hex(),
+			"to":          toAddr.Hex(),
+			"value":       "50000",
+			"validAfter":  float64(validAfter.Int64()),
+			"validBefore": float64(validBefore.Int64()),
+			"nonce":       common.BytesToHash(nonce[:]).Hex(),
+			"v":           float64(v),
+			"r":           common.BytesToHash(r.Bytes()).Hex(),
+			"s":           common.BytesToHash(s.Bytes()).Hex(),
+		}
+	}
+	settle := func(authInput map[string]interface{}) map[string]interface{} {
+		result, err := tool.Execute(map[string]interface{}{
+			"authorization": authInput,
+			"network":       "base",
+		})
+		if err != nil {
+			t.Errorf("Tool execution failed: %v", err)
+			return nil
+		}
+		return result.(map[string]interface{})
+	}
+
+	// Concurrent retries of one authorization
+	authInput := sign(0x71)
+	results := make([]map[string]interface{}, 8)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = settle(authInput)
+		}(i)
+	}
+	wg.Wait()
+
+	// A later retry, after the broadcast completed
+	results = append(results, settle(authInput))
+
+	if n := backend.broadcasts(); n != 1 {
+		t.Fatalf("Expected 1 broadcast for retried settlements, got %d", n)
+	}
+	txHash := backend.sent[0].Hash().Hex()
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		if result["tx_hash"] != txHash {
+			t.Errorf("Retry %d: expected cached tx_hash %s, got %v", i, txHash, result["tx_hash"])
+		}
+	}
+
+	// A different authorization still broadcasts
+	settle(sign(0x72))
+	if n := backend.broadcasts(); n != 2 {
+		t.Errorf("Expected a second broadcast for a new nonce, got %d", n)
+	}
+}
+
+// TestSettlePayment_ReloadToOnChain tests that a reload switching to on-chain mode
+// settles with the relayer, and that a missing relayer key is reported
+func TestSettlePayment_ReloadToOnChain(t *testing.T) {
+	cfg := createTestConfigForSettlement()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewSettlePaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	settle := func(nonceByte byte) (interface{}, error) {
+		var nonce [32]byte
+		nonce[31] = nonceByte
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		return tool.Execute(map[string]interface{}{
+			"authorization": authInput,
+			"network":       "base",
+		})
+	}
+
+	// Without the relayer key, settlement names the cause
+	missingKey := createTestConfigForSettlement()
+	missingKey.Settlement.Mode = config.SettlementModeOnChain
+	missingKey.Settlement.RelayerKeyEnv = "TEST_RELOAD_RELAYER_KEY_UNSET"
+	if err := srv.ReloadConfig(missingKey); err != nil {
+		t.Fatalf("Config reload failed: %v", err)
+	}
+	if _, err := settle(0x91); err == nil || !strings.Contains(err.Error(), "TEST_RELOAD_RELAYER_KEY_UNSET") {
+		t.Errorf("Expected the missing relayer key to be reported, got %v", err)
+	}
+
+	relayerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate relayer key: %v", err)
+	}
+	t.Setenv("TEST_RELOAD_RELAYER_KEY", hex.EncodeToString(crypto.FromECDSA(relayerKey)))
+
+	onChainCfg := createTestConfigForSettlement()
+	onChainCfg.Settlement.Mode = config.SettlementModeOnChain
+	onChainCfg.Settlement.RelayerKeyEnv = "TEST_RELOAD_RELAYER_KEY"
+	if err := srv.ReloadConfig(onChainCfg); err != nil {
+		t.Fatalf("Config reload failed: %v", err)
+	}
+	if tool.OnChainSettler() == nil {
+		t.Fatal("Expected an on-chain settler after the reload")
+	}
+	backend := &countingSettlementBackend{}
+	tool.OnChainSettler().SetBackend("base", backend)
+
+	result, err := settle(0x92)
+	if err != nil {
+		t.Fatalf("On-chain settlement failed: %v", err)
+	}
+	if n := backend.broadcasts(); n != 1 {
+		t.Fatalf("Expected 1 broadcast, got %d", n)
+	}
+	if txHash := result.(map[string]interface{})["tx_hash"]; txHash != backend.sent[0].Hash().Hex() {
+		t.Errorf("Expected tx_hash %s, got %v", backend.sent[0].Hash().Hex(), txHash)
+	}
+}
@@ -0,0 +1,128 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// newCodeRPC serves eth_getCode, reporting contract code only for the given addresses
+func newCodeRPC(t *testing.T, contracts ...common.Address) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []string        `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		code := "0x"
+		for _, contract := range contracts {
+			if request.Method == "eth_getCode" && strings.EqualFold(request.Params[0], contract.Hex()) {
+				code = "0x6080604052"
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      request.ID,
+			"result":  code,
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestVerifyPayment_PayeeAllowlist tests the payee policy for contract payees on and off the allowlist
+func TestVerifyPayment_PayeeAllowlist(t *testing.T) {
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	listedContract := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	unlistedContract := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	eoaPayee := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	rpc := newCodeRPC(t, listedContract, unlistedContract)
+
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+	}
+
+	tests := []struct {
+		name          string
+		policy        string
+		payee         common.Address
+		expectValid   bool
+		expectCode    string
+		expectWarning bool
+	}{
+		{"allowlisted contract", config.PayeePolicyReject, listedContract, true, "", false},
+		{"unlisted EOA", config.PayeePolicyReject, eoaPayee, true, "", false},
+		{"unlisted contract rejected", config.PayeePolicyReject, unlistedContract, false, eip3009.ErrorCodePayeeNotAllowlisted, false},
+		{"unlisted contract rejected by default", "", unlistedContract, false, eip3009.ErrorCodePayeeNotAllowlisted, false},
+		{"unlisted contract warned", config.PayeePolicyWarn, unlistedContract, true, "", true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfigForVerification()
+			cfg.AllowPrivateURLs = true
+			network := cfg.Networks["base"]
+			network.RPCURL = rpc.URL
+			cfg.Networks["base"] = network
+			cfg.Verification.PayeeAllowlist = []string{strings.ToLower(listedContract.Hex())}
+			cfg.Verification.PayeePolicy = tt.policy
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			tool := tools.NewVerifyPaymentTool(srv)
+
+			authorization, err := buildSignedAuthorizationInput(privateKey, domain, tt.payee, big.NewInt(50000), [32]byte{byte(i + 1), 0x5a})
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization": authorization,
+				"network":       "base",
+			})
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["is_valid"] != tt.expectValid {
+				t.Errorf("Expected is_valid=%v, got %v (%v)", tt.expectValid, resultMap["is_valid"], resultMap["error"])
+			}
+			if code, _ := resultMap["error_code"].(string); code != tt.expectCode {
+				t.Errorf("Expected error_code %q, got %q", tt.expectCode, code)
+			}
+			if _, warned := resultMap["payee_not_allowlisted"]; warned != tt.expectWarning {
+				t.Errorf("Expected payee_not_allowlisted present=%v, got %v", tt.expectWarning, resultMap["payee_not_allowlisted"])
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// buildPaymentPayload signs an authorization and wraps it in an x402 PaymentPayload JSON
+// document, with string timestamps and a 65-byte signature as x402 clients send them
+func buildPaymentPayload(t *testing.T, domain *eip3009.EIP712Domain, network string, nonce byte) string {
+	t.Helper()
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	var nonceBytes [32]byte
+	nonceBytes[31] = nonce
+	input, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonceBytes)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	signature := fmt.Sprintf("0x%s%s%02x",
+		strings.TrimPrefix(input["r"].(string), "0x"),
+		strings.TrimPrefix(input["s"].(string), "0x"),
+		int(input["v"].(float64)))
+
+	return fmt.Sprintf(`{
+  "x402Version": 1,
+  "scheme": "exact",
+  "network": %q,
+  "payload": {
+    "signature": %q,
+    "authorization": {
+      "from": %q,
+      "to": %q,
+      "value": %q,
+      "validAfter": "%d",
+      "validBefore": "%d",
+      "nonce": %q
+    }
+  }
+}`, network, signature, input["from"], input["to"], input["value"],
+		int64(input["validAfter"].(float64)), int64(input["validBefore"].(float64)), input["nonce"])
+}
+
+// TestVerifyPaymentPayload tests verification of x402 PaymentPayload objects in each accepted encoding
+func TestVerifyPaymentPayload(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewVerifyPaymentPayloadTool(tools.NewVerifyPaymentTool(srv))
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	payloadJSON := buildPaymentPayload(t, domain, "base", 1)
+	var payloadObject map[string]interface{}
+	if err := json.Unmarshal([]byte(payloadJSON), &payloadObject); err != nil {
+		t.Fatalf("Failed to decode payload: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload interface{}
+	}{
+		{"object", payloadObject},
+		{"json string", payloadJSON},
+		{"X-PAYMENT header", base64.StdEncoding.EncodeToString([]byte(payloadJSON))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(map[string]interface{}{
+				"payment_payload": tt.payload,
+			})
+			if err != nil {
+				t.Fatalf("verify_payment_payload failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["is_valid"] != true {
+				t.Errorf("Expected valid payload, got %v", resultMap)
+			}
+			if resultMap["to"] != "0x2222222222222222222222222222222222222222" {
+				t.Errorf("Expected payee from the payload, got %v", resultMap["to"])
+			}
+		})
+	}
+
+	// A payload signed for another network's domain does not verify on the payload's network
+	sepolia, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base-sepolia")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	result, err := tool.Execute(map[string]interface{}{
+		"payment_payload": buildPaymentPayload(t, sepolia, "base", 2),
+	})
+	if err != nil {
+		t.Fatalf("verify_payment_payload failed: %v", err)
+	}
+	if result.(map[string]interface{})["is_valid"] != false {
+		t.Errorf("Expected cross-network payload to be invalid, got %v", result)
+	}
+
+	// Malformed envelopes are input errors; unsupported schemes are results (see TestPaymentPayload_UnsupportedScheme)
+	for name, payload := range map[string]string{
+		"short signature": strings.Replace(payloadJSON, `"signature": "0x`, `"signature": "0x00`, 1),
+		"not base64":      "%%%",
+	} {
+		if _, err := tool.Execute(map[string]interface{}{"payment_payload": payload}); err == nil {
+			t.Errorf("Expected error for %s", name)
+		}
+	}
+}
+
+// TestSettlePaymentPayload tests settlement of an x402 PaymentPayload through the shared settle tool
+func TestSettlePaymentPayload(t *testing.T) {
+	calls := 0
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	base := cfg.Networks["base"]
+	base.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = base
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	settle := tools.NewSettlePaymentTool(srv)
+	tool := tools.NewSettlePaymentPayloadTool(settle)
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	payload := buildPaymentPayload(t, domain, "base", 3)
+
+	result, err := tool.Execute(map[string]interface{}{
+		"payment_payload":      base64.StdEncoding.EncodeToString([]byte(payload)),
+		"expected_value_human": "0.05",
+	})
+	if err != nil {
+		t.Fatalf("settle_payment_payload failed: %v", err)
+	}
+	if status := result.(map[string]interface{})["status"]; status != "settled" {
+		t.Fatalf("Expected status 'settled', got %v", result)
+	}
+
+	// The payload tool shares settle_payment's idempotency cache
+	var decoded map[string]interface{}
+	json.Unmarshal([]byte(payload), &decoded)
+	if _, err := tool.Execute(map[string]interface{}{"payment_payload": decoded}); err != nil {
+		t.Fatalf("Repeat settle_payment_payload failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected one facilitator call for a repeated payload, got %d", calls)
+	}
+
+	schema := tool.Schema().(map[string]interface{})
+	properties := schema["properties"].(map[string]interface{})
+	if _, exists := properties["authorization"]; exists {
+		t.Error("Expected payload schema to replace authorization with payment_payload")
+	}
+	if _, exists := properties["idempotency_token"]; !exists {
+		t.Error("Expected payload schema to keep settle_payment options")
+	}
+}
+
+// TestVerifyPaymentPayload_CompactProof tests that verify_payment's compact_proof round-trips through verify_payment_payload
+func TestVerifyPaymentPayload_CompactProof(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	verify := tools.NewVerifyPaymentTool(srv)
+	payloadTool := tools.NewVerifyPaymentPayloadTool(verify)
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	var nonce [32]byte
+	nonce[31] = 7
+	input, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	result, err := verify.Execute(map[string]interface{}{
+		"network":               "base",
+		"authorization":         input,
+		"include_compact_proof": true,
+	})
+	if err != nil {
+		t.Fatalf("verify_payment failed: %v", err)
+	}
+	proof, ok := result.(map[string]interface{})["compact_proof"].(string)
+	if !ok {
+		t.Fatalf("Expected compact_proof in result, got %v", result)
+	}
+
+	jsonSize, _ := json.Marshal(map[string]interface{}{"network": "base", "authorization": input})
+	if len(proof) >= len(jsonSize) {
+		t.Errorf("Expected compact proof (%d bytes) to be smaller than JSON (%d bytes)", len(proof), len(jsonSize))
+	}
+
+	result, err = payloadTool.Execute(map[string]interface{}{"compact_proof": proof})
+	if err != nil {
+		t.Fatalf("verify_payment_payload failed: %v", err)
+	}
+	if result.(map[string]interface{})["is_valid"] != true {
+		t.Errorf("Expected compact proof to verify, got %v", result)
+	}
+
+	// Malformed proofs and ambiguous inputs are rejected
+	invalid := []map[string]interface{}{
+		{"compact_proof": "not base64!"},
+		{"compact_proof": base64.StdEncoding.EncodeToString([]byte{1, 2, 3})},
+		{"compact_proof": proof, "payment_payload": buildPaymentPayload(t, domain, "base", 8)},
+	}
+	for _, args := range invalid {
+		if _, err := payloadTool.Execute(args); err == nil {
+			t.Errorf("Expected error for %v", args)
+		}
+	}
+}
+
+// TestPaymentPayload_UnsupportedScheme tests that payloads outside verification.accepted_schemes are rejected
+func TestPaymentPayload_UnsupportedScheme(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	cfg.Verification.AcceptedSchemes = []string{"exact"}
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	verifyTool := tools.NewVerifyPaymentPayloadTool(tools.NewVerifyPaymentTool(srv))
+	settleTool := tools.NewSettlePaymentPayloadTool(tools.NewSettlePaymentTool(srv))
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	exact := buildPaymentPayload(t, domain, "base", 9)
+	result, err := verifyTool.Execute(map[string]interface{}{"payment_payload": exact})
+	if err != nil {
+		t.Fatalf("verify_payment_payload failed: %v", err)
+	}
+	if result.(map[string]interface{})["is_valid"] != true {
+		t.Errorf("Expected exact scheme payload to verify, got %v", result)
+	}
+
+	upto := strings.Replace(exact, `"scheme": "exact"`, `"scheme": "upto"`, 1)
+	result, err = verifyTool.Execute(map[string]interface{}{"payment_payload": upto})
+	if err != nil {
+		t.Fatalf("verify_payment_payload failed: %v", err)
+	}
+	resultMap := result.(map[string]interface{})
+	if resultMap["is_valid"] != false || resultMap["error_code"] != "unsupported_scheme" {
+		t.Errorf("Expected unsupported_scheme, got %v", resultMap)
+	}
+
+	result, err = settleTool.Execute(map[string]interface{}{"payment_payload": upto})
+	if err != nil {
+		t.Fatalf("settle_payment_payload failed: %v", err)
+	}
+	resultMap = result.(map[string]interface{})
+	if resultMap["status"] != "failed" || resultMap["error_code"] != "unsupported_scheme" {
+		t.Errorf("Expected unsupported_scheme, got %v", resultMap)
+	}
+}
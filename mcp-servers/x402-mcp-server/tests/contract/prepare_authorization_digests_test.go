@@ -0,0 +1,175 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestPrepareAuthorizationDigests tests that each digest matches TypedDataHash for its inputs, in input order
+func TestPrepareAuthorizationDigests(t *testing.T) {
+	cfg := createTestConfigForPayment()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	createTool := tools.NewCreatePaymentRequirementTool(srv)
+	newRequirement := func(amount, network string) map[string]interface{} {
+		result, err := createTool.Execute(map[string]interface{}{
+			"amount":  amount,
+			"network": network,
+		})
+		if err != nil {
+			t.Fatalf("create_payment_requirement failed: %v", err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	// A requirement naming its own domain overrides the configured name/version
+	renamed := newRequirement("2500", "arbitrum")
+	renamed["extra"] = map[string]interface{}{"name": "Bridged USDC", "version": "1"}
+
+	baseJSON, err := json.Marshal(newRequirement("1000", "base"))
+	if err != nil {
+		t.Fatalf("Failed to encode requirement: %v", err)
+	}
+
+	expired := newRequirement("1000", "base")
+	expired["valid_until"] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+	invalid := newRequirement("1000", "base")
+	invalid["scheme"] = "upto"
+
+	payer := "0x1111111111111111111111111111111111111111"
+	fixedNonce := "0x" + strings.Repeat("ab", 32)
+
+	items := []interface{}{
+		map[string]interface{}{"requirement": newRequirement("50000", "base-sepolia"), "from": payer, "nonce": fixedNonce, "validAfter": float64(1700000000)},
+		map[string]interface{}{"requirement": string(baseJSON), "from": payer}, // JSON string, random nonce
+		map[string]interface{}{"requirement": renamed, "from": payer},
+		map[string]interface{}{"requirement": expired, "from": payer},
+		map[string]interface{}{"requirement": invalid, "from": payer},
+		map[string]interface{}{"requirement": newRequirement("1000", "base"), "from": "not-an-address"},
+	}
+
+	result, err := tools.NewPrepareAuthorizationDigestsTool(srv).Execute(map[string]interface{}{"items": items})
+	if err != nil {
+		t.Fatalf("prepare_authorization_digests failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["prepared_count"] != 3 {
+		t.Errorf("Expected 3 prepared digests, got %v", resultMap["prepared_count"])
+	}
+
+	results := resultMap["results"].([]interface{})
+	if len(results) != len(items) {
+		t.Fatalf("Expected %d results, got %d", len(items), len(results))
+	}
+
+	verifier := eip3009.NewSignatureVerifier(cfg)
+	expectedNetworks := []string{"base-sepolia", "base", "arbitrum"}
+
+	for i, network := range expectedNetworks {
+		entry := results[i].(map[string]interface{})
+		if entry["index"] != i {
+			t.Errorf("Result %d: expected index %d, got %v", i, i, entry["index"])
+		}
+		if entry["error"] != nil {
+			t.Fatalf("Result %d: unexpected error %v", i, entry["error"])
+		}
+		if entry["network"] != network {
+			t.Errorf("Result %d: expected network %s, got %v", i, network, entry["network"])
+		}
+
+		domain, err := verifier.VerifyDomain(network)
+		if err != nil {
+			t.Fatalf("VerifyDomain(%s) failed: %v", network, err)
+		}
+		if i == 2 {
+			domain.Name = "Bridged USDC"
+			domain.Version = "1"
+		}
+
+		auth := entry["authorization"].(map[string]interface{})
+		value, _ := new(big.Int).SetString(auth["value"].(string), 10)
+		message := &eip3009.ReceiveWithAuthorizationMessage{
+			From:        common.HexToAddress(auth["from"].(string)),
+			To:          common.HexToAddress(auth["to"].(string)),
+			Value:       value,
+			ValidAfter:  new(big.Int).SetUint64(auth["validAfter"].(uint64)),
+			ValidBefore: new(big.Int).SetUint64(auth["validBefore"].(uint64)),
+			Nonce:       common.HexToHash(auth["nonce"].(string)),
+		}
+
+		expected, err := eip3009.TypedDataHash(domain, message)
+		if err != nil {
+			t.Fatalf("TypedDataHash failed: %v", err)
+		}
+		if entry["digest"] != expected.Hex() {
+			t.Errorf("Result %d: digest %v does not match TypedDataHash %s", i, entry["digest"], expected.Hex())
+		}
+		if entry["domain_separator"] != domain.DomainSeparator().Hex() {
+			t.Errorf("Result %d: domain separator %v does not match %s", i, entry["domain_separator"], domain.DomainSeparator().Hex())
+		}
+	}
+
+	// Caller-supplied nonce and validAfter are committed to as given
+	first := results[0].(map[string]interface{})["authorization"].(map[string]interface{})
+	if first["nonce"] != fixedNonce {
+		t.Errorf("Expected nonce %s, got %v", fixedNonce, first["nonce"])
+	}
+	if first["validAfter"] != uint64(1700000000) {
+		t.Errorf("Expected validAfter 1700000000, got %v", first["validAfter"])
+	}
+
+	errorCodes := map[int]string{
+		3: x402.ErrorCodeRequirementExpired,
+		4: x402.ErrorCodeInvalidRequirement,
+		5: x402.ErrorCodeInvalidRequirement,
+	}
+	for i, code := range errorCodes {
+		entry := results[i].(map[string]interface{})
+		if entry["index"] != i {
+			t.Errorf("Result %d: expected index %d, got %v", i, i, entry["index"])
+		}
+		if entry["error_code"] != code {
+			t.Errorf("Result %d: expected error_code %s, got %v", i, code, entry["error_code"])
+		}
+		if entry["digest"] != nil {
+			t.Errorf("Result %d: expected no digest for a failed item", i)
+		}
+	}
+}
+
+// TestPrepareAuthorizationDigests_BatchLimits tests rejection of empty and oversized batches
+func TestPrepareAuthorizationDigests_BatchLimits(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewPrepareAuthorizationDigestsTool(srv)
+
+	if _, err := tool.Execute(map[string]interface{}{"items": []interface{}{}}); err == nil {
+		t.Error("Expected error for empty items")
+	}
+
+	oversized := make([]interface{}, 51)
+	for i := range oversized {
+		oversized[i] = map[string]interface{}{}
+	}
+	if _, err := tool.Execute(map[string]interface{}{"items": oversized}); err == nil {
+		t.Error("Expected error for more than 50 items")
+	}
+}
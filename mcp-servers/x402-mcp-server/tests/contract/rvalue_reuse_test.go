@@ -0,0 +1,133 @@
+package contract
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// signWithFixedK signs hash with a caller-chosen ECDSA nonce k, as a faulty signer would
+// Returns v in the 27/28 convention with a low-s signature.
+func signWithFixedK(priv *ecdsa.PrivateKey, hash common.Hash, k *big.Int) (uint8, string, string) {
+	curve := crypto.S256()
+	n := curve.Params().N
+
+	rx, ry := curve.ScalarBaseMult(k.Bytes())
+	r := new(big.Int).Mod(rx, n)
+
+	// s = k^-1 * (z + r*d) mod n
+	s := new(big.Int).Mul(r, priv.D)
+	s.Add(s, new(big.Int).SetBytes(hash.Bytes()))
+	s.Mul(s, new(big.Int).ModInverse(k, n))
+	s.Mod(s, n)
+
+	recovery := uint8(ry.Bit(0))
+	if s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s.Sub(n, s)
+		recovery ^= 1
+	}
+
+	return recovery + 27, common.BigToHash(r).Hex(), common.BigToHash(s).Hex()
+}
+
+// TestVerifyPayment_RValueReuse verifies two signatures sharing r raise a CRITICAL alert and, in block mode, are rejected
+func TestVerifyPayment_RValueReuse(t *testing.T) {
+	for _, mode := range []string{config.RValueReuseAlert, config.RValueReuseBlock} {
+		t.Run(mode, func(t *testing.T) {
+			cfg := createTestConfigForVerification()
+			cfg.Verification.RValueReuse = mode
+			logs := &bytes.Buffer{}
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, logs))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			tool := tools.NewVerifyPaymentTool(srv)
+
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+
+			privateKey, _ := crypto.GenerateKey()
+			k := big.NewInt(0xfeedface) // Reused nonce
+			now := time.Now().Unix()
+
+			verify := func(tag byte) map[string]interface{} {
+				message := &eip3009.ReceiveWithAuthorizationMessage{
+					From:        crypto.PubkeyToAddress(privateKey.PublicKey),
+					To:          common.HexToAddress("0x1234567890123456789012345678901234567890"),
+					Value:       big.NewInt(50000),
+					ValidAfter:  big.NewInt(now - 60),
+					ValidBefore: big.NewInt(now + 3600),
+					Nonce:       [32]byte{tag},
+				}
+				hash, err := eip3009.TypedDataHash(domain, message)
+				if err != nil {
+					t.Fatalf("Failed to hash message: %v", err)
+				}
+				v, r, s := signWithFixedK(privateKey, hash, k)
+
+				result, err := tool.Execute(map[string]interface{}{
+					"network": "base",
+					"authorization": map[string]interface{}{
+						"from":        message.From.Hex(),
+						"to":          message.To.Hex(),
+						"value":       "50000",
+						"validAfter":  float64(now - 60),
+						"validBefore": float64(now + 3600),
+						"nonce":       common.BytesToHash(message.Nonce[:]).Hex(),
+						"v":           float64(v),
+						"r":           r,
+						"s":           s,
+					},
+				})
+				if err != nil {
+					t.Fatalf("Tool execution failed: %v", err)
+				}
+				return result.(map[string]interface{})
+			}
+
+			first := verify(1)
+			if first["is_valid"] != true {
+				t.Fatalf("Expected first signature to verify, got %v", first)
+			}
+			if strings.Contains(logs.String(), "CRITICAL") {
+				t.Fatal("Expected no alert after a single signature")
+			}
+
+			// Re-verifying the same message is not a reuse
+			verify(1)
+			if strings.Contains(logs.String(), "CRITICAL") {
+				t.Fatal("Expected no alert when the same message is verified twice")
+			}
+
+			second := verify(2)
+			if !strings.Contains(logs.String(), `"level":"CRITICAL"`) {
+				t.Errorf("Expected a CRITICAL alert, got logs: %s", logs.String())
+			}
+			if count := srv.GetMetrics().CounterValue(eip3009.MetricRValueReuse, metrics.Labels{"network": "base"}); count != 1 {
+				t.Errorf("Expected 1 reuse counted, got %v", count)
+			}
+
+			if mode == config.RValueReuseBlock {
+				if second["is_valid"] != false || second["error_code"] != eip3009.ErrorCodeRValueReused {
+					t.Errorf("Expected reuse to be rejected with %s, got %v", eip3009.ErrorCodeRValueReused, second)
+				}
+			} else if second["is_valid"] != true {
+				t.Errorf("Expected alert mode to still accept the signature, got %v", second)
+			}
+		})
+	}
+}
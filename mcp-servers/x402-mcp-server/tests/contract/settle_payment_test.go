@@ -2,17 +2,29 @@ package contract
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/cache"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
 	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/webhook"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
 )
 
@@ -88,8 +100,13 @@ func TestSettlePayment_ToolSchema(t *testing.T) {
 		t.Fatal("Schema should have required fields")
 	}
 
-	if len(required) != 2 {
-		t.Errorf("Expected 2 required fields, got %d", len(required))
+	if len(required) != 1 || required[0] != "authorization" {
+		t.Errorf("Expected only authorization required, got %v", required)
+	}
+
+	// network may be replaced by the {chain_id, asset} pair
+	if _, ok := schemaMap["anyOf"].([]interface{}); !ok {
+		t.Error("Schema should require network or chain_id+asset via anyOf")
 	}
 }
 
@@ -588,5 +605,1575 @@ func createTestConfigForSettlement() *config.Config {
 		Cache: config.CacheConfig{
 			SettlementTTLMinutes: 10,
 		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+}
+
+// TestSettlePayment_ExecuteWithProgress tests that settlement phases are emitted in order
+func TestSettlePayment_ExecuteWithProgress(t *testing.T) {
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewSettlePaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	var nonce [32]byte
+	nonce[31] = 0x42
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	var events []tools.SettlementEvent
+	_, err = tool.ExecuteWithProgress(map[string]interface{}{
+		"authorization": authInput,
+		"network":       "base",
+	}, func(event tools.SettlementEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+
+	expected := []string{
+		tools.SettlementPhaseVerifying,
+		tools.SettlementPhaseSubmitting,
+		tools.SettlementPhaseSettled,
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+
+	for i, phase := range expected {
+		if events[i].Phase != phase {
+			t.Errorf("Event %d: expected phase '%s', got '%s'", i, phase, events[i].Phase)
+		}
+		if events[i].Timestamp.IsZero() {
+			t.Errorf("Event %d: missing timestamp", i)
+		}
+		if i > 0 && events[i].Timestamp.Before(events[i-1].Timestamp) {
+			t.Errorf("Event %d: timestamp earlier than previous event", i)
+		}
+	}
+
+	if events[2].TxHash == "" {
+		t.Error("Settled event should carry tx_hash")
+	}
+}
+
+// TestSettlePayment_VerificationErrorCode tests that a settlement refused by signature
+// verification carries the verifier's error_code
+func TestSettlePayment_VerificationErrorCode(t *testing.T) {
+	var submissions atomic.Int32
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		submissions.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "settled", "tx_hash": "0xabc"})
+	}))
+	defer facilitator.Close()
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	payee := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	tests := []struct {
+		name       string
+		configure  func(cfg *config.Config)
+		chainID    uint64 // re-encode v with this EIP-155 chain ID when non-zero
+		expectCode string
+	}{
+		{
+			name: "authorization too old",
+			configure: func(cfg *config.Config) {
+				cfg.Verification.MaxAuthorizationAgeSeconds = 300 // validAfter is an hour ago
+			},
+			expectCode: eip3009.ErrorCodeTooOld,
+		},
+		{
+			name: "v encodes the wrong chain",
+			configure: func(cfg *config.Config) {
+				cfg.Verification.EIP155V = config.EIP155VMatchChain
+			},
+			chainID:    42161,
+			expectCode: eip3009.ErrorCodeChainMismatch,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfigForSettlement()
+			baseNet := cfg.Networks["base"]
+			baseNet.FacilitatorURL = facilitator.URL
+			cfg.Networks["base"] = baseNet
+			tt.configure(cfg)
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			tool := tools.NewSettlePaymentTool(srv)
+
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain, payee, big.NewInt(50000), [32]byte{0x51, byte(i)})
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+			if tt.chainID != 0 {
+				authInput["v"] = float64(tt.chainID*2+35) + authInput["v"].(float64) - 27
+			}
+
+			var events []tools.SettlementEvent
+			result, err := tool.ExecuteWithProgress(map[string]interface{}{
+				"authorization": authInput,
+				"network":       "base",
+			}, func(event tools.SettlementEvent) {
+				events = append(events, event)
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["status"] != "failed" {
+				t.Errorf("Expected status failed, got %v", resultMap)
+			}
+			if resultMap["error_code"] != tt.expectCode {
+				t.Errorf("Expected error_code %q, got %v", tt.expectCode, resultMap["error_code"])
+			}
+			if len(events) == 0 || events[len(events)-1].Phase != tools.SettlementPhaseFailed {
+				t.Errorf("Expected a final failed phase, got %+v", events)
+			}
+		})
+	}
+
+	if n := submissions.Load(); n != 0 {
+		t.Errorf("Expected no facilitator submissions for rejected authorizations, got %d", n)
+	}
+}
+
+// TestSettlePayment_ExpectedValueHumanMismatch tests that settlement is refused on amount mismatch
+func TestSettlePayment_ExpectedValueHumanMismatch(t *testing.T) {
+	submitted := false
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		submitted = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	var nonce [32]byte
+	nonce[31] = 0x36
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	result, err := tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+		"authorization":        authInput,
+		"network":              "base",
+		"expected_value_human": "5",
+	})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["status"] != "failed" {
+		t.Errorf("Expected status 'failed', got %v", resultMap["status"])
+	}
+	if resultMap["error_code"] != eip3009.ErrorCodeAmountMismatch {
+		t.Errorf("Expected error_code '%s', got %v", eip3009.ErrorCodeAmountMismatch, resultMap["error_code"])
+	}
+	if submitted {
+		t.Error("Facilitator should not be called on amount mismatch")
+	}
+}
+
+// TestSettlePayment_MaxInFlight tests that a saturated network returns settlement_queue_full
+// while another network keeps settling
+func TestSettlePayment_MaxInFlight(t *testing.T) {
+	entered := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	settled := func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}
+
+	// The base facilitator holds each request until the test releases it
+	slowFacilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-unblock
+		settled(w)
+	}))
+	defer slowFacilitator.Close()
+
+	fastFacilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		settled(w)
+	}))
+	defer fastFacilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = slowFacilitator.URL
+	cfg.Networks["base"] = baseNet
+	sepoliaNet := cfg.Networks["base-sepolia"]
+	sepoliaNet.FacilitatorURL = fastFacilitator.URL
+	cfg.Networks["base-sepolia"] = sepoliaNet
+	cfg.Settlement.MaxInFlight = map[string]int{"base": 1, "base-sepolia": 1}
+	cfg.Settlement.QueueTimeoutMs = 50
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewSettlePaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	verifier := eip3009.NewSignatureVerifier(cfg)
+	signFor := func(network string, nonceByte byte) map[string]interface{} {
+		domain, err := verifier.VerifyDomain(network)
+		if err != nil {
+			t.Fatalf("Failed to build domain: %v", err)
+		}
+		var nonce [32]byte
+		nonce[31] = nonceByte
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		return authInput
+	}
+
+	// Occupy the only base slot
+	firstDone := make(chan error, 1)
+	firstAuth := signFor("base", 0x01)
+	go func() {
+		_, err := tool.Execute(map[string]interface{}{"authorization": firstAuth, "network": "base"})
+		firstDone <- err
+	}()
+
+	select {
+	case <-entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("First settlement never reached the facilitator")
+	}
+
+	// A second base settlement waits out the deadline and is rejected
+	result, err := tool.Execute(map[string]interface{}{"authorization": signFor("base", 0x02), "network": "base"})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["status"] != "failed" {
+		t.Errorf("Expected status 'failed', got %v", resultMap["status"])
+	}
+	if resultMap["error_code"] != "settlement_queue_full" {
+		t.Errorf("Expected error_code 'settlement_queue_full', got %v", resultMap["error_code"])
+	}
+
+	// Another network is unaffected by base saturation
+	result, err = tool.Execute(map[string]interface{}{"authorization": signFor("base-sepolia", 0x03), "network": "base-sepolia"})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+
+	if status := result.(map[string]interface{})["status"]; status != "settled" {
+		t.Errorf("Expected base-sepolia status 'settled', got %v", status)
+	}
+
+	close(unblock)
+	if err := <-firstDone; err != nil {
+		t.Errorf("First settlement failed: %v", err)
+	}
+}
+
+// TestSettlePayment_RequirementPayeeMismatch tests that settlement is refused when the
+// authorization pays someone other than the requirement's payTo
+func TestSettlePayment_RequirementPayeeMismatch(t *testing.T) {
+	submitted := false
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		submitted = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// The requirement pays the configured payee (0x2222...)
+	requirement, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	var nonce [32]byte
+	nonce[31] = 0x47
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x3333333333333333333333333333333333333333"), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	result, err := tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+		"authorization": authInput,
+		"network":       "base",
+		"requirement":   requirement,
+	})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["status"] != "failed" {
+		t.Errorf("Expected status 'failed', got %v", resultMap["status"])
+	}
+	if resultMap["error_code"] != eip3009.ErrorCodePayeeMismatch {
+		t.Errorf("Expected error_code '%s', got %v", eip3009.ErrorCodePayeeMismatch, resultMap["error_code"])
+	}
+	if submitted {
+		t.Error("Facilitator should not be called on payee mismatch")
+	}
+}
+
+// TestSettlePayment_IdempotencyToken tests that repeats with the same token settle once,
+// even when the caller re-signed with a fresh nonce
+func TestSettlePayment_IdempotencyToken(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "settled",
+			"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewSettlePaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	sign := func(nonceByte byte) map[string]interface{} {
+		var nonce [32]byte
+		nonce[31] = nonceByte
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		return authInput
+	}
+
+	settle := func(nonceByte byte, token string) map[string]interface{} {
+		result, err := tool.Execute(map[string]interface{}{
+			"authorization":     sign(nonceByte),
+			"network":           "base",
+			"idempotency_token": token,
+		})
+		if err != nil {
+			t.Fatalf("Tool execution failed: %v", err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	first := settle(0x51, "order-1001")
+	second := settle(0x52, "order-1001") // Same order, re-signed with a new nonce
+	if first["status"] != "settled" || second["status"] != "settled" {
+		t.Fatalf("Expected both calls settled, got %v and %v", first["status"], second["status"])
+	}
+
+	mu.Lock()
+	if len(keys) != 1 {
+		t.Fatalf("Expected 1 facilitator call for a repeated token, got %d", len(keys))
+	}
+	if keys[0] != "order-1001" {
+		t.Errorf("Expected Idempotency-Key 'order-1001', got %q", keys[0])
+	}
+	mu.Unlock()
+
+	// A different token settles independently
+	settle(0x53, "order-1002")
+	mu.Lock()
+	defer mu.Unlock()
+	if len(keys) != 2 {
+		t.Errorf("Expected a distinct token to reach the facilitator, got %d calls", len(keys))
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{
+		"authorization":     sign(0x54),
+		"network":           "base",
+		"idempotency_token": "bad\ntoken",
+	}); err == nil {
+		t.Error("Expected error for idempotency token with control characters")
+	}
+}
+
+// TestSettlePayment_OverpaymentPolicy tests each overpayment policy with an authorization
+// worth more than expected_value_human
+func TestSettlePayment_OverpaymentPolicy(t *testing.T) {
+	tests := []struct {
+		policy        string
+		wantStatus    string
+		wantSubmitted bool
+		wantRefund    bool
+	}{
+		{"", "failed", false, false}, // Default rejects
+		{config.OverpaymentReject, "failed", false, false},
+		{config.OverpaymentAccept, "settled", true, false},
+		{config.OverpaymentAcceptAndRefundExcess, "settled", true, true},
+	}
+
+	for i, tt := range tests {
+		t.Run("policy="+tt.policy, func(t *testing.T) {
+			var mu sync.Mutex
+			submitted := false
+			facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				submitted = true
+				mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"status":  "settled",
+					"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+				})
+			}))
+			defer facilitator.Close()
+
+			cfg := createTestConfigForSettlement()
+			baseNet := cfg.Networks["base"]
+			baseNet.FacilitatorURL = facilitator.URL
+			cfg.Networks["base"] = baseNet
+			cfg.Verification.Overpayment = tt.policy
+			cfg.Refunds.Path = filepath.Join(t.TempDir(), "refunds.jsonl")
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+
+			privateKey, fromAddr, err := createTestPrivateKeyAndAddress()
+			if err != nil {
+				t.Fatalf("Failed to create test private key: %v", err)
+			}
+
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+
+			// Authorizes 0.06 USDC against an expected 0.05
+			var nonce [32]byte
+			nonce[31] = byte(0x60 + i)
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(60000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			result, err := tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+				"authorization":        authInput,
+				"network":              "base",
+				"expected_value_human": "0.05",
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["status"] != tt.wantStatus {
+				t.Errorf("Expected status %s, got %v", tt.wantStatus, resultMap["status"])
+			}
+			if tt.wantStatus == "failed" && resultMap["error_code"] != eip3009.ErrorCodeAmountMismatch {
+				t.Errorf("Expected error_code '%s', got %v", eip3009.ErrorCodeAmountMismatch, resultMap["error_code"])
+			}
+
+			mu.Lock()
+			if submitted != tt.wantSubmitted {
+				t.Errorf("Expected facilitator submitted=%v, got %v", tt.wantSubmitted, submitted)
+			}
+			mu.Unlock()
+
+			refund, hasRefund := resultMap["refund"].(map[string]interface{})
+			if hasRefund != tt.wantRefund {
+				t.Fatalf("Expected refund present=%v, got %v", tt.wantRefund, resultMap["refund"])
+			}
+			if hasRefund {
+				if refund["amount"] != "10000" || refund["amount_human"] != "0.01" {
+					t.Errorf("Expected refund of 10000 (0.01), got %v (%v)", refund["amount"], refund["amount_human"])
+				}
+				if refund["to"] != fromAddr.Hex() {
+					t.Errorf("Expected refund to payer %s, got %v", fromAddr.Hex(), refund["to"])
+				}
+				if refund["status"] != "queued" {
+					t.Errorf("Expected refund status 'queued', got %v", refund["status"])
+				}
+			}
+
+			queued, err := srv.GetRefundQueue().Entries()
+			if err != nil {
+				t.Fatalf("Failed to read refund queue: %v", err)
+			}
+			if !tt.wantRefund {
+				if len(queued) != 0 {
+					t.Errorf("Expected no queued refunds, got %+v", queued)
+				}
+				return
+			}
+			if len(queued) != 1 {
+				t.Fatalf("Expected 1 queued refund, got %+v", queued)
+			}
+			if queued[0].To != fromAddr.Hex() || queued[0].Amount != "10000" || queued[0].From != "0x2222222222222222222222222222222222222222" {
+				t.Errorf("Expected 10000 owed by the payee to %s, got %+v", fromAddr.Hex(), queued[0])
+			}
+		})
+	}
+}
+
+// TestSettlePayment_PendingOverpaymentRefund tests that an accepted overpayment whose
+// settlement was pending has its refund queued once reconciliation or a callback settles it
+func TestSettlePayment_PendingOverpaymentRefund(t *testing.T) {
+	const txHash = "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
+	const secret = "callback-secret"
+
+	tests := []struct {
+		name        string
+		finalStatus string
+		viaCallback bool
+		wantRefund  bool
+	}{
+		{"reconciled settled", "settled", false, true},
+		{"callback settled", "settled", true, true},
+		{"callback failed", "failed", true, false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Submissions stay pending; status checks report the final status
+			facilitatorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == http.MethodGet {
+					json.NewEncoder(w).Encode(map[string]interface{}{"status": tt.finalStatus, "tx_hash": txHash})
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"status": "pending"})
+			}))
+			defer facilitatorServer.Close()
+
+			cfg := createTestConfigForSettlement()
+			baseNet := cfg.Networks["base"]
+			baseNet.FacilitatorURL = facilitatorServer.URL
+			cfg.Networks["base"] = baseNet
+			cfg.Verification.Overpayment = config.OverpaymentAcceptAndRefundExcess
+			cfg.Refunds.Path = filepath.Join(t.TempDir(), "refunds.jsonl")
+			cfg.Reconciliation.IntervalSeconds = 3600
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			tool := tools.NewSettlePaymentTool(srv)
+
+			privateKey, fromAddr, err := createTestPrivateKeyAndAddress()
+			if err != nil {
+				t.Fatalf("Failed to create test private key: %v", err)
+			}
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+
+			// Authorizes 0.06 USDC against an expected 0.05
+			var nonce [32]byte
+			nonce[31] = byte(0x70 + i)
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(60000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization":        authInput,
+				"network":              "base",
+				"expected_value_human": "0.05",
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+			resultMap := result.(map[string]interface{})
+			if resultMap["status"] != "pending" {
+				t.Fatalf("Expected status pending, got %v", resultMap["status"])
+			}
+			if _, hasRefund := resultMap["refund"]; hasRefund {
+				t.Errorf("Expected no refund while pending, got %v", resultMap["refund"])
+			}
+
+			queued, err := srv.GetRefundQueue().Entries()
+			if err != nil {
+				t.Fatalf("Failed to read refund queue: %v", err)
+			}
+			if len(queued) != 0 {
+				t.Fatalf("Expected no queued refunds while pending, got %+v", queued)
+			}
+
+			nonceHex := authInput["nonce"].(string)
+			if tt.viaCallback {
+				body, _ := json.Marshal(webhook.Callback{Network: "base", Nonce: nonceHex, Status: tt.finalStatus, TxHash: txHash})
+				req := httptest.NewRequest(http.MethodPost, "/callbacks/settlement", bytes.NewReader(body))
+				req.Header.Set(webhook.SignatureHeader, webhook.Sign([]byte(secret), body))
+				rec := httptest.NewRecorder()
+				webhook.NewHandler(tool.FacilitatorClient(), []byte(secret), srv.GetAuditStore(), srv.GetMetrics(), srv.GetLogger()).ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("Expected callback accepted, got %d: %s", rec.Code, rec.Body.String())
+				}
+			} else if transitions := tool.Reconciler().RunOnce(); transitions != 1 {
+				t.Fatalf("Expected 1 reconciled settlement, got %d", transitions)
+			}
+
+			// A repeated callback or pass must not queue the refund twice
+			tool.FacilitatorClient().UpdateSettlement("base", nonceHex, &facilitator.FacilitatorResponse{Status: tt.finalStatus, TxHash: txHash})
+
+			queued, err = srv.GetRefundQueue().Entries()
+			if err != nil {
+				t.Fatalf("Failed to read refund queue: %v", err)
+			}
+			if !tt.wantRefund {
+				if len(queued) != 0 {
+					t.Errorf("Expected no queued refunds, got %+v", queued)
+				}
+				return
+			}
+			if len(queued) != 1 {
+				t.Fatalf("Expected 1 queued refund, got %+v", queued)
+			}
+			if queued[0].To != fromAddr.Hex() || queued[0].Amount != "10000" || queued[0].TxHash != txHash || queued[0].Nonce != nonceHex {
+				t.Errorf("Expected 10000 owed to %s for settlement %s, got %+v", fromAddr.Hex(), txHash, queued[0])
+			}
+		})
+	}
+}
+
+// TestSettlePayment_MarksAlreadySeen tests that verify_payment flags an authorization that was
+// settled (without being verified first) as already_seen, but not one whose settlement failed
+func TestSettlePayment_MarksAlreadySeen(t *testing.T) {
+	tests := []struct {
+		status   string
+		wantSeen bool
+	}{
+		{"settled", true},
+		{"pending", true},
+		{"failed", false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			facilitatorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"status":  tt.status,
+					"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+					"error":   "authorization rejected",
+				})
+			}))
+			defer facilitatorServer.Close()
+
+			cfg := createTestConfigForSettlement()
+			baseNet := cfg.Networks["base"]
+			baseNet.FacilitatorURL = facilitatorServer.URL
+			cfg.Networks["base"] = baseNet
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+
+			privateKey, _, err := createTestPrivateKeyAndAddress()
+			if err != nil {
+				t.Fatalf("Failed to create test private key: %v", err)
+			}
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+
+			var nonce [32]byte
+			nonce[31] = byte(0x80 + i)
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress(cfg.Networks["base"].PayeeAddress), big.NewInt(50000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			settled, err := tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+				"authorization": authInput,
+				"network":       "base",
+			})
+			if err != nil {
+				t.Fatalf("Settle failed: %v", err)
+			}
+			if status := settled.(map[string]interface{})["status"]; status != tt.status {
+				t.Fatalf("Expected status %s, got %v", tt.status, status)
+			}
+
+			verified, err := tools.NewVerifyPaymentTool(srv).Execute(map[string]interface{}{
+				"authorization": authInput,
+				"network":       "base",
+			})
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if seen := verified.(map[string]interface{})["already_seen"]; seen != tt.wantSeen {
+				t.Errorf("Expected already_seen=%v after a %s settlement, got %v", tt.wantSeen, tt.status, seen)
+			}
+		})
+	}
+}
+
+// TestSettlePayment_UnderpaymentAlwaysRejected tests that accepting overpayment never admits less
+func TestSettlePayment_UnderpaymentAlwaysRejected(t *testing.T) {
+	cfg := createTestConfigForSettlement()
+	cfg.Verification.Overpayment = config.OverpaymentAccept
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	var nonce [32]byte
+	nonce[31] = 0x6f
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(40000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	result, err := tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+		"authorization":        authInput,
+		"network":              "base",
+		"expected_value_human": "0.05",
+	})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+
+	if code := result.(map[string]interface{})["error_code"]; code != eip3009.ErrorCodeAmountMismatch {
+		t.Errorf("Expected error_code '%s' for underpayment, got %v", eip3009.ErrorCodeAmountMismatch, code)
+	}
+}
+
+// TestSettlePayment_RequirePriorVerifySharedStore verifies a verify_payment on one replica
+// enables settlement on another replica sharing the verification store
+func TestSettlePayment_RequirePriorVerifySharedStore(t *testing.T) {
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	shared := cache.NewMemoryStore(eip3009.VerificationResultTTL)
+	newReplica := func(store cache.Store) *x402server.Server {
+		cfg := createTestConfigForSettlement()
+		cfg.Settlement.RequirePriorVerify = true
+		baseNet := cfg.Networks["base"]
+		baseNet.FacilitatorURL = facilitator.URL
+		cfg.Networks["base"] = baseNet
+
+		srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+		if err != nil {
+			t.Fatalf("Failed to create server: %v", err)
+		}
+		srv.SetVerificationStore(store)
+		return srv
+	}
+
+	replicaA := newReplica(shared)
+	replicaB := newReplica(shared)
+	isolated := newReplica(cache.NewMemoryStore(eip3009.VerificationResultTTL))
+
+	verifyA := tools.NewVerifyPaymentTool(replicaA)
+	settleB := tools.NewSettlePaymentTool(replicaB)
+	settleIsolated := tools.NewSettlePaymentTool(isolated)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(replicaA.GetConfig()).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), [32]byte{0x81})
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+	input := map[string]interface{}{
+		"authorization": authInput,
+		"network":       "base",
+	}
+
+	// Not yet verified anywhere: settlement is refused
+	result, err := settleB.Execute(input)
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+	if result.(map[string]interface{})["error_code"] != eip3009.ErrorCodeNotVerified {
+		t.Fatalf("Expected %s before verification, got %v", eip3009.ErrorCodeNotVerified, result)
+	}
+
+	verified, err := verifyA.Execute(input)
+	if err != nil {
+		t.Fatalf("verify_payment failed: %v", err)
+	}
+	if verified.(map[string]interface{})["is_valid"] != true {
+		t.Fatalf("Expected authorization to verify, got %v", verified)
+	}
+
+	// Replica B sees replica A's verification through the shared store
+	result, err = settleB.Execute(input)
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+	if result.(map[string]interface{})["status"] != "settled" {
+		t.Errorf("Expected settlement after verification on another replica, got %v", result)
+	}
+
+	// A replica with its own store has no record of the verification
+	result, err = settleIsolated.Execute(input)
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+	if result.(map[string]interface{})["error_code"] != eip3009.ErrorCodeNotVerified {
+		t.Errorf("Expected %s on a replica without the shared store, got %v", eip3009.ErrorCodeNotVerified, result)
+	}
+}
+
+// revertingTokenReader simulates a paused USDC contract whose views revert
+type revertingTokenReader struct{}
+
+func (revertingTokenReader) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, errors.New("execution reverted")
+}
+
+// TestSettlePayment_NetworkDegraded tests that settlements flag a network whose USDC contract check failed
+func TestSettlePayment_NetworkDegraded(t *testing.T) {
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewSettlePaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	settle := func(nonce byte) map[string]interface{} {
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), [32]byte{nonce})
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		result, err := tool.Execute(map[string]interface{}{"authorization": authInput, "network": "base"})
+		if err != nil {
+			t.Fatalf("Tool execution failed: %v", err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	if _, flagged := settle(0x91)["network_degraded"]; flagged {
+		t.Error("Expected no network_degraded flag before any contract check")
+	}
+
+	srv.GetContractMonitor().SetBackend("base", revertingTokenReader{})
+	srv.GetContractMonitor().SetBackend("base-sepolia", revertingTokenReader{})
+	srv.GetContractMonitor().RunOnce()
+
+	result := settle(0x92)
+	if result["status"] != "settled" || result["network_degraded"] != true {
+		t.Errorf("Expected settled result flagged network_degraded, got %v", result)
+	}
+}
+
+// TestSettlePayment_RequirementTimeout tests that, when enforced, settlements later than the
+// requirement's maxTimeoutSeconds after issuance fail with timeout_exceeded
+func TestSettlePayment_RequirementTimeout(t *testing.T) {
+	submissions := 0
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		submissions++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+	cfg.Settlement.EnforceRequirementTimeout = true
+	cfg.Requirements.ValiditySeconds = 3600
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewSettlePaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		issuedAgo time.Duration
+		wantCode  string
+	}{
+		{"within window", 0, ""},
+		{"beyond window", 2 * time.Minute, x402.ErrorCodeTimeoutExceeded},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+				"amount":  "50000",
+				"network": "base",
+			})
+			if err != nil {
+				t.Fatalf("create_payment_requirement failed: %v", err)
+			}
+			requirement := created.(map[string]interface{})
+			if requirement["maxTimeoutSeconds"] != 60 {
+				t.Fatalf("Expected the default 60s maxTimeoutSeconds, got %v", requirement["maxTimeoutSeconds"])
+			}
+
+			// Backdate issuance by shifting valid_until, which is issuance + validity
+			issuedAt := time.Now().Add(-tt.issuedAgo)
+			requirement["valid_until"] = issuedAt.Add(time.Hour).UTC().Format(time.RFC3339)
+
+			var nonce [32]byte
+			nonce[31] = byte(0x60 + i)
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			before := submissions
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization": authInput,
+				"network":       "base",
+				"requirement":   requirement,
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if tt.wantCode == "" {
+				if resultMap["status"] != "settled" {
+					t.Errorf("Expected status 'settled', got %v (%v)", resultMap["status"], resultMap["error"])
+				}
+				if submissions != before+1 {
+					t.Error("Expected the facilitator to be called within the timeout window")
+				}
+				return
+			}
+
+			if resultMap["status"] != "failed" {
+				t.Errorf("Expected status 'failed', got %v", resultMap["status"])
+			}
+			if resultMap["error_code"] != tt.wantCode {
+				t.Errorf("Expected error_code '%s', got %v", tt.wantCode, resultMap["error_code"])
+			}
+			if submissions != before {
+				t.Error("Facilitator should not be called once the timeout window has closed")
+			}
+		})
+	}
+}
+
+// TestSettlePayment_FacilitatorOverride tests that facilitator_url_override is used only when
+// allowed and only for URLs passing the SSRF policy
+func TestSettlePayment_FacilitatorOverride(t *testing.T) {
+	newFacilitator := func(hits *int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*hits++
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":       "settled",
+				"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+				"block_number": 12345678,
+			})
+		}))
+	}
+
+	var configuredHits, stagingHits int
+	configured := newFacilitator(&configuredHits)
+	defer configured.Close()
+	staging := newFacilitator(&stagingHits)
+	defer staging.Close()
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		allowOverride bool
+		allowPrivate  bool
+		wantErr       bool
+		wantStaging   bool
+	}{
+		{"allowed", true, true, false, true},
+		{"disallowed", false, true, true, false},
+		{"private URL blocked by SSRF policy", true, false, true, false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfigForSettlement()
+			baseNet := cfg.Networks["base"]
+			baseNet.FacilitatorURL = configured.URL
+			cfg.Networks["base"] = baseNet
+			cfg.Environment = config.EnvironmentTest
+			cfg.Settlement.AllowFacilitatorOverride = tt.allowOverride
+			cfg.AllowPrivateURLs = tt.allowPrivate
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+			var nonce [32]byte
+			nonce[31] = byte(0x70 + i)
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			configuredBefore, stagingBefore := configuredHits, stagingHits
+			result, err := tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+				"authorization":            authInput,
+				"network":                  "base",
+				"allow_mainnet":            true,
+				"facilitator_url_override": staging.URL,
+			})
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected the override to be rejected, got %v", result)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Tool execution failed: %v", err)
+				}
+				if status := result.(map[string]interface{})["status"]; status != "settled" {
+					t.Errorf("Expected status 'settled', got %v", status)
+				}
+			}
+
+			if got := stagingHits - stagingBefore; (got == 1) != tt.wantStaging {
+				t.Errorf("Expected staging facilitator used=%v, got %d calls", tt.wantStaging, got)
+			}
+			if configuredHits != configuredBefore {
+				t.Error("Configured facilitator should not be called when an override is given")
+			}
+		})
+	}
+}
+
+// TestSettlePayment_DeadLetter tests that permanently failed settlements are recorded with their reason
+func TestSettlePayment_DeadLetter(t *testing.T) {
+	rejectedNonce := [32]byte{0xd1}
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		w.Header().Set("Content-Type", "application/json")
+		if body["nonce"] == common.BytesToHash(rejectedNonce[:]).Hex() {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":     "failed",
+				"error":      "authorization already used",
+				"error_code": "nonce_used",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	cfg.DeadLetter.Path = filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewSettlePaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	for _, nonce := range [][32]byte{{0xd0}, rejectedNonce} {
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		if _, err := tool.Execute(map[string]interface{}{"authorization": authInput, "network": "base"}); err != nil {
+			t.Fatalf("Tool execution failed: %v", err)
+		}
+	}
+
+	entries, err := srv.GetDeadLetterStore().Entries()
+	if err != nil {
+		t.Fatalf("Failed to read dead letters: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the rejected settlement to be dead-lettered, got %d entries", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Network != "base" || entry.Error != "authorization already used" || entry.ErrorCode != "nonce_used" {
+		t.Errorf("Expected rejected settlement with its reason, got %+v", entry)
+	}
+	if entry.Authorization == nil || entry.Authorization.Nonce != common.BytesToHash(rejectedNonce[:]).Hex() {
+		t.Fatalf("Expected the rejected authorization, got %+v", entry.Authorization)
+	}
+	if entry.Authorization.R == "" || entry.Authorization.S == "" {
+		t.Error("Expected the signature to be kept for replay")
+	}
+}
+
+// TestSettlePayment_WarmCacheFromAudit tests that a restarted server reuses settlements from a persisted audit trail
+func TestSettlePayment_WarmCacheFromAudit(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	facilitatorCalls := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+
+	cfg := createTestConfigForSettlement()
+	cfg.Audit = config.AuditConfig{
+		RecordReceipts:   true,
+		Path:             filepath.Join(t.TempDir(), "audit.jsonl"),
+		WarmCacheMinutes: 60,
+	}
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	sign := func(nonce [32]byte) map[string]interface{} {
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		return authInput
+	}
+	recent, stale := sign([32]byte{0xe1}), sign([32]byte{0xe2})
+
+	// First run settles and records a receipt
+	first, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if _, err := tools.NewSettlePaymentTool(first).Execute(map[string]interface{}{"authorization": recent, "network": "base"}); err != nil {
+		t.Fatalf("settle_payment failed: %v", err)
+	}
+	if facilitatorCalls() != 1 {
+		t.Fatalf("Expected 1 facilitator call, got %d", facilitatorCalls())
+	}
+
+	// A settlement recorded before the settled TTL is no longer worth caching
+	if err := audit.NewFileStore(cfg.Audit.Path).Append(audit.Record{
+		Timestamp: time.Now().Add(-30 * time.Minute),
+		Event:     audit.EventSettlementReceipt,
+		Network:   "base",
+		Nonce:     stale["nonce"].(string),
+		Status:    "settled",
+	}); err != nil {
+		t.Fatalf("Failed to seed audit record: %v", err)
+	}
+
+	// After a restart the warmed cache short-circuits the recent settlement
+	restarted, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewSettlePaymentTool(restarted)
+	if err := tool.Warmup(context.Background()); err != nil {
+		t.Fatalf("Warmup failed: %v", err)
+	}
+
+	result, err := tool.Execute(map[string]interface{}{"authorization": recent, "network": "base"})
+	if err != nil {
+		t.Fatalf("settle_payment failed: %v", err)
+	}
+	resultMap := result.(map[string]interface{})
+	if resultMap["status"] != "settled" || resultMap["tx_hash"] != "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890" {
+		t.Errorf("Expected cached settled result, got %v", resultMap)
+	}
+	if facilitatorCalls() != 1 {
+		t.Errorf("Expected recent settlement to skip the facilitator, got %d calls", facilitatorCalls())
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{"authorization": stale, "network": "base"}); err != nil {
+		t.Fatalf("settle_payment failed: %v", err)
+	}
+	if facilitatorCalls() != 2 {
+		t.Errorf("Expected stale settlement to reach the facilitator, got %d calls", facilitatorCalls())
+	}
+}
+
+// TestSettlePayment_ReconcilerLifecycle tests that constructing the tool starts no background
+// reconciliation; it runs only between Start and Stop
+func TestSettlePayment_ReconcilerLifecycle(t *testing.T) {
+	var mu sync.Mutex
+	statusChecks := 0
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			statusChecks++
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "pending"})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+	cfg.Reconciliation.IntervalSeconds = 1
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewSettlePaymentTool(srv)
+	tool.Stop() // Before Start: a no-op that must not block
+
+	auth := &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  0,
+		ValidBefore: uint64(time.Now().Add(time.Hour).Unix()),
+		Nonce:       "0x00000000000000000000000000000000000000000000000000000000000000a1",
+		V:           27,
+		R:           "0x0000000000000000000000000000000000000000000000000000000000000001",
+		S:           "0x0000000000000000000000000000000000000000000000000000000000000001",
+	}
+	if _, err := tool.FacilitatorClient().SubmitSettlement(auth, "base"); err != nil {
+		t.Fatalf("SubmitSettlement failed: %v", err)
+	}
+
+	checks := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return statusChecks
+	}
+
+	time.Sleep(1200 * time.Millisecond)
+	if n := checks(); n != 0 {
+		t.Fatalf("Expected no reconciliation before Start, got %d status checks", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tool.Start(ctx)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for checks() == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if checks() == 0 {
+		t.Fatal("Expected reconciliation after Start")
+	}
+
+	cancel()
+	tool.Stop() // After ctx cancellation: returns once the loop has exited
+
+	stoppedAt := checks()
+	time.Sleep(1200 * time.Millisecond)
+	if n := checks(); n != stoppedAt {
+		t.Errorf("Expected no reconciliation after Stop, got %d more status checks", n-stoppedAt)
+	}
+}
+
+// TestSettlePayment_ConfigReload tests that settlement follows a reloaded config: a
+// network added by the reload settles, and a changed facilitator_url is used
+func TestSettlePayment_ConfigReload(t *testing.T) {
+	newFacilitator := func(submissions *atomic.Int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			submissions.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "settled",
+				"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			})
+		}))
+	}
+	var oldSubmissions, newSubmissions atomic.Int32
+	oldFacilitator := newFacilitator(&oldSubmissions)
+	defer oldFacilitator.Close()
+	reloadedFacilitator := newFacilitator(&newSubmissions)
+	defer reloadedFacilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	delete(cfg.Networks, "base-sepolia")
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = oldFacilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	tool := tools.NewSettlePaymentTool(srv)
+
+	// Point base at a new facilitator and add base-sepolia
+	newCfg := createTestConfigForSettlement()
+	for name, network := range newCfg.Networks {
+		network.FacilitatorURL = reloadedFacilitator.URL
+		newCfg.Networks[name] = network
+	}
+	if err := srv.ReloadConfig(newCfg); err != nil {
+		t.Fatalf("Config reload failed: %v", err)
+	}
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	settle := func(network string, nonceByte byte) map[string]interface{} {
+		domain, err := eip3009.NewSignatureVerifier(newCfg).VerifyDomain(network)
+		if err != nil {
+			t.Fatalf("Failed to build %s domain: %v", network, err)
+		}
+		var nonce [32]byte
+		nonce[31] = nonceByte
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		result, err := tool.Execute(map[string]interface{}{
+			"authorization": authInput,
+			"network":       network,
+		})
+		if err != nil {
+			t.Fatalf("Settlement on %s failed: %v", network, err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	if result := settle("base-sepolia", 0x81); result["status"] != "settled" {
+		t.Errorf("Expected settlement on the added network, got %v", result)
+	}
+	if result := settle("base", 0x82); result["status"] != "settled" {
+		t.Errorf("Expected settlement on base, got %v", result)
+	}
+
+	if n := newSubmissions.Load(); n != 2 {
+		t.Errorf("Expected 2 submissions to the reloaded facilitator, got %d", n)
+	}
+	if n := oldSubmissions.Load(); n != 0 {
+		t.Errorf("Expected no submissions to the replaced facilitator, got %d", n)
 	}
 }
@@ -0,0 +1,148 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/events"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// failingPublisher rejects every event
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(ctx context.Context, event events.Event) error {
+	return errors.New("bus unavailable")
+}
+
+// newPublisherTestServer creates a server whose facilitator settles nonces ending in 0x01
+// and rejects everything else
+func newPublisherTestServer(t *testing.T) (*x402server.Server, func(nonceByte byte) map[string]interface{}) {
+	t.Helper()
+
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&request)
+
+		w.Header().Set("Content-Type", "application/json")
+		if request["nonce"] != common.BytesToHash([]byte{0x01}).Hex() {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "failed", "error": "nonce already used"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "settled",
+			"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+		})
+	}))
+	t.Cleanup(facilitator.Close)
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	sign := func(nonceByte byte) map[string]interface{} {
+		var nonce [32]byte
+		nonce[31] = nonceByte
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		return authInput
+	}
+
+	return srv, sign
+}
+
+// TestSettlePayment_PublishesSettlementEvents tests that each settled or failed settlement
+// publishes one event carrying the receipt
+func TestSettlePayment_PublishesSettlementEvents(t *testing.T) {
+	srv, sign := newPublisherTestServer(t)
+	publisher := events.NewMemoryPublisher()
+	srv.SetEventPublisher(publisher)
+
+	tool := tools.NewSettlePaymentTool(srv)
+	for _, nonceByte := range []byte{0x01, 0x02} {
+		if _, err := tool.Execute(map[string]interface{}{
+			"authorization": sign(nonceByte),
+			"network":       "base",
+		}); err != nil {
+			t.Fatalf("Tool execution failed: %v", err)
+		}
+	}
+
+	published := publisher.Events()
+	if len(published) != 2 {
+		t.Fatalf("Expected one event per settlement (2), got %d", len(published))
+	}
+
+	settled, failed := published[0], published[1]
+	if settled.Type != events.EventSettlementSettled || failed.Type != events.EventSettlementFailed {
+		t.Errorf("Expected settled then failed events, got %s then %s", settled.Type, failed.Type)
+	}
+	if settled.Network != "base" || settled.Value != "50000" {
+		t.Errorf("Unexpected settled event: %+v", settled)
+	}
+	if settled.Receipt["tx_hash"] != "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890" {
+		t.Errorf("Expected receipt with tx_hash, got %v", settled.Receipt)
+	}
+	if failed.Receipt["error"] != "nonce already used" {
+		t.Errorf("Expected failed receipt with facilitator error, got %v", failed.Receipt)
+	}
+
+	// Events serialize to JSON for the bus
+	if _, err := json.Marshal(settled); err != nil {
+		t.Errorf("Event should marshal to JSON: %v", err)
+	}
+}
+
+// TestSettlePayment_PublishFailureDoesNotFailSettlement tests that publish errors are logged
+// and counted while the settlement result is still returned
+func TestSettlePayment_PublishFailureDoesNotFailSettlement(t *testing.T) {
+	srv, sign := newPublisherTestServer(t)
+	srv.SetEventPublisher(failingPublisher{})
+
+	result, err := tools.NewSettlePaymentTool(srv).Execute(map[string]interface{}{
+		"authorization": sign(0x01),
+		"network":       "base",
+	})
+	if err != nil {
+		t.Fatalf("Settlement should not fail on publish error: %v", err)
+	}
+	if status := result.(map[string]interface{})["status"]; status != "settled" {
+		t.Errorf("Expected status 'settled', got %v", status)
+	}
+
+	failures := srv.GetMetrics().CounterValue(events.MetricPublishFailures, metrics.Labels{"network": "base"})
+	if failures != 1 {
+		t.Errorf("Expected 1 publish failure recorded, got %v", failures)
+	}
+}
@@ -1,13 +1,17 @@
 package contract
 
 import (
+	"context"
 	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 // generateValidSignature creates a cryptographically valid EIP-3009 signature
@@ -73,3 +77,100 @@ func createTestPrivateKeyAndAddress() (*ecdsa.PrivateKey, common.Address, error)
 
 	return privateKey, address, nil
 }
+
+// buildSignedAuthorizationInput signs an authorization under the given domain and returns it
+// in the map form accepted by the verify/settle tools (valid from one hour ago for one hour)
+func buildSignedAuthorizationInput(
+	privateKey *ecdsa.PrivateKey,
+	domain *eip3009.EIP712Domain,
+	to common.Address,
+	value *big.Int,
+	nonce [32]byte,
+) (map[string]interface{}, error) {
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+	now := time.Now().Unix()
+	validAfter := big.NewInt(now - 3600)
+	validBefore := big.NewInt(now + 3600)
+
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        from,
+		To:          to,
+		Value:       value,
+		ValidAfter:  validAfter,
+		ValidBefore: validBefore,
+		Nonce:       nonce,
+	}
+
+	auth, err := eip3009.SignAuthorization(message, domain, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"from":        auth.From,
+		"to":          auth.To,
+		"value":       auth.Value,
+		"validAfter":  float64(auth.ValidAfter),
+		"validBefore": float64(auth.ValidBefore),
+		"nonce":       auth.Nonce,
+		"v":           float64(auth.V),
+		"r":           auth.R,
+		"s":           auth.S,
+	}, nil
+}
+
+// toolCallResult is the result of an MCP tools/call request
+type toolCallResult struct {
+	IsError           bool                   `json:"isError"`
+	StructuredContent map[string]interface{} `json:"structuredContent"`
+	Content           []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// callTool sends a tools/call request through the MCP server's message handler, the
+// dispatch path client calls take in the running server
+func callTool(mcpServer *server.MCPServer, name string, args map[string]interface{}) (*toolCallResult, error) {
+	return callToolWithMeta(mcpServer, name, args, nil)
+}
+
+// callToolWithMeta sends a tools/call request carrying meta as its _meta, e.g. a traceparent
+func callToolWithMeta(mcpServer *server.MCPServer, name string, args, meta map[string]interface{}) (*toolCallResult, error) {
+	params := map[string]interface{}{
+		"name":      name,
+		"arguments": args,
+	}
+	if meta != nil {
+		params["_meta"] = meta
+	}
+
+	request, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "tools/call",
+		"params":  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(mcpServer.HandleMessage(context.Background(), request))
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Result *toolCallResult `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(encoded, &response); err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("tools/call %s: %s", name, response.Error.Message)
+	}
+	return response.Result, nil
+}
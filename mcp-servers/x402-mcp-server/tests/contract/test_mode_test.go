@@ -0,0 +1,109 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestSettlePayment_TestModeMainnetGuard tests that test mode refuses mainnet settlement
+// unless overridden per call, while production mode settles anywhere
+func TestSettlePayment_TestModeMainnetGuard(t *testing.T) {
+	calls := 0
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		environment  string
+		network      string
+		allowMainnet bool
+		expectStatus string
+	}{
+		{"test mode blocks mainnet", config.EnvironmentTest, "base", false, "failed"},
+		{"test mode allows testnet", config.EnvironmentTest, "base-sepolia", false, "settled"},
+		{"test mode mainnet override", config.EnvironmentTest, "base", true, "settled"},
+		{"production allows mainnet", config.EnvironmentProduction, "base", false, "settled"},
+		{"default environment allows mainnet", "", "base", false, "settled"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfigForSettlement()
+			cfg.Environment = tt.environment
+			for name, network := range cfg.Networks {
+				network.FacilitatorURL = facilitator.URL
+				cfg.Networks[name] = network
+			}
+
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			tool := tools.NewSettlePaymentTool(srv)
+
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain(tt.network)
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+			var nonce [32]byte
+			nonce[31] = byte(i + 1)
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+				common.HexToAddress("0x2222222222222222222222222222222222222222"), big.NewInt(50000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			args := map[string]interface{}{
+				"authorization": authInput,
+				"network":       tt.network,
+			}
+			if tt.allowMainnet {
+				args["allow_mainnet"] = true
+			}
+
+			before := calls
+			result, err := tool.Execute(args)
+			if err != nil {
+				t.Fatalf("settle_payment failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["status"] != tt.expectStatus {
+				t.Fatalf("Expected status %s, got %v", tt.expectStatus, resultMap)
+			}
+
+			if tt.expectStatus == "failed" {
+				if resultMap["error_code"] != config.ErrorCodeMainnetBlocked {
+					t.Errorf("Expected error_code %s, got %v", config.ErrorCodeMainnetBlocked, resultMap["error_code"])
+				}
+				if calls != before {
+					t.Error("Expected blocked settlement not to reach the facilitator")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// SlowTool is an executable tool that sleeps before returning
+type SlowTool struct {
+	MockTool
+	delay time.Duration
+}
+
+func (s *SlowTool) Execute(args map[string]interface{}) (interface{}, error) {
+	time.Sleep(s.delay)
+	return map[string]interface{}{"done": true}, nil
+}
+
+// TestMCPServer_ToolTimeout verifies a tool exceeding its configured budget yields tool_timeout
+func TestMCPServer_ToolTimeout(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.Limits = config.LimitsConfig{
+		ToolTimeouts: map[string]int{"slow_tool": 50},
+	}
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	slow := &SlowTool{MockTool: MockTool{name: "slow_tool", description: "Sleeps"}, delay: time.Second}
+	fast := &SlowTool{MockTool: MockTool{name: "fast_tool", description: "Returns at once"}}
+	for _, tool := range []x402server.Tool{slow, fast} {
+		if err := srv.AddTool(tool); err != nil {
+			t.Fatalf("AddTool failed: %v", err)
+		}
+	}
+
+	start := time.Now()
+	result, err := srv.ExecuteTool("slow_tool", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("ExecuteTool returned error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= slow.delay {
+		t.Errorf("Expected the call to return at its budget, took %v", elapsed)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["error_code"] != x402server.ErrorCodeToolTimeout {
+		t.Errorf("Expected error_code %q, got %v", x402server.ErrorCodeToolTimeout, resultMap["error_code"])
+	}
+	if resultMap["timeout_ms"] != int64(50) {
+		t.Errorf("Expected timeout_ms 50, got %v", resultMap["timeout_ms"])
+	}
+
+	// Tools without an entry run under the generous default
+	result, err = srv.ExecuteTool("fast_tool", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("ExecuteTool returned error: %v", err)
+	}
+	if result.(map[string]interface{})["done"] != true {
+		t.Errorf("Expected fast tool result, got %v", result)
+	}
+
+	// MCP tools/call requests are held to the same budgets
+	mcpServer := server.NewMCPServer("test-server", "0.1.0")
+	if err := srv.RegisterTools(mcpServer); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+
+	start = time.Now()
+	dispatched, err := callTool(mcpServer, "slow_tool", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= slow.delay {
+		t.Errorf("Expected tools/call to return at its budget, took %v", elapsed)
+	}
+	if code := dispatched.StructuredContent["error_code"]; code != x402server.ErrorCodeToolTimeout {
+		t.Errorf("Expected tools/call error_code %q, got %v", x402server.ErrorCodeToolTimeout, code)
+	}
+
+	dispatched, err = callTool(mcpServer, "fast_tool", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	if dispatched.StructuredContent["done"] != true {
+		t.Errorf("Expected fast tool result from tools/call, got %+v", dispatched)
+	}
+}
+
+// TestSettlePayment_ToolTimeoutPending verifies a settlement outliving its tool timeout is
+// reported as pending with its idempotency key, keeps running, and is never submitted twice
+// by retries made while it is in flight
+func TestSettlePayment_ToolTimeoutPending(t *testing.T) {
+	var submissions atomic.Int32
+	release := make(chan struct{})
+	facilitator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		submissions.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+		})
+	}))
+	defer facilitator.Close()
+
+	cfg := createTestConfigForSettlement()
+	baseNet := cfg.Networks["base"]
+	baseNet.FacilitatorURL = facilitator.URL
+	cfg.Networks["base"] = baseNet
+	cfg.Limits = config.LimitsConfig{
+		ToolTimeouts: map[string]int{"settle_payment": 50},
+	}
+
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, io.Discard))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := srv.AddTool(tools.NewSettlePaymentTool(srv)); err != nil {
+		t.Fatalf("AddTool failed: %v", err)
+	}
+	mcpServer := server.NewMCPServer("test-server", "0.1.0")
+	if err := srv.RegisterTools(mcpServer); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create test private key: %v", err)
+	}
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress(baseNet.PayeeAddress), big.NewInt(50000), [32]byte{0x77})
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+	args := map[string]interface{}{
+		"authorization": authInput,
+		"network":       "base",
+	}
+
+	// The first call and a retry both time out while the facilitator holds the submission
+	for attempt := 1; attempt <= 2; attempt++ {
+		result, err := callTool(mcpServer, "settle_payment", args)
+		if err != nil {
+			t.Fatalf("Attempt %d: tools/call failed: %v", attempt, err)
+		}
+		content := result.StructuredContent
+		if content["status"] != "pending" || content["error_code"] != x402server.ErrorCodeToolTimeout {
+			t.Errorf("Attempt %d: expected pending with error_code %q, got %v", attempt, x402server.ErrorCodeToolTimeout, content)
+		}
+		if content["idempotency_key"] != authInput["nonce"] {
+			t.Errorf("Attempt %d: expected idempotency_key %v, got %v", attempt, authInput["nonce"], content["idempotency_key"])
+		}
+		if content["retry_after"] == nil {
+			t.Errorf("Attempt %d: expected a retry_after hint, got %v", attempt, content)
+		}
+	}
+
+	close(release)
+
+	// Once the original submission completes, a retry returns its result
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		result, err := callTool(mcpServer, "settle_payment", args)
+		if err != nil {
+			t.Fatalf("tools/call failed: %v", err)
+		}
+		if result.StructuredContent["status"] == "settled" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Settlement did not complete, last result %v", result.StructuredContent)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if n := submissions.Load(); n != 1 {
+		t.Errorf("Expected 1 facilitator submission across retries, got %d", n)
+	}
+}
@@ -0,0 +1,147 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// TestValidateAccepts_MixedEntries tests validity, expiry, and affordability across an accepts array
+func TestValidateAccepts_MixedEntries(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	createTool := tools.NewCreatePaymentRequirementTool(srv)
+	newRequirement := func(amount, network string) map[string]interface{} {
+		result, err := createTool.Execute(map[string]interface{}{
+			"amount":  amount,
+			"network": network,
+		})
+		if err != nil {
+			t.Fatalf("create_payment_requirement failed: %v", err)
+		}
+		return result.(map[string]interface{})
+	}
+
+	expired := newRequirement("1000", "arbitrum")
+	expired["valid_until"] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+
+	invalid := newRequirement("1000", "base")
+	invalid["scheme"] = "upto"
+
+	// Entries given as a JSON string are accepted too
+	cheapJSON, err := json.Marshal(newRequirement("20000", "base-sepolia"))
+	if err != nil {
+		t.Fatalf("Failed to encode requirement: %v", err)
+	}
+
+	accepts := []interface{}{
+		newRequirement("50000", "base"),  // 0: valid, affordable
+		string(cheapJSON),                // 1: valid, affordable, cheapest
+		expired,                          // 2: expired
+		invalid,                          // 3: fails Validate
+		newRequirement("500000", "base"), // 4: valid, over balance
+	}
+
+	result, err := tools.NewValidateAcceptsTool(srv).Execute(map[string]interface{}{
+		"accepts": accepts,
+		"balance": "100000",
+	})
+	if err != nil {
+		t.Fatalf("validate_accepts failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["valid_count"] != 3 {
+		t.Errorf("Expected 3 valid entries, got %v", resultMap["valid_count"])
+	}
+	if resultMap["affordable_count"] != 2 {
+		t.Errorf("Expected 2 affordable entries, got %v", resultMap["affordable_count"])
+	}
+	if resultMap["recommended_index"] != 1 {
+		t.Errorf("Expected cheapest affordable entry (1) to be recommended, got %v", resultMap["recommended_index"])
+	}
+
+	results := resultMap["results"].([]interface{})
+	if len(results) != len(accepts) {
+		t.Fatalf("Expected %d results, got %d", len(accepts), len(results))
+	}
+
+	expectations := []struct {
+		valid      bool
+		affordable interface{}
+		errorCode  interface{}
+	}{
+		{true, true, nil},
+		{true, true, nil},
+		{false, nil, x402.ErrorCodeRequirementExpired},
+		{false, nil, x402.ErrorCodeInvalidRequirement},
+		{true, false, nil},
+	}
+	for i, want := range expectations {
+		entry := results[i].(map[string]interface{})
+		if entry["index"] != i {
+			t.Errorf("Entry %d: expected index %d, got %v", i, i, entry["index"])
+		}
+		if entry["valid"] != want.valid {
+			t.Errorf("Entry %d: expected valid=%v, got %v", i, want.valid, entry["valid"])
+		}
+		if entry["affordable"] != want.affordable {
+			t.Errorf("Entry %d: expected affordable=%v, got %v", i, want.affordable, entry["affordable"])
+		}
+		if entry["error_code"] != want.errorCode {
+			t.Errorf("Entry %d: expected error_code %v, got %v", i, want.errorCode, entry["error_code"])
+		}
+	}
+}
+
+// TestValidateAccepts_NoBalance tests that affordability is omitted without a balance
+func TestValidateAccepts_NoBalance(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForPayment(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	requirement, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+
+	tool := tools.NewValidateAcceptsTool(srv)
+	result, err := tool.Execute(map[string]interface{}{
+		"accepts": []interface{}{requirement},
+	})
+	if err != nil {
+		t.Fatalf("validate_accepts failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if _, exists := resultMap["affordable_count"]; exists {
+		t.Error("Expected affordable_count to be omitted without a balance")
+	}
+	if resultMap["recommended_index"] != 0 {
+		t.Errorf("Expected the only valid entry to be recommended, got %v", resultMap["recommended_index"])
+	}
+
+	// Malformed inputs are rejected outright
+	if _, err := tool.Execute(map[string]interface{}{"accepts": []interface{}{}}); err == nil {
+		t.Error("Expected error for empty accepts array")
+	}
+	if _, err := tool.Execute(map[string]interface{}{
+		"accepts": []interface{}{requirement},
+		"balance": "-5",
+	}); err == nil {
+		t.Error("Expected error for negative balance")
+	}
+}
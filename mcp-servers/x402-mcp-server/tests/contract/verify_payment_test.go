@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
@@ -89,8 +93,13 @@ func TestVerifyPayment_ToolSchema(t *testing.T) {
 		t.Fatal("Schema should have required fields")
 	}
 
-	if len(required) != 2 {
-		t.Errorf("Expected 2 required fields, got %d", len(required))
+	if len(required) != 1 || required[0] != "authorization" {
+		t.Errorf("Expected only authorization required, got %v", required)
+	}
+
+	// network may be replaced by the {chain_id, asset} pair
+	if _, ok := schemaMap["anyOf"].([]interface{}); !ok {
+		t.Error("Schema should require network or chain_id+asset via anyOf")
 	}
 }
 
@@ -426,3 +435,912 @@ func createTestConfigForVerification() *config.Config {
 		},
 	}
 }
+
+// TestVerifyPayment_ConfigReloadInvalidatesCachedDomain tests that a config reload changing
+// the EIP-712 domain flushes cached verification state for the affected network
+func TestVerifyPayment_ConfigReloadInvalidatesCachedDomain(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	log := logger.New(logger.DEBUG, &bytes.Buffer{})
+
+	srv, err := x402server.NewServer(cfg, log)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	privateKey, _ := crypto.GenerateKey()
+	nonce := [32]byte{}
+	copy(nonce[:], []byte("reload-nonce"))
+
+	domainV2 := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+	}
+
+	authV2, err := buildSignedAuthorizationInput(privateKey, domainV2,
+		common.HexToAddress("0x1234567890123456789012345678901234567890"), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	input := map[string]interface{}{"authorization": authV2, "network": "base"}
+
+	// Verify twice so the second result is served from cache
+	for i := 0; i < 2; i++ {
+		result, err := tool.Execute(input)
+		if err != nil {
+			t.Fatalf("Verification %d failed: %v", i+1, err)
+		}
+		if !result.(map[string]interface{})["is_valid"].(bool) {
+			t.Fatalf("Expected valid signature before reload, got %v", result)
+		}
+	}
+
+	// Reload with a different domain version
+	newCfg := createTestConfigForVerification()
+	newCfg.EIP712.DomainVersion = "1"
+	if err := srv.ReloadConfig(newCfg); err != nil {
+		t.Fatalf("Config reload failed: %v", err)
+	}
+
+	// The old signature must no longer verify against the new domain
+	result, err := tool.Execute(input)
+	if err != nil {
+		t.Fatalf("Verification after reload failed: %v", err)
+	}
+	if result.(map[string]interface{})["is_valid"].(bool) {
+		t.Error("Expected signature under old domain to be invalid after reload")
+	}
+
+	// A signature under the new domain verifies
+	domainV1 := *domainV2
+	domainV1.Version = "1"
+	authV1, err := buildSignedAuthorizationInput(privateKey, &domainV1,
+		common.HexToAddress("0x1234567890123456789012345678901234567890"), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	result, err = tool.Execute(map[string]interface{}{"authorization": authV1, "network": "base"})
+	if err != nil {
+		t.Fatalf("Verification under new domain failed: %v", err)
+	}
+	if !result.(map[string]interface{})["is_valid"].(bool) {
+		t.Errorf("Expected signature under new domain to be valid, got %v", result)
+	}
+}
+
+// TestVerifyPayment_ConfigReloadRejectsInvalidConfig tests that an invalid reload keeps the running config
+func TestVerifyPayment_ConfigReloadRejectsInvalidConfig(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	log := logger.New(logger.DEBUG, &bytes.Buffer{})
+
+	srv, err := x402server.NewServer(cfg, log)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	invalid := createTestConfigForVerification()
+	invalid.Cache.SettlementTTLMinutes = 0
+
+	if err := srv.ReloadConfig(invalid); err == nil {
+		t.Error("Expected reload of invalid config to fail")
+	}
+
+	if srv.GetConfig() != cfg {
+		t.Error("Running config should be unchanged after rejected reload")
+	}
+}
+
+// TestVerifyPayment_ExpectedValueHuman tests comparing the authorization value to a human amount
+func TestVerifyPayment_ExpectedValueHuman(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	privateKey, _ := crypto.GenerateKey()
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	var nonce [32]byte
+	copy(nonce[:], []byte("expected-value-nonce"))
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x1234567890123456789012345678901234567890"), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		expected     string
+		expectValid  bool
+		expectedCode string
+	}{
+		{"matching amount", "0.05", true, ""},
+		{"mismatching amount", "0.06", false, eip3009.ErrorCodeAmountMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization":        authInput,
+				"network":              "base",
+				"expected_value_human": tt.expected,
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["is_valid"] != tt.expectValid {
+				t.Errorf("Expected is_valid=%v, got %v", tt.expectValid, resultMap)
+			}
+
+			code, _ := resultMap["error_code"].(string)
+			if code != tt.expectedCode {
+				t.Errorf("Expected error_code '%s', got '%s'", tt.expectedCode, code)
+			}
+		})
+	}
+
+	// Amounts finer than USDC precision are rejected as invalid input
+	_, err = tool.Execute(map[string]interface{}{
+		"authorization":        authInput,
+		"network":              "base",
+		"expected_value_human": "0.0500001",
+	})
+	if err == nil {
+		t.Error("Expected error for expected_value_human with more than 6 decimals")
+	}
+}
+
+// TestVerifyPayment_ExpectedValueHuman18Decimals tests human amounts for an 18-decimal asset
+func TestVerifyPayment_ExpectedValueHuman18Decimals(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	baseNet := cfg.Networks["base"]
+	baseNet.AssetDecimals = 18
+	cfg.Networks["base"] = baseNet
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	privateKey, _ := crypto.GenerateKey()
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	// 1.5 tokens at 18 decimals, far beyond any 6-decimal USDC amount
+	value, _ := new(big.Int).SetString("1500000000000000000", 10)
+	var nonce [32]byte
+	copy(nonce[:], []byte("eighteen-decimals-nonce"))
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+		common.HexToAddress("0x1234567890123456789012345678901234567890"), value, nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		expected     string
+		expectedCode string
+	}{
+		{"matching amount", "1.5", ""},
+		{"matching amount with full precision", "1.500000000000000000", ""},
+		{"mismatch in the last decimal", "1.500000000000000001", eip3009.ErrorCodeAmountMismatch},
+		{"USDC-scaled amount does not match", "0.0000000000015", eip3009.ErrorCodeAmountMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization":        authInput,
+				"network":              "base",
+				"expected_value_human": tt.expected,
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["is_valid"] != (tt.expectedCode == "") {
+				t.Errorf("Expected is_valid=%v, got %v", tt.expectedCode == "", resultMap)
+			}
+			code, _ := resultMap["error_code"].(string)
+			if code != tt.expectedCode {
+				t.Errorf("Expected error_code '%s', got '%s'", tt.expectedCode, code)
+			}
+		})
+	}
+
+	// Amounts finer than the asset's 18 decimals are rejected as invalid input
+	if _, err := tool.Execute(map[string]interface{}{
+		"authorization":        authInput,
+		"network":              "base",
+		"expected_value_human": "1.5000000000000000001",
+	}); err == nil {
+		t.Error("Expected error for expected_value_human with more than 18 decimals")
+	}
+}
+
+// TestVerifyPayment_AddressFormatCAIP10 tests CAIP-10 output via config and per-call override
+func TestVerifyPayment_AddressFormatCAIP10(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+	payee := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	for _, network := range []string{"base", "base-sepolia"} {
+		t.Run(network, func(t *testing.T) {
+			cfg := createTestConfigForVerification()
+			cfg.Verification.AddressFormat = config.AddressFormatCAIP10
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+
+			tool := tools.NewVerifyPaymentTool(srv)
+
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain(network)
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+
+			var nonce [32]byte
+			copy(nonce[:], []byte("caip10-nonce-"+network))
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain, payee, big.NewInt(50000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization": authInput,
+				"network":       network,
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["is_valid"] != true {
+				t.Fatalf("Expected valid signature, got %v", resultMap)
+			}
+
+			prefix := fmt.Sprintf("eip155:%d:", cfg.Networks[network].ChainID)
+			expected := map[string]string{
+				"signer_address": prefix + signer.Hex(),
+				"from":           prefix + signer.Hex(),
+				"to":             prefix + payee.Hex(),
+			}
+			for field, want := range expected {
+				if resultMap[field] != want {
+					t.Errorf("Expected %s=%s, got %v", field, want, resultMap[field])
+				}
+			}
+
+			// A per-call override returns plain hex
+			result, err = tool.Execute(map[string]interface{}{
+				"authorization":  authInput,
+				"network":        network,
+				"address_format": config.AddressFormatHex,
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			if got := result.(map[string]interface{})["signer_address"]; got != signer.Hex() {
+				t.Errorf("Expected hex signer_address %s, got %v", signer.Hex(), got)
+			}
+		})
+	}
+}
+
+// TestVerifyPayment_RequirementPayee tests that the authorization must pay the requirement's payTo
+func TestVerifyPayment_RequirementPayee(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	created, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+	requirement := created.(map[string]interface{})
+
+	// payTo is compared case-insensitively against the signed payee
+	payee := common.HexToAddress("0xabcdef0123456789abcdef0123456789abcdef01")
+	requirement["payTo"] = strings.ToLower(payee.Hex())
+
+	privateKey, _ := crypto.GenerateKey()
+	domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		to           common.Address
+		expectValid  bool
+		expectedCode string
+	}{
+		{"correct payee", payee, true, ""},
+		{"incorrect payee", common.HexToAddress("0x9999999999999999999999999999999999999999"), false, eip3009.ErrorCodePayeeMismatch},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nonce [32]byte
+			copy(nonce[:], []byte(fmt.Sprintf("payee-nonce-%d", i)))
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain, tt.to, big.NewInt(50000), nonce)
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization": authInput,
+				"network":       "base",
+				"requirement":   requirement,
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["is_valid"] != tt.expectValid {
+				t.Errorf("Expected is_valid=%v, got %v", tt.expectValid, resultMap)
+			}
+
+			code, _ := resultMap["error_code"].(string)
+			if code != tt.expectedCode {
+				t.Errorf("Expected error_code '%s', got '%s'", tt.expectedCode, code)
+			}
+		})
+	}
+
+	// A malformed requirement is an input error, not a mismatch
+	_, err = tool.Execute(map[string]interface{}{
+		"authorization": createTestCalldataAuthorization(),
+		"network":       "base",
+		"requirement":   `{"payTo": "0x1234"}`,
+	})
+	if err == nil {
+		t.Error("Expected error for invalid requirement")
+	}
+}
+
+// TestVerifyPayment_RequirementDomain tests that a requirement's extra name/version
+// replace the configured EIP-712 domain, so foreign requirements verify as signed
+func TestVerifyPayment_RequirementDomain(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	created, err := tools.NewCreatePaymentRequirementTool(srv).Execute(map[string]interface{}{
+		"amount":  "50000",
+		"network": "base",
+	})
+	if err != nil {
+		t.Fatalf("create_payment_requirement failed: %v", err)
+	}
+	requirement := created.(map[string]interface{})
+	requirement["extra"] = map[string]interface{}{"name": "Bridged USDC", "version": "3"}
+	payee := common.HexToAddress(requirement["payTo"].(string))
+
+	configured, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	foreign := *configured
+	foreign.Name = "Bridged USDC"
+	foreign.Version = "3"
+
+	privateKey, _ := crypto.GenerateKey()
+	sign := func(domain *eip3009.EIP712Domain, tag byte) map[string]interface{} {
+		var nonce [32]byte
+		nonce[0] = tag
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain, payee, big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+		return authInput
+	}
+
+	tests := []struct {
+		name        string
+		auth        map[string]interface{}
+		requirement interface{}
+		expectValid bool
+	}{
+		{"signed for requirement domain", sign(&foreign, 1), requirement, true},
+		{"requirement domain without requirement", sign(&foreign, 2), nil, false},
+		{"configured domain against requirement domain", sign(configured, 3), requirement, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{
+				"authorization": tt.auth,
+				"network":       "base",
+			}
+			if tt.requirement != nil {
+				args["requirement"] = tt.requirement
+			}
+
+			result, err := tool.Execute(args)
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["is_valid"] != tt.expectValid {
+				t.Errorf("Expected is_valid=%v, got %v", tt.expectValid, resultMap)
+			}
+		})
+	}
+
+	// A requirement naming only a version keeps the configured domain name
+	requirement["extra"] = map[string]interface{}{"version": "3"}
+	versionOnly := *configured
+	versionOnly.Version = "3"
+	result, err := tool.Execute(map[string]interface{}{
+		"authorization": sign(&versionOnly, 4),
+		"network":       "base",
+		"requirement":   requirement,
+	})
+	if err != nil {
+		t.Fatalf("Tool execution failed: %v", err)
+	}
+	if valid := result.(map[string]interface{})["is_valid"]; valid != true {
+		t.Errorf("Expected version-only requirement domain to verify, got %v", result)
+	}
+}
+
+// TestVerifyPayment_TimestampBounds tests that malformed validAfter/validBefore are rejected, not wrapped or truncated
+func TestVerifyPayment_TimestampBounds(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForVerification(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+	now := float64(time.Now().Unix())
+
+	tests := []struct {
+		name        string
+		field       string
+		validAfter  float64
+		validBefore float64
+	}{
+		{"negative validAfter", "validAfter", -1, now + 3600},
+		{"NaN validAfter", "validAfter", math.NaN(), now + 3600},
+		{"infinite validBefore", "validBefore", now - 3600, math.Inf(1)},
+		{"fractional validAfter", "validAfter", 1.5, now + 3600},
+		{"fractional validBefore", "validBefore", now - 3600, now + 3600.25},
+		{"validAfter beyond year 2200", "validAfter", float64(eip3009.MaxTimestamp) + 1, now + 3600},
+		{"validBefore beyond 2^63", "validBefore", now - 3600, math.Pow(2, 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(map[string]interface{}{
+				"authorization": map[string]interface{}{
+					"from":        "0x0000000000000000000000000000000000000001",
+					"to":          "0x1234567890123456789012345678901234567890",
+					"value":       "50000",
+					"validAfter":  tt.validAfter,
+					"validBefore": tt.validBefore,
+					"nonce":       "0x0000000000000000000000000000000000000000000000000000000000000001",
+					"v":           float64(27),
+					"r":           "0x0000000000000000000000000000000000000000000000000000000000000001",
+					"s":           "0x0000000000000000000000000000000000000000000000000000000000000001",
+				},
+				"network": "base",
+			})
+			if err == nil {
+				t.Fatal("Expected error for malformed timestamp")
+			}
+
+			var validationErr *eip3009.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected ValidationError, got %T: %v", err, err)
+			}
+			if validationErr.Field != tt.field {
+				t.Errorf("Expected field %s, got %s", tt.field, validationErr.Field)
+			}
+		})
+	}
+}
+
+// TestVerifyPayment_MalformedHex tests that non-hex characters in r, s, or nonce are reported by field
+func TestVerifyPayment_MalformedHex(t *testing.T) {
+	srv, err := x402server.NewServer(createTestConfigForVerification(), logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+	now := float64(time.Now().Unix())
+	valid := "0x0000000000000000000000000000000000000000000000000000000000000001"
+	malformed := "0x00000000000000000000000000000000000000000000000000000000000000zz"
+
+	for _, field := range []string{"r", "s", "nonce"} {
+		t.Run(field, func(t *testing.T) {
+			authorization := map[string]interface{}{
+				"from":        "0x0000000000000000000000000000000000000001",
+				"to":          "0x1234567890123456789012345678901234567890",
+				"value":       "50000",
+				"validAfter":  now - 3600,
+				"validBefore": now + 3600,
+				"nonce":       valid,
+				"v":           float64(27),
+				"r":           valid,
+				"s":           valid,
+			}
+			authorization[field] = malformed
+
+			_, err := tool.Execute(map[string]interface{}{
+				"authorization": authorization,
+				"network":       "base",
+			})
+			if err == nil {
+				t.Fatalf("Expected error for non-hex %s", field)
+			}
+
+			var validationErr *eip3009.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected ValidationError, got %T: %v", err, err)
+			}
+			if validationErr.Field != field {
+				t.Errorf("Expected field %s, got %s", field, validationErr.Field)
+			}
+			if !strings.Contains(err.Error(), "invalid hex") {
+				t.Errorf("Expected an invalid hex error, got: %v", err)
+			}
+		})
+	}
+}
+
+// TestVerifyPayment_ResolveNetworkByAsset tests selecting the network from chain_id and asset
+func TestVerifyPayment_ResolveNetworkByAsset(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	privateKey, from, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress(cfg.Networks["base"].USDCContract),
+	}
+
+	var nonce [32]byte
+	copy(nonce[:], []byte("resolve-by-asset"))
+
+	authInput, err := buildSignedAuthorizationInput(privateKey, domain, common.HexToAddress(cfg.Networks["base"].PayeeAddress), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	// Lowercase asset still resolves: addresses compare case-insensitively
+	result, err := tool.Execute(map[string]interface{}{
+		"authorization": authInput,
+		"chain_id":      float64(8453),
+		"asset":         strings.ToLower(cfg.Networks["base"].USDCContract),
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	resultMap := result.(map[string]interface{})
+	if resultMap["is_valid"] != true {
+		t.Fatalf("Expected valid signature on resolved network, got error: %v", resultMap["error"])
+	}
+	if !strings.EqualFold(resultMap["signer_address"].(string), from.Hex()) {
+		t.Errorf("Expected signer %s, got %v", from.Hex(), resultMap["signer_address"])
+	}
+
+	// Unknown asset on a configured chain
+	_, err = tool.Execute(map[string]interface{}{
+		"authorization": authInput,
+		"chain_id":      float64(8453),
+		"asset":         "0x0000000000000000000000000000000000000bad",
+	})
+	if err == nil {
+		t.Error("Expected error for unknown asset")
+	}
+
+	// Known asset on the wrong chain
+	_, err = tool.Execute(map[string]interface{}{
+		"authorization": authInput,
+		"chain_id":      float64(84532),
+		"asset":         cfg.Networks["base"].USDCContract,
+	})
+	if err == nil {
+		t.Error("Expected error for asset on mismatched chain")
+	}
+
+	// Neither network nor chain_id+asset
+	if _, err := tool.Execute(map[string]interface{}{"authorization": authInput, "chain_id": float64(8453)}); err == nil {
+		t.Error("Expected error when asset is missing")
+	}
+}
+
+// TestVerifyPayment_VerboseSNormalized tests the informational s_normalized flag for low-s and high-s signatures
+func TestVerifyPayment_VerboseSNormalized(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress(cfg.Networks["base"].USDCContract),
+	}
+
+	var nonce [32]byte
+	copy(nonce[:], []byte("s-normalized"))
+
+	lowS, err := buildSignedAuthorizationInput(privateKey, domain, common.HexToAddress(cfg.Networks["base"].PayeeAddress), big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	// The high-s twin (N - s with v flipped) recovers the same signer but is non-canonical
+	highS := make(map[string]interface{}, len(lowS))
+	for k, v := range lowS {
+		highS[k] = v
+	}
+	s := new(big.Int).SetBytes(common.FromHex(lowS["s"].(string)))
+	highS["s"] = common.BigToHash(new(big.Int).Sub(crypto.S256().Params().N, s)).Hex()
+	highS["v"] = float64(55) - lowS["v"].(float64)
+
+	tests := []struct {
+		name     string
+		auth     map[string]interface{}
+		expected bool
+	}{
+		{"low-s", lowS, true},
+		{"high-s", highS, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization": tt.auth,
+				"network":       "base",
+				"verbose":       true,
+			})
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if got := result.(map[string]interface{})["s_normalized"]; got != tt.expected {
+				t.Errorf("Expected s_normalized=%v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// Non-verbose output omits the flag
+	result, err := tool.Execute(map[string]interface{}{"authorization": lowS, "network": "base"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, exists := result.(map[string]interface{})["s_normalized"]; exists {
+		t.Error("Expected s_normalized only in verbose output")
+	}
+}
+
+// TestVerifyPayment_NetworkCaseInsensitive tests that mixed-case network names verify
+// against the canonical network's domain
+func TestVerifyPayment_NetworkCaseInsensitive(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+	privateKey, _ := crypto.GenerateKey()
+
+	tests := map[string]string{
+		"Base":         "base",
+		"BASE":         "base",
+		"base-Sepolia": "base-sepolia",
+	}
+	for input, canonical := range tests {
+		domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain(canonical)
+		if err != nil {
+			t.Fatalf("Failed to build domain: %v", err)
+		}
+
+		var nonce [32]byte
+		copy(nonce[:], []byte(input))
+		authInput, err := buildSignedAuthorizationInput(privateKey, domain,
+			common.HexToAddress("0x1234567890123456789012345678901234567890"), big.NewInt(50000), nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+
+		result, err := tool.Execute(map[string]interface{}{
+			"authorization": authInput,
+			"network":       input,
+		})
+		if err != nil {
+			t.Errorf("Network %q: unexpected error: %v", input, err)
+			continue
+		}
+		if valid := result.(map[string]interface{})["is_valid"]; valid != true {
+			t.Errorf("Network %q: expected valid signature against %s domain, got %v", input, canonical, result)
+		}
+	}
+
+	if _, err := tool.Execute(map[string]interface{}{
+		"authorization": map[string]interface{}{},
+		"network":       "Arbitrum", // Not configured in this test config
+	}); err == nil {
+		t.Error("Expected error for unconfigured network after normalization")
+	}
+}
+
+// TestVerifyPayment_EIP155V tests EIP-155 encoded v values under each verification.eip155_v policy
+func TestVerifyPayment_EIP155V(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	payee := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	tests := []struct {
+		name        string
+		policy      string
+		chainID     uint64
+		expectValid bool
+		expectCode  string
+	}{
+		{"rejected by default", "", 8453, false, eip3009.ErrorCodeEIP155VRejected},
+		{"accept ignores chain", config.EIP155VAccept, 42161, true, ""},
+		{"match_chain correct chain", config.EIP155VMatchChain, 8453, true, ""},
+		{"match_chain wrong chain", config.EIP155VMatchChain, 42161, false, eip3009.ErrorCodeChainMismatch},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createTestConfigForVerification()
+			cfg.Verification.EIP155V = tt.policy
+			srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+			if err != nil {
+				t.Fatalf("Failed to create server: %v", err)
+			}
+			tool := tools.NewVerifyPaymentTool(srv)
+
+			domain, err := eip3009.NewSignatureVerifier(cfg).VerifyDomain("base")
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+			authInput, err := buildSignedAuthorizationInput(privateKey, domain, payee, big.NewInt(50000), [32]byte{0x90, byte(i)})
+			if err != nil {
+				t.Fatalf("Failed to sign authorization: %v", err)
+			}
+
+			// Re-encode v as chainId*2 + 35/36
+			authInput["v"] = float64(tt.chainID*2+35) + authInput["v"].(float64) - 27
+
+			result, err := tool.Execute(map[string]interface{}{
+				"authorization": authInput,
+				"network":       "base",
+			})
+			if err != nil {
+				t.Fatalf("Tool execution failed: %v", err)
+			}
+
+			resultMap := result.(map[string]interface{})
+			if resultMap["is_valid"] != tt.expectValid {
+				t.Errorf("Expected is_valid=%v, got %v", tt.expectValid, resultMap)
+			}
+			if tt.expectCode != "" && resultMap["error_code"] != tt.expectCode {
+				t.Errorf("Expected error_code %q, got %v", tt.expectCode, resultMap["error_code"])
+			}
+		})
+	}
+}
+
+// TestVerifyPayment_AlreadySeen tests that a second verification of the same nonce is flagged without failing
+func TestVerifyPayment_AlreadySeen(t *testing.T) {
+	cfg := createTestConfigForVerification()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	tool := tools.NewVerifyPaymentTool(srv)
+
+	privateKey, _, err := createTestPrivateKeyAndAddress()
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress(cfg.Networks["base"].USDCContract),
+	}
+	payee := common.HexToAddress(cfg.Networks["base"].PayeeAddress)
+
+	var nonce [32]byte
+	copy(nonce[:], []byte("already-seen"))
+	auth, err := buildSignedAuthorizationInput(privateKey, domain, payee, big.NewInt(50000), nonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	var otherNonce [32]byte
+	copy(otherNonce[:], []byte("fresh-nonce"))
+	other, err := buildSignedAuthorizationInput(privateKey, domain, payee, big.NewInt(50000), otherNonce)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	for i, tt := range []struct {
+		auth     map[string]interface{}
+		expected bool
+	}{
+		{auth, false},
+		{auth, true},
+		{other, false},
+	} {
+		result, err := tool.Execute(map[string]interface{}{
+			"authorization": tt.auth,
+			"network":       "base",
+		})
+		if err != nil {
+			t.Fatalf("call %d: Execute failed: %v", i+1, err)
+		}
+
+		resultMap := result.(map[string]interface{})
+		if resultMap["is_valid"] != true {
+			t.Errorf("call %d: expected replay flag not to block verification, got %v", i+1, resultMap["error"])
+		}
+		if resultMap["already_seen"] != tt.expected {
+			t.Errorf("call %d: expected already_seen=%v, got %v", i+1, tt.expected, resultMap["already_seen"])
+		}
+	}
+}
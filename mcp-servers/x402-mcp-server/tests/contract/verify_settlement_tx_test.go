@@ -0,0 +1,95 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+	x402server "github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/tools"
+)
+
+// stubTxReader serves a single transaction regardless of the requested hash
+type stubTxReader struct {
+	tx *types.Transaction
+}
+
+func (s *stubTxReader) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if s.tx == nil {
+		return nil, false, fmt.Errorf("not found")
+	}
+	return s.tx, false, nil
+}
+
+// TestVerifySettlementTx_Execute tests the tool against matching and tampered on-chain calldata
+func TestVerifySettlementTx_Execute(t *testing.T) {
+	cfg := createTestConfigForSettlement()
+	srv, err := x402server.NewServer(cfg, logger.New(logger.DEBUG, &bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+
+	// Build the on-chain calldata with the calldata tool from the same authorization
+	authInput := createTestCalldataAuthorization()
+	built, err := tools.NewBuildSettlementCalldataTool(srv).Execute(map[string]interface{}{
+		"authorization": authInput,
+		"network":       "base",
+	})
+	if err != nil {
+		t.Fatalf("Failed to build calldata: %v", err)
+	}
+	calldata := common.FromHex(built.(map[string]interface{})["calldata"].(string))
+
+	usdc := common.HexToAddress(cfg.Networks["base"].USDCContract)
+	tx := types.NewTx(&types.LegacyTx{Nonce: 3, To: &usdc, Gas: 90000, Data: calldata})
+
+	tool := tools.NewVerifySettlementTxTool(srv)
+	if tool.Name() != "verify_settlement_tx" {
+		t.Errorf("Expected tool name verify_settlement_tx, got %s", tool.Name())
+	}
+	tool.TxVerifier().SetBackend("base", &stubTxReader{tx: tx})
+
+	t.Run("matching", func(t *testing.T) {
+		result, err := tool.Execute(map[string]interface{}{
+			"tx_hash":       tx.Hash().Hex(),
+			"authorization": authInput,
+			"network":       "base",
+		})
+		if err != nil {
+			t.Fatalf("Execute returned error: %v", err)
+		}
+
+		resultMap := result.(map[string]interface{})
+		if resultMap["match"] != true {
+			t.Errorf("Expected match=true, got %v (error: %v)", resultMap["match"], resultMap["error"])
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		// The caller authorized a smaller amount than the facilitator settled
+		expected := createTestCalldataAuthorization()
+		expected["value"] = "10000"
+
+		result, err := tool.Execute(map[string]interface{}{
+			"tx_hash":       tx.Hash().Hex(),
+			"authorization": expected,
+			"network":       "base",
+		})
+		if err != nil {
+			t.Fatalf("Execute returned error: %v", err)
+		}
+
+		resultMap := result.(map[string]interface{})
+		if resultMap["match"] != false {
+			t.Error("Expected match=false for tampered value")
+		}
+		if resultMap["error_code"] != onchain.ErrorCodeTxMismatch {
+			t.Errorf("Expected error_code '%s', got %v", onchain.ErrorCodeTxMismatch, resultMap["error_code"])
+		}
+	})
+}
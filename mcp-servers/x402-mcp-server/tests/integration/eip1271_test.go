@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+// TestEIP1271_RealRPC exercises the contract wallet path against Base Sepolia
+// The payer is the Base Sepolia USDC contract: it has code but no isValidSignature, so the
+// call reverts and the signature must be rejected as invalid_contract_signature.
+// Skipped by default unless RPC_TEST_ENABLED=1 is set
+func TestEIP1271_RealRPC(t *testing.T) {
+	if os.Getenv("RPC_TEST_ENABLED") != "1" {
+		t.Skip("Skipping RPC integration test (set RPC_TEST_ENABLED=1 to run)")
+	}
+
+	usdc := common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base-sepolia": {
+				ChainID:      84532,
+				USDCContract: usdc.Hex(),
+				RPCURL:       "https://sepolia.base.org",
+			},
+		},
+		Verification: config.VerificationConfig{EIP1271: true},
+	}
+	verifier := eip3009.NewSignatureVerifier(cfg)
+
+	domain, err := verifier.VerifyDomain("base-sepolia")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	now := time.Now().Unix()
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        usdc,
+		To:          common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Value:       big.NewInt(1000),
+		ValidAfter:  big.NewInt(now - 60),
+		ValidBefore: big.NewInt(now + 3600),
+		Nonce:       [32]byte{1},
+	}
+	typedDataHash, err := eip3009.TypedDataHash(domain, message)
+	if err != nil {
+		t.Fatalf("Failed to hash authorization: %v", err)
+	}
+	signature, err := crypto.Sign(typedDataHash.Bytes(), key)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	result, err := verifier.VerifyAuthorization(&eip3009.EIP3009Authorization{
+		From:        usdc.Hex(),
+		To:          message.To.Hex(),
+		Value:       message.Value.String(),
+		ValidAfter:  message.ValidAfter.Uint64(),
+		ValidBefore: message.ValidBefore.Uint64(),
+		Nonce:       common.BytesToHash(message.Nonce[:]).Hex(),
+		V:           signature[64] + 27,
+		R:           common.BytesToHash(signature[0:32]).Hex(),
+		S:           common.BytesToHash(signature[32:64]).Hex(),
+	}, "base-sepolia")
+	if err != nil {
+		t.Fatalf("VerifyAuthorization failed: %v", err)
+	}
+
+	if result.SignerType != eip3009.SignerTypeContract {
+		t.Errorf("Expected the USDC contract to be detected as a contract payer, got %q", result.SignerType)
+	}
+	if result.IsValid || result.ErrorCode != eip3009.ErrorCodeContractSignature {
+		t.Errorf("Expected %s, got valid=%v code=%q (%s)", eip3009.ErrorCodeContractSignature, result.IsValid, result.ErrorCode, result.Error)
+	}
+}
@@ -221,8 +221,6 @@ func createTestAuthorization(t *testing.T, cfg *config.Config, privateKey *ecdsa
 
 	// Convert nonce to 32-byte format for EIP-3009
 	nonce32 := hexToBytes32(nonceHex)
-	// Convert back to hex string for authorization struct
-	nonce32Hex := "0x" + common.Bytes2Hex(nonce32[:])
 
 	message := &eip3009.ReceiveWithAuthorizationMessage{
 		From:        payerAddress,
@@ -242,34 +240,12 @@ func createTestAuthorization(t *testing.T, cfg *config.Config, privateKey *ecdsa
 		VerifyingContract: common.HexToAddress(networkCfg.USDCContract),
 	}
 
-	// Compute typed data hash
-	typedDataHash, err := eip3009.TypedDataHash(domain, message)
-	if err != nil {
-		t.Fatalf("Failed to compute typed data hash: %v", err)
-	}
-
-	// Sign the hash
-	signature, err := crypto.Sign(typedDataHash.Bytes(), privateKey)
+	auth, err := eip3009.SignAuthorization(message, domain, privateKey)
 	if err != nil {
 		t.Fatalf("Failed to sign authorization: %v", err)
 	}
 
-	// Extract v, r, s from signature
-	r := common.BytesToHash(signature[0:32])
-	s := common.BytesToHash(signature[32:64])
-	v := signature[64] + 27 // Convert from 0/1 to 27/28
-
-	return &eip3009.EIP3009Authorization{
-		From:        payerAddress.Hex(),
-		To:          payToAddress,
-		Value:       amountAtomic,
-		ValidAfter:  validAfter,
-		ValidBefore: validBefore,
-		Nonce:       nonce32Hex, // Use 32-byte formatted nonce
-		V:           v,
-		R:           r.Hex(),
-		S:           s.Hex(),
-	}
+	return auth
 }
 
 // hexToBytes32 converts a hex string to [32]byte, padding if necessary
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+)
+
+// TestAuditFileStore_RoundTrip tests that records persist across store instances
+func TestAuditFileStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	records, err := audit.NewFileStore(path).Records()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("Expected no records before the first append, got %v (%v)", records, err)
+	}
+
+	if err := audit.NewFileStore(path).Append(audit.Record{Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x01", Status: "settled"}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	records, err = audit.NewFileStore(path).Records()
+	if err != nil {
+		t.Fatalf("Records failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Nonce != "0x01" || records[0].Timestamp.IsZero() {
+		t.Errorf("Expected one stamped record, got %+v", records)
+	}
+}
+
+// TestRecentSettlements tests selection of settled nonces for cache warming
+func TestRecentSettlements(t *testing.T) {
+	t0 := time.Unix(1700000000, 0)
+	at := func(seconds int) time.Time { return t0.Add(time.Duration(seconds) * time.Second) }
+
+	records := []audit.Record{
+		{Timestamp: at(0), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x01", Status: "settled"},
+		{Timestamp: at(10), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x02", Status: "pending"},
+		{Timestamp: at(20), Event: audit.EventSettlementReconciled, Network: "base", Nonce: "0x02", PreviousStatus: "pending", Status: "settled"},
+		{Timestamp: at(30), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x03", Status: "pending"},
+		{Timestamp: at(40), Event: audit.EventSettlementReceipt, Network: "arbitrum", Nonce: "0x01", Status: "settled"},
+		{Timestamp: at(50), Event: audit.EventSettlementReceipt, Network: "base", Nonce: "0x01", Status: "settled"},
+	}
+
+	settled := audit.RecentSettlements(records, at(5), 0)
+	if len(settled) != 3 {
+		t.Fatalf("Expected 3 settlements since t0+5s, got %+v", settled)
+	}
+	// Newest first, with base/0x01 at its latest record
+	if settled[0].Network != "base" || settled[0].Nonce != "0x01" || !settled[0].Timestamp.Equal(at(50)) {
+		t.Errorf("Expected base/0x01 first, got %+v", settled[0])
+	}
+	if settled[2].Nonce != "0x02" || settled[2].Event != audit.EventSettlementReconciled {
+		t.Errorf("Expected reconciled base/0x02 last, got %+v", settled[2])
+	}
+
+	if limited := audit.RecentSettlements(records, t0, 2); len(limited) != 2 || limited[1].Network != "arbitrum" {
+		t.Errorf("Expected the 2 newest settlements, got %+v", limited)
+	}
+}
@@ -1,10 +1,15 @@
 package unit
 
 import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/cache"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
 )
 
 func TestTTLCache_SetAndGet(t *testing.T) {
@@ -152,3 +157,235 @@ func TestTTLCache_CustomTTL(t *testing.T) {
 		t.Error("Key should have expired with custom TTL")
 	}
 }
+
+// TestTTLCache_EvictionAgeHistogram tests that entries of known ages land in the expected buckets
+func TestTTLCache_EvictionAgeHistogram(t *testing.T) {
+	c := cache.NewTTLCache(1 * time.Hour) // Background sweep never fires during the test
+	registry := metrics.NewRegistry()
+	c.OnEvict(cache.EvictionRecorder(registry, "test"))
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	c.SetClock(func() time.Time { return now })
+
+	c.SetWithTTL("short", "v", 2*time.Second) // Expires, evicted at age 4s
+	c.SetWithTTL("long", "v", 2*time.Hour)    // Deleted explicitly at age 100s
+	c.SetWithTTL("stale", "v", 30*time.Second)
+
+	now = start.Add(4 * time.Second)
+	if removed := c.RemoveExpired(); removed != 1 {
+		t.Fatalf("Expected 1 expired entry at 4s, got %d", removed)
+	}
+
+	now = start.Add(100 * time.Second)
+	if removed := c.RemoveExpired(); removed != 1 {
+		t.Fatalf("Expected 1 expired entry at 100s, got %d", removed)
+	}
+	c.Delete("long")
+	c.Delete("missing") // Absent keys record nothing
+
+	expired := registry.Histogram(cache.MetricEvictionAge, metrics.Labels{"cache": "test", "reason": cache.EvictionExpired})
+	if expired.Count != 2 || expired.Sum != 104 {
+		t.Fatalf("Expected 2 expired observations summing to 104s, got count=%d sum=%v", expired.Count, expired.Sum)
+	}
+
+	// Buckets: 1, 5, 15, 30, 60, 120, ... -> 4s in <=5, 100s in <=120
+	bucketFor := func(snapshot metrics.HistogramSnapshot, bound float64) uint64 {
+		for i, upper := range snapshot.Buckets {
+			if upper == bound {
+				return snapshot.Counts[i]
+			}
+		}
+		t.Fatalf("Bucket %v not found", bound)
+		return 0
+	}
+
+	if got := bucketFor(expired, 5); got != 1 {
+		t.Errorf("Expected 1 expired entry in <=5s bucket, got %d", got)
+	}
+	if got := bucketFor(expired, 120); got != 1 {
+		t.Errorf("Expected 1 expired entry in <=120s bucket, got %d", got)
+	}
+
+	deleted := registry.Histogram(cache.MetricEvictionAge, metrics.Labels{"cache": "test", "reason": cache.EvictionDeleted})
+	if deleted.Count != 1 || bucketFor(deleted, 120) != 1 {
+		t.Errorf("Expected one deleted entry aged 100s, got count=%d counts=%v", deleted.Count, deleted.Counts)
+	}
+}
+
+// TestRegistry_HistogramOverflow tests observations above the last bound land in the +Inf slot
+func TestRegistry_HistogramOverflow(t *testing.T) {
+	registry := metrics.NewRegistry()
+	buckets := []float64{1, 10}
+
+	for _, value := range []float64{0.5, 1, 10, 11, 500} {
+		registry.ObserveHistogram("test_seconds", buckets, nil, value)
+	}
+
+	snapshot := registry.Histogram("test_seconds", nil)
+	expected := []uint64{2, 1, 2}
+	for i, count := range expected {
+		if snapshot.Counts[i] != count {
+			t.Errorf("Bucket %d: expected %d, got %d", i, count, snapshot.Counts[i])
+		}
+	}
+
+	if empty := registry.Histogram("never_observed", nil); empty.Count != 0 {
+		t.Errorf("Expected empty snapshot for unknown histogram, got count %d", empty.Count)
+	}
+}
+
+// TestRegistry_HistogramExemplar tests that a trace ID exemplar is attached to the observed bucket
+func TestRegistry_HistogramExemplar(t *testing.T) {
+	registry := metrics.NewRegistry()
+	buckets := []float64{1, 10}
+	labels := metrics.Labels{"network": "base"}
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	registry.ObserveHistogram("test_seconds", buckets, labels, 0.5)
+	registry.ObserveHistogramWithExemplar("test_seconds", buckets, labels, 4.2, metrics.Labels{"trace_id": traceID})
+
+	// Exemplars over the OpenMetrics size limit are dropped; the observation still counts
+	oversized := metrics.Labels{"trace_id": strings.Repeat("a", metrics.MaxExemplarRunes)}
+	registry.ObserveHistogramWithExemplar("test_seconds", buckets, labels, 50, oversized)
+
+	snapshot := registry.Histogram("test_seconds", labels)
+	if snapshot.Count != 3 {
+		t.Fatalf("Expected 3 observations, got %d", snapshot.Count)
+	}
+
+	if snapshot.Exemplars[0] != nil {
+		t.Errorf("Expected no exemplar on a bucket observed without one, got %+v", snapshot.Exemplars[0])
+	}
+	exemplar := snapshot.Exemplars[1]
+	if exemplar == nil {
+		t.Fatal("Expected an exemplar on the (1, 10] bucket")
+	}
+	if exemplar.Labels["trace_id"] != traceID {
+		t.Errorf("Expected exemplar trace_id %s, got %v", traceID, exemplar.Labels)
+	}
+	if exemplar.Value != 4.2 || exemplar.Timestamp.IsZero() {
+		t.Errorf("Expected exemplar value 4.2 with a timestamp, got %+v", exemplar)
+	}
+	if snapshot.Exemplars[2] != nil {
+		t.Errorf("Expected oversized exemplar to be dropped, got %+v", snapshot.Exemplars[2])
+	}
+}
+
+// TestTTLCache_Stats tests hit, miss, and eviction counters
+func TestTTLCache_Stats(t *testing.T) {
+	c := cache.NewTTLCache(time.Minute)
+	now := time.Now()
+	c.SetClock(func() time.Time { return now })
+
+	c.Set("key1", "value1")
+	c.Set("key2", "value2")
+
+	if _, found := c.Get("key1"); !found {
+		t.Fatal("Expected to find key1")
+	}
+	if _, found := c.Get("missing"); found {
+		t.Fatal("Expected missing key not to be found")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Evictions != 0 || stats.Size != 2 {
+		t.Errorf("Expected 1 hit, 1 miss, 0 evictions, size 2, got %+v", stats)
+	}
+
+	// Expired entries count as misses until swept, then as evictions
+	now = now.Add(2 * time.Minute)
+	if _, found := c.Get("key2"); found {
+		t.Fatal("Expected key2 to have expired")
+	}
+	if removed := c.RemoveExpired(); removed != 2 {
+		t.Fatalf("Expected 2 expired entries removed, got %d", removed)
+	}
+
+	if c.Hits() != 1 || c.Misses() != 2 || c.Evictions() != 2 || c.Size() != 0 {
+		t.Errorf("Expected 1 hit, 2 misses, 2 evictions, size 0, got %+v", c.Stats())
+	}
+}
+
+// TestTTLCache_StatsConcurrent tests that hit and miss counts stay exact under concurrent
+// Get and Set calls; run with -race to check the counters add no data races
+func TestTTLCache_StatsConcurrent(t *testing.T) {
+	const workers = 8
+	const iterations = 500
+
+	c := cache.NewTTLCache(time.Minute)
+	defer c.Close()
+	for i := 0; i < workers; i++ {
+		c.Set(fmt.Sprintf("present-%d", i), i)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				c.Get(fmt.Sprintf("present-%d", w))
+				c.Get(fmt.Sprintf("absent-%d", w))
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				c.Set(fmt.Sprintf("written-%d-%d", w, i), i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	stats := c.Stats()
+	if stats.Hits != workers*iterations {
+		t.Errorf("Expected %d hits, got %d", workers*iterations, stats.Hits)
+	}
+	if stats.Misses != workers*iterations {
+		t.Errorf("Expected %d misses, got %d", workers*iterations, stats.Misses)
+	}
+	if stats.Size != workers+workers*iterations {
+		t.Errorf("Expected size %d, got %d", workers+workers*iterations, stats.Size)
+	}
+}
+
+// TestTTLCache_BackgroundSweep tests that the sweeper removes expired entries without a Get and stops on Close
+func TestTTLCache_BackgroundSweep(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	c := cache.NewTTLCacheWithSweep(10*time.Millisecond, 5*time.Millisecond)
+	c.Set("key1", "value1")
+	c.Set("key2", "value2")
+
+	deadline := time.Now().Add(time.Second)
+	for c.Size() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if c.Size() != 0 || c.Evictions() != 2 {
+		t.Fatalf("Expected sweeper to evict both entries, got %+v", c.Stats())
+	}
+	if c.Hits() != 0 || c.Misses() != 0 {
+		t.Errorf("Expected no lookups, got %+v", c.Stats())
+	}
+
+	c.Close()
+	c.Close() // Idempotent
+
+	deadline = time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("Expected sweeper goroutine to exit after Close: %d goroutines before, %d after", before, after)
+	}
+
+	// Without a sweeper, expired entries stay until swept on demand
+	lazy := cache.NewTTLCacheWithSweep(time.Millisecond, 0)
+	defer lazy.Close()
+	lazy.Set("key", "value")
+	time.Sleep(5 * time.Millisecond)
+	if _, found := lazy.Get("key"); found || lazy.Size() != 1 {
+		t.Errorf("Expected expired entry hidden but retained, got size %d", lazy.Size())
+	}
+}
@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+// TestEncodeReceiveWithAuthorization_Selector tests that calldata starts with the USDC selector
+func TestEncodeReceiveWithAuthorization_Selector(t *testing.T) {
+	calldata, err := eip3009.EncodeReceiveWithAuthorization(createOnChainTestAuthorization())
+	if err != nil {
+		t.Fatalf("EncodeReceiveWithAuthorization returned error: %v", err)
+	}
+
+	// receiveWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)
+	expected := []byte{0xef, 0x55, 0xbe, 0xc6}
+	if !bytes.Equal(calldata[:4], expected) {
+		t.Errorf("Expected selector 0x%x, got 0x%x", expected, calldata[:4])
+	}
+
+	// 4-byte selector + 9 static 32-byte words
+	if len(calldata) != 4+9*32 {
+		t.Errorf("Expected calldata length %d, got %d", 4+9*32, len(calldata))
+	}
+}
+
+// TestEncodeReceiveWithAuthorization_RoundTrip tests that decoding produced calldata yields the original authorization
+func TestEncodeReceiveWithAuthorization_RoundTrip(t *testing.T) {
+	auth := createOnChainTestAuthorization()
+
+	calldata, err := eip3009.EncodeReceiveWithAuthorization(auth)
+	if err != nil {
+		t.Fatalf("EncodeReceiveWithAuthorization returned error: %v", err)
+	}
+
+	decoded, err := eip3009.DecodeReceiveWithAuthorization(calldata)
+	if err != nil {
+		t.Fatalf("DecodeReceiveWithAuthorization returned error: %v", err)
+	}
+
+	if decoded.From != auth.From || decoded.To != auth.To {
+		t.Errorf("Address mismatch: got from=%s to=%s", decoded.From, decoded.To)
+	}
+	if decoded.Value != auth.Value {
+		t.Errorf("Expected value %s, got %s", auth.Value, decoded.Value)
+	}
+	if decoded.ValidAfter != auth.ValidAfter || decoded.ValidBefore != auth.ValidBefore {
+		t.Errorf("Time bounds mismatch: got %d-%d", decoded.ValidAfter, decoded.ValidBefore)
+	}
+	if decoded.Nonce != auth.Nonce {
+		t.Errorf("Expected nonce %s, got %s", auth.Nonce, decoded.Nonce)
+	}
+	if decoded.V != auth.V || decoded.R != auth.R || decoded.S != auth.S {
+		t.Errorf("Signature mismatch: got v=%d r=%s s=%s", decoded.V, decoded.R, decoded.S)
+	}
+}
+
+// TestDecodeReceiveWithAuthorization_WrongSelector tests that foreign calldata is rejected
+func TestDecodeReceiveWithAuthorization_WrongSelector(t *testing.T) {
+	calldata, err := eip3009.EncodeReceiveWithAuthorization(createOnChainTestAuthorization())
+	if err != nil {
+		t.Fatalf("EncodeReceiveWithAuthorization returned error: %v", err)
+	}
+
+	calldata[0] = 0xa9 // transfer(address,uint256)
+	if _, err := eip3009.DecodeReceiveWithAuthorization(calldata); err == nil {
+		t.Error("Expected error for non-receiveWithAuthorization calldata")
+	}
+}
@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/canonical"
+)
+
+// TestCanonicalJSON_KeyOrderIndependent tests that key order does not affect output
+func TestCanonicalJSON_KeyOrderIndependent(t *testing.T) {
+	type ordered struct {
+		Network string `json:"network"`
+		Amount  string `json:"amount"`
+		Nonce   string `json:"nonce"`
+	}
+
+	a := ordered{Network: "base", Amount: "50000", Nonce: "0x01"}
+	b := map[string]interface{}{
+		"nonce":   "0x01",
+		"amount":  "50000",
+		"network": "base",
+	}
+
+	aBytes, err := canonical.JSON(a)
+	if err != nil {
+		t.Fatalf("canonical.JSON failed: %v", err)
+	}
+
+	bBytes, err := canonical.JSON(b)
+	if err != nil {
+		t.Fatalf("canonical.JSON failed: %v", err)
+	}
+
+	expected := `{"amount":"50000","network":"base","nonce":"0x01"}`
+	if string(aBytes) != expected {
+		t.Errorf("Expected %s, got %s", expected, aBytes)
+	}
+
+	if string(aBytes) != string(bBytes) {
+		t.Errorf("Expected identical bytes, got %s and %s", aBytes, bBytes)
+	}
+
+	aHash, err := canonical.Hash(a)
+	if err != nil {
+		t.Fatalf("canonical.Hash failed: %v", err)
+	}
+
+	bHash, err := canonical.Hash(b)
+	if err != nil {
+		t.Fatalf("canonical.Hash failed: %v", err)
+	}
+
+	if aHash != bHash {
+		t.Errorf("Expected identical hashes, got %s and %s", aHash.Hex(), bHash.Hex())
+	}
+}
+
+// TestCanonicalJSON_Nested tests sorting of nested objects and preservation of numbers
+func TestCanonicalJSON_Nested(t *testing.T) {
+	value := map[string]interface{}{
+		"z": []interface{}{map[string]interface{}{"b": 1, "a": 2}},
+		"a": map[string]interface{}{"y": "<tag>", "x": uint64(18446744073709551615)},
+	}
+
+	data, err := canonical.JSON(value)
+	if err != nil {
+		t.Fatalf("canonical.JSON failed: %v", err)
+	}
+
+	expected := `{"a":{"x":18446744073709551615,"y":"<tag>"},"z":[{"a":2,"b":1}]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+// TestCanonicalJSON_DifferentValues tests that different content yields different hashes
+func TestCanonicalJSON_DifferentValues(t *testing.T) {
+	a, err := canonical.Hash(map[string]string{"amount": "50000"})
+	if err != nil {
+		t.Fatalf("canonical.Hash failed: %v", err)
+	}
+
+	b, err := canonical.Hash(map[string]string{"amount": "50001"})
+	if err != nil {
+		t.Fatalf("canonical.Hash failed: %v", err)
+	}
+
+	if a == b {
+		t.Error("Expected different hashes for different values")
+	}
+}
@@ -0,0 +1,99 @@
+package unit
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+func TestCompactProof_RoundTrip(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+	}
+	var nonce [32]byte
+	copy(nonce[:], crypto.Keccak256([]byte("compact")))
+
+	value, _ := new(big.Int).SetString("115792089237316195423570985008687907853269984665640564039457584007913129639935", 10)
+	for _, amount := range []*big.Int{big.NewInt(1), big.NewInt(50000), value} {
+		auth, err := eip3009.SignAuthorization(&eip3009.ReceiveWithAuthorizationMessage{
+			From:        crypto.PubkeyToAddress(privateKey.PublicKey),
+			To:          common.HexToAddress("0x2222222222222222222222222222222222222222"),
+			Value:       amount,
+			ValidAfter:  big.NewInt(1700000000),
+			ValidBefore: big.NewInt(1700003600),
+			Nonce:       nonce,
+		}, domain, privateKey)
+		if err != nil {
+			t.Fatalf("Failed to sign authorization: %v", err)
+		}
+
+		encoded, err := eip3009.EncodeCompact(auth, "base-sepolia")
+		if err != nil {
+			t.Fatalf("EncodeCompact failed: %v", err)
+		}
+		if len(encoded) != 187+len("base-sepolia") {
+			t.Errorf("Expected %d bytes, got %d", 187+len("base-sepolia"), len(encoded))
+		}
+
+		decoded, network, err := eip3009.DecodeCompact(encoded)
+		if err != nil {
+			t.Fatalf("DecodeCompact failed: %v", err)
+		}
+		if network != "base-sepolia" {
+			t.Errorf("Expected network base-sepolia, got %s", network)
+		}
+		if !reflect.DeepEqual(decoded, auth) {
+			t.Errorf("Round trip mismatch:\n got %+v\nwant %+v", decoded, auth)
+		}
+	}
+}
+
+func TestCompactProof_Invalid(t *testing.T) {
+	valid := &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1,
+		ValidBefore: 2,
+		Nonce:       "0x0000000000000000000000000000000000000000000000000000000000000001",
+		V:           27,
+		R:           "0x0000000000000000000000000000000000000000000000000000000000000001",
+		S:           "0x0000000000000000000000000000000000000000000000000000000000000001",
+	}
+
+	if _, err := eip3009.EncodeCompact(valid, ""); err == nil {
+		t.Error("Expected error for empty network")
+	}
+	tooLarge := *valid
+	tooLarge.Value = "115792089237316195423570985008687907853269984665640564039457584007913129639936"
+	if _, err := eip3009.EncodeCompact(&tooLarge, "base"); err == nil {
+		t.Error("Expected error for value above uint256")
+	}
+
+	encoded, err := eip3009.EncodeCompact(valid, "base")
+	if err != nil {
+		t.Fatalf("EncodeCompact failed: %v", err)
+	}
+
+	wrongVersion := append([]byte{}, encoded...)
+	wrongVersion[0] = 2
+	truncated := encoded[:len(encoded)-1]
+	trailing := append(append([]byte{}, encoded...), 0)
+	for name, data := range map[string][]byte{"version": wrongVersion, "truncated": truncated, "trailing": trailing, "short": encoded[:10]} {
+		if _, _, err := eip3009.DecodeCompact(data); err == nil {
+			t.Errorf("Expected error for %s proof", name)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package unit
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
 )
 
 func TestLoadConfig_HappyPath(t *testing.T) {
@@ -115,7 +119,7 @@ func TestNetworkConfig_Validate_MalformedAddress(t *testing.T) {
 }
 
 func TestNetworkConfig_Validate_AllowedChainIDs(t *testing.T) {
-	allowedIDs := []uint64{8453, 84532, 42161}
+	allowedIDs := []uint64{8453, 84532, 42161, 421614, 10}
 
 	for _, chainID := range allowedIDs {
 		nc := config.NetworkConfig{
@@ -131,3 +135,950 @@ func TestNetworkConfig_Validate_AllowedChainIDs(t *testing.T) {
 		}
 	}
 }
+
+func TestDomainChangedNetworks(t *testing.T) {
+	base := config.NetworkConfig{ChainID: 8453, USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"}
+	sepolia := config.NetworkConfig{ChainID: 84532, USDCContract: "0x036CbD53842c5426634e7929541eC2318f3dCF7e"}
+
+	oldCfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{"base": base, "base-sepolia": sepolia},
+		EIP712:   config.EIP712Config{DomainName: "USD Coin", DomainVersion: "2"},
+	}
+
+	// Only base-sepolia's contract changes (payee changes don't affect the domain)
+	newSepolia := sepolia
+	newSepolia.USDCContract = "0x1111111111111111111111111111111111111111"
+	newBase := base
+	newBase.PayeeAddress = "0x2222222222222222222222222222222222222222"
+	newCfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{"base": newBase, "base-sepolia": newSepolia},
+		EIP712:   oldCfg.EIP712,
+	}
+
+	changed := config.DomainChangedNetworks(oldCfg, newCfg)
+	if len(changed) != 1 || changed[0] != "base-sepolia" {
+		t.Errorf("Expected [base-sepolia], got %v", changed)
+	}
+
+	// A global domain change affects every network
+	newCfg.EIP712.DomainVersion = "1"
+	changed = config.DomainChangedNetworks(oldCfg, newCfg)
+	if len(changed) != 2 {
+		t.Errorf("Expected both networks affected, got %v", changed)
+	}
+}
+
+func TestConfig_Validate_MaxInFlight(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: "https://api.cdp.coinbase.com",
+				RPCURL:         "https://mainnet.base.org",
+				PayeeAddress:   "0x1234567890123456789012345678901234567890",
+			},
+		},
+		EIP712: config.EIP712Config{DomainName: "USD Coin", DomainVersion: "2"},
+		Cache:  config.CacheConfig{SettlementTTLMinutes: 10},
+	}
+
+	cfg.Settlement.MaxInFlight = map[string]int{"base": 4}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid max_in_flight, got: %v", err)
+	}
+
+	if cfg.Settlement.QueueTimeout() != config.DefaultQueueTimeout {
+		t.Errorf("Expected default queue timeout, got %v", cfg.Settlement.QueueTimeout())
+	}
+
+	cfg.Settlement.MaxInFlight = map[string]int{"polygon": 4}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for max_in_flight on unknown network")
+	}
+
+	cfg.Settlement.MaxInFlight = map[string]int{"base": -1}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative max_in_flight")
+	}
+}
+
+// TestConfig_ResolveByAsset tests network resolution from chain ID and asset address
+func TestConfig_ResolveByAsset(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base":         {ChainID: 8453, USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"},
+			"base-sepolia": {ChainID: 84532, USDCContract: "0x036CbD53842c5426634e7929541eC2318f3dCF7e"},
+		},
+	}
+
+	if network, ok := cfg.ResolveByAsset(8453, "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913"); !ok || network != "base" {
+		t.Errorf("Expected USDC on 8453 to resolve to base, got %q (ok=%v)", network, ok)
+	}
+
+	if _, ok := cfg.ResolveByAsset(8453, "0x0000000000000000000000000000000000000bad"); ok {
+		t.Error("Expected unknown asset not to resolve")
+	}
+
+	if _, ok := cfg.ResolveByAsset(42161, "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"); ok {
+		t.Error("Expected asset on unconfigured chain not to resolve")
+	}
+
+	// Two networks sharing chain and asset are ambiguous
+	cfg.Networks["base-alt"] = cfg.Networks["base"]
+	if network, ok := cfg.ResolveByAsset(8453, "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"); ok {
+		t.Errorf("Expected ambiguous match to be rejected, got %q", network)
+	}
+}
+
+// TestConfig_DomainParams_PerChainDefaults tests the built-in USDC domain applied when config doesn't override
+func TestConfig_DomainParams_PerChainDefaults(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base":         {ChainID: 8453},
+			"base-sepolia": {ChainID: 84532},
+			"arbitrum":     {ChainID: 42161},
+			"polygon":      {ChainID: 137},
+			"unknown":      {ChainID: 999999},
+		},
+	}
+
+	tests := []struct {
+		network string
+		name    string
+		version string
+	}{
+		{"base", "USD Coin", "2"},
+		{"base-sepolia", "USDC", "2"},
+		{"arbitrum", "USD Coin", "2"},
+		{"polygon", "USD Coin", "2"},
+	}
+
+	for _, tt := range tests {
+		params, err := cfg.DomainParams(tt.network)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.network, err)
+			continue
+		}
+		if params.Name != tt.name || params.Version != tt.version {
+			t.Errorf("%s: expected %s/%s, got %s/%s", tt.network, tt.name, tt.version, params.Name, params.Version)
+		}
+	}
+
+	if _, err := cfg.DomainParams("unknown"); err == nil {
+		t.Error("Expected error for chain without a built-in domain")
+	}
+
+	// Global eip712 section overrides the table, and a per-network domain overrides both
+	cfg.EIP712 = config.EIP712Config{DomainName: "Global Coin", DomainVersion: "3"}
+	cfg.Networks["arbitrum"] = config.NetworkConfig{ChainID: 42161, DomainName: "Bridged USDC", DomainVersion: "1"}
+
+	if params, _ := cfg.DomainParams("base-sepolia"); params.Name != "Global Coin" || params.Version != "3" {
+		t.Errorf("Expected global domain to override table, got %s/%s", params.Name, params.Version)
+	}
+	if params, _ := cfg.DomainParams("arbitrum"); params.Name != "Bridged USDC" || params.Version != "1" {
+		t.Errorf("Expected network domain to override global, got %s/%s", params.Name, params.Version)
+	}
+}
+
+// TestConfig_DomainParams_FieldFallback tests that domain name and version resolve separately,
+// so either may be overridden alone
+func TestConfig_DomainParams_FieldFallback(t *testing.T) {
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base-sepolia": {
+					ChainID:        84532,
+					USDCContract:   "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+					FacilitatorURL: "https://x402.org/facilitator",
+					RPCURL:         "https://sepolia.base.org",
+					PayeeAddress:   "0x1234567890123456789012345678901234567890",
+				},
+			},
+			Cache: config.CacheConfig{SettlementTTLMinutes: 10},
+		}
+	}
+
+	if err := newConfig().Validate(); err != nil {
+		t.Errorf("Expected config without eip712 section to fall back to built-in domain, got: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		globalName    string
+		globalVersion string
+		netName       string
+		netVersion    string
+		wantName      string
+		wantVersion   string
+	}{
+		{"global name only", "USD Coin", "", "", "", "USD Coin", "2"},
+		{"global version only", "", "3", "", "", "USDC", "3"},
+		{"network version only", "", "", "", "1", "USDC", "1"},
+		{"network version over global name", "Global Coin", "", "", "4", "Global Coin", "4"},
+		{"network name over global pair", "Global Coin", "5", "Bridged USDC", "", "Bridged USDC", "5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newConfig()
+			cfg.EIP712 = config.EIP712Config{DomainName: tt.globalName, DomainVersion: tt.globalVersion}
+			network := cfg.Networks["base-sepolia"]
+			network.DomainName = tt.netName
+			network.DomainVersion = tt.netVersion
+			cfg.Networks["base-sepolia"] = network
+
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("Expected a partial domain override to be valid, got: %v", err)
+			}
+			params, err := cfg.DomainParams("base-sepolia")
+			if err != nil {
+				t.Fatalf("DomainParams failed: %v", err)
+			}
+			if params.Name != tt.wantName || params.Version != tt.wantVersion {
+				t.Errorf("Expected %s/%s, got %s/%s", tt.wantName, tt.wantVersion, params.Name, params.Version)
+			}
+		})
+	}
+
+	// A chain without a built-in domain needs both fields from some source
+	cfg := newConfig()
+	network := cfg.Networks["base-sepolia"]
+	network.ChainID = 999999
+	network.DomainName = "Custom USDC"
+	cfg.Networks["base-sepolia"] = network
+	if _, err := cfg.DomainParams("base-sepolia"); err == nil || !strings.Contains(err.Error(), "domain_version") {
+		t.Errorf("Expected error naming the missing domain_version, got %v", err)
+	}
+
+	cfg.EIP712.DomainVersion = "1"
+	if params, err := cfg.DomainParams("base-sepolia"); err != nil || params.Name != "Custom USDC" || params.Version != "1" {
+		t.Errorf("Expected Custom USDC/1 from network name and global version, got %+v (%v)", params, err)
+	}
+}
+
+// TestConfig_Validate_PendingTTL tests the pending/failed TTL bounds
+func TestConfig_Validate_PendingTTL(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: "https://api.cdp.coinbase.com",
+				RPCURL:         "https://mainnet.base.org",
+				PayeeAddress:   "0x1234567890123456789012345678901234567890",
+			},
+		},
+		Cache: config.CacheConfig{SettlementTTLMinutes: 10, PendingTTLSeconds: 30},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid pending TTL, got: %v", err)
+	}
+
+	cfg.Cache.PendingTTLSeconds = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative pending_ttl_seconds")
+	}
+
+	cfg.Cache.PendingTTLSeconds = 601
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for pending TTL longer than settled TTL")
+	}
+}
+
+// TestNetworkConfig_ExplorerURL tests explorer link composition and validation
+func TestNetworkConfig_ExplorerURL(t *testing.T) {
+	network := config.NetworkConfig{
+		ChainID:        42161,
+		USDCContract:   "0xaf88d065e77c8cC2239327C5EDb3A432268e5831",
+		FacilitatorURL: "https://api.cdp.coinbase.com",
+		RPCURL:         "https://arb1.arbitrum.io/rpc",
+		PayeeAddress:   "0x1234567890123456789012345678901234567890",
+	}
+
+	if link := network.TxExplorerURL("0xabc"); link != "" {
+		t.Errorf("Expected no link without explorer_url, got %s", link)
+	}
+
+	network.ExplorerURL = "https://arbiscan.io/"
+	if link := network.TxExplorerURL("0xabc"); link != "https://arbiscan.io/tx/0xabc" {
+		t.Errorf("Expected https://arbiscan.io/tx/0xabc, got %s", link)
+	}
+	if link := network.TxExplorerURL(""); link != "" {
+		t.Errorf("Expected no link without tx hash, got %s", link)
+	}
+
+	network.ExplorerURL = "arbiscan.io"
+	if err := network.Validate(); err == nil {
+		t.Error("Expected error for explorer_url without http(s) scheme")
+	}
+}
+
+// TestLoadConfig_SchemaErrors tests that every unknown key and wrongly typed value is reported by key path
+func TestLoadConfig_SchemaErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+networks:
+  base:
+    chain_id: "eight"
+    usdc_contract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+    facilitator_url: "https://api.cdp.coinbase.com"
+    rpc_url: "https://mainnet.base.org"
+    payee_adress: "0x1234567890123456789012345678901234567890"
+
+cache:
+  settlement_ttl_minutes: 10
+  grace_ms: [100]
+
+allow_private_urls: "yes"
+loging:
+  level: "INFO"
+`
+
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := config.LoadConfig(configPath)
+	if err == nil {
+		t.Fatal("Expected LoadConfig to reject the config")
+	}
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got %T: %v", err, err)
+	}
+	if len(validationErr.Problems) != 5 {
+		t.Errorf("Expected 5 problems, got %d: %v", len(validationErr.Problems), err)
+	}
+
+	for _, expected := range []string{
+		"networks.base.chain_id (line 4): expected a non-negative integer, got string \"eight\"",
+		"networks.base.payee_adress (line 8): unknown key",
+		"cache.grace_ms (line 12): expected an integer, got a list",
+		"allow_private_urls (line 14): expected true or false",
+		"loging (line 15): unknown key",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got:\n%v", expected, err)
+		}
+	}
+}
+
+// TestLoadConfig_Example tests that the shipped example passes the strict schema check
+func TestLoadConfig_Example(t *testing.T) {
+	if _, err := config.LoadConfig("../../config.yaml.example"); err != nil {
+		t.Fatalf("LoadConfig(config.yaml.example) failed: %v", err)
+	}
+}
+
+// TestLoadConfig_ShippedDomains tests that the shipped configs leave each network on its
+// built-in USDC domain rather than shadowing it with a global eip712 section
+func TestLoadConfig_ShippedDomains(t *testing.T) {
+	for _, path := range []string{"../../config.yaml", "../../config.yaml.example"} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			cfg, err := config.LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig(%s) failed: %v", path, err)
+			}
+
+			params, err := cfg.DomainParams("base-sepolia")
+			if err != nil {
+				t.Fatalf("DomainParams(base-sepolia) failed: %v", err)
+			}
+			if params.Name != "USDC" || params.Version != "2" {
+				t.Errorf("Expected base-sepolia domain USDC/2, got %s/%s", params.Name, params.Version)
+			}
+		})
+	}
+}
+
+// TestConfig_Validate_ReportsAllProblems tests that Validate lists every problem rather than the first
+func TestConfig_Validate_ReportsAllProblems(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: "https://api.cdp.coinbase.com",
+				RPCURL:         "https://mainnet.base.org",
+				PayeeAddress:   "0x1234567890123456789012345678901234567890",
+			},
+		},
+		Cache:        config.CacheConfig{SettlementTTLMinutes: 0, GraceMs: -1},
+		Verification: config.VerificationConfig{AddressFormat: "base58"},
+		Retry:        config.RetryConfig{Jitter: "some"},
+	}
+
+	err := cfg.Validate()
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected *config.ValidationError, got %T: %v", err, err)
+	}
+
+	for _, expected := range []string{
+		"cache.settlement_ttl_minutes",
+		"cache.grace_ms",
+		"verification.address_format",
+		"retry.jitter",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to mention %s, got:\n%v", expected, err)
+		}
+	}
+	if len(validationErr.Problems) != 4 {
+		t.Errorf("Expected 4 problems, got %d: %v", len(validationErr.Problems), err)
+	}
+}
+
+// TestConfig_Environment tests environment validation and mainnet classification
+func TestConfig_Environment(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base":         {ChainID: 8453},
+			"base-sepolia": {ChainID: 84532},
+			"arbitrum":     {ChainID: 42161},
+			"custom":       {ChainID: 999999}, // Unknown chains are treated as mainnet
+		},
+		Environment: config.EnvironmentTest,
+	}
+
+	if !cfg.TestMode() {
+		t.Error("Expected test mode")
+	}
+
+	mainnets := cfg.MainnetNetworks()
+	expected := []string{"arbitrum", "base", "custom"}
+	if strings.Join(mainnets, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected mainnet networks %v, got %v", expected, mainnets)
+	}
+
+	if config.ValidEnvironment("staging") {
+		t.Error("Expected 'staging' to be rejected")
+	}
+	for _, env := range []string{"", config.EnvironmentProduction, config.EnvironmentTest} {
+		if !config.ValidEnvironment(env) {
+			t.Errorf("Expected environment %q to be valid", env)
+		}
+	}
+}
+
+// TestNetworkConfig_BlockTime tests block time resolution for settlement estimates
+func TestNetworkConfig_BlockTime(t *testing.T) {
+	network := config.NetworkConfig{ChainID: 42161}
+	if blockTime := network.BlockTime(); blockTime != 250*time.Millisecond {
+		t.Errorf("Expected built-in Arbitrum block time 250ms, got %v", blockTime)
+	}
+
+	network.BlockTimeSeconds = 0.5
+	if blockTime := network.BlockTime(); blockTime != 500*time.Millisecond {
+		t.Errorf("Expected configured block time 500ms, got %v", blockTime)
+	}
+
+	unknown := config.NetworkConfig{ChainID: 999999}
+	if blockTime := unknown.BlockTime(); blockTime != config.DefaultBlockTime {
+		t.Errorf("Expected default block time %v for unknown chain, got %v", config.DefaultBlockTime, blockTime)
+	}
+}
+
+// TestNetworkConfig_AssetDecimals tests asset decimal defaults and validation
+func TestNetworkConfig_AssetDecimals(t *testing.T) {
+	network := config.NetworkConfig{
+		ChainID:        8453,
+		USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		FacilitatorURL: "https://api.cdp.coinbase.com",
+		RPCURL:         "https://mainnet.base.org",
+		PayeeAddress:   "0x1234567890123456789012345678901234567890",
+	}
+	if decimals := network.Decimals(); decimals != units.USDCDecimals {
+		t.Errorf("Expected USDC default of %d decimals, got %d", units.USDCDecimals, decimals)
+	}
+
+	network.AssetDecimals = 18
+	if decimals := network.Decimals(); decimals != 18 {
+		t.Errorf("Expected 18 decimals, got %d", decimals)
+	}
+	if err := network.Validate(); err != nil {
+		t.Errorf("Expected 18 decimals to be valid, got %v", err)
+	}
+
+	cfg := &config.Config{Networks: map[string]config.NetworkConfig{"base": network}}
+	if decimals := cfg.AssetDecimals("base"); decimals != 18 {
+		t.Errorf("Expected base to use 18 decimals, got %d", decimals)
+	}
+	if decimals := cfg.AssetDecimals("unknown"); decimals != units.USDCDecimals {
+		t.Errorf("Expected unconfigured network to use the USDC default, got %d", decimals)
+	}
+
+	for _, invalid := range []int{-1, config.MaxAssetDecimals + 1} {
+		network.AssetDecimals = invalid
+		if err := network.Validate(); err == nil {
+			t.Errorf("Expected asset_decimals %d to be rejected", invalid)
+		}
+	}
+}
+
+// TestLimitsConfig_ToolTimeout tests per-tool timeout resolution
+func TestLimitsConfig_ToolTimeout(t *testing.T) {
+	limits := config.LimitsConfig{}
+	if timeout := limits.ToolTimeout("settle_payment"); timeout != config.DefaultToolTimeout {
+		t.Errorf("Expected default timeout %v, got %v", config.DefaultToolTimeout, timeout)
+	}
+
+	limits.DefaultToolTimeoutMs = 30000
+	limits.ToolTimeouts = map[string]int{"settle_payment": 5000}
+	if timeout := limits.ToolTimeout("settle_payment"); timeout != 5*time.Second {
+		t.Errorf("Expected configured timeout 5s, got %v", timeout)
+	}
+	if timeout := limits.ToolTimeout("verify_payment"); timeout != 30*time.Second {
+		t.Errorf("Expected default_tool_timeout_ms 30s, got %v", timeout)
+	}
+}
+
+// TestConfig_Validate_RetryJitter tests retry jitter mode validation
+func TestConfig_Validate_RetryJitter(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: "https://api.cdp.coinbase.com",
+				RPCURL:         "https://mainnet.base.org",
+				PayeeAddress:   "0x1234567890123456789012345678901234567890",
+			},
+		},
+		Cache: config.CacheConfig{SettlementTTLMinutes: 10},
+		Retry: config.RetryConfig{MaxRetries: 3},
+	}
+
+	for _, mode := range []string{"", config.JitterFull, config.JitterNone} {
+		cfg.Retry.Jitter = mode
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Expected jitter %q to be valid, got: %v", mode, err)
+		}
+	}
+
+	cfg.Retry.Jitter = "decorrelated"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for unsupported jitter mode")
+	}
+
+	cfg.Retry.Jitter = ""
+	cfg.Retry.MaxRetries = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative max_retries")
+	}
+}
+
+// TestConfig_CanonicalNetwork tests case-insensitive network name resolution
+func TestConfig_CanonicalNetwork(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base":         {ChainID: 8453},
+			"base-sepolia": {ChainID: 84532},
+		},
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"base", "base", true},
+		{"Base", "base", true},
+		{"BASE", "base", true},
+		{"base-Sepolia", "base-sepolia", true},
+		{" base ", "base", true},
+		{"Polygon", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := cfg.CanonicalNetwork(tt.input)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("CanonicalNetwork(%q) = (%q, %v), expected (%q, %v)", tt.input, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+// TestConfig_Validate_FacilitatorAttestation tests facilitator_public_key format and require_attestation coverage
+func TestConfig_Validate_FacilitatorAttestation(t *testing.T) {
+	newConfig := func(key string) *config.Config {
+		return &config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base-sepolia": {
+					ChainID:              84532,
+					USDCContract:         "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+					FacilitatorURL:       "https://x402.org/facilitator",
+					RPCURL:               "https://sepolia.base.org",
+					PayeeAddress:         "0x1234567890123456789012345678901234567890",
+					FacilitatorPublicKey: key,
+				},
+			},
+			Cache: config.CacheConfig{SettlementTTLMinutes: 10},
+		}
+	}
+
+	compressed := "0x02" + strings.Repeat("ab", 32)
+	if err := newConfig(compressed).Validate(); err != nil {
+		t.Errorf("Expected compressed public key to be accepted, got: %v", err)
+	}
+	if err := newConfig("0x04" + strings.Repeat("cd", 64)).Validate(); err != nil {
+		t.Errorf("Expected uncompressed public key to be accepted, got: %v", err)
+	}
+	if err := newConfig("0x1234567890123456789012345678901234567890").Validate(); err == nil {
+		t.Error("Expected error for an address given as facilitator_public_key")
+	}
+
+	cfg := newConfig("")
+	cfg.Settlement.RequireAttestation = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for require_attestation without facilitator_public_key")
+	}
+
+	cfg = newConfig(compressed)
+	cfg.Settlement.RequireAttestation = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected require_attestation with a key to be valid, got: %v", err)
+	}
+}
+
+// TestConfig_Validate_AcceptedSchemes tests that only schemes the server implements may be accepted
+func TestConfig_Validate_AcceptedSchemes(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base-sepolia": {
+				ChainID:        84532,
+				USDCContract:   "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+				FacilitatorURL: "https://x402.org/facilitator",
+				RPCURL:         "https://sepolia.base.org",
+				PayeeAddress:   "0x1234567890123456789012345678901234567890",
+			},
+		},
+		Cache: config.CacheConfig{SettlementTTLMinutes: 10},
+	}
+
+	if !cfg.Verification.AcceptsScheme("exact") || cfg.Verification.AcceptsScheme("upto") {
+		t.Error("Expected only exact to be accepted by default")
+	}
+
+	cfg.Verification.AcceptedSchemes = []string{"exact"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected exact to be a valid accepted scheme, got: %v", err)
+	}
+
+	cfg.Verification.AcceptedSchemes = []string{"exact", "upto"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for a scheme the server does not implement")
+	}
+}
+
+// TestConfig_Validate_FacilitatorOverride tests that facilitator overrides are refused outside the test environment
+func TestConfig_Validate_FacilitatorOverride(t *testing.T) {
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base-sepolia": {
+				ChainID:        84532,
+				USDCContract:   "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+				FacilitatorURL: "https://x402.org/facilitator",
+				RPCURL:         "https://sepolia.base.org",
+				PayeeAddress:   "0x1234567890123456789012345678901234567890",
+			},
+		},
+		Cache:      config.CacheConfig{SettlementTTLMinutes: 10},
+		Settlement: config.SettlementConfig{AllowFacilitatorOverride: true},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for allow_facilitator_override in production")
+	}
+
+	cfg.Environment = config.EnvironmentTest
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected allow_facilitator_override to be valid in the test environment, got: %v", err)
+	}
+}
+
+// TestConfig_Validate_Profile tests verification profile validation
+func TestConfig_Validate_Profile(t *testing.T) {
+	for _, tt := range []struct {
+		profile string
+		valid   bool
+	}{
+		{"", true},
+		{config.ProfileLenient, true},
+		{config.ProfileStrict, true},
+		{"paranoid", false},
+	} {
+		cfg := &config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base": {
+					ChainID:        8453,
+					USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+					FacilitatorURL: "https://api.cdp.coinbase.com",
+					RPCURL:         "https://mainnet.base.org",
+					PayeeAddress:   "0x1234567890123456789012345678901234567890",
+				},
+			},
+			Cache:        config.CacheConfig{SettlementTTLMinutes: 10},
+			Verification: config.VerificationConfig{Profile: tt.profile},
+		}
+
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("profile %q: expected valid=%v, got error %v", tt.profile, tt.valid, err)
+		}
+	}
+}
+
+// TestConfig_Validate_WeakNonce tests weak nonce mode and threshold validation
+func TestConfig_Validate_WeakNonce(t *testing.T) {
+	for _, tt := range []struct {
+		mode      string
+		threshold int
+		valid     bool
+	}{
+		{"", 0, true},
+		{config.WeakNonceWarn, 0, true},
+		{config.WeakNonceReject, 20, true},
+		{config.WeakNonceOff, 32, true},
+		{"block", 0, false},
+		{config.WeakNonceWarn, 33, false},
+		{config.WeakNonceWarn, -1, false},
+	} {
+		cfg := &config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base": {
+					ChainID:        8453,
+					USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+					FacilitatorURL: "https://api.cdp.coinbase.com",
+					RPCURL:         "https://mainnet.base.org",
+					PayeeAddress:   "0x1234567890123456789012345678901234567890",
+				},
+			},
+			Cache: config.CacheConfig{SettlementTTLMinutes: 10},
+			Verification: config.VerificationConfig{
+				WeakNonce:                 tt.mode,
+				WeakNonceMinDistinctBytes: tt.threshold,
+			},
+		}
+
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("weak_nonce %q threshold %d: expected valid=%v, got error %v", tt.mode, tt.threshold, tt.valid, err)
+		}
+	}
+}
+
+// TestConfig_Validate_WarmCache tests that cache warming requires a persisted audit trail
+func TestConfig_Validate_WarmCache(t *testing.T) {
+	for _, tt := range []struct {
+		audit config.AuditConfig
+		valid bool
+	}{
+		{config.AuditConfig{}, true},
+		{config.AuditConfig{Path: "audit.jsonl", WarmCacheMinutes: 60}, true},
+		{config.AuditConfig{WarmCacheMinutes: 60}, false},
+		{config.AuditConfig{Path: "audit.jsonl", WarmCacheMinutes: -1}, false},
+		{config.AuditConfig{Path: "audit.jsonl", WarmCacheMinutes: 60, WarmCacheMaxEntries: -1}, false},
+	} {
+		cfg := &config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base": {
+					ChainID:        8453,
+					USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+					FacilitatorURL: "https://api.cdp.coinbase.com",
+					RPCURL:         "https://mainnet.base.org",
+					PayeeAddress:   "0x1234567890123456789012345678901234567890",
+				},
+			},
+			Cache: config.CacheConfig{SettlementTTLMinutes: 10},
+			Audit: tt.audit,
+		}
+
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("audit %+v: expected valid=%v, got error %v", tt.audit, tt.valid, err)
+		}
+	}
+}
+
+// TestConfig_Validate_MetricsPort tests metrics port bounds and the default listen address
+func TestConfig_Validate_MetricsPort(t *testing.T) {
+	for _, tt := range []struct {
+		metrics config.MetricsConfig
+		valid   bool
+		addr    string
+	}{
+		{config.MetricsConfig{}, true, ":9402"},
+		{config.MetricsConfig{Enabled: true, Port: 9100}, true, ":9100"},
+		{config.MetricsConfig{Enabled: true, Port: -1}, false, ""},
+		{config.MetricsConfig{Enabled: true, Port: 65536}, false, ""},
+	} {
+		cfg := &config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base": {
+					ChainID:        8453,
+					USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+					FacilitatorURL: "https://api.cdp.coinbase.com",
+					RPCURL:         "https://mainnet.base.org",
+					PayeeAddress:   "0x1234567890123456789012345678901234567890",
+				},
+			},
+			Cache:   config.CacheConfig{SettlementTTLMinutes: 10},
+			Metrics: tt.metrics,
+		}
+
+		if err := cfg.Validate(); (err == nil) != tt.valid {
+			t.Errorf("metrics %+v: expected valid=%v, got error %v", tt.metrics, tt.valid, err)
+		}
+		if tt.valid && cfg.Metrics.ListenAddr() != tt.addr {
+			t.Errorf("metrics %+v: expected listen address %s, got %s", tt.metrics, tt.addr, cfg.Metrics.ListenAddr())
+		}
+	}
+}
+
+// TestConfig_Validate_PayeeAllowlist tests payee policy and allowlist address validation
+func TestConfig_Validate_PayeeAllowlist(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      string
+		allowlist   []string
+		expectError bool
+	}{
+		{"disabled", "", nil, false},
+		{"warn with contract", config.PayeePolicyWarn, []string{"0x1111111111111111111111111111111111111111"}, false},
+		{"unknown policy", "allow", nil, true},
+		{"malformed address", config.PayeePolicyReject, []string{"0x1234"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Networks: map[string]config.NetworkConfig{
+					"base": {
+						ChainID:        8453,
+						USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+						FacilitatorURL: "https://api.cdp.coinbase.com",
+						RPCURL:         "https://mainnet.base.org",
+						PayeeAddress:   "0x1234567890123456789012345678901234567890",
+					},
+				},
+				Cache: config.CacheConfig{SettlementTTLMinutes: 10},
+				Verification: config.VerificationConfig{
+					PayeePolicy:    tt.policy,
+					PayeeAllowlist: tt.allowlist,
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.expectError && err == nil {
+				t.Error("Expected validation error")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected validation error: %v", err)
+			}
+		})
+	}
+}
+
+// TestConfig_CheckSecrets tests that secrets required by enabled modes are reported when unset
+func TestConfig_CheckSecrets(t *testing.T) {
+	tests := []struct {
+		name        string
+		settlement  config.SettlementConfig
+		env         map[string]string
+		missing     string // Env var the error must name ("" = no error)
+		notRequired string // Env var the error must not name
+	}{
+		{
+			name:        "facilitator mode needs no relayer key",
+			settlement:  config.SettlementConfig{RelayerKeyEnv: "RELAYER_PRIVATE_KEY"},
+			notRequired: "RELAYER_PRIVATE_KEY",
+		},
+		{
+			name:       "missing relayer key",
+			settlement: config.SettlementConfig{Mode: config.SettlementModeOnChain, RelayerKeyEnv: "RELAYER_PRIVATE_KEY"},
+			missing:    "RELAYER_PRIVATE_KEY",
+		},
+		{
+			name:       "empty relayer key",
+			settlement: config.SettlementConfig{Mode: config.SettlementModeOnChain, RelayerKeyEnv: "RELAYER_PRIVATE_KEY"},
+			env:        map[string]string{"RELAYER_PRIVATE_KEY": ""},
+			missing:    "RELAYER_PRIVATE_KEY",
+		},
+		{
+			name:       "missing api key",
+			settlement: config.SettlementConfig{FacilitatorAPIKeyEnv: "X402_FACILITATOR_API_KEY"},
+			missing:    "X402_FACILITATOR_API_KEY",
+		},
+		{
+			name: "all present",
+			settlement: config.SettlementConfig{
+				Mode:                 config.SettlementModeOnChain,
+				RelayerKeyEnv:        "RELAYER_PRIVATE_KEY",
+				FacilitatorAPIKeyEnv: "X402_FACILITATOR_API_KEY",
+			},
+			env: map[string]string{"RELAYER_PRIVATE_KEY": "0xabc", "X402_FACILITATOR_API_KEY": "key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Settlement: tt.settlement}
+			lookup := func(name string) (string, bool) {
+				value, ok := tt.env[name]
+				return value, ok
+			}
+
+			err := cfg.CheckSecrets(lookup)
+			if tt.missing == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.missing) {
+				t.Errorf("Expected error naming %s, got %v", tt.missing, err)
+			}
+			if tt.notRequired != "" && err != nil && strings.Contains(err.Error(), tt.notRequired) {
+				t.Errorf("Did not expect %s to be required, got %v", tt.notRequired, err)
+			}
+		})
+	}
+}
+
+// TestConfig_Validate_RefundExcessNeedsQueue tests that accept_and_refund_excess requires a refund queue
+func TestConfig_Validate_RefundExcessNeedsQueue(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		path        string
+		expectError bool
+	}{
+		{"without refunds.path", "", true},
+		{"with refunds.path", "refunds.jsonl", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Networks: map[string]config.NetworkConfig{
+					"base": {
+						ChainID:        8453,
+						USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+						FacilitatorURL: "https://api.cdp.coinbase.com",
+						RPCURL:         "https://mainnet.base.org",
+						PayeeAddress:   "0x1234567890123456789012345678901234567890",
+					},
+				},
+				Cache:        config.CacheConfig{SettlementTTLMinutes: 10},
+				Verification: config.VerificationConfig{Overpayment: config.OverpaymentAcceptAndRefundExcess},
+				Refunds:      config.RefundsConfig{Path: tt.path},
+			}
+
+			err := cfg.Validate()
+			if tt.expectError && (err == nil || !strings.Contains(err.Error(), "refunds.path")) {
+				t.Errorf("Expected refunds.path error, got %v", err)
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Unexpected validation error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+)
+
+// mockTokenReader answers ERC-20 name() and decimals() calls
+type mockTokenReader struct {
+	name     string
+	decimals uint8
+	revert   bool
+}
+
+func (m *mockTokenReader) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if m.revert {
+		return nil, fmt.Errorf("execution reverted: Pausable: paused")
+	}
+
+	switch {
+	case bytes.Equal(call.Data, crypto.Keccak256([]byte("name()"))[:4]):
+		// ABI string: offset word, length word, right-padded data
+		data := make([]byte, 32*3)
+		data[31] = 0x20
+		data[63] = byte(len(m.name))
+		copy(data[64:], m.name)
+		return data, nil
+	case bytes.Equal(call.Data, crypto.Keccak256([]byte("decimals()"))[:4]):
+		return common.LeftPadBytes([]byte{m.decimals}, 32), nil
+	default:
+		return nil, fmt.Errorf("unexpected call 0x%x", call.Data)
+	}
+}
+
+func TestContractMonitor_HealthyAndUnhealthy(t *testing.T) {
+	cfg := createOnChainTestConfig(0)
+	registry := metrics.NewRegistry()
+	var logs bytes.Buffer
+	monitor := onchain.NewContractMonitor(cfg, registry, logger.New(logger.DEBUG, &logs), time.Minute, time.Second)
+
+	if monitor.Degraded("base") {
+		t.Error("Expected unchecked network not to be degraded")
+	}
+
+	reader := &mockTokenReader{name: "USD Coin", decimals: 6}
+	monitor.SetBackend("base", reader)
+
+	if degraded := monitor.RunOnce(); degraded != 0 || monitor.Degraded("base") {
+		t.Fatalf("Expected healthy contract, got %d degraded: %+v", degraded, monitor.Health())
+	}
+
+	unhealthy := []struct {
+		name   string
+		reader mockTokenReader
+	}{
+		{"paused", mockTokenReader{revert: true}},
+		{"wrong decimals", mockTokenReader{name: "USD Coin", decimals: 18}},
+		{"wrong name", mockTokenReader{name: "Bridged USDC", decimals: 6}},
+	}
+	for i, tt := range unhealthy {
+		*reader = tt.reader
+		if degraded := monitor.RunOnce(); degraded != 1 || !monitor.Degraded("base") {
+			t.Errorf("%s: expected network degraded, got %+v", tt.name, monitor.Health())
+		}
+		if count := registry.CounterValue(onchain.MetricContractCheckFailures, metrics.Labels{"network": "base"}); count != float64(i+1) {
+			t.Errorf("%s: expected %d failures counted, got %v", tt.name, i+1, count)
+		}
+	}
+
+	// Recovery clears the degraded state
+	*reader = mockTokenReader{name: "USD Coin", decimals: 6}
+	monitor.RunOnce()
+	if monitor.Degraded("base") {
+		t.Error("Expected network to recover after a passing check")
+	}
+	if !bytes.Contains(logs.Bytes(), []byte("USDC contract check recovered")) {
+		t.Error("Expected recovery to be logged")
+	}
+
+	health := monitor.Health()
+	if len(health) != 1 || !health[0].Healthy || health[0].Error != "" {
+		t.Errorf("Expected one healthy entry, got %+v", health)
+	}
+}
+
+// TestContractMonitor_AssetDecimals tests that decimals() is checked against the configured asset decimals
+func TestContractMonitor_AssetDecimals(t *testing.T) {
+	cfg := createOnChainTestConfig(0)
+	baseNet := cfg.Networks["base"]
+	baseNet.AssetDecimals = 18
+	cfg.Networks["base"] = baseNet
+
+	monitor := onchain.NewContractMonitor(cfg, metrics.NewRegistry(), logger.New(logger.DEBUG, &bytes.Buffer{}), time.Minute, time.Second)
+	reader := &mockTokenReader{name: "USD Coin", decimals: 18}
+	monitor.SetBackend("base", reader)
+
+	if degraded := monitor.RunOnce(); degraded != 0 {
+		t.Fatalf("Expected 18-decimal asset to pass, got %+v", monitor.Health())
+	}
+
+	reader.decimals = 6
+	if degraded := monitor.RunOnce(); degraded != 1 {
+		t.Errorf("Expected 6 decimals to fail for an 18-decimal asset, got %+v", monitor.Health())
+	}
+}
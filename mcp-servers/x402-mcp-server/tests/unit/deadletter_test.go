@@ -0,0 +1,66 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/deadletter"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+// TestDeadLetterFileStore_RoundTrip tests that appended entries are read back in order from an owner-only file
+func TestDeadLetterFileStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	store := deadletter.NewStore(&config.DeadLetterConfig{Path: path})
+
+	entries, err := store.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected no entries before the file exists, got %v (%v)", entries, err)
+	}
+
+	for _, reason := range []string{"insufficient balance", "authorization already used"} {
+		if err := store.Append(deadletter.Entry{
+			Network:       "base",
+			Authorization: &eip3009.EIP3009Authorization{From: "0x1111111111111111111111111111111111111111", Value: "50000", V: 27},
+			Error:         reason,
+		}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	entries, err = store.Entries()
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Error != "insufficient balance" || entries[1].Error != "authorization already used" {
+		t.Fatalf("Expected both entries in append order, got %+v", entries)
+	}
+	if entries[0].Timestamp.IsZero() || entries[0].Authorization.Value != "50000" || entries[0].Authorization.V != 27 {
+		t.Errorf("Expected a timestamped entry with its authorization, got %+v", entries[0])
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("Expected dead letter file mode 0600, got %o", mode)
+	}
+}
+
+// TestDeadLetterStore_Disabled tests that no file is written without a configured path
+func TestDeadLetterStore_Disabled(t *testing.T) {
+	store := deadletter.NewStore(&config.DeadLetterConfig{})
+	if _, ok := store.(deadletter.NopStore); !ok {
+		t.Fatalf("Expected NopStore when disabled, got %T", store)
+	}
+
+	if err := store.Append(deadletter.Entry{Network: "base", Error: "rejected"}); err != nil {
+		t.Errorf("Append failed: %v", err)
+	}
+	if entries, _ := store.Entries(); len(entries) != 0 {
+		t.Errorf("Expected NopStore to keep nothing, got %v", entries)
+	}
+}
@@ -0,0 +1,165 @@
+package unit
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+// createMigrationTestConfig returns a base config migrating from "USD Coin" v1 to v2 until the given time
+func createMigrationTestConfig(until time.Time) *config.Config {
+	return &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:             8453,
+				USDCContract:        "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				DomainName:          "USD Coin",
+				DomainVersion:       "2",
+				LegacyDomainName:    "USD Coin",
+				LegacyDomainVersion: "1",
+				LegacyDomainUntil:   until.UTC().Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+// TestSignatureVerifier_LegacyDomainWindow tests acceptance of legacy-domain signatures before and after migration
+func TestSignatureVerifier_LegacyDomainWindow(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	now := time.Now()
+
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        crypto.PubkeyToAddress(privateKey.PublicKey),
+		To:          common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Value:       big.NewInt(50000),
+		ValidAfter:  big.NewInt(now.Unix() - 60),
+		ValidBefore: big.NewInt(now.Unix() + 3600),
+		Nonce:       [32]byte{0x42},
+	}
+	domain := func(version string) *eip3009.EIP712Domain {
+		return &eip3009.EIP712Domain{
+			Name:              "USD Coin",
+			Version:           version,
+			ChainID:           big.NewInt(8453),
+			VerifyingContract: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+		}
+	}
+
+	legacyAuth, err := eip3009.SignAuthorization(message, domain("1"), privateKey)
+	if err != nil {
+		t.Fatalf("Failed to sign legacy authorization: %v", err)
+	}
+	currentAuth, err := eip3009.SignAuthorization(message, domain("2"), privateKey)
+	if err != nil {
+		t.Fatalf("Failed to sign current authorization: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		until        time.Time
+		auth         *eip3009.EIP3009Authorization
+		expectValid  bool
+		expectLegacy bool
+	}{
+		{"legacy signature during migration", now.Add(time.Hour), legacyAuth, true, true},
+		{"current signature during migration", now.Add(time.Hour), currentAuth, true, false},
+		{"legacy signature after migration", now.Add(-time.Hour), legacyAuth, false, false},
+		{"current signature after migration", now.Add(-time.Hour), currentAuth, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := eip3009.NewSignatureVerifier(createMigrationTestConfig(tt.until))
+
+			result, err := verifier.VerifyAuthorization(tt.auth, "base")
+			if err != nil {
+				t.Fatalf("Verification returned error: %v", err)
+			}
+			if result.IsValid != tt.expectValid {
+				t.Errorf("Expected is_valid=%v, got %v (%s)", tt.expectValid, result.IsValid, result.Error)
+			}
+			if result.LegacyDomain != tt.expectLegacy {
+				t.Errorf("Expected legacy_domain=%v, got %v", tt.expectLegacy, result.LegacyDomain)
+			}
+		})
+	}
+}
+
+// TestSignatureVerifier_UpgradeAuthorization tests re-binding a legacy authorization to the current domain
+func TestSignatureVerifier_UpgradeAuthorization(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	now := time.Now()
+	cfg := createMigrationTestConfig(now.Add(-time.Hour)) // Window closed: only the new domain verifies
+	verifier := eip3009.NewSignatureVerifier(cfg)
+
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        crypto.PubkeyToAddress(privateKey.PublicKey),
+		To:          common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Value:       big.NewInt(50000),
+		ValidAfter:  big.NewInt(now.Unix() - 60),
+		ValidBefore: big.NewInt(now.Unix() + 3600),
+		Nonce:       [32]byte{0x43},
+	}
+	current, err := verifier.VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	legacyDomain := *current
+	legacyDomain.Version = "1"
+
+	legacyAuth, err := eip3009.SignAuthorization(message, &legacyDomain, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to sign legacy authorization: %v", err)
+	}
+	resigned, err := eip3009.SignAuthorization(message, current, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to re-sign authorization: %v", err)
+	}
+
+	// The legacy signature is not a valid new-domain signature
+	if _, err := verifier.UpgradeAuthorization(legacyAuth, eip3009.Signature{V: legacyAuth.V, R: legacyAuth.R, S: legacyAuth.S}, "base"); err == nil {
+		t.Error("Expected upgrade with the legacy signature to fail")
+	}
+
+	upgraded, err := verifier.UpgradeAuthorization(legacyAuth, eip3009.Signature{V: resigned.V, R: resigned.R, S: resigned.S}, "base")
+	if err != nil {
+		t.Fatalf("UpgradeAuthorization failed: %v", err)
+	}
+	if upgraded.Nonce != legacyAuth.Nonce || upgraded.Value != legacyAuth.Value || upgraded.ValidBefore != legacyAuth.ValidBefore {
+		t.Errorf("Expected the original message to be kept, got %+v", upgraded)
+	}
+	if legacyAuth.R == upgraded.R {
+		t.Error("Expected the original authorization to be left unchanged")
+	}
+
+	result, err := verifier.VerifyAuthorization(upgraded, "base")
+	if err != nil || !result.IsValid {
+		t.Errorf("Expected upgraded authorization to verify, got %+v (%v)", result, err)
+	}
+}
+
+// TestNetworkConfig_Validate_LegacyDomain tests migration window validation
+func TestNetworkConfig_Validate_LegacyDomain(t *testing.T) {
+	network := createMigrationTestConfig(time.Now()).Networks["base"]
+	network.FacilitatorURL = "https://x402.org/facilitator"
+	network.RPCURL = "https://mainnet.base.org"
+	network.PayeeAddress = "0x1234567890123456789012345678901234567890"
+	if err := network.Validate(); err != nil {
+		t.Fatalf("Expected valid migration config, got %v", err)
+	}
+
+	network.LegacyDomainUntil = "next week"
+	if err := network.Validate(); err == nil {
+		t.Error("Expected non-RFC 3339 legacy_domain_until to be rejected")
+	}
+
+	network.LegacyDomainUntil = ""
+	if err := network.Validate(); err == nil {
+		t.Error("Expected legacy domain without legacy_domain_until to be rejected")
+	}
+}
@@ -0,0 +1,190 @@
+package unit
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+// fakeContractWallet is a ContractReader serving one EIP-1271 wallet that accepts
+// signatures by its single owner key
+type fakeContractWallet struct {
+	address common.Address
+	owner   common.Address
+	callErr error
+	calls   int
+}
+
+func (w *fakeContractWallet) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	if account == w.address {
+		return []byte{0x60, 0x80, 0x60, 0x40}, nil
+	}
+	return nil, nil
+}
+
+func (w *fakeContractWallet) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	w.calls++
+	if w.callErr != nil {
+		return nil, w.callErr
+	}
+
+	// isValidSignature(bytes32 hash, bytes signature): hash, offset, length, then r || s || v
+	args := call.Data[4:]
+	hash := args[:32]
+	signature := append([]byte(nil), args[96:96+65]...)
+	signature[64] -= 27
+
+	output := make([]byte, 32)
+	if pub, err := crypto.SigToPub(hash, signature); err == nil && crypto.PubkeyToAddress(*pub) == w.owner {
+		copy(output, eip3009.EIP1271MagicValue[:])
+	}
+	return output, nil
+}
+
+// signForWallet signs a Base authorization from wallet with the given owner key
+func signForWallet(t *testing.T, wallet common.Address, owner *ecdsa.PrivateKey) *eip3009.EIP3009Authorization {
+	t.Helper()
+
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+	}
+
+	now := time.Now().Unix()
+	var nonce [32]byte
+	copy(nonce[:], []byte("eip1271-nonce"))
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        wallet,
+		To:          common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Value:       big.NewInt(50000),
+		ValidAfter:  big.NewInt(now - 60),
+		ValidBefore: big.NewInt(now + 3600),
+		Nonce:       nonce,
+	}
+
+	typedDataHash, err := eip3009.TypedDataHash(domain, message)
+	if err != nil {
+		t.Fatalf("Failed to hash authorization: %v", err)
+	}
+	signature, err := crypto.Sign(typedDataHash.Bytes(), owner)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	return &eip3009.EIP3009Authorization{
+		From:        wallet.Hex(),
+		To:          message.To.Hex(),
+		Value:       message.Value.String(),
+		ValidAfter:  message.ValidAfter.Uint64(),
+		ValidBefore: message.ValidBefore.Uint64(),
+		Nonce:       common.BytesToHash(nonce[:]).Hex(),
+		V:           signature[64] + 27,
+		R:           common.BytesToHash(signature[0:32]).Hex(),
+		S:           common.BytesToHash(signature[32:64]).Hex(),
+	}
+}
+
+// TestSignatureVerification_EIP1271 tests contract wallet verification via isValidSignature
+func TestSignatureVerification_EIP1271(t *testing.T) {
+	owner, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	stranger, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	walletAddress := common.HexToAddress("0x5afe5afe5afe5afe5afe5afe5afe5afe5afe5afe")
+
+	tests := []struct {
+		name         string
+		enabled      bool
+		signer       *ecdsa.PrivateKey
+		expectValid  bool
+		expectCode   string
+		expectType   string
+		expectCalled bool
+	}{
+		{"owner signature accepted", true, owner, true, "", eip3009.SignerTypeContract, true},
+		{"non-owner signature rejected", true, stranger, false, eip3009.ErrorCodeContractSignature, eip3009.SignerTypeContract, true},
+		{"disabled falls back to signer mismatch", false, owner, false, "", eip3009.SignerTypeEOA, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createSignerTestConfig()
+			cfg.Verification.EIP1271 = tt.enabled
+			wallet := &fakeContractWallet{address: walletAddress, owner: crypto.PubkeyToAddress(owner.PublicKey)}
+			verifier := eip3009.NewSignatureVerifier(cfg)
+			verifier.SetContractReader("base", wallet)
+
+			result, err := verifier.VerifyAuthorization(signForWallet(t, walletAddress, tt.signer), "base")
+			if err != nil {
+				t.Fatalf("VerifyAuthorization returned error: %v", err)
+			}
+
+			if result.IsValid != tt.expectValid || result.ErrorCode != tt.expectCode {
+				t.Errorf("Expected valid=%v code=%q, got valid=%v code=%q (%s)", tt.expectValid, tt.expectCode, result.IsValid, result.ErrorCode, result.Error)
+			}
+			if result.SignerType != tt.expectType {
+				t.Errorf("Expected signer_type %q, got %q", tt.expectType, result.SignerType)
+			}
+			if (wallet.calls > 0) != tt.expectCalled {
+				t.Errorf("Expected isValidSignature called=%v, got %d calls", tt.expectCalled, wallet.calls)
+			}
+			if tt.expectValid && result.SignerAddress != walletAddress.Hex() {
+				t.Errorf("Expected signer_address %s, got %s", walletAddress.Hex(), result.SignerAddress)
+			}
+		})
+	}
+}
+
+// TestSignatureVerification_EIP1271EOAAndRPCErrors tests that EOAs skip the RPC entirely,
+// reverts reject the signature, and unreachable nodes surface as errors
+func TestSignatureVerification_EIP1271EOAAndRPCErrors(t *testing.T) {
+	owner, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate owner key: %v", err)
+	}
+	walletAddress := common.HexToAddress("0x5afe5afe5afe5afe5afe5afe5afe5afe5afe5afe")
+
+	cfg := createSignerTestConfig()
+	cfg.Verification.EIP1271 = true
+
+	wallet := &fakeContractWallet{address: walletAddress, owner: crypto.PubkeyToAddress(owner.PublicKey)}
+	verifier := eip3009.NewSignatureVerifier(cfg)
+	verifier.SetContractReader("base", wallet)
+
+	now := time.Now().Unix()
+	result, err := verifier.VerifyAuthorization(signTestAuthorization(t, owner, now-60, now+3600), "base")
+	if err != nil {
+		t.Fatalf("VerifyAuthorization returned error: %v", err)
+	}
+	if !result.IsValid || result.SignerType != eip3009.SignerTypeEOA || wallet.calls != 0 {
+		t.Errorf("Expected EOA verified without RPC, got valid=%v type=%q calls=%d", result.IsValid, result.SignerType, wallet.calls)
+	}
+
+	wallet.callErr = errors.New("execution reverted")
+	result, err = verifier.VerifyAuthorization(signForWallet(t, walletAddress, owner), "base")
+	if err != nil {
+		t.Fatalf("Expected a revert to reject the signature, got error: %v", err)
+	}
+	if result.IsValid || result.ErrorCode != eip3009.ErrorCodeContractSignature {
+		t.Errorf("Expected %s on revert, got valid=%v code=%q", eip3009.ErrorCodeContractSignature, result.IsValid, result.ErrorCode)
+	}
+
+	wallet.callErr = errors.New("connection refused")
+	if _, err := verifier.VerifyAuthorization(signForWallet(t, walletAddress, owner), "base"); err == nil {
+		t.Error("Expected an unreachable node to surface as an error")
+	}
+}
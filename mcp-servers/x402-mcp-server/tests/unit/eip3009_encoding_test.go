@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+// TestBytes32Encoding_RoundTrip tests that nonces and signature components round-trip through both encodings
+func TestBytes32Encoding_RoundTrip(t *testing.T) {
+	var value [32]byte
+	for i := range value {
+		value[i] = byte(i * 7)
+	}
+
+	tests := []struct {
+		encoding string
+		prefix   string
+	}{
+		{"", "0x"},
+		{config.ByteEncodingHex, "0x"},
+		{config.ByteEncodingBase64, ""},
+	}
+
+	for _, tt := range tests {
+		encoded := eip3009.EncodeBytes32(value, tt.encoding)
+		if !strings.HasPrefix(encoded, tt.prefix) || (tt.prefix == "" && strings.HasPrefix(encoded, "0x")) {
+			t.Errorf("encoding %q: unexpected form %s", tt.encoding, encoded)
+		}
+
+		decoded, err := eip3009.DecodeBytes32(encoded)
+		if err != nil {
+			t.Fatalf("encoding %q: DecodeBytes32(%s) failed: %v", tt.encoding, encoded, err)
+		}
+		if decoded != value {
+			t.Errorf("encoding %q: round trip changed the value", tt.encoding)
+		}
+
+		normalized, err := eip3009.NormalizeBytes32(encoded)
+		if err != nil {
+			t.Fatalf("encoding %q: NormalizeBytes32(%s) failed: %v", tt.encoding, encoded, err)
+		}
+		if normalized != eip3009.EncodeBytes32(value, config.ByteEncodingHex) {
+			t.Errorf("encoding %q: expected hex after normalization, got %s", tt.encoding, normalized)
+		}
+	}
+}
+
+// TestBytes32Encoding_Invalid tests rejection of values that are not 32 bytes in either encoding
+func TestBytes32Encoding_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"0x1234",
+		"0x" + strings.Repeat("zz", 32),
+		"AAAA",                          // 3 bytes of base64
+		strings.Repeat("A", 43),         // unpadded base64
+		strings.Repeat("A", 42) + "B=",  // non-canonical trailing bits
+		strings.Repeat("_", 43) + "=",   // URL-safe alphabet
+		"0x" + strings.Repeat("ab", 33), // 33 bytes of hex
+	} {
+		if _, err := eip3009.DecodeBytes32(input); err == nil {
+			t.Errorf("Expected DecodeBytes32(%q) to fail", input)
+		}
+	}
+}
+
+// TestValidateHex_NamesField tests that non-hex r, s, and nonce values fail as ValidationError
+// before decoding, rather than as a length error from truncated bytes
+func TestValidateHex_NamesField(t *testing.T) {
+	valid := "0x" + strings.Repeat("01", 32)
+	malformed := "0x" + strings.Repeat("01", 30) + "g1zz"
+
+	if err := eip3009.ValidateHex("r", valid); err != nil {
+		t.Errorf("Expected valid hex to pass, got: %v", err)
+	}
+
+	checks := map[string]func() error{
+		"r": func() error {
+			_, err := (&eip3009.Signature{V: 27, R: malformed, S: valid}).Bytes()
+			return err
+		},
+		"s": func() error {
+			return (&eip3009.Signature{V: 27, R: valid, S: malformed}).Validate()
+		},
+		"nonce": func() error {
+			auth := &eip3009.EIP3009Authorization{
+				From:        "0x0000000000000000000000000000000000000001",
+				To:          "0x0000000000000000000000000000000000000002",
+				Value:       "1",
+				ValidBefore: 1,
+				Nonce:       malformed,
+			}
+			_, err := auth.ToMessage()
+			return err
+		},
+	}
+
+	for field, check := range checks {
+		err := check()
+		var validationErr *eip3009.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("%s: expected ValidationError, got %T: %v", field, err, err)
+		}
+		if validationErr.Field != field || !strings.Contains(err.Error(), "invalid hex") {
+			t.Errorf("%s: expected an invalid hex error for the field, got: %v", field, err)
+		}
+		if !strings.Contains(err.Error(), "position 62") {
+			t.Errorf("%s: expected the offending position, got: %v", field, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+func createSignerTestConfig() *config.Config {
+	return &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:      8453,
+				USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			},
+			"base-sepolia": {
+				ChainID:      84532,
+				USDCContract: "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+			},
+			"arbitrum": {
+				ChainID:      42161,
+				USDCContract: "0xaf88d065e77c8cC2239327C5EDb3A432268e5831",
+			},
+		},
+		EIP712: config.EIP712Config{
+			DomainName:    "USD Coin",
+			DomainVersion: "2",
+		},
+	}
+}
+
+// TestSignAuthorization_RoundTrip tests that signed authorizations verify for several messages
+func TestSignAuthorization_RoundTrip(t *testing.T) {
+	cfg := createSignerTestConfig()
+	verifier := eip3009.NewSignatureVerifier(cfg)
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name    string
+		network string
+		to      string
+		value   *big.Int
+		nonce   string
+	}{
+		{"base small payment", "base", "0x1234567890123456789012345678901234567890", big.NewInt(1), "round-trip-1"},
+		{"base-sepolia typical", "base-sepolia", "0x2222222222222222222222222222222222222222", big.NewInt(50000), "round-trip-2"},
+		{"arbitrum large", "arbitrum", "0xabcdef0123456789abcdef0123456789abcdef01", new(big.Int).Lsh(big.NewInt(1), 120), "round-trip-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKey, err := crypto.GenerateKey()
+			if err != nil {
+				t.Fatalf("Failed to generate key: %v", err)
+			}
+
+			domain, err := verifier.VerifyDomain(tt.network)
+			if err != nil {
+				t.Fatalf("Failed to build domain: %v", err)
+			}
+
+			var nonce [32]byte
+			copy(nonce[:], []byte(tt.nonce))
+
+			message := &eip3009.ReceiveWithAuthorizationMessage{
+				From:        crypto.PubkeyToAddress(privateKey.PublicKey),
+				To:          common.HexToAddress(tt.to),
+				Value:       tt.value,
+				ValidAfter:  big.NewInt(now - 60),
+				ValidBefore: big.NewInt(now + 3600),
+				Nonce:       nonce,
+			}
+
+			auth, err := eip3009.SignAuthorization(message, domain, privateKey)
+			if err != nil {
+				t.Fatalf("SignAuthorization failed: %v", err)
+			}
+
+			if auth.V != 27 && auth.V != 28 {
+				t.Errorf("Expected v in 27/28 convention, got %d", auth.V)
+			}
+
+			result, err := verifier.VerifyAuthorization(auth, tt.network)
+			if err != nil {
+				t.Fatalf("VerifyAuthorization returned error: %v", err)
+			}
+
+			if !result.IsValid {
+				t.Fatalf("Expected valid signature, got error: %s", result.Error)
+			}
+
+			if !strings.EqualFold(result.SignerAddress, message.From.Hex()) {
+				t.Errorf("Expected signer %s, got %s", message.From.Hex(), result.SignerAddress)
+			}
+
+			// The same authorization must not verify under another network's domain
+			other := "base"
+			if tt.network == "base" {
+				other = "arbitrum"
+			}
+			if result, err := verifier.VerifyAuthorization(auth, other); err == nil && result.IsValid {
+				t.Errorf("Expected authorization signed for %s to fail on %s", tt.network, other)
+			}
+		})
+	}
+}
+
+// TestSignAuthorization_FromMismatch tests that signing for another payer is refused
+func TestSignAuthorization_FromMismatch(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	domain, err := eip3009.NewSignatureVerifier(createSignerTestConfig()).VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		To:          common.HexToAddress("0x2222222222222222222222222222222222222222"),
+		Value:       big.NewInt(50000),
+		ValidAfter:  big.NewInt(0),
+		ValidBefore: big.NewInt(time.Now().Unix() + 3600),
+	}
+
+	if _, err := eip3009.SignAuthorization(message, domain, privateKey); err == nil {
+		t.Error("Expected error when message from differs from signing key")
+	}
+}
+
+// TestSignatureVerifier_PerChainDefaultDomain tests verification against the built-in domain when eip712 is unset
+func TestSignatureVerifier_PerChainDefaultDomain(t *testing.T) {
+	cfg := createSignerTestConfig()
+	cfg.EIP712 = config.EIP712Config{}
+	verifier := eip3009.NewSignatureVerifier(cfg)
+
+	domain, err := verifier.VerifyDomain("base-sepolia")
+	if err != nil {
+		t.Fatalf("Failed to build domain: %v", err)
+	}
+	if domain.Name != "USDC" || domain.Version != "2" {
+		t.Fatalf("Expected Base Sepolia domain USDC/2, got %s/%s", domain.Name, domain.Version)
+	}
+
+	privateKey, _ := crypto.GenerateKey()
+	now := time.Now().Unix()
+	auth, err := eip3009.SignAuthorization(&eip3009.ReceiveWithAuthorizationMessage{
+		From:        crypto.PubkeyToAddress(privateKey.PublicKey),
+		To:          common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Value:       big.NewInt(50000),
+		ValidAfter:  big.NewInt(now - 60),
+		ValidBefore: big.NewInt(now + 3600),
+	}, domain, privateKey)
+	if err != nil {
+		t.Fatalf("SignAuthorization failed: %v", err)
+	}
+
+	result, err := verifier.VerifyAuthorization(auth, "base-sepolia")
+	if err != nil || !result.IsValid {
+		t.Errorf("Expected signature under built-in domain to verify, got %+v (err=%v)", result, err)
+	}
+
+	if base, _ := verifier.VerifyDomain("base"); base.Name != "USD Coin" {
+		t.Errorf("Expected Base domain name 'USD Coin', got %s", base.Name)
+	}
+}
@@ -2,12 +2,18 @@ package unit
 
 import (
 	"crypto/ecdsa"
+	"crypto/rand"
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
 )
 
@@ -300,6 +306,39 @@ func TestSignatureVerification_VParameter(t *testing.T) {
 	}
 }
 
+// TestDecodeV tests normalization of plain and EIP-155 encoded v values
+func TestDecodeV(t *testing.T) {
+	tests := []struct {
+		raw         uint64
+		expectV     uint8
+		expectChain uint64
+		expectError bool
+	}{
+		{27, 27, 0, false},
+		{28, 28, 0, false},
+		{8453*2 + 35, 27, 8453, false},
+		{8453*2 + 36, 28, 8453, false},
+		{1*2 + 35, 27, 1, false},
+		{0, 0, 0, true},
+		{1, 0, 0, true},
+		{29, 0, 0, true},
+		{36, 0, 0, true}, // Would encode chain ID 0
+	}
+
+	for _, tt := range tests {
+		v, chainID, err := eip3009.DecodeV(tt.raw)
+		if tt.expectError {
+			if err == nil {
+				t.Errorf("v=%d: expected error, got v=%d chain=%d", tt.raw, v, chainID)
+			}
+			continue
+		}
+		if err != nil || v != tt.expectV || chainID != tt.expectChain {
+			t.Errorf("v=%d: expected (%d, %d), got (%d, %d, %v)", tt.raw, tt.expectV, tt.expectChain, v, chainID, err)
+		}
+	}
+}
+
 // TestSignatureVerification_AddressFormats tests Ethereum address validation
 func TestSignatureVerification_AddressFormats(t *testing.T) {
 	testCases := []struct {
@@ -341,3 +380,462 @@ func validateAddress(addr string) bool {
 	}
 	return true
 }
+
+// signTestAuthorization signs an authorization for Base mainnet with the given time bounds
+func signTestAuthorization(t *testing.T, privateKey *ecdsa.PrivateKey, validAfter, validBefore int64) *eip3009.EIP3009Authorization {
+	t.Helper()
+
+	return signTestAuthorizationTo(t, privateKey, common.HexToAddress("0x1234567890123456789012345678901234567890"), validAfter, validBefore)
+}
+
+// signTestAuthorizationTo signs an authorization for Base mainnet paying the given address
+func signTestAuthorizationTo(t *testing.T, privateKey *ecdsa.PrivateKey, to common.Address, validAfter, validBefore int64) *eip3009.EIP3009Authorization {
+	t.Helper()
+
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+	}
+
+	nonce := [32]byte{}
+	copy(nonce[:], []byte("max-age-nonce"))
+
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        crypto.PubkeyToAddress(privateKey.PublicKey),
+		To:          to,
+		Value:       big.NewInt(50000),
+		ValidAfter:  big.NewInt(validAfter),
+		ValidBefore: big.NewInt(validBefore),
+		Nonce:       nonce,
+	}
+
+	auth, err := eip3009.SignAuthorization(message, domain, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	return auth
+}
+
+// TestSignatureVerification_MaxAuthorizationAge tests rejection of stale authorizations
+func TestSignatureVerification_MaxAuthorizationAge(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:      8453,
+				USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			},
+		},
+		EIP712: config.EIP712Config{
+			DomainName:    "USD Coin",
+			DomainVersion: "2",
+		},
+		Verification: config.VerificationConfig{
+			MaxAuthorizationAgeSeconds: 300,
+		},
+	}
+
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name         string
+		validAfter   int64
+		expectValid  bool
+		expectedCode string
+	}{
+		{"fresh authorization", now - 60, true, ""},
+		{"stale authorization", now - 3600, false, eip3009.ErrorCodeTooOld},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := eip3009.NewSignatureVerifier(cfg)
+			auth := signTestAuthorization(t, privateKey, tt.validAfter, now+3600)
+
+			result, err := verifier.VerifyAuthorization(auth, "base")
+			if err != nil {
+				t.Fatalf("VerifyAuthorization returned error: %v", err)
+			}
+
+			if result.IsValid != tt.expectValid {
+				t.Errorf("Expected is_valid=%v, got %v (%s)", tt.expectValid, result.IsValid, result.Error)
+			}
+
+			if result.ErrorCode != tt.expectedCode {
+				t.Errorf("Expected error_code '%s', got '%s'", tt.expectedCode, result.ErrorCode)
+			}
+		})
+	}
+}
+
+// TestSignatureVerification_MaxAuthorizationAgeDisabled tests that zero disables the age check
+func TestSignatureVerification_MaxAuthorizationAgeDisabled(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:      8453,
+				USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			},
+		},
+		EIP712: config.EIP712Config{
+			DomainName:    "USD Coin",
+			DomainVersion: "2",
+		},
+	}
+
+	now := time.Now().Unix()
+	auth := signTestAuthorization(t, privateKey, now-30*24*3600, now+3600)
+
+	result, err := eip3009.NewSignatureVerifier(cfg).VerifyAuthorization(auth, "base")
+	if err != nil {
+		t.Fatalf("VerifyAuthorization returned error: %v", err)
+	}
+
+	if !result.IsValid {
+		t.Errorf("Expected valid authorization with age check disabled, got error: %s", result.Error)
+	}
+}
+
+// TestSignatureVerification_TimeBoundErrorCodes tests that an inverted window is reported apart
+// from a well-formed window the current clock falls outside of
+func TestSignatureVerification_TimeBoundErrorCodes(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:      8453,
+				USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			},
+		},
+		EIP712: config.EIP712Config{
+			DomainName:    "USD Coin",
+			DomainVersion: "2",
+		},
+	}
+
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name         string
+		validAfter   int64
+		validBefore  int64
+		expectedCode string
+	}{
+		{"inside window", now - 60, now + 3600, ""},
+		{"zero validAfter is valid immediately", 0, now + 3600, ""},
+		{"inverted window", now + 3600, now - 60, eip3009.ErrorCodeInvertedWindow},
+		{"empty window", now, now, eip3009.ErrorCodeInvertedWindow},
+		{"not yet valid", now + 3600, now + 7200, eip3009.ErrorCodeNotYetValid},
+		{"expired", now - 7200, now - 3600, eip3009.ErrorCodeExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := signTestAuthorization(t, privateKey, tt.validAfter, tt.validBefore)
+
+			result, err := eip3009.NewSignatureVerifier(cfg).VerifyAuthorization(auth, "base")
+			if err != nil {
+				t.Fatalf("VerifyAuthorization returned error: %v", err)
+			}
+
+			if result.IsValid != (tt.expectedCode == "") {
+				t.Errorf("Expected is_valid=%v, got %v (%s)", tt.expectedCode == "", result.IsValid, result.Error)
+			}
+			if result.ErrorCode != tt.expectedCode {
+				t.Errorf("Expected error_code '%s', got '%s' (%s)", tt.expectedCode, result.ErrorCode, result.Error)
+			}
+
+			timeErr := eip3009.ValidateTimeBounds(uint64(tt.validAfter), uint64(tt.validBefore))
+			if inverted := errors.Is(timeErr, eip3009.ErrInvertedWindow); inverted != (tt.expectedCode == eip3009.ErrorCodeInvertedWindow) {
+				t.Errorf("ValidateTimeBounds: expected ErrInvertedWindow=%v, got %v", !inverted, timeErr)
+			}
+		})
+	}
+}
+
+// TestSignatureVerification_StrictProfile tests that the strict profile rejects a zero validAfter the lenient default accepts
+func TestSignatureVerification_StrictProfile(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+
+	now := time.Now().Unix()
+	zeroValidAfter := signTestAuthorization(t, privateKey, 0, now+3600)
+	explicit := signTestAuthorization(t, privateKey, now-60, now+3600)
+
+	tests := []struct {
+		name         string
+		profile      string
+		auth         *eip3009.EIP3009Authorization
+		expectedCode string
+	}{
+		{"lenient default accepts zero validAfter", "", zeroValidAfter, ""},
+		{"lenient accepts zero validAfter", config.ProfileLenient, zeroValidAfter, ""},
+		{"strict rejects zero validAfter", config.ProfileStrict, zeroValidAfter, eip3009.ErrorCodeStrictProfile},
+		{"strict accepts explicit fields", config.ProfileStrict, explicit, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createSignerTestConfig()
+			cfg.Verification.Profile = tt.profile
+
+			result, err := eip3009.NewSignatureVerifier(cfg).VerifyAuthorization(tt.auth, "base")
+			if err != nil {
+				t.Fatalf("VerifyAuthorization returned error: %v", err)
+			}
+
+			if result.IsValid != (tt.expectedCode == "") {
+				t.Errorf("Expected is_valid=%v, got %v (%s)", tt.expectedCode == "", result.IsValid, result.Error)
+			}
+			if result.ErrorCode != tt.expectedCode {
+				t.Errorf("Expected error_code '%s', got '%s' (%s)", tt.expectedCode, result.ErrorCode, result.Error)
+			}
+		})
+	}
+
+	zeroNonce := *explicit
+	zeroNonce.Nonce = "0x0000000000000000000000000000000000000000000000000000000000000000"
+	if err := zeroNonce.ValidateStrict(); err == nil {
+		t.Error("Expected strict validation to reject an all-zero nonce")
+	}
+	if err := explicit.ValidateStrict(); err != nil {
+		t.Errorf("Expected explicit authorization to pass strict validation, got: %v", err)
+	}
+}
+
+// TestSignatureVerification_RequireChecksum tests checksum enforcement under strict and lenient modes
+func TestSignatureVerification_RequireChecksum(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+
+	now := time.Now().Unix()
+	payee := common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913")
+	signed := signTestAuthorizationTo(t, privateKey, payee, now-60, now+3600)
+
+	checksummed := payee.Hex()
+	wrongChecksum := "0x833589Fcd6eDb6E08f4c7C32D4f71b54bdA02913" // two letters case-swapped
+
+	tests := []struct {
+		name        string
+		to          string
+		strictValid bool
+	}{
+		{"correct checksum", checksummed, true},
+		{"wrong checksum", wrongChecksum, false},
+		{"all lowercase", strings.ToLower(checksummed), true},
+		{"all uppercase", "0x" + strings.ToUpper(checksummed[2:]), true},
+	}
+
+	for _, strict := range []bool{false, true} {
+		cfg := &config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base": {
+					ChainID:      8453,
+					USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				},
+			},
+			EIP712: config.EIP712Config{
+				DomainName:    "USD Coin",
+				DomainVersion: "2",
+			},
+			Verification: config.VerificationConfig{
+				RequireChecksum: strict,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s/strict=%v", tt.name, strict), func(t *testing.T) {
+				// Only the casing differs; the signed address bytes are unchanged
+				auth := *signed
+				auth.To = tt.to
+
+				result, err := eip3009.NewSignatureVerifier(cfg).VerifyAuthorization(&auth, "base")
+				if err != nil {
+					t.Fatalf("VerifyAuthorization returned error: %v", err)
+				}
+
+				expectValid := !strict || tt.strictValid
+				if result.IsValid != expectValid {
+					t.Errorf("Expected is_valid=%v, got %v (%s)", expectValid, result.IsValid, result.Error)
+				}
+
+				if !expectValid && result.ErrorCode != eip3009.ErrorCodeBadChecksum {
+					t.Errorf("Expected error_code '%s', got '%s'", eip3009.ErrorCodeBadChecksum, result.ErrorCode)
+				}
+			})
+		}
+	}
+}
+
+// TestWeakNonceReason tests the predictable-nonce heuristic
+func TestWeakNonceReason(t *testing.T) {
+	var random [32]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		t.Fatalf("Failed to generate random nonce: %v", err)
+	}
+
+	var sequential [32]byte
+	for i := range sequential {
+		sequential[i] = byte(i + 1)
+	}
+
+	tests := []struct {
+		name  string
+		nonce string
+		weak  bool
+	}{
+		{"all zeros", "0x0000000000000000000000000000000000000000000000000000000000000000", true},
+		{"repeated byte", "0x" + strings.Repeat("ab", 32), true},
+		{"sequential bytes", hexutil.Encode(sequential[:]), true},
+		{"zero-padded counter", "0x000000000000000000000000000000000000000000000000000000000000002a", true},
+		{"random", hexutil.Encode(random[:]), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := eip3009.WeakNonceReason(tt.nonce, config.DefaultWeakNonceMinDistinctBytes)
+			if (reason != "") != tt.weak {
+				t.Errorf("Expected weak=%v for %s, got reason %q", tt.weak, tt.nonce, reason)
+			}
+		})
+	}
+}
+
+// TestSignatureVerification_WeakNonce tests that only reject mode fails a predictable nonce
+func TestSignatureVerification_WeakNonce(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+
+	// The test helper signs the zero-padded ASCII nonce "max-age-nonce"
+	now := time.Now().Unix()
+	auth := signTestAuthorization(t, privateKey, now-60, now+3600)
+
+	tests := []struct {
+		mode         string
+		expectedCode string
+	}{
+		{"", ""},
+		{config.WeakNonceOff, ""},
+		{config.WeakNonceWarn, ""},
+		{config.WeakNonceReject, eip3009.ErrorCodeWeakNonce},
+	}
+
+	for _, tt := range tests {
+		t.Run("mode "+tt.mode, func(t *testing.T) {
+			cfg := createSignerTestConfig()
+			cfg.Verification.WeakNonce = tt.mode
+
+			result, err := eip3009.NewSignatureVerifier(cfg).VerifyAuthorization(auth, "base")
+			if err != nil {
+				t.Fatalf("VerifyAuthorization returned error: %v", err)
+			}
+			if result.ErrorCode != tt.expectedCode {
+				t.Errorf("Expected error_code '%s', got '%s' (%s)", tt.expectedCode, result.ErrorCode, result.Error)
+			}
+		})
+	}
+}
+
+// TestSignatureVerification_PerNetworkDomain tests that a network's domain_version overrides the
+// global eip712 section in VerifyAuthorization, VerifyDomain, and RecoverSigner
+func TestSignatureVerification_PerNetworkDomain(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate private key: %v", err)
+	}
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	// Signed under version "1", while the global default is version "2"
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "1",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+	}
+	now := time.Now().Unix()
+	nonce := [32]byte{}
+	copy(nonce[:], []byte("per-network-domain"))
+	auth, err := eip3009.SignAuthorization(&eip3009.ReceiveWithAuthorizationMessage{
+		From:        from,
+		To:          common.HexToAddress("0x1234567890123456789012345678901234567890"),
+		Value:       big.NewInt(50000),
+		ValidAfter:  big.NewInt(now - 60),
+		ValidBefore: big.NewInt(now + 3600),
+		Nonce:       nonce,
+	}, domain, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to sign authorization: %v", err)
+	}
+
+	newConfig := func(network config.NetworkConfig) *config.Config {
+		return &config.Config{
+			Networks: map[string]config.NetworkConfig{"base": network},
+			EIP712:   config.EIP712Config{DomainName: "USD Coin", DomainVersion: "2"},
+		}
+	}
+	base := config.NetworkConfig{
+		ChainID:      8453,
+		USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+	}
+
+	// Global default: the version "1" signature must not verify
+	result, err := eip3009.NewSignatureVerifier(newConfig(base)).VerifyAuthorization(auth, "base")
+	if err != nil {
+		t.Fatalf("VerifyAuthorization returned error: %v", err)
+	}
+	if result.IsValid {
+		t.Error("Expected signature under version 1 to fail against the global version 2 domain")
+	}
+
+	// Per-network override: the same signature verifies
+	overridden := base
+	overridden.DomainName = "USD Coin"
+	overridden.DomainVersion = "1"
+	verifier := eip3009.NewSignatureVerifier(newConfig(overridden))
+
+	result, err = verifier.VerifyAuthorization(auth, "base")
+	if err != nil {
+		t.Fatalf("VerifyAuthorization returned error: %v", err)
+	}
+	if !result.IsValid {
+		t.Errorf("Expected valid signature with per-network domain, got %s", result.Error)
+	}
+
+	verified, err := verifier.VerifyDomain("base")
+	if err != nil {
+		t.Fatalf("VerifyDomain returned error: %v", err)
+	}
+	if verified.Version != "1" {
+		t.Errorf("Expected domain version '1', got %q", verified.Version)
+	}
+
+	recovered, err := verifier.RecoverSigner(auth, "base")
+	if err != nil {
+		t.Fatalf("RecoverSigner returned error: %v", err)
+	}
+	if recovered != from {
+		t.Errorf("Expected signer %s, got %s", from.Hex(), recovered.Hex())
+	}
+}
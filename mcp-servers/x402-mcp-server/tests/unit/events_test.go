@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/events"
+)
+
+// fakeNATSServer accepts one connection, speaks enough of the NATS protocol to take a
+// publish, and reports the subject and payload it received
+func fakeNATSServer(t *testing.T, reply string) (string, <-chan [2]string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan [2]string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.Write([]byte(`INFO {"server_id":"test","max_payload":1048576}` + "\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch {
+			case len(fields) == 3 && fields[0] == "PUB":
+				size, _ := strconv.Atoi(fields[2])
+				payload := make([]byte, size+2) // payload + CRLF
+				if _, err := io.ReadFull(reader, payload); err != nil {
+					return
+				}
+				received <- [2]string{fields[1], string(payload[:size])}
+			case len(fields) == 1 && fields[0] == "PING":
+				conn.Write([]byte(reply + "\r\n"))
+				return
+			}
+		}
+	}()
+
+	return "nats://" + listener.Addr().String(), received
+}
+
+// TestNATSPublisher_Publish tests that events are published as JSON on the configured subject
+func TestNATSPublisher_Publish(t *testing.T) {
+	url, received := fakeNATSServer(t, "PONG")
+
+	publisher, err := events.NewPublisher(&config.EventsConfig{
+		Publisher: config.EventPublisherNATS,
+		NATSURL:   url,
+		Subject:   "payments.x402",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+
+	event := events.Event{
+		Type:    events.EventSettlementSettled,
+		Network: "base",
+		Nonce:   "0x01",
+		Receipt: map[string]interface{}{"status": "settled", "tx_hash": "0xabc"},
+	}
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if msg[0] != "payments.x402" {
+			t.Errorf("Expected subject payments.x402, got %s", msg[0])
+		}
+		var decoded events.Event
+		if err := json.Unmarshal([]byte(msg[1]), &decoded); err != nil {
+			t.Fatalf("Payload is not an event JSON: %v", err)
+		}
+		if decoded.Type != events.EventSettlementSettled || decoded.Receipt["tx_hash"] != "0xabc" {
+			t.Errorf("Unexpected published event: %+v", decoded)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Fake NATS server received no publish")
+	}
+}
+
+// TestNATSPublisher_Rejected tests that a server -ERR is reported as a publish error
+func TestNATSPublisher_Rejected(t *testing.T) {
+	url, _ := fakeNATSServer(t, "-ERR 'Permissions Violation for Publish'")
+
+	publisher, err := events.NewNATSPublisher(url, "x402.settlements", time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+
+	if err := publisher.Publish(context.Background(), events.Event{Type: events.EventSettlementFailed}); err == nil {
+		t.Error("Expected error when the server rejects the publish")
+	}
+}
+
+// TestEventPublisher_Selection tests publisher selection and URL validation
+func TestEventPublisher_Selection(t *testing.T) {
+	publisher, err := events.NewPublisher(&config.EventsConfig{})
+	if err != nil {
+		t.Fatalf("Expected default publisher, got error: %v", err)
+	}
+	if _, ok := publisher.(events.NopPublisher); !ok {
+		t.Errorf("Expected NopPublisher by default, got %T", publisher)
+	}
+
+	if _, err := events.NewPublisher(&config.EventsConfig{Publisher: config.EventPublisherNATS, NATSURL: "http://localhost:4222"}); err == nil {
+		t.Error("Expected error for non-nats:// URL")
+	}
+	if _, err := events.NewPublisher(&config.EventsConfig{Publisher: "kafka"}); err == nil {
+		t.Error("Expected error for unsupported publisher")
+	}
+}
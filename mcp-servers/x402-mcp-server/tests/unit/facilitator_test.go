@@ -2,14 +2,20 @@ package unit
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/netguard"
 )
 
 // TestFacilitatorClient_ConstructRequest tests HTTP POST request body construction
@@ -92,6 +98,7 @@ func TestFacilitatorClient_SuccessResponse(t *testing.T) {
 				FacilitatorURL: server.URL,
 			},
 		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
 	}
 
 	client := facilitator.NewClient(cfg, 5*time.Second)
@@ -152,6 +159,7 @@ func TestFacilitatorClient_ErrorResponse_BadRequest(t *testing.T) {
 				FacilitatorURL: server.URL,
 			},
 		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
 	}
 
 	client := facilitator.NewClient(cfg, 5*time.Second)
@@ -203,6 +211,7 @@ func TestFacilitatorClient_ErrorResponse_ServerError(t *testing.T) {
 				FacilitatorURL: server.URL,
 			},
 		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
 	}
 
 	client := facilitator.NewClient(cfg, 5*time.Second)
@@ -244,6 +253,7 @@ func TestFacilitatorClient_Timeout(t *testing.T) {
 				FacilitatorURL: server.URL,
 			},
 		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
 	}
 
 	// Create client with 5-second timeout
@@ -304,6 +314,7 @@ func TestFacilitatorClient_IdempotencyCache(t *testing.T) {
 		Cache: config.CacheConfig{
 			SettlementTTLMinutes: 10,
 		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
 	}
 
 	client := facilitator.NewClient(cfg, 5*time.Second)
@@ -349,6 +360,59 @@ func TestFacilitatorClient_IdempotencyCache(t *testing.T) {
 	t.Logf("Idempotency test passed: facilitator called %d time(s)", callCount)
 }
 
+// TestFacilitatorClient_ConcurrentSubmissionsJoin tests that submissions of an authorization
+// already in flight wait for it and share its result instead of posting it again
+func TestFacilitatorClient_ConcurrentSubmissionsJoin(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "settled",
+			"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+		})
+	}))
+	defer server.Close()
+
+	client := facilitator.NewClient(graceTestConfig(server.URL), 5*time.Second)
+	auth := graceTestAuthorization()
+
+	const callers = 5
+	var wg sync.WaitGroup
+	responses := make([]*facilitator.FacilitatorResponse, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = client.SubmitSettlement(auth, "base")
+		}(i)
+	}
+
+	// Hold the first submission until the others have had time to queue behind it
+	deadline := time.Now().Add(5 * time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Submission %d failed: %v", i, errs[i])
+		}
+		if responses[i].Status != "settled" || responses[i].TxHash == "" {
+			t.Errorf("Submission %d: expected the shared settled result, got %+v", i, responses[i])
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 facilitator call for concurrent submissions, got %d", n)
+	}
+}
+
 // TestFacilitatorClient_PendingResponse tests handling of pending settlement
 func TestFacilitatorClient_PendingResponse(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -370,6 +434,7 @@ func TestFacilitatorClient_PendingResponse(t *testing.T) {
 				FacilitatorURL: server.URL,
 			},
 		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
 	}
 
 	client := facilitator.NewClient(cfg, 5*time.Second)
@@ -401,3 +466,827 @@ func TestFacilitatorClient_PendingResponse(t *testing.T) {
 
 	t.Logf("Pending response: status=%s, retry_after=%d", response.Status, response.RetryAfter)
 }
+
+// TestFacilitatorClient_IdempotencyCache_PerNetwork tests that the same nonce on two networks
+// produces two independent cache entries
+func TestFacilitatorClient_IdempotencyCache_PerNetwork(t *testing.T) {
+	callCounts := map[string]int{}
+	newFacilitator := func(network, txHash string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			callCounts[network]++
+			response := map[string]interface{}{
+				"status":       "settled",
+				"tx_hash":      txHash,
+				"block_number": 12345678,
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(response)
+		}))
+	}
+
+	baseServer := newFacilitator("base", "0x1111111111111111111111111111111111111111111111111111111111111111")
+	defer baseServer.Close()
+	sepoliaServer := newFacilitator("base-sepolia", "0x2222222222222222222222222222222222222222222222222222222222222222")
+	defer sepoliaServer.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: baseServer.URL,
+			},
+			"base-sepolia": {
+				ChainID:        84532,
+				USDCContract:   "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+				FacilitatorURL: sepoliaServer.URL,
+			},
+		},
+		Cache: config.CacheConfig{
+			SettlementTTLMinutes: 10,
+		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+
+	client := facilitator.NewClient(cfg, 5*time.Second)
+
+	auth := &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1700000000,
+		ValidBefore: 1700003600,
+		Nonce:       "0x0000000000000000000000000000000000000000000000000000000000000001",
+		V:           27,
+		R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+
+	baseResponse, err := client.SubmitSettlement(auth, "base")
+	if err != nil {
+		t.Fatalf("Base settlement failed: %v", err)
+	}
+
+	sepoliaResponse, err := client.SubmitSettlement(auth, "base-sepolia")
+	if err != nil {
+		t.Fatalf("Base Sepolia settlement failed: %v", err)
+	}
+
+	// Same nonce on a second network must not be served from the first network's entry
+	if baseResponse.TxHash == sepoliaResponse.TxHash {
+		t.Error("Same nonce on different networks returned the same cached result")
+	}
+
+	// Repeats within each network still dedupe
+	if _, err := client.SubmitSettlement(auth, "base"); err != nil {
+		t.Fatalf("Repeat base settlement failed: %v", err)
+	}
+	if _, err := client.SubmitSettlement(auth, "base-sepolia"); err != nil {
+		t.Fatalf("Repeat base-sepolia settlement failed: %v", err)
+	}
+
+	if callCounts["base"] != 1 || callCounts["base-sepolia"] != 1 {
+		t.Errorf("Expected 1 call per network, got base=%d base-sepolia=%d",
+			callCounts["base"], callCounts["base-sepolia"])
+	}
+}
+
+// TestFacilitatorClient_SettledOutlivesPending tests that settled results are reused after pending ones expire
+func TestFacilitatorClient_SettledOutlivesPending(t *testing.T) {
+	settledNonce := "0x0000000000000000000000000000000000000000000000000000000000000011"
+	pendingNonce := "0x0000000000000000000000000000000000000000000000000000000000000022"
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&request)
+		nonce, _ := request["nonce"].(string)
+
+		mu.Lock()
+		calls[nonce]++
+		mu.Unlock()
+
+		status := "pending"
+		if nonce == settledNonce {
+			status = "settled"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": status})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: server.URL,
+			},
+		},
+		Cache: config.CacheConfig{
+			SettlementTTLMinutes: 10,
+			PendingTTLSeconds:    1,
+		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+
+	client := facilitator.NewClient(cfg, 5*time.Second)
+
+	authFor := func(nonce string) *eip3009.EIP3009Authorization {
+		return &eip3009.EIP3009Authorization{
+			From:        "0x1111111111111111111111111111111111111111",
+			To:          "0x2222222222222222222222222222222222222222",
+			Value:       "50000",
+			ValidAfter:  1700000000,
+			ValidBefore: 1700003600,
+			Nonce:       nonce,
+			V:           27,
+			R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+			S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+		}
+	}
+
+	submitBoth := func() {
+		for _, nonce := range []string{settledNonce, pendingNonce} {
+			if _, err := client.SubmitSettlement(authFor(nonce), "base"); err != nil {
+				t.Fatalf("Settlement of %s failed: %v", nonce, err)
+			}
+		}
+	}
+
+	callCount := func(nonce string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls[nonce]
+	}
+
+	// Within both TTLs every repeat is served from cache
+	submitBoth()
+	submitBoth()
+	if callCount(settledNonce) != 1 || callCount(pendingNonce) != 1 {
+		t.Fatalf("Expected one facilitator call per nonce within TTLs, got %d/%d", callCount(settledNonce), callCount(pendingNonce))
+	}
+
+	// After the pending TTL only the pending result is refreshed
+	time.Sleep(1100 * time.Millisecond)
+	submitBoth()
+
+	if got := callCount(settledNonce); got != 1 {
+		t.Errorf("Expected settled result still cached, got %d calls", got)
+	}
+	if got := callCount(pendingNonce); got != 2 {
+		t.Errorf("Expected pending result refreshed after its TTL, got %d calls", got)
+	}
+}
+
+// TestBackoff_Jitter tests that full jitter spreads delays while "none" is deterministic
+func TestBackoff_Jitter(t *testing.T) {
+	exact := &facilitator.Backoff{Base: 100 * time.Millisecond, Max: time.Second, Jitter: config.JitterNone}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for attempt, want := range expected {
+		for i := 0; i < 3; i++ {
+			if got := exact.Delay(attempt); got != want {
+				t.Errorf("Attempt %d: expected deterministic delay %v, got %v", attempt, want, got)
+			}
+		}
+	}
+
+	jittered := &facilitator.Backoff{Base: 100 * time.Millisecond, Max: time.Second, Jitter: config.JitterFull}
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		delay := jittered.Delay(3)
+		if delay < 0 || delay > 800*time.Millisecond {
+			t.Fatalf("Jittered delay %v outside [0, 800ms]", delay)
+		}
+		seen[delay] = true
+	}
+	if len(seen) < 2 {
+		t.Error("Expected jittered delays to vary across attempts")
+	}
+}
+
+// TestFacilitatorClient_RetriesServerErrors tests that 5xx responses are retried up to max_retries
+func TestFacilitatorClient_RetriesServerErrors(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		attempt := calls
+		mu.Unlock()
+
+		if attempt <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "settled",
+			"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+		})
+	}))
+	defer server.Close()
+
+	newClient := func(maxRetries int) *facilitator.Client {
+		return facilitator.NewClient(&config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base": {
+					ChainID:        8453,
+					USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+					FacilitatorURL: server.URL,
+				},
+			},
+			Cache:            config.CacheConfig{SettlementTTLMinutes: 10},
+			Retry:            config.RetryConfig{MaxRetries: maxRetries, BaseDelayMs: 1, Jitter: config.JitterNone},
+			AllowPrivateURLs: true, // httptest facilitators listen on loopback
+		}, 5*time.Second)
+	}
+
+	auth := &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1700000000,
+		ValidBefore: 1700003600,
+		Nonce:       "0x0000000000000000000000000000000000000000000000000000000000000033",
+		V:           27,
+		R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+
+	// One retry is not enough to outlast two failures
+	if _, err := newClient(1).SubmitSettlement(auth, "base"); err == nil {
+		t.Fatal("Expected server error after exhausting retries")
+	}
+
+	mu.Lock()
+	calls = 0
+	mu.Unlock()
+
+	result, err := newClient(2).SubmitSettlement(auth, "base")
+	if err != nil {
+		t.Fatalf("Expected settlement after retries, got: %v", err)
+	}
+	if result.Status != "settled" {
+		t.Errorf("Expected status 'settled', got %s", result.Status)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 3 {
+		t.Errorf("Expected 3 facilitator calls (2 retries), got %d", calls)
+	}
+}
+
+// TestFacilitatorClient_RetryableStatuses tests that only transient failures are retried
+func TestFacilitatorClient_RetryableStatuses(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          map[string]interface{}
+		expectedCalls int
+	}{
+		{"500 retried", http.StatusInternalServerError, nil, 3},
+		{"502 retried", http.StatusBadGateway, nil, 3},
+		{"503 retried", http.StatusServiceUnavailable, nil, 3},
+		{"501 not retried", http.StatusNotImplemented, nil, 1},
+		{"400 not retried", http.StatusBadRequest, map[string]interface{}{"error": "invalid signature"}, 1},
+		{"pending returned immediately", http.StatusAccepted, map[string]interface{}{"status": "pending", "retry_after": 30}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statusCode)
+				if tt.body != nil {
+					json.NewEncoder(w).Encode(tt.body)
+				}
+			}))
+			defer server.Close()
+
+			client := facilitator.NewClient(&config.Config{
+				Networks: map[string]config.NetworkConfig{
+					"base": {
+						ChainID:        8453,
+						USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+						FacilitatorURL: server.URL,
+					},
+				},
+				Cache:            config.CacheConfig{SettlementTTLMinutes: 10},
+				Retry:            config.RetryConfig{MaxRetries: 2, BaseDelayMs: 1, Jitter: config.JitterNone},
+				AllowPrivateURLs: true, // httptest facilitators listen on loopback
+			}, 5*time.Second)
+
+			auth := &eip3009.EIP3009Authorization{
+				From:        "0x1111111111111111111111111111111111111111",
+				To:          "0x2222222222222222222222222222222222222222",
+				Value:       "50000",
+				ValidAfter:  1700000000,
+				ValidBefore: 1700003600,
+				Nonce:       "0x0000000000000000000000000000000000000000000000000000000000000034",
+				V:           27,
+				R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+				S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+			}
+
+			// The outcome depends on the status; only the number of attempts matters here
+			_, _ = client.SubmitSettlement(auth, "base")
+
+			if got := int(calls.Load()); got != tt.expectedCalls {
+				t.Errorf("Expected %d facilitator calls, got %d", tt.expectedCalls, got)
+			}
+		})
+	}
+}
+
+// TestFacilitatorClient_RedirectPolicy tests that redirects to another host are refused by default
+func TestFacilitatorClient_RedirectPolicy(t *testing.T) {
+	var mu sync.Mutex
+	otherHits := 0
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		otherHits++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "settled"})
+	}))
+	defer other.Close()
+
+	// 307 preserves the method and body, so following it would re-send the signed authorization
+	redirecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL, http.StatusTemporaryRedirect)
+	}))
+	defer redirecting.Close()
+
+	newClient := func(redirects config.RedirectConfig) *facilitator.Client {
+		return facilitator.NewClient(&config.Config{
+			Networks: map[string]config.NetworkConfig{
+				"base": {
+					ChainID:        8453,
+					USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+					FacilitatorURL: redirecting.URL,
+				},
+			},
+			Cache:            config.CacheConfig{SettlementTTLMinutes: 10},
+			Redirects:        redirects,
+			AllowPrivateURLs: true, // httptest facilitators listen on loopback
+		}, 5*time.Second)
+	}
+
+	auth := &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1700000000,
+		ValidBefore: 1700003600,
+		Nonce:       "0x0000000000000000000000000000000000000000000000000000000000000044",
+		V:           27,
+		R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+
+	refused := []config.RedirectConfig{
+		{},                // Default: no redirects
+		{MaxRedirects: 1}, // Redirects allowed, but not to another host
+	}
+	for _, redirects := range refused {
+		_, err := newClient(redirects).SubmitSettlement(auth, "base")
+		if !errors.Is(err, netguard.ErrRedirectNotAllowed) {
+			t.Errorf("Expected ErrRedirectNotAllowed with %+v, got: %v", redirects, err)
+		}
+	}
+
+	mu.Lock()
+	if otherHits != 0 {
+		t.Errorf("Expected redirect target to receive no requests, got %d", otherHits)
+	}
+	mu.Unlock()
+
+	result, err := newClient(config.RedirectConfig{MaxRedirects: 1, AllowCrossHost: true}).SubmitSettlement(auth, "base")
+	if err != nil {
+		t.Fatalf("Expected explicitly allowed cross-host redirect to be followed, got: %v", err)
+	}
+	if result.Status != "settled" {
+		t.Errorf("Expected status 'settled', got %s", result.Status)
+	}
+}
+
+// TestFacilitatorClient_SubmitSettlementRaw tests that the raw response exposes fields the typed response does not model
+func TestFacilitatorClient_SubmitSettlementRaw(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":       "settled",
+			"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			"block_number": 12345678,
+			"gas_used":     "61234",
+			"facilitator": map[string]interface{}{
+				"region": "us-east-1",
+			},
+		})
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: server.URL,
+			},
+		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+
+	client := facilitator.NewClient(cfg, 5*time.Second)
+
+	auth := &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1700000000,
+		ValidBefore: 1700003600,
+		Nonce:       "0x00000000000000000000000000000000000000000000000000000000000000aa",
+		V:           27,
+		R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+
+	response, raw, err := client.SubmitSettlementRaw(auth, "base")
+	if err != nil {
+		t.Fatalf("Settlement submission failed: %v", err)
+	}
+
+	if response.Status != "settled" || response.BlockNumber != 12345678 {
+		t.Errorf("Expected typed settled response at block 12345678, got %+v", response)
+	}
+
+	if raw["gas_used"] != "61234" {
+		t.Errorf("Expected raw gas_used '61234', got %v", raw["gas_used"])
+	}
+	extras, ok := raw["facilitator"].(map[string]interface{})
+	if !ok || extras["region"] != "us-east-1" {
+		t.Errorf("Expected raw facilitator.region 'us-east-1', got %v", raw["facilitator"])
+	}
+	if _, exists := response.ToMap()["gas_used"]; exists {
+		t.Error("Expected typed response to omit unmodeled gas_used")
+	}
+
+	// Cached replays still carry the raw body; callers cannot mutate the cached copy
+	raw["gas_used"] = "tampered"
+	_, replay, err := client.SubmitSettlementRaw(auth, "base")
+	if err != nil {
+		t.Fatalf("Cached settlement lookup failed: %v", err)
+	}
+	if replay["gas_used"] != "61234" {
+		t.Errorf("Expected cached raw gas_used '61234', got %v", replay["gas_used"])
+	}
+}
+
+// graceTestClock is a settable time source for driving settlement cache ages
+type graceTestClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *graceTestClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *graceTestClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// graceTestAuthorization is the authorization settled by the grace window tests
+func graceTestAuthorization() *eip3009.EIP3009Authorization {
+	return &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1700000000,
+		ValidBefore: 1700003600,
+		Nonce:       "0x00000000000000000000000000000000000000000000000000000000000000bb",
+		V:           27,
+		R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+}
+
+// graceTestConfig caches pending results for 1s and serves expired results for 2s more
+func graceTestConfig(facilitatorURL string) *config.Config {
+	return &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: facilitatorURL,
+			},
+		},
+		Cache: config.CacheConfig{
+			SettlementTTLMinutes: 10,
+			PendingTTLSeconds:    1,
+			GraceMs:              2000,
+		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+}
+
+// TestFacilitatorClient_CacheGraceWindow tests that reads hammering an expired pending result
+// are served the stale result while exactly one status check refreshes it, and that the
+// signed authorization is never re-submitted
+func TestFacilitatorClient_CacheGraceWindow(t *testing.T) {
+	var submits, statusChecks atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			statusChecks.Add(1)
+			<-release // Hold the refresh open while reads continue
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":  "settled",
+				"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+			})
+			return
+		}
+
+		submits.Add(1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":      "pending",
+			"retry_after": 5,
+		})
+	}))
+	defer server.Close()
+
+	clock := &graceTestClock{now: time.Now()}
+	client := facilitator.NewClient(graceTestConfig(server.URL), 5*time.Second)
+	client.SetClock(clock.Now)
+	auth := graceTestAuthorization()
+
+	if _, err := client.SubmitSettlement(auth, "base"); err != nil {
+		t.Fatalf("Settlement submission failed: %v", err)
+	}
+
+	// Just past the pending TTL, inside the grace window
+	clock.Advance(1500 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				response, err := client.SubmitSettlement(auth, "base")
+				if err != nil {
+					errs <- err
+					return
+				}
+				if response.Status != "pending" {
+					errs <- fmt.Errorf("unexpected status %s", response.Status)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Read in grace window failed: %v", err)
+	}
+
+	// Let the single refresh complete, then the refreshed result is served
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		cached := client.CachedSettlement("base", auth.Nonce)
+		if cached != nil && cached.Status == "settled" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the refresh to record the settled status, got %+v", cached)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := submits.Load(); n != 1 {
+		t.Errorf("Expected exactly 1 submission, got %d", n)
+	}
+	if n := statusChecks.Load(); n != 1 {
+		t.Errorf("Expected exactly 1 status check refresh, got %d", n)
+	}
+}
+
+// TestFacilitatorClient_CacheGraceWindowSettled tests that a settled result in its grace
+// window is served with its TTL extended, without any facilitator call
+func TestFacilitatorClient_CacheGraceWindowSettled(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "failed", "error": "authorization already used"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "settled",
+			"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+		})
+	}))
+	defer server.Close()
+
+	clock := &graceTestClock{now: time.Now()}
+	client := facilitator.NewClient(graceTestConfig(server.URL), 5*time.Second)
+	client.SetClock(clock.Now)
+	auth := graceTestAuthorization()
+
+	if _, err := client.SubmitSettlement(auth, "base"); err != nil {
+		t.Fatalf("Settlement submission failed: %v", err)
+	}
+
+	// Past the 10 minute settled TTL, inside the grace window
+	clock.Advance(10*time.Minute + time.Second)
+	response, err := client.SubmitSettlement(auth, "base")
+	if err != nil {
+		t.Fatalf("Read in grace window failed: %v", err)
+	}
+	if response.Status != "settled" {
+		t.Fatalf("Expected the settled result, got %s", response.Status)
+	}
+
+	// The TTL restarted at that read, so the result is still live well past the original expiry
+	clock.Advance(9 * time.Minute)
+	if cached := client.CachedSettlement("base", auth.Nonce); cached == nil || cached.Status != "settled" {
+		t.Fatalf("Expected the settled result with its TTL extended, got %+v", cached)
+	}
+
+	// No refresh runs in the background either
+	time.Sleep(50 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected only the original submission, got %d facilitator calls", n)
+	}
+}
+
+// TestFacilitatorClient_CacheStats tests that idempotent resubmissions count as settlement cache hits
+func TestFacilitatorClient_CacheStats(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "settled",
+			"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+		})
+	}))
+	defer server.Close()
+
+	client := facilitator.NewClient(&config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: server.URL,
+			},
+		},
+		Cache:            config.CacheConfig{SettlementTTLMinutes: 10},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}, 5*time.Second)
+
+	auth := &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1700000000,
+		ValidBefore: 1700003600,
+		Nonce:       "0x0000000000000000000000000000000000000000000000000000000000000035",
+		V:           27,
+		R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.SubmitSettlement(auth, "base"); err != nil {
+			t.Fatalf("SubmitSettlement failed: %v", err)
+		}
+	}
+
+	stats := client.CacheStats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("Expected 1 hit, 1 miss, size 1, got %+v", stats)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("Expected 1 facilitator call, got %d", calls.Load())
+	}
+}
+
+// TestFacilitatorClient_CacheSweeper tests background removal of expired settlement results
+func TestFacilitatorClient_CacheSweeper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": "invalid signature"})
+	}))
+	defer server.Close()
+
+	client := facilitator.NewClient(&config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: server.URL,
+			},
+		},
+		// Failed results are cached for a second and swept every second
+		Cache:            config.CacheConfig{SettlementTTLMinutes: 10, PendingTTLSeconds: 1, SweepIntervalSeconds: 1},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}, 5*time.Second)
+
+	auth := &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1700000000,
+		ValidBefore: 1700003600,
+		Nonce:       "0x0000000000000000000000000000000000000000000000000000000000000036",
+		V:           27,
+		R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		S:           "0xfedcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+	if _, err := client.SubmitSettlement(auth, "base"); err != nil {
+		t.Fatalf("SubmitSettlement failed: %v", err)
+	}
+	if size := client.CacheStats().Size; size != 1 {
+		t.Fatalf("Expected the failed result to be cached, got size %d", size)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for client.CacheStats().Size > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if stats := client.CacheStats(); stats.Size != 0 || stats.Evictions != 1 {
+		t.Errorf("Expected the sweeper to evict the expired result, got %+v", stats)
+	}
+
+	// Idle HTTP connections hold goroutines too, so only a drop is asserted
+	before := runtime.NumGoroutine()
+	client.Close()
+
+	deadline = time.Now().Add(time.Second)
+	for runtime.NumGoroutine() >= before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after >= before {
+		t.Errorf("Expected sweeper goroutine to exit after Close: %d goroutines before, %d after", before, after)
+	}
+}
+
+// TestFacilitatorResponse_NumericOrStringFields tests that block_number and retry_after decode
+// from both JSON numbers and decimal strings
+func TestFacilitatorResponse_NumericOrStringFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		blockNumber uint64
+		retryAfter  int
+		expectError bool
+	}{
+		{"numbers", `{"status":"settled","tx_hash":"0xabc","block_number":12345678,"retry_after":5}`, 12345678, 5, false},
+		{"strings", `{"status":"settled","tx_hash":"0xabc","block_number":"12345678","retry_after":"5"}`, 12345678, 5, false},
+		{"absent and null", `{"status":"pending","block_number":null}`, 0, 0, false},
+		{"empty string", `{"status":"pending","block_number":"","retry_after":""}`, 0, 0, false},
+		{"non-numeric string", `{"status":"settled","block_number":"latest"}`, 0, 0, true},
+		{"negative retry_after", `{"status":"pending","retry_after":"-1"}`, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response facilitator.FacilitatorResponse
+			err := json.Unmarshal([]byte(tt.body), &response)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error decoding %s", tt.body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to decode %s: %v", tt.body, err)
+			}
+
+			if response.BlockNumber != tt.blockNumber || response.RetryAfter != tt.retryAfter {
+				t.Errorf("Expected block_number=%d retry_after=%d, got %d and %d",
+					tt.blockNumber, tt.retryAfter, response.BlockNumber, response.RetryAfter)
+			}
+			if response.Status == "" {
+				t.Error("Expected the other fields to decode as before")
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/inflight"
+)
+
+// TestLimiter_SaturatedNetwork tests that a full network times out while others proceed
+func TestLimiter_SaturatedNetwork(t *testing.T) {
+	limiter := inflight.NewLimiter(map[string]int{"base": 2, "arbitrum": 1}, 20*time.Millisecond)
+
+	releaseA, err := limiter.Acquire("base")
+	if err != nil {
+		t.Fatalf("First acquire failed: %v", err)
+	}
+	releaseB, err := limiter.Acquire("base")
+	if err != nil {
+		t.Fatalf("Second acquire failed: %v", err)
+	}
+
+	if got := limiter.InFlight("base"); got != 2 {
+		t.Errorf("Expected 2 in flight, got %d", got)
+	}
+
+	start := time.Now()
+	if _, err := limiter.Acquire("base"); !errors.Is(err, inflight.ErrQueueFull) {
+		t.Fatalf("Expected ErrQueueFull, got %v", err)
+	}
+	if waited := time.Since(start); waited < 20*time.Millisecond {
+		t.Errorf("Expected to wait for the deadline, waited %v", waited)
+	}
+
+	releaseArb, err := limiter.Acquire("arbitrum")
+	if err != nil {
+		t.Fatalf("Other network should not be affected: %v", err)
+	}
+	releaseArb()
+
+	// Releasing twice must only free one slot
+	releaseA()
+	releaseA()
+	if got := limiter.InFlight("base"); got != 1 {
+		t.Errorf("Expected 1 in flight after release, got %d", got)
+	}
+
+	if _, err := limiter.Acquire("base"); err != nil {
+		t.Errorf("Acquire after release failed: %v", err)
+	}
+	releaseB()
+}
+
+// TestLimiter_WaitsForRelease tests that a waiting call takes a slot freed before the deadline
+func TestLimiter_WaitsForRelease(t *testing.T) {
+	limiter := inflight.NewLimiter(map[string]int{"base": 1}, time.Second)
+
+	release, err := limiter.Acquire("base")
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+
+	if _, err := limiter.Acquire("base"); err != nil {
+		t.Errorf("Expected slot after release, got %v", err)
+	}
+}
+
+// TestLimiter_Unbounded tests that networks without a limit never block
+func TestLimiter_Unbounded(t *testing.T) {
+	limiter := inflight.NewLimiter(map[string]int{"base": 0}, time.Millisecond)
+
+	for i := 0; i < 100; i++ {
+		if _, err := limiter.Acquire("base"); err != nil {
+			t.Fatalf("Unbounded acquire %d failed: %v", i, err)
+		}
+	}
+}
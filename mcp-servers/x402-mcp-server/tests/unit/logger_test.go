@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+)
+
+// TestLogger_RedactFields tests that listed field keys are truncated in the JSON output
+// while other fields and the caller's map are left untouched
+func TestLogger_RedactFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(logger.INFO, &buf)
+	log.SetRedactFields([]string{"nonce", "r"})
+
+	nonce := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	from := "0x0000000000000000000000000000000000000001"
+	fields := map[string]interface{}{
+		"nonce":   nonce,
+		"from":    from,
+		"network": "base",
+	}
+	log.Info("Verifying payment authorization", fields)
+
+	var entry struct {
+		Fields map[string]interface{} `json:"Fields"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log entry %q: %v", buf.String(), err)
+	}
+
+	if entry.Fields["nonce"] != "0x1234…" {
+		t.Errorf("Expected nonce redacted to 0x1234…, got %v", entry.Fields["nonce"])
+	}
+	if entry.Fields["from"] != from || entry.Fields["network"] != "base" {
+		t.Errorf("Expected unlisted fields untouched, got %v", entry.Fields)
+	}
+	if fields["nonce"] != nonce {
+		t.Error("Redaction must not modify the caller's fields")
+	}
+
+	// Clearing the list disables redaction
+	buf.Reset()
+	log.SetRedactFields(nil)
+	log.Info("Verifying payment authorization", fields)
+	if !bytes.Contains(buf.Bytes(), []byte(nonce)) {
+		t.Errorf("Expected full nonce once redaction is disabled, got %s", buf.String())
+	}
+}
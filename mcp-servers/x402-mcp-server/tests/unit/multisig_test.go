@@ -0,0 +1,181 @@
+package unit
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+)
+
+const multisigWallet = "0x9999999999999999999999999999999999999999"
+
+// createMultisigTestSetup returns owner keys, a 2-of-3 config, and an unsigned authorization from the wallet
+func createMultisigTestSetup(t *testing.T) ([]*ecdsa.PrivateKey, *config.Config, *eip3009.EIP3009Authorization) {
+	t.Helper()
+
+	owners := make([]*ecdsa.PrivateKey, 3)
+	ownerAddresses := make([]string, 3)
+	for i := range owners {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("Failed to generate owner key: %v", err)
+		}
+		owners[i] = key
+		ownerAddresses[i] = crypto.PubkeyToAddress(key.PublicKey).Hex()
+	}
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:      8453,
+				USDCContract: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			},
+		},
+		EIP712: config.EIP712Config{
+			DomainName:    "USD Coin",
+			DomainVersion: "2",
+		},
+		Verification: config.VerificationConfig{
+			Multisig: []config.MultisigWallet{
+				{Wallet: multisigWallet, Threshold: 2, Owners: ownerAddresses},
+			},
+		},
+	}
+
+	now := time.Now().Unix()
+	auth := &eip3009.EIP3009Authorization{
+		From:        multisigWallet,
+		To:          "0x1234567890123456789012345678901234567890",
+		Value:       "50000",
+		ValidAfter:  uint64(now - 60),
+		ValidBefore: uint64(now + 3600),
+		Nonce:       "0x00000000000000000000000000000000000000000000000000000000000000aa",
+	}
+
+	return owners, cfg, auth
+}
+
+// signMultisig signs the authorization's typed data with the given key
+func signMultisig(t *testing.T, key *ecdsa.PrivateKey, auth *eip3009.EIP3009Authorization) eip3009.Signature {
+	t.Helper()
+
+	message, err := auth.ToMessage()
+	if err != nil {
+		t.Fatalf("Failed to convert authorization: %v", err)
+	}
+
+	domain := &eip3009.EIP712Domain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(8453),
+		VerifyingContract: common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"),
+	}
+
+	typedDataHash, err := eip3009.TypedDataHash(domain, message)
+	if err != nil {
+		t.Fatalf("Failed to compute typed data hash: %v", err)
+	}
+
+	signature, err := crypto.Sign(typedDataHash.Bytes(), key)
+	if err != nil {
+		t.Fatalf("Failed to sign: %v", err)
+	}
+
+	return eip3009.Signature{
+		V: signature[64] + 27,
+		R: common.BytesToHash(signature[0:32]).Hex(),
+		S: common.BytesToHash(signature[32:64]).Hex(),
+	}
+}
+
+// TestMultisigVerification_Threshold tests threshold enforcement with sufficient and insufficient signatures
+func TestMultisigVerification_Threshold(t *testing.T) {
+	owners, cfg, auth := createMultisigTestSetup(t)
+
+	outsider, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		signers      []*ecdsa.PrivateKey
+		expectValid  bool
+		expectedCode string
+	}{
+		{"threshold met", []*ecdsa.PrivateKey{owners[0], owners[2]}, true, ""},
+		{"all owners", owners, true, ""},
+		{"single owner", []*ecdsa.PrivateKey{owners[1]}, false, eip3009.ErrorCodeInsufficientSignatures},
+		{"owner plus outsider", []*ecdsa.PrivateKey{owners[0], outsider}, false, eip3009.ErrorCodeInsufficientSignatures},
+		{"duplicate owner", []*ecdsa.PrivateKey{owners[0], owners[0]}, false, eip3009.ErrorCodeDuplicateSigner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signatures := make([]eip3009.Signature, 0, len(tt.signers))
+			for _, key := range tt.signers {
+				signatures = append(signatures, signMultisig(t, key, auth))
+			}
+
+			result, err := eip3009.NewSignatureVerifier(cfg).VerifyMultisigAuthorization(auth, signatures, "base")
+			if err != nil {
+				t.Fatalf("VerifyMultisigAuthorization returned error: %v", err)
+			}
+
+			if result.IsValid != tt.expectValid {
+				t.Errorf("Expected is_valid=%v, got %v (%s)", tt.expectValid, result.IsValid, result.Error)
+			}
+
+			if result.ErrorCode != tt.expectedCode {
+				t.Errorf("Expected error_code '%s', got '%s'", tt.expectedCode, result.ErrorCode)
+			}
+		})
+	}
+}
+
+// TestMultisigVerification_NotConfigured tests rejection of payers without a multisig policy
+func TestMultisigVerification_NotConfigured(t *testing.T) {
+	owners, cfg, auth := createMultisigTestSetup(t)
+	auth.From = "0x8888888888888888888888888888888888888888"
+
+	signatures := []eip3009.Signature{signMultisig(t, owners[0], auth), signMultisig(t, owners[1], auth)}
+
+	result, err := eip3009.NewSignatureVerifier(cfg).VerifyMultisigAuthorization(auth, signatures, "base")
+	if err != nil {
+		t.Fatalf("VerifyMultisigAuthorization returned error: %v", err)
+	}
+
+	if result.IsValid || result.ErrorCode != eip3009.ErrorCodeMultisigNotConfigured {
+		t.Errorf("Expected multisig_not_configured, got %+v", result)
+	}
+}
+
+// TestMultisigWallet_Validate tests multisig policy validation
+func TestMultisigWallet_Validate(t *testing.T) {
+	owner := "0x1111111111111111111111111111111111111111"
+	tests := []struct {
+		name    string
+		wallet  config.MultisigWallet
+		wantErr bool
+	}{
+		{"valid", config.MultisigWallet{Wallet: multisigWallet, Threshold: 1, Owners: []string{owner}}, false},
+		{"threshold too high", config.MultisigWallet{Wallet: multisigWallet, Threshold: 2, Owners: []string{owner}}, true},
+		{"zero threshold", config.MultisigWallet{Wallet: multisigWallet, Threshold: 0, Owners: []string{owner}}, true},
+		{"duplicate owner", config.MultisigWallet{Wallet: multisigWallet, Threshold: 1, Owners: []string{owner, owner}}, true},
+		{"bad wallet", config.MultisigWallet{Wallet: "0x123", Threshold: 1, Owners: []string{owner}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.wallet.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/netguard"
+)
+
+func TestNetguard_CheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		private bool
+	}{
+		{"http://127.0.0.1:8080/settle", true},
+		{"http://localhost:8080", true},
+		{"http://api.localhost", true},
+		{"http://[::1]:8545", true},
+		{"http://10.0.0.5", true},
+		{"http://192.168.1.10", true},
+		{"http://172.16.0.1", true},
+		{"http://169.254.169.254/latest/meta-data", true},
+		{"http://0.0.0.0", true},
+		{"https://api.cdp.coinbase.com/x402/base", false},
+		{"https://mainnet.base.org", false},
+		{"https://8.8.8.8", false},
+	}
+
+	for _, tt := range tests {
+		err := netguard.CheckURL(tt.url, false)
+		if tt.private && !errors.Is(err, netguard.ErrPrivateAddress) {
+			t.Errorf("CheckURL(%s): expected ErrPrivateAddress, got %v", tt.url, err)
+		}
+		if !tt.private && err != nil {
+			t.Errorf("CheckURL(%s): expected no error, got %v", tt.url, err)
+		}
+
+		// The explicit flag permits any host
+		if err := netguard.CheckURL(tt.url, true); err != nil {
+			t.Errorf("CheckURL(%s, allowPrivate): expected no error, got %v", tt.url, err)
+		}
+	}
+
+	if err := netguard.CheckURL("not a url", true); err == nil {
+		t.Error("Expected error for URL without host")
+	}
+}
+
+func TestNetguard_IsPrivateIP(t *testing.T) {
+	if !netguard.IsPrivateIP(net.ParseIP("fd00::1")) {
+		t.Error("Expected unique local IPv6 to be private")
+	}
+	if netguard.IsPrivateIP(net.ParseIP("1.1.1.1")) {
+		t.Error("Expected public IPv4 not to be private")
+	}
+}
+
+// TestNetguard_HTTPClient tests that resolved loopback connections are refused by default
+func TestNetguard_HTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if _, err := netguard.HTTPClient(false).Get(server.URL); !errors.Is(err, netguard.ErrPrivateAddress) {
+		t.Errorf("Expected loopback connection to be refused, got %v", err)
+	}
+
+	resp, err := netguard.HTTPClient(true).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected loopback connection with allow flag, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+// TestNetguard_FacilitatorLoopback tests the policy at config load and before each request
+func TestNetguard_FacilitatorLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: server.URL,
+				RPCURL:         "https://mainnet.base.org",
+				PayeeAddress:   "0x1234567890123456789012345678901234567890",
+			},
+		},
+		EIP712: config.EIP712Config{DomainName: "USD Coin", DomainVersion: "2"},
+		Cache:  config.CacheConfig{SettlementTTLMinutes: 10},
+	}
+
+	if err := cfg.Validate(); !errors.Is(err, netguard.ErrPrivateAddress) {
+		t.Errorf("Expected config load to reject loopback facilitator_url, got %v", err)
+	}
+
+	client := facilitator.NewClient(cfg, 5*time.Second)
+	if _, err := client.SubmitSettlement(createOnChainTestAuthorization(), "base"); !errors.Is(err, netguard.ErrPrivateAddress) {
+		t.Errorf("Expected request to loopback facilitator to be refused, got %v", err)
+	}
+
+	cfg.AllowPrivateURLs = true
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected loopback facilitator_url allowed with allow_private_urls, got %v", err)
+	}
+}
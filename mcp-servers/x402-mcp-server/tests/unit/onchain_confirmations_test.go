@@ -0,0 +1,137 @@
+package unit
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+)
+
+const confirmationsTestTxHash = "0x00000000000000000000000000000000000000000000000000000000000000aa"
+
+// mockBlockReader simulates an RPC node at a fixed head serving a fixed set of receipts
+type mockBlockReader struct {
+	head     uint64
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (m *mockBlockReader) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.head, nil
+}
+
+func (m *mockBlockReader) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	receipt, exists := m.receipts[hash]
+	if !exists {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+// newConfirmationCounter builds a counter requiring minConfirmations on base, whose RPC
+// node is at head with the test transaction mined in txBlock
+func newConfirmationCounter(minConfirmations, head, txBlock uint64, status uint64) *onchain.ConfirmationCounter {
+	cfg := createOnChainTestConfig(0)
+	networkCfg := cfg.Networks["base"]
+	networkCfg.Confirmations = minConfirmations
+	cfg.Networks["base"] = networkCfg
+
+	counter := onchain.NewConfirmationCounter(cfg, 5*time.Second)
+	counter.SetBackend("base", &mockBlockReader{
+		head: head,
+		receipts: map[common.Hash]*types.Receipt{
+			common.HexToHash(confirmationsTestTxHash): {
+				Status:      status,
+				BlockNumber: new(big.Int).SetUint64(txBlock),
+			},
+		},
+	})
+	return counter
+}
+
+// TestConfirmationCounter_Threshold tests that settled is reported only at the configured minimum
+func TestConfirmationCounter_Threshold(t *testing.T) {
+	tests := []struct {
+		name                  string
+		head                  uint64
+		expectedConfirmations uint64
+		expectedStatus        string
+	}{
+		{"below threshold", 1002, 2, onchain.StatusPending},
+		{"at threshold", 1003, 3, onchain.StatusSettled},
+		{"above threshold", 1010, 10, onchain.StatusSettled},
+		{"lagging node", 999, 0, onchain.StatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := newConfirmationCounter(3, tt.head, 1000, types.ReceiptStatusSuccessful)
+
+			result, err := counter.Status("base", confirmationsTestTxHash)
+			if err != nil {
+				t.Fatalf("Status returned error: %v", err)
+			}
+
+			if !result.Mined || result.BlockNumber != 1000 {
+				t.Errorf("Expected tx mined in block 1000, got mined=%v block=%d", result.Mined, result.BlockNumber)
+			}
+			if result.Confirmations != tt.expectedConfirmations {
+				t.Errorf("Expected %d confirmations, got %d", tt.expectedConfirmations, result.Confirmations)
+			}
+			if result.Required != 3 {
+				t.Errorf("Expected 3 required confirmations, got %d", result.Required)
+			}
+			if result.Status != tt.expectedStatus {
+				t.Errorf("Expected status '%s', got '%s'", tt.expectedStatus, result.Status)
+			}
+		})
+	}
+}
+
+// TestConfirmationCounter_Unmined tests that a transaction without a receipt is pending
+func TestConfirmationCounter_Unmined(t *testing.T) {
+	counter := onchain.NewConfirmationCounter(createOnChainTestConfig(0), 5*time.Second)
+	counter.SetBackend("base", &mockBlockReader{head: 1000})
+
+	result, err := counter.Status("base", confirmationsTestTxHash)
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+
+	if result.Mined || result.Confirmations != 0 || result.Status != onchain.StatusPending {
+		t.Errorf("Expected unmined pending tx, got %+v", result)
+	}
+	if _, exists := result.ToMap()["block_number"]; exists {
+		t.Error("Expected no block_number for an unmined tx")
+	}
+}
+
+// TestConfirmationCounter_Reverted tests that a reverted transaction fails regardless of depth
+func TestConfirmationCounter_Reverted(t *testing.T) {
+	counter := newConfirmationCounter(1, 1010, 1000, types.ReceiptStatusFailed)
+
+	result, err := counter.Status("base", confirmationsTestTxHash)
+	if err != nil {
+		t.Fatalf("Status returned error: %v", err)
+	}
+
+	if result.Status != onchain.StatusFailed {
+		t.Errorf("Expected status 'failed', got '%s'", result.Status)
+	}
+}
+
+// TestConfirmationCounter_InvalidInput tests rejection of unknown networks and malformed hashes
+func TestConfirmationCounter_InvalidInput(t *testing.T) {
+	counter := newConfirmationCounter(1, 1010, 1000, types.ReceiptStatusSuccessful)
+
+	if _, err := counter.Status("optimism", confirmationsTestTxHash); err == nil {
+		t.Error("Expected error for unsupported network")
+	}
+	if _, err := counter.Status("base", "0x1234"); err == nil {
+		t.Error("Expected error for malformed tx_hash")
+	}
+}
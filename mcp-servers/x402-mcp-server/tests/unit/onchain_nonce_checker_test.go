@@ -0,0 +1,164 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+)
+
+// mockStateReader answers authorizationState from a set of used (from, nonce) pairs
+type mockStateReader struct {
+	used  map[string]bool // lowercase from + ":" + nonce hash hex
+	fail  map[string]bool
+	delay time.Duration
+
+	mu        sync.Mutex
+	contracts map[common.Address]bool
+
+	active    int32
+	maxActive int32
+}
+
+func (m *mockStateReader) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	active := atomic.AddInt32(&m.active, 1)
+	defer atomic.AddInt32(&m.active, -1)
+	for {
+		peak := atomic.LoadInt32(&m.maxActive)
+		if active <= peak || atomic.CompareAndSwapInt32(&m.maxActive, peak, active) {
+			break
+		}
+	}
+	time.Sleep(m.delay)
+
+	m.mu.Lock()
+	if m.contracts == nil {
+		m.contracts = make(map[common.Address]bool)
+	}
+	m.contracts[*call.To] = true
+	m.mu.Unlock()
+
+	// Calldata: selector + address word + nonce word
+	from := common.BytesToAddress(call.Data[4:36])
+	nonce := common.BytesToHash(call.Data[36:68])
+	key := fmt.Sprintf("%s:%s", from.Hex(), nonce.Hex())
+
+	if m.fail[key] {
+		return nil, fmt.Errorf("execution reverted")
+	}
+
+	output := make([]byte, 32)
+	if m.used[key] {
+		output[31] = 1
+	}
+	return output, nil
+}
+
+func nonceHex(label string) string {
+	return crypto.Keccak256Hash([]byte(label)).Hex()
+}
+
+// TestNonceChecker_MixedStates tests a batch returning used, unused, and per-entry errors in order
+func TestNonceChecker_MixedStates(t *testing.T) {
+	payer := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	usedNonce, unusedNonce, failingNonce := nonceHex("used"), nonceHex("unused"), nonceHex("failing")
+
+	reader := &mockStateReader{
+		used: map[string]bool{payer.Hex() + ":" + usedNonce: true},
+		fail: map[string]bool{payer.Hex() + ":" + failingNonce: true},
+	}
+
+	cfg := createOnChainTestConfig(0)
+	checker := onchain.NewNonceChecker(cfg, 5*time.Second, 4)
+	checker.SetBackend("base", reader)
+
+	statuses, err := checker.Check("base", []onchain.NonceQuery{
+		{From: payer.Hex(), Nonce: usedNonce},
+		{From: payer.Hex(), Nonce: unusedNonce},
+		{From: payer.Hex(), Nonce: failingNonce},
+		{From: payer.Hex(), Nonce: "0x1234"},
+	})
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if len(statuses) != 4 {
+		t.Fatalf("Expected 4 statuses, got %d", len(statuses))
+	}
+
+	if !statuses[0].Used || statuses[0].Error != "" {
+		t.Errorf("Expected first nonce used, got %+v", statuses[0])
+	}
+	if statuses[1].Used || statuses[1].Error != "" {
+		t.Errorf("Expected second nonce unused, got %+v", statuses[1])
+	}
+	if statuses[2].Error == "" {
+		t.Errorf("Expected failed read reported on third entry, got %+v", statuses[2])
+	}
+	if statuses[3].Error == "" {
+		t.Errorf("Expected malformed nonce reported on fourth entry, got %+v", statuses[3])
+	}
+
+	// Every read targets the network's USDC contract
+	if !reader.contracts[common.HexToAddress(cfg.Networks["base"].USDCContract)] || len(reader.contracts) != 1 {
+		t.Errorf("Expected reads against the USDC contract only, got %v", reader.contracts)
+	}
+}
+
+// TestNonceChecker_BoundedConcurrency tests that parallel reads never exceed the configured limit
+func TestNonceChecker_BoundedConcurrency(t *testing.T) {
+	reader := &mockStateReader{delay: 10 * time.Millisecond}
+
+	checker := onchain.NewNonceChecker(createOnChainTestConfig(0), 5*time.Second, 3)
+	checker.SetBackend("base", reader)
+
+	queries := make([]onchain.NonceQuery, 20)
+	for i := range queries {
+		queries[i] = onchain.NonceQuery{
+			From:  "0x1111111111111111111111111111111111111111",
+			Nonce: nonceHex(fmt.Sprintf("nonce-%d", i)),
+		}
+	}
+
+	if _, err := checker.Check("base", queries); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if peak := atomic.LoadInt32(&reader.maxActive); peak > 3 || peak < 2 {
+		t.Errorf("Expected between 2 and 3 concurrent reads, peak was %d", peak)
+	}
+}
+
+// TestAuthorizationState_RoundTrip tests authorizationState calldata and result encoding
+func TestAuthorizationState_RoundTrip(t *testing.T) {
+	var nonce [32]byte
+	copy(nonce[:], []byte("state"))
+
+	calldata, err := eip3009.EncodeAuthorizationState(common.HexToAddress("0x1111111111111111111111111111111111111111"), nonce)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	// authorizationState(address,bytes32) selector
+	if fmt.Sprintf("%x", calldata[:4]) != "e94a0102" {
+		t.Errorf("Unexpected selector 0x%x", calldata[:4])
+	}
+
+	used, err := eip3009.DecodeAuthorizationState(common.LeftPadBytes([]byte{1}, 32))
+	if err != nil || !used {
+		t.Errorf("Expected used=true, got %v (err=%v)", used, err)
+	}
+
+	if _, err := eip3009.DecodeAuthorizationState([]byte{}); err == nil {
+		t.Error("Expected error decoding empty result")
+	}
+}
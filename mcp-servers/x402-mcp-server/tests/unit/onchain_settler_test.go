@@ -0,0 +1,164 @@
+package unit
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+)
+
+// mockSettlementBackend simulates an RPC node with a fixed gas price
+type mockSettlementBackend struct {
+	gasPrice *big.Int
+	sent     []*types.Transaction
+}
+
+func (m *mockSettlementBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return m.gasPrice, nil
+}
+
+func (m *mockSettlementBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 7, nil
+}
+
+func (m *mockSettlementBackend) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 90000, nil
+}
+
+func (m *mockSettlementBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	m.sent = append(m.sent, tx)
+	return nil
+}
+
+func createOnChainTestConfig(maxGasPriceGwei float64) *config.Config {
+	return &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:         8453,
+				USDCContract:    "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL:  "https://api.cdp.coinbase.com",
+				RPCURL:          "https://mainnet.base.org",
+				PayeeAddress:    "0x2222222222222222222222222222222222222222",
+				MaxGasPriceGwei: maxGasPriceGwei,
+			},
+		},
+		Settlement: config.SettlementConfig{
+			Mode:          config.SettlementModeOnChain,
+			RelayerKeyEnv: "TEST_RELAYER_KEY",
+		},
+	}
+}
+
+func createOnChainTestAuthorization() *eip3009.EIP3009Authorization {
+	return &eip3009.EIP3009Authorization{
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "50000",
+		ValidAfter:  1700000000,
+		ValidBefore: 1700003600,
+		Nonce:       "0x0000000000000000000000000000000000000000000000000000000000000001",
+		V:           27,
+		R:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
+		S:           "0x3edcba0987654321fedcba0987654321fedcba0987654321fedcba0987654321",
+	}
+}
+
+// TestOnChainSettler_GasPriceAboveCeiling tests that settlement aborts during gas spikes
+func TestOnChainSettler_GasPriceAboveCeiling(t *testing.T) {
+	relayerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate relayer key: %v", err)
+	}
+
+	backend := &mockSettlementBackend{gasPrice: big.NewInt(2_000_000_000)} // 2 gwei
+	settler := onchain.NewSettler(createOnChainTestConfig(1), relayerKey, 5*time.Second)
+	settler.SetBackend("base", backend)
+
+	response, err := settler.Settle(createOnChainTestAuthorization(), "base")
+	if err != nil {
+		t.Fatalf("Settle returned error: %v", err)
+	}
+
+	if response.Status != "failed" {
+		t.Errorf("Expected status 'failed', got '%s'", response.Status)
+	}
+
+	if response.ErrorCode != onchain.ErrorCodeGasTooHigh {
+		t.Errorf("Expected error_code '%s', got '%s'", onchain.ErrorCodeGasTooHigh, response.ErrorCode)
+	}
+
+	if response.RetryAfter <= 0 {
+		t.Error("Expected retry hint for gas_too_high")
+	}
+
+	if len(backend.sent) != 0 {
+		t.Errorf("Expected no transaction to be sent, got %d", len(backend.sent))
+	}
+}
+
+// TestOnChainSettler_GasPriceBelowCeiling tests that settlement proceeds under the ceiling
+func TestOnChainSettler_GasPriceBelowCeiling(t *testing.T) {
+	relayerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate relayer key: %v", err)
+	}
+
+	backend := &mockSettlementBackend{gasPrice: big.NewInt(500_000_000)} // 0.5 gwei
+	settler := onchain.NewSettler(createOnChainTestConfig(1), relayerKey, 5*time.Second)
+	settler.SetBackend("base", backend)
+
+	response, err := settler.Settle(createOnChainTestAuthorization(), "base")
+	if err != nil {
+		t.Fatalf("Settle returned error: %v", err)
+	}
+
+	if response.Status != "pending" {
+		t.Errorf("Expected status 'pending', got '%s' (%s)", response.Status, response.Error)
+	}
+
+	if len(backend.sent) != 1 {
+		t.Fatalf("Expected 1 transaction sent, got %d", len(backend.sent))
+	}
+
+	tx := backend.sent[0]
+	if tx.GasPrice().Cmp(backend.gasPrice) != 0 {
+		t.Errorf("Expected gas price %s, got %s", backend.gasPrice, tx.GasPrice())
+	}
+
+	if tx.Hash().Hex() != response.TxHash {
+		t.Errorf("Expected tx_hash %s, got %s", tx.Hash().Hex(), response.TxHash)
+	}
+
+	if *tx.To() != common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913") {
+		t.Errorf("Expected transaction to USDC contract, got %s", tx.To().Hex())
+	}
+}
+
+// TestOnChainSettler_NoCeiling tests that a zero ceiling disables the gas check
+func TestOnChainSettler_NoCeiling(t *testing.T) {
+	relayerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate relayer key: %v", err)
+	}
+
+	backend := &mockSettlementBackend{gasPrice: big.NewInt(500_000_000_000)} // 500 gwei
+	settler := onchain.NewSettler(createOnChainTestConfig(0), relayerKey, 5*time.Second)
+	settler.SetBackend("base", backend)
+
+	response, err := settler.Settle(createOnChainTestAuthorization(), "base")
+	if err != nil {
+		t.Fatalf("Settle returned error: %v", err)
+	}
+
+	if response.Status != "pending" {
+		t.Errorf("Expected status 'pending' with no ceiling, got '%s'", response.Status)
+	}
+}
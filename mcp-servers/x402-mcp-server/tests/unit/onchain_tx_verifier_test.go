@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+)
+
+// mockTxReader simulates an RPC node serving a fixed set of transactions
+type mockTxReader struct {
+	txs map[common.Hash]*types.Transaction
+}
+
+func (m *mockTxReader) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	tx, exists := m.txs[hash]
+	if !exists {
+		return nil, false, fmt.Errorf("not found")
+	}
+	return tx, false, nil
+}
+
+// settlementTx builds a transaction to the given contract carrying calldata for auth
+func settlementTx(t *testing.T, contract string, auth *eip3009.EIP3009Authorization) *types.Transaction {
+	t.Helper()
+
+	calldata, err := eip3009.EncodeReceiveWithAuthorization(auth)
+	if err != nil {
+		t.Fatalf("Failed to encode calldata: %v", err)
+	}
+
+	to := common.HexToAddress(contract)
+	return types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Gas: 90000, Data: calldata})
+}
+
+func newTxVerifierWithTx(t *testing.T, tx *types.Transaction) *onchain.TxVerifier {
+	t.Helper()
+
+	verifier := onchain.NewTxVerifier(createOnChainTestConfig(0), 5*time.Second)
+	verifier.SetBackend("base", &mockTxReader{txs: map[common.Hash]*types.Transaction{tx.Hash(): tx}})
+	return verifier
+}
+
+// TestTxVerifier_Match tests that on-chain calldata matching the authorization passes
+func TestTxVerifier_Match(t *testing.T) {
+	auth := createOnChainTestAuthorization()
+	tx := settlementTx(t, createOnChainTestConfig(0).Networks["base"].USDCContract, auth)
+
+	result, err := newTxVerifierWithTx(t, tx).Verify(auth, "base", tx.Hash().Hex())
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+
+	if !result.Match {
+		t.Errorf("Expected match, got error_code=%s error=%s", result.ErrorCode, result.Error)
+	}
+
+	if result.TxHash != tx.Hash().Hex() {
+		t.Errorf("Expected tx_hash %s, got %s", tx.Hash().Hex(), result.TxHash)
+	}
+}
+
+// TestTxVerifier_TamperedCalldata tests that substituted parameters are detected
+func TestTxVerifier_TamperedCalldata(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(a *eip3009.EIP3009Authorization)
+		field  string
+	}{
+		{"from", func(a *eip3009.EIP3009Authorization) { a.From = "0x3333333333333333333333333333333333333333" }, "from"},
+		{"to", func(a *eip3009.EIP3009Authorization) { a.To = "0x4444444444444444444444444444444444444444" }, "to"},
+		{"value", func(a *eip3009.EIP3009Authorization) { a.Value = "5000000" }, "value"},
+		{"nonce", func(a *eip3009.EIP3009Authorization) {
+			a.Nonce = "0x00000000000000000000000000000000000000000000000000000000000000ff"
+		}, "nonce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := createOnChainTestAuthorization()
+			tampered := createOnChainTestAuthorization()
+			tt.tamper(tampered)
+			tx := settlementTx(t, createOnChainTestConfig(0).Networks["base"].USDCContract, tampered)
+
+			result, err := newTxVerifierWithTx(t, tx).Verify(auth, "base", tx.Hash().Hex())
+			if err != nil {
+				t.Fatalf("Verify returned error: %v", err)
+			}
+
+			if result.Match {
+				t.Fatal("Expected mismatch for tampered calldata")
+			}
+
+			if result.ErrorCode != onchain.ErrorCodeTxMismatch {
+				t.Errorf("Expected error_code '%s', got '%s'", onchain.ErrorCodeTxMismatch, result.ErrorCode)
+			}
+
+			if len(result.Mismatches) != 1 || result.Mismatches[0] != tt.field {
+				t.Errorf("Expected mismatches [%s], got %v", tt.field, result.Mismatches)
+			}
+		})
+	}
+}
+
+// TestTxVerifier_WrongContract tests that a transaction to another contract is rejected
+func TestTxVerifier_WrongContract(t *testing.T) {
+	auth := createOnChainTestAuthorization()
+	tx := settlementTx(t, "0x5555555555555555555555555555555555555555", auth)
+
+	result, err := newTxVerifierWithTx(t, tx).Verify(auth, "base", tx.Hash().Hex())
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+
+	if result.Match || result.ErrorCode != onchain.ErrorCodeNotSettlementTx {
+		t.Errorf("Expected error_code '%s', got match=%v error_code='%s'", onchain.ErrorCodeNotSettlementTx, result.Match, result.ErrorCode)
+	}
+}
+
+// TestTxVerifier_InvalidTxHash tests that malformed hashes are rejected before any RPC call
+func TestTxVerifier_InvalidTxHash(t *testing.T) {
+	verifier := onchain.NewTxVerifier(createOnChainTestConfig(0), 5*time.Second)
+	verifier.SetBackend("base", &mockTxReader{})
+
+	if _, err := verifier.Verify(createOnChainTestAuthorization(), "base", "0x1234"); err == nil {
+		t.Error("Expected error for short tx_hash")
+	}
+}
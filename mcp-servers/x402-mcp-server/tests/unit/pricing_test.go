@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/pricing"
+)
+
+func TestStaticOracle_USDPerToken(t *testing.T) {
+	oracle := pricing.NewStaticOracle(map[string]float64{"0xABC": 0.5})
+
+	price, err := oracle.USDPerToken("0xabc")
+	if err != nil || price != 0.5 {
+		t.Errorf("Expected configured price 0.5, got %v (err %v)", price, err)
+	}
+
+	// Unlisted assets are treated as USD-pegged
+	price, err = oracle.USDPerToken("0xdef")
+	if err != nil || price != 1.0 {
+		t.Errorf("Expected default price 1.0, got %v (err %v)", price, err)
+	}
+}
+
+func TestHTTPOracle_USDPerToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("asset") {
+		case "0xabc":
+			w.Write([]byte(`{"usd_per_token": 0.9998}`))
+		case "0xempty":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	oracle := pricing.NewHTTPOracle(ts.URL, time.Second, true)
+
+	price, err := oracle.USDPerToken("0xabc")
+	if err != nil || price != 0.9998 {
+		t.Errorf("Expected price 0.9998, got %v (err %v)", price, err)
+	}
+	if _, err := oracle.USDPerToken("0xempty"); err == nil {
+		t.Error("Expected error for response without usd_per_token")
+	}
+	if _, err := oracle.USDPerToken("0xmissing"); err == nil {
+		t.Error("Expected error for non-200 response")
+	}
+
+	// Private addresses are refused unless explicitly allowed
+	if _, err := pricing.NewHTTPOracle(ts.URL, time.Second, false).USDPerToken("0xabc"); err == nil {
+		t.Error("Expected private oracle URL to be refused")
+	}
+}
+
+func TestFiatToAtomic(t *testing.T) {
+	oracle := pricing.NewStaticOracle(map[string]float64{"0xabc": 3})
+
+	amount, err := pricing.FiatToAtomic(oracle, "0xabc", "1", "USD", nil, 6)
+	if err != nil {
+		t.Fatalf("FiatToAtomic failed: %v", err)
+	}
+	// 1 / 3 = 0.333333..., rounded up to 333334
+	if amount.String() != "333334" {
+		t.Errorf("Expected 333334, got %s", amount)
+	}
+
+	if _, err := pricing.FiatToAtomic(oracle, "0xabc", "1", "JPY", nil, 6); err == nil {
+		t.Error("Expected error for currency without an exchange rate")
+	}
+	if _, err := pricing.FiatToAtomic(oracle, "0xabc", "abc", "USD", nil, 6); err == nil {
+		t.Error("Expected error for non-decimal amount")
+	}
+}
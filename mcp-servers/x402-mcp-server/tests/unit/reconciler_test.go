@@ -0,0 +1,336 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/reconciler"
+)
+
+// TestReconciler_PendingToSettled tests that a pending settlement is updated once the facilitator settles it
+func TestReconciler_PendingToSettled(t *testing.T) {
+	var settled atomic.Bool
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Submission returns pending; status checks return pending until flipped
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/status/") && settled.Load() {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"status":       "settled",
+				"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+				"block_number": 12345678,
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":      "pending",
+			"retry_after": 30,
+		})
+	}))
+	defer mockServer.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: mockServer.URL,
+			},
+		},
+		Cache: config.CacheConfig{
+			SettlementTTLMinutes: 10,
+		},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+
+	client := facilitator.NewClient(cfg, 5*time.Second)
+	auth := createOnChainTestAuthorization()
+
+	response, err := client.SubmitSettlement(auth, "base")
+	if err != nil {
+		t.Fatalf("SubmitSettlement failed: %v", err)
+	}
+	if response.Status != "pending" {
+		t.Fatalf("Expected pending submission, got %s", response.Status)
+	}
+
+	if pending := client.PendingSettlements(); len(pending) != 1 {
+		t.Fatalf("Expected 1 pending settlement, got %d", len(pending))
+	}
+
+	auditStore := audit.NewMemoryStore()
+	registry := metrics.NewRegistry()
+	worker := reconciler.New(client, auditStore, registry, logger.New(logger.DEBUG, &bytes.Buffer{}), time.Hour, 0)
+
+	// Still pending: no transition
+	if transitions := worker.RunOnce(); transitions != 0 {
+		t.Errorf("Expected 0 transitions while pending, got %d", transitions)
+	}
+
+	settled.Store(true)
+
+	if transitions := worker.RunOnce(); transitions != 1 {
+		t.Fatalf("Expected 1 transition after settlement, got %d", transitions)
+	}
+
+	cached := client.CachedSettlement("base", auth.Nonce)
+	if cached == nil || cached.Status != "settled" {
+		t.Fatalf("Expected cached entry to be settled, got %+v", cached)
+	}
+
+	if len(client.PendingSettlements()) != 0 {
+		t.Error("Expected no pending settlements after reconciliation")
+	}
+
+	records, _ := auditStore.Records()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(records))
+	}
+	if records[0].Event != audit.EventSettlementReconciled || records[0].PreviousStatus != "pending" || records[0].Status != "settled" {
+		t.Errorf("Unexpected audit record: %+v", records[0])
+	}
+
+	if value := registry.CounterValue(reconciler.MetricTransitions, metrics.Labels{"network": "base", "status": "settled"}); value != 1 {
+		t.Errorf("Expected transition counter 1, got %v", value)
+	}
+
+	// Settled entry is now served from the idempotency cache
+	again, err := client.SubmitSettlement(auth, "base")
+	if err != nil {
+		t.Fatalf("SubmitSettlement after reconciliation failed: %v", err)
+	}
+	if again.Status != "settled" {
+		t.Errorf("Expected cached settled response, got %s", again.Status)
+	}
+}
+
+// TestReconciler_StartStop tests the background loop reconciles on its interval and stops cleanly
+func TestReconciler_StartStop(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": "failed", "error": "authorization already used"})
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "pending"})
+	}))
+	defer mockServer.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {FacilitatorURL: mockServer.URL},
+		},
+		Cache:            config.CacheConfig{SettlementTTLMinutes: 10},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+
+	client := facilitator.NewClient(cfg, 5*time.Second)
+	if _, err := client.SubmitSettlement(createOnChainTestAuthorization(), "base"); err != nil {
+		t.Fatalf("SubmitSettlement failed: %v", err)
+	}
+
+	auditStore := audit.NewMemoryStore()
+	worker := reconciler.New(client, auditStore, metrics.NewRegistry(), logger.New(logger.DEBUG, &bytes.Buffer{}), 20*time.Millisecond, 0)
+	worker.Start()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(client.PendingSettlements()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	worker.Stop()
+
+	if len(client.PendingSettlements()) != 0 {
+		t.Fatal("Expected background reconciliation to resolve the pending settlement")
+	}
+
+	records, _ := auditStore.Records()
+	if len(records) != 1 || records[0].Status != "failed" {
+		t.Errorf("Expected one failed transition record, got %+v", records)
+	}
+}
+
+// TestReconciler_BatchSize tests that a bounded pass checks the oldest settlements and carries the rest over
+func TestReconciler_BatchSize(t *testing.T) {
+	var settled atomic.Bool
+	var mu sync.Mutex
+	var checked []string
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			checked = append(checked, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+			mu.Unlock()
+
+			if settled.Load() {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"status":  "settled",
+					"tx_hash": "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+				})
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "pending"})
+	}))
+	defer mockServer.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {FacilitatorURL: mockServer.URL},
+		},
+		Cache:            config.CacheConfig{SettlementTTLMinutes: 10},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+
+	// Five pending settlements, submitted oldest first
+	client := facilitator.NewClient(cfg, 5*time.Second)
+	nonces := make([]string, 5)
+	for i := range nonces {
+		auth := createOnChainTestAuthorization()
+		auth.Nonce = fmt.Sprintf("0x%064x", i+1)
+		nonces[i] = auth.Nonce
+		if _, err := client.SubmitSettlement(auth, "base"); err != nil {
+			t.Fatalf("SubmitSettlement failed: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	registry := metrics.NewRegistry()
+	worker := reconciler.New(client, audit.NewMemoryStore(), registry, logger.New(logger.DEBUG, &bytes.Buffer{}), time.Hour, 2)
+
+	// Still pending: passes walk the list two at a time, oldest first
+	passes := []struct {
+		checked []string
+		backlog float64
+	}{
+		{nonces[0:2], 3},
+		{nonces[2:4], 3},
+		{nonces[4:5], 4},
+		{nonces[0:2], 3}, // The next round starts again from the oldest
+	}
+	for i, pass := range passes {
+		mu.Lock()
+		checked = nil
+		mu.Unlock()
+
+		if transitions := worker.RunOnce(); transitions != 0 {
+			t.Fatalf("Pass %d: expected 0 transitions while pending, got %d", i+1, transitions)
+		}
+		mu.Lock()
+		got := strings.Join(checked, ",")
+		mu.Unlock()
+		if got != strings.Join(pass.checked, ",") {
+			t.Errorf("Pass %d: expected to check %v, got %s", i+1, pass.checked, got)
+		}
+		if backlog := registry.GaugeValue(reconciler.MetricBacklog, nil); backlog != pass.backlog {
+			t.Errorf("Pass %d: expected backlog %v, got %v", i+1, pass.backlog, backlog)
+		}
+	}
+
+	// Once settled, the remaining settlements drain across passes
+	settled.Store(true)
+	total := 0
+	for i, expected := range []int{2, 1, 2} {
+		transitions := worker.RunOnce()
+		if transitions != expected {
+			t.Errorf("Settled pass %d: expected %d transitions, got %d", i+1, expected, transitions)
+		}
+		total += transitions
+	}
+
+	if total != len(nonces) || len(client.PendingSettlements()) != 0 {
+		t.Errorf("Expected all %d settlements reconciled, got %d (%d still pending)", len(nonces), total, len(client.PendingSettlements()))
+	}
+	if backlog := registry.GaugeValue(reconciler.MetricBacklog, nil); backlog != 0 {
+		t.Errorf("Expected empty backlog, got %v", backlog)
+	}
+}
+
+// TestReconciler_ExpiresUnattested tests that a settlement pending on a missing attestation
+// is failed once it outlives the unattested max age, while younger ones stay pending
+func TestReconciler_ExpiresUnattested(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "settled", "tx_hash": "0xabc"}) // Never signed
+	}))
+	defer mockServer.Close()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {FacilitatorURL: mockServer.URL},
+		},
+		Cache:            config.CacheConfig{SettlementTTLMinutes: 10},
+		Settlement:       config.SettlementConfig{RequireAttestation: true},
+		AllowPrivateURLs: true, // httptest facilitators listen on loopback
+	}
+
+	client := facilitator.NewClient(cfg, 5*time.Second)
+
+	// One settlement went pending two hours ago, the other just now
+	stale := createOnChainTestAuthorization()
+	fresh := createOnChainTestAuthorization()
+	fresh.Nonce = "0x0000000000000000000000000000000000000000000000000000000000000002"
+
+	client.SetClock(func() time.Time { return time.Now().Add(-2 * time.Hour) })
+	if response, err := client.SubmitSettlement(stale, "base"); err != nil || response.ErrorCode != facilitator.ErrorCodeAttestationInvalid {
+		t.Fatalf("Expected unattested pending submission, got %+v (%v)", response, err)
+	}
+	client.SetClock(time.Now)
+	if _, err := client.SubmitSettlement(fresh, "base"); err != nil {
+		t.Fatalf("SubmitSettlement failed: %v", err)
+	}
+
+	auditStore := audit.NewMemoryStore()
+	registry := metrics.NewRegistry()
+	worker := reconciler.New(client, auditStore, registry, logger.New(logger.DEBUG, &bytes.Buffer{}), time.Hour, 0)
+
+	// Without a max age nothing is expired
+	if transitions := worker.RunOnce(); transitions != 0 {
+		t.Fatalf("Expected 0 transitions without a max age, got %d", transitions)
+	}
+
+	worker.ExpireUnattestedAfter(time.Hour)
+	if transitions := worker.RunOnce(); transitions != 1 {
+		t.Fatalf("Expected 1 expired settlement, got %d", transitions)
+	}
+
+	cached := client.CachedSettlement("base", stale.Nonce)
+	if cached != nil && cached.Status != "failed" {
+		t.Errorf("Expected the stale settlement failed, got %+v", cached)
+	}
+	pending := client.PendingSettlements()
+	if len(pending) != 1 || pending[0].Nonce != fresh.Nonce {
+		t.Errorf("Expected only the fresh settlement still pending, got %+v", pending)
+	}
+
+	records, _ := auditStore.Records()
+	if len(records) != 1 || records[0].Nonce != stale.Nonce || records[0].Status != "failed" {
+		t.Fatalf("Expected a failed reconciliation record for the stale settlement, got %+v", records)
+	}
+	if !strings.Contains(records[0].Error, "unattested") {
+		t.Errorf("Expected the record to explain the expiry, got %q", records[0].Error)
+	}
+	if value := registry.CounterValue(reconciler.MetricTransitions, metrics.Labels{"network": "base", "status": "failed"}); value != 1 {
+		t.Errorf("Expected failed transition counter 1, got %v", value)
+	}
+}
@@ -0,0 +1,94 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+)
+
+// TestRPCLimiter_BoundsConcurrentCalls tests that concurrent RPC calls through the shared
+// limiter never exceed rpc.max_concurrent
+func TestRPCLimiter_BoundsConcurrentCalls(t *testing.T) {
+	const limit = 2
+
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := active.Add(1)
+		defer active.Add(-1)
+		for {
+			observed := peak.Load()
+			if current <= observed || peak.CompareAndSwap(observed, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		var request struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": request.ID, "result": "0x2105"})
+	}))
+	defer server.Close()
+
+	previous := rpc.Shared()
+	rpc.SetShared(rpc.NewLimiter(limit, 5*time.Second))
+	t.Cleanup(func() { rpc.SetShared(previous) })
+
+	client, err := rpc.DialEthClient(context.Background(), server.URL, true)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.ChainID(context.Background()); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("RPC call failed: %v", err)
+	}
+	if got := peak.Load(); got > limit {
+		t.Errorf("Expected at most %d concurrent RPC calls, observed %d", limit, got)
+	}
+}
+
+// TestRPCLimiter_QueueTimeout tests that a call waiting longer than the queue timeout fails
+func TestRPCLimiter_QueueTimeout(t *testing.T) {
+	limiter := rpc.NewLimiter(1, 20*time.Millisecond)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatalf("First acquire failed: %v", err)
+	}
+
+	if err := limiter.Acquire(context.Background()); !errors.Is(err, rpc.ErrTooManyCalls) {
+		t.Errorf("Expected ErrTooManyCalls while the only slot is held, got %v", err)
+	}
+
+	limiter.Release()
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Errorf("Expected acquire to succeed after release, got %v", err)
+	}
+
+	if err := rpc.NewLimiter(0, 0).Acquire(context.Background()); err != nil {
+		t.Errorf("Expected an unlimited limiter never to block, got %v", err)
+	}
+}
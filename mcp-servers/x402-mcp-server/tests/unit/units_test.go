@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
+)
+
+// TestToAtomic tests human to atomic amount conversion
+func TestToAtomic(t *testing.T) {
+	tests := []struct {
+		human    string
+		expected string
+		wantErr  bool
+	}{
+		{"0.05", "50000", false},
+		{"1", "1000000", false},
+		{"12.345678", "12345678", false},
+		{"0.000001", "1", false},
+		{"0.0000001", "", true}, // more than 6 decimals
+		{"-1", "", true},
+		{"1.", "", true},
+		{"abc", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.human, func(t *testing.T) {
+			atomic, err := units.ToAtomic(tt.human, units.USDCDecimals)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %s, got %s", tt.human, atomic)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ToAtomic(%s) returned error: %v", tt.human, err)
+			}
+
+			if atomic.String() != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, atomic.String())
+			}
+		})
+	}
+}
+
+// TestToHuman tests atomic to human amount conversion
+func TestToHuman(t *testing.T) {
+	tests := []struct {
+		atomic   int64
+		expected string
+	}{
+		{50000, "0.05"},
+		{1000000, "1"},
+		{12345678, "12.345678"},
+		{1, "0.000001"},
+	}
+
+	for _, tt := range tests {
+		if got := units.ToHuman(big.NewInt(tt.atomic), units.USDCDecimals); got != tt.expected {
+			t.Errorf("ToHuman(%d): expected %s, got %s", tt.atomic, tt.expected, got)
+		}
+	}
+}
+
+// TestToHumanGrouped tests thousands grouping of large amounts
+func TestToHumanGrouped(t *testing.T) {
+	tests := []struct {
+		atomic  string
+		plain   string
+		grouped string
+	}{
+		{"50000", "0.05", "0.05"},
+		{"999000000", "999", "999"},
+		{"1000500000", "1000.5", "1,000.5"},
+		{"1234567890123", "1234567.890123", "1,234,567.890123"},
+		{"100000000000000000", "100000000000", "100,000,000,000"},
+	}
+
+	for _, tt := range tests {
+		atomic, _ := new(big.Int).SetString(tt.atomic, 10)
+
+		if got := units.ToHuman(atomic, units.USDCDecimals); got != tt.plain {
+			t.Errorf("ToHuman(%s): expected %s, got %s", tt.atomic, tt.plain, got)
+		}
+		if got := units.ToHumanGrouped(atomic, units.USDCDecimals); got != tt.grouped {
+			t.Errorf("ToHumanGrouped(%s): expected %s, got %s", tt.atomic, tt.grouped, got)
+		}
+	}
+}
+
+// TestToAtomic_18Decimals tests conversion for an 18-decimal asset such as DAI
+func TestToAtomic_18Decimals(t *testing.T) {
+	atomic, err := units.ToAtomic("1.5", 18)
+	if err != nil {
+		t.Fatalf("ToAtomic returned error: %v", err)
+	}
+	if atomic.String() != "1500000000000000000" {
+		t.Errorf("Expected 1500000000000000000, got %s", atomic)
+	}
+
+	if _, err := units.ToAtomic("0.0000000000000000001", 18); err == nil {
+		t.Error("Expected error for more than 18 decimals")
+	}
+
+	smallest, _ := new(big.Int).SetString("1", 10)
+	if got := units.ToHuman(smallest, 18); got != "0.000000000000000001" {
+		t.Errorf("Expected 0.000000000000000001, got %s", got)
+	}
+	if got := units.ToHumanGrouped(atomic.Mul(atomic, big.NewInt(1000)), 18); got != "1,500" {
+		t.Errorf("Expected 1,500, got %s", got)
+	}
+}
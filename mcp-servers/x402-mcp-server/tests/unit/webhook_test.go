@@ -0,0 +1,193 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/logger"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/webhook"
+)
+
+const testWebhookSecret = "test-webhook-secret"
+
+// newWebhookFixture returns a handler whose client holds one pending base settlement
+func newWebhookFixture(t *testing.T) (*webhook.Handler, *facilitator.Client, *audit.MemoryStore, *metrics.Registry, string) {
+	t.Helper()
+
+	cfg := &config.Config{
+		Networks: map[string]config.NetworkConfig{
+			"base": {
+				ChainID:        8453,
+				USDCContract:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+				FacilitatorURL: "https://facilitator.example",
+			},
+		},
+		Cache: config.CacheConfig{
+			SettlementTTLMinutes: 10,
+		},
+	}
+
+	client := facilitator.NewClient(cfg, 5*time.Second)
+	nonce := createOnChainTestAuthorization().Nonce
+	client.UpdateSettlement("base", nonce, &facilitator.FacilitatorResponse{Status: "pending", RetryAfter: 30})
+
+	auditStore := audit.NewMemoryStore()
+	registry := metrics.NewRegistry()
+	handler := webhook.NewHandler(client, []byte(testWebhookSecret), auditStore, registry, logger.New(logger.DEBUG, &bytes.Buffer{}))
+
+	return handler, client, auditStore, registry, nonce
+}
+
+func postCallback(handler http.Handler, body []byte, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, config.DefaultWebhookPath, bytes.NewReader(body))
+	if signature != "" {
+		req.Header.Set(webhook.SignatureHeader, signature)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestWebhook_ValidCallback tests that a signed callback settles the pending settlement
+func TestWebhook_ValidCallback(t *testing.T) {
+	handler, client, auditStore, registry, nonce := newWebhookFixture(t)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"network":      "base",
+		"nonce":        nonce,
+		"status":       "settled",
+		"tx_hash":      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
+		"block_number": 12345678,
+	})
+
+	rec := postCallback(handler, body, webhook.Sign([]byte(testWebhookSecret), body))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	cached := client.CachedSettlement("base", nonce)
+	if cached == nil || cached.Status != "settled" || cached.BlockNumber != 12345678 {
+		t.Errorf("Expected cached settled response, got %+v", cached)
+	}
+
+	if pending := client.PendingSettlements(); len(pending) != 0 {
+		t.Errorf("Expected no pending settlements, got %d", len(pending))
+	}
+
+	records, _ := auditStore.Records()
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 audit record, got %d", len(records))
+	}
+	if records[0].Event != audit.EventSettlementCallback || records[0].PreviousStatus != "pending" || records[0].Status != "settled" {
+		t.Errorf("Unexpected audit record: %+v", records[0])
+	}
+
+	if got := registry.CounterValue(webhook.MetricCallbacks, metrics.Labels{"network": "base", "status": "settled"}); got != 1 {
+		t.Errorf("Expected callback counter 1, got %v", got)
+	}
+}
+
+// TestWebhook_InvalidSignature tests that unsigned or mis-signed callbacks change nothing
+func TestWebhook_InvalidSignature(t *testing.T) {
+	handler, client, auditStore, _, nonce := newWebhookFixture(t)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"network": "base",
+		"nonce":   nonce,
+		"status":  "settled",
+	})
+
+	signatures := map[string]string{
+		"missing":      "",
+		"wrong secret": webhook.Sign([]byte("other-secret"), body),
+		"bad format":   "deadbeef",
+	}
+
+	for name, signature := range signatures {
+		t.Run(name, func(t *testing.T) {
+			rec := postCallback(handler, body, signature)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("Expected 401, got %d", rec.Code)
+			}
+		})
+	}
+
+	// A valid signature over a different body must not authorize this one
+	tampered := append([]byte(nil), body...)
+	tampered[len(tampered)-2] = 'X'
+	if rec := postCallback(handler, tampered, webhook.Sign([]byte(testWebhookSecret), body)); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for tampered body, got %d", rec.Code)
+	}
+
+	if cached := client.CachedSettlement("base", nonce); cached == nil || cached.Status != "pending" {
+		t.Errorf("Expected settlement to remain pending, got %+v", cached)
+	}
+
+	if records, _ := auditStore.Records(); len(records) != 0 {
+		t.Errorf("Expected no audit records, got %d", len(records))
+	}
+}
+
+// TestWebhook_InvalidCallback tests that authenticated but malformed callbacks are rejected
+func TestWebhook_InvalidCallback(t *testing.T) {
+	handler, _, _, _, nonce := newWebhookFixture(t)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"network": "base",
+		"nonce":   nonce,
+		"status":  "confirmed",
+	})
+
+	if rec := postCallback(handler, body, webhook.Sign([]byte(testWebhookSecret), body)); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown status, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, config.DefaultWebhookPath, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", rec.Code)
+	}
+}
+
+// TestWebhook_Server tests that the callback listener bounds slow clients and oversized bodies
+func TestWebhook_Server(t *testing.T) {
+	handler, client, _, _, nonce := newWebhookFixture(t)
+
+	srv := webhook.NewServer(":0", config.DefaultWebhookPath, handler)
+	if srv.ReadHeaderTimeout <= 0 || srv.ReadTimeout <= 0 || srv.WriteTimeout <= 0 {
+		t.Errorf("Expected read header, read and write timeouts, got %v, %v, %v",
+			srv.ReadHeaderTimeout, srv.ReadTimeout, srv.WriteTimeout)
+	}
+
+	// A signed body over 1 MiB is refused before it is parsed
+	body := append([]byte(`{"network":"base","nonce":"`+nonce+`","status":"settled","error":"`), bytes.Repeat([]byte("x"), 1<<20)...)
+	body = append(body, `"}`...)
+	if rec := postCallback(srv.Handler, body, webhook.Sign([]byte(testWebhookSecret), body)); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for oversized body, got %d", rec.Code)
+	}
+	if cached := client.CachedSettlement("base", nonce); cached == nil || cached.Status != "pending" {
+		t.Errorf("Expected settlement still pending, got %+v", cached)
+	}
+
+	// Callbacks are served at the configured path only
+	valid, _ := json.Marshal(map[string]interface{}{"network": "base", "nonce": nonce, "status": "settled"})
+	req := httptest.NewRequest(http.MethodPost, "/other", bytes.NewReader(valid))
+	rec := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 off the callback path, got %d", rec.Code)
+	}
+	if rec := postCallback(srv.Handler, valid, webhook.Sign([]byte(testWebhookSecret), valid)); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for valid callback, got %d", rec.Code)
+	}
+}
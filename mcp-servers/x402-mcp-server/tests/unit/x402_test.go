@@ -1,6 +1,9 @@
 package unit
 
 import (
+	"encoding/json"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -101,6 +104,112 @@ func TestPaymentRequirement_NonceUniqueness(t *testing.T) {
 	t.Logf("Nonce 2: %s", req2.Nonce)
 }
 
+// TestPaymentRequirement_NonceConcurrentUniqueness tests that thousands of concurrent
+// generations produce no colliding nonces, each a full 32-byte random value
+func TestPaymentRequirement_NonceConcurrentUniqueness(t *testing.T) {
+	payee := "0x1234567890123456789012345678901234567890"
+	asset := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+
+	const workers = 64
+	const perWorker = 100
+
+	nonces := make(chan string, workers*perWorker)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				req, err := x402.NewPaymentRequirement("100000", "base", payee, asset, "https://api.example.com/resource", "Concurrent nonce", "application/json", time.Hour)
+				if err != nil {
+					errs <- err
+					return
+				}
+				nonces <- req.Nonce
+			}
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("NewPaymentRequirement failed: %v", err)
+	}
+
+	seen := make(map[string]bool, workers*perWorker)
+	for nonce := range nonces {
+		if len(nonce) != 66 {
+			t.Fatalf("Expected 0x-prefixed 32-byte nonce, got %s", nonce)
+		}
+		if seen[nonce] {
+			t.Fatalf("Nonce collision: %s", nonce)
+		}
+		seen[nonce] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("Expected %d nonces, got %d", workers*perWorker, len(seen))
+	}
+}
+
+// BenchmarkPaymentRequirement_Nonce measures requirement (and nonce) generation throughput
+func BenchmarkPaymentRequirement_Nonce(b *testing.B) {
+	payee := "0x1234567890123456789012345678901234567890"
+	asset := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := x402.NewPaymentRequirement("100000", "base", payee, asset, "https://api.example.com/resource", "Benchmark nonce", "application/json", time.Hour); err != nil {
+				b.Fatalf("NewPaymentRequirement failed: %v", err)
+			}
+		}
+	})
+}
+
+// TestPaymentRequirement_AssetMatchesNetwork tests that the asset must be the network's USDC or allowlisted
+func TestPaymentRequirement_AssetMatchesNetwork(t *testing.T) {
+	payee := "0x1234567890123456789012345678901234567890"
+
+	tests := []struct {
+		name      string
+		network   string
+		asset     string
+		expectErr bool
+	}{
+		{"base USDC", "base", "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", false},
+		{"base USDC lowercase", "base", "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913", false},
+		{"arbitrum USDC", "arbitrum", "0xaf88d065e77c8cC2239327C5EDb3A432268e5831", false},
+		{"arbitrum USDC on base", "base", "0xaf88d065e77c8cC2239327C5EDb3A432268e5831", true},
+		{"arbitrum-sepolia USDC", "arbitrum-sepolia", "0x75faf114eafb1BDbe2F0316DF893fd58CE46AA4d", false},
+		{"optimism USDC", "optimism", "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", false},
+		{"optimism USDC on arbitrum-sepolia", "arbitrum-sepolia", "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", true},
+		{"unknown token", "base-sepolia", "0x9999999999999999999999999999999999999999", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := x402.NewPaymentRequirement("100000", tt.network, payee, tt.asset, "https://api.example.com/resource", "Asset check", "application/json", time.Hour)
+			if tt.expectErr && err == nil {
+				t.Errorf("Expected error for asset %s on %s", tt.asset, tt.network)
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("Expected asset %s on %s to be accepted, got %v", tt.asset, tt.network, err)
+			}
+		})
+	}
+
+	// Allowlisting an asset permits it on that network only
+	custom := "0x4444444444444444444444444444444444444444"
+	x402.AllowAsset("base-sepolia", custom)
+	if _, err := x402.NewPaymentRequirement("100000", "base-sepolia", payee, custom, "https://api.example.com/resource", "Asset check", "application/json", time.Hour); err != nil {
+		t.Errorf("Expected allowlisted asset to be accepted, got %v", err)
+	}
+	if _, err := x402.NewPaymentRequirement("100000", "base", payee, custom, "https://api.example.com/resource", "Asset check", "application/json", time.Hour); err == nil {
+		t.Error("Expected asset allowlisted on base-sepolia to be rejected on base")
+	}
+}
+
 // TestPaymentRequirement_InvalidNetwork tests error handling for unsupported networks
 func TestPaymentRequirement_InvalidNetwork(t *testing.T) {
 	_, err := x402.NewPaymentRequirement(
@@ -223,6 +332,51 @@ func TestPaymentRequirement_MultipleNetworks(t *testing.T) {
 	}
 }
 
+// TestPaymentRequirement_GenerateNewNetworks tests requirement generation and validation on
+// Arbitrum Sepolia and Optimism
+func TestPaymentRequirement_GenerateNewNetworks(t *testing.T) {
+	tests := []struct {
+		network string
+		asset   string
+	}{
+		{"arbitrum-sepolia", "0x75faf114eafb1BDbe2F0316DF893fd58CE46AA4d"},
+		{"optimism", "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			req, err := x402.NewPaymentRequirement(
+				"100000",
+				tt.network,
+				"0x1234567890123456789012345678901234567890",
+				tt.asset,
+				"https://api.example.com/resource",
+				"Test payment requirement",
+				"application/json",
+				time.Hour,
+			)
+			if err != nil {
+				t.Fatalf("NewPaymentRequirement failed for %s: %v", tt.network, err)
+			}
+
+			if req.Network != tt.network || req.Asset != tt.asset {
+				t.Errorf("Expected %s on %s, got %s on %s", tt.asset, tt.network, req.Asset, req.Network)
+			}
+			if err := req.Validate(); err != nil {
+				t.Errorf("Expected generated requirement to validate, got %v", err)
+			}
+
+			found := false
+			for _, name := range x402.SupportedNetworks() {
+				found = found || name == tt.network
+			}
+			if !found {
+				t.Errorf("Expected %s in SupportedNetworks, got %v", tt.network, x402.SupportedNetworks())
+			}
+		})
+	}
+}
+
 // TestPaymentRequirement_ToJSON tests JSON serialization
 func TestPaymentRequirement_ToJSON(t *testing.T) {
 	req, err := x402.NewPaymentRequirement(
@@ -271,3 +425,272 @@ func TestPaymentRequirement_ToJSON(t *testing.T) {
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || contains(s[1:], substr)))
 }
+
+// TestPaymentRequirement_Expiry tests IsExpired and TimeUntilExpiry around the valid_until boundary
+func TestPaymentRequirement_Expiry(t *testing.T) {
+	validUntil := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	req := &x402.PaymentRequirement{ValidUntil: validUntil.Format(time.RFC3339)}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		expired     bool
+		untilExpiry time.Duration
+	}{
+		{"one minute before", validUntil.Add(-time.Minute), false, time.Minute},
+		{"one second before", validUntil.Add(-time.Second), false, time.Second},
+		{"exactly at valid_until", validUntil, true, 0},
+		{"one second after", validUntil.Add(time.Second), true, -time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := req.IsExpired(tt.now); got != tt.expired {
+				t.Errorf("IsExpired: expected %v, got %v", tt.expired, got)
+			}
+			if got := req.TimeUntilExpiry(tt.now); got != tt.untilExpiry {
+				t.Errorf("TimeUntilExpiry: expected %v, got %v", tt.untilExpiry, got)
+			}
+		})
+	}
+
+	// Unparseable valid_until is treated as expired
+	invalid := &x402.PaymentRequirement{ValidUntil: "not-a-time"}
+	if !invalid.IsExpired(validUntil) {
+		t.Error("Expected requirement with invalid valid_until to be expired")
+	}
+}
+
+// TestPaymentRequirement_SettlementDeadline tests the deadline is issuance plus maxTimeoutSeconds
+func TestPaymentRequirement_SettlementDeadline(t *testing.T) {
+	issuedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	req := &x402.PaymentRequirement{
+		ValidUntil:        issuedAt.Add(24 * time.Hour).Format(time.RFC3339),
+		MaxTimeoutSeconds: 60,
+	}
+
+	deadline, err := req.SettlementDeadline(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("SettlementDeadline failed: %v", err)
+	}
+	if want := issuedAt.Add(time.Minute); !deadline.Equal(want) {
+		t.Errorf("Expected deadline %s, got %s", want, deadline)
+	}
+
+	invalid := &x402.PaymentRequirement{ValidUntil: "not-a-time", MaxTimeoutSeconds: 60}
+	if _, err := invalid.SettlementDeadline(time.Hour); err == nil {
+		t.Error("Expected error for invalid valid_until")
+	}
+}
+
+// TestParsePaymentRequirement tests round-tripping a generated requirement through JSON
+func TestParsePaymentRequirement(t *testing.T) {
+	req, err := x402.NewPaymentRequirement(
+		"50000",
+		"base",
+		"0x1234567890123456789012345678901234567890",
+		"0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		"https://api.example.com/resource",
+		"Test payment requirement",
+		"application/json",
+		time.Hour,
+	)
+	if err != nil {
+		t.Fatalf("NewPaymentRequirement failed: %v", err)
+	}
+
+	data, err := req.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+
+	parsed, err := x402.ParsePaymentRequirement(data)
+	if err != nil {
+		t.Fatalf("ParsePaymentRequirement failed: %v", err)
+	}
+
+	if parsed.Nonce != req.Nonce || parsed.ValidUntil != req.ValidUntil {
+		t.Errorf("Parsed requirement differs: %+v", parsed)
+	}
+
+	if _, err := x402.ParsePaymentRequirement([]byte(`{"x402_version":2}`)); err == nil {
+		t.Error("Expected error for invalid requirement")
+	}
+}
+
+// TestParseVersion tests the tolerant x402 version parser
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       interface{}
+		expectError bool
+	}{
+		{"int", 1, false},
+		{"float64 from JSON", float64(1), false},
+		{"json.Number", json.Number("1"), false},
+		{"string", "1", false},
+		{"padded string", " 1 ", false},
+		{"unsupported int", 2, true},
+		{"unsupported string", "2", true},
+		{"zero", 0, true},
+		{"fractional float", 1.5, true},
+		{"non-numeric string", "v1", true},
+		{"missing", nil, true},
+		{"wrong type", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := x402.ParseVersion(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got version %d", version)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version != x402.Version {
+				t.Errorf("Expected version %d, got %d", x402.Version, version)
+			}
+		})
+	}
+}
+
+// TestParsePaymentRequirement_VersionForms tests requirements carrying the version under
+// either wire name and as a number or string
+func TestParsePaymentRequirement_VersionForms(t *testing.T) {
+	req, err := x402.NewPaymentRequirement(
+		"50000",
+		"base",
+		"0x1234567890123456789012345678901234567890",
+		"0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		"https://api.example.com/resource",
+		"Test payment",
+		"application/json",
+		time.Hour,
+	)
+	if err != nil {
+		t.Fatalf("NewPaymentRequirement failed: %v", err)
+	}
+
+	withVersion := func(fields map[string]interface{}) []byte {
+		var object map[string]interface{}
+		encoded, _ := json.Marshal(req)
+		json.Unmarshal(encoded, &object)
+		delete(object, "x402_version")
+		for key, value := range fields {
+			object[key] = value
+		}
+		data, _ := json.Marshal(object)
+		return data
+	}
+
+	tests := []struct {
+		name        string
+		fields      map[string]interface{}
+		expectError bool
+	}{
+		{"snake_case number", map[string]interface{}{"x402_version": 1}, false},
+		{"camelCase number", map[string]interface{}{"x402Version": 1}, false},
+		{"string", map[string]interface{}{"x402_version": "1"}, false},
+		{"both names agreeing", map[string]interface{}{"x402_version": 1, "x402Version": "1"}, false},
+		{"both names conflicting", map[string]interface{}{"x402_version": 1, "x402Version": 2}, true},
+		{"unsupported", map[string]interface{}{"x402Version": "2"}, true},
+		{"missing", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := x402.ParsePaymentRequirement(withVersion(tt.fields))
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePaymentRequirement failed: %v", err)
+			}
+			if parsed.X402Version != x402.Version {
+				t.Errorf("Expected x402_version %d, got %d", x402.Version, parsed.X402Version)
+			}
+		})
+	}
+}
+
+// TestParsePaymentPayload_VersionForms tests payloads carrying the version as a string or snake_case key
+func TestParsePaymentPayload_VersionForms(t *testing.T) {
+	signature := "0x" + strings.Repeat("ab", 64) + "1b"
+	for _, version := range []string{`"x402Version":1`, `"x402Version":"1"`, `"x402_version":1.0`} {
+		data := `{` + version + `,"scheme":"exact","network":"base","payload":{"signature":"` + signature + `","authorization":{}}}`
+		payload, err := x402.ParsePaymentPayload(data)
+		if err != nil {
+			t.Errorf("%s: ParsePaymentPayload failed: %v", version, err)
+			continue
+		}
+		if payload.X402Version != x402.Version {
+			t.Errorf("%s: expected x402Version %d, got %d", version, x402.Version, payload.X402Version)
+		}
+	}
+
+	if _, err := x402.ParsePaymentPayload(`{"x402Version":"2","scheme":"exact","network":"base"}`); err == nil {
+		t.Error("Expected error for unsupported x402Version")
+	}
+}
+
+func TestFormatCAIP10(t *testing.T) {
+	tests := []struct {
+		chainID  uint64
+		address  string
+		expected string
+	}{
+		{8453, "0x833589fcd6edb6e08f4c7c32d4f71b54bda02913", "eip155:8453:0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"},
+		{84532, "0x036CbD53842c5426634e7929541eC2318f3dCF7e", "eip155:84532:0x036CbD53842c5426634e7929541eC2318f3dCF7e"},
+		{42161, "0xAF88D065E77C8CC2239327C5EDB3A432268E5831", "eip155:42161:0xaf88d065e77c8cC2239327C5EDb3A432268e5831"},
+	}
+
+	for _, tt := range tests {
+		if got := x402.FormatCAIP10(tt.chainID, tt.address); got != tt.expected {
+			t.Errorf("FormatCAIP10(%d, %s) = %s, want %s", tt.chainID, tt.address, got, tt.expected)
+		}
+	}
+}
+
+// TestPaymentPayload_SplitSignature tests v/r/s extraction and v normalization from 65-byte signatures
+func TestPaymentPayload_SplitSignature(t *testing.T) {
+	r := "1111111111111111111111111111111111111111111111111111111111111111"
+	s := "2222222222222222222222222222222222222222222222222222222222222222"
+
+	tests := []struct {
+		suffix    string
+		expectedV uint8
+		expectErr bool
+	}{
+		{"1b", 27, false},
+		{"1c", 28, false},
+		{"00", 27, false}, // Raw recovery id
+		{"01", 28, false},
+		{"05", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.suffix, func(t *testing.T) {
+			payload := x402.ExactEVMPayload{Signature: "0x" + r + s + tt.suffix}
+			v, gotR, gotS, err := payload.SplitSignature()
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("Expected error for v byte %s", tt.suffix)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitSignature failed: %v", err)
+			}
+			if v != tt.expectedV || gotR != "0x"+r || gotS != "0x"+s {
+				t.Errorf("Expected v=%d r=0x%s s=0x%s, got v=%d r=%s s=%s", tt.expectedV, r, s, v, gotR, gotS)
+			}
+		})
+	}
+}
@@ -0,0 +1,606 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+)
+
+// bytes32InputPattern matches a nonce or signature component given as 0x-prefixed hex or base64
+const bytes32InputPattern = "^(0x[a-fA-F0-9]{64}|[A-Za-z0-9+/]{43}=)$"
+
+// formatBytes32 renders a 0x-prefixed 32-byte hex value in the configured display.byte_encoding
+// Values that are not 32-byte hex are returned unchanged.
+func formatBytes32(cfg *config.Config, value string) string {
+	decoded, err := eip3009.DecodeBytes32(value)
+	if err != nil || !strings.HasPrefix(value, "0x") {
+		return value
+	}
+	return eip3009.EncodeBytes32(decoded, cfg.Display.ByteEncoding)
+}
+
+// weakNonceWarning logs and returns why the authorization's nonce looks predictable when
+// verification.weak_nonce is warn, or ""; in reject mode the verifier fails it instead
+func weakNonceWarning(srv *server.Server, network string, auth *eip3009.EIP3009Authorization) string {
+	verification := &srv.GetConfig().Verification
+	if verification.WeakNonce != config.WeakNonceWarn {
+		return ""
+	}
+
+	reason := eip3009.WeakNonceReason(auth.Nonce, verification.WeakNonceThreshold())
+	if reason != "" {
+		srv.GetLogger().Warn("Authorization nonce looks predictable", map[string]interface{}{
+			"network": network,
+			"from":    auth.From,
+			"nonce":   auth.Nonce,
+			"reason":  reason,
+		})
+	}
+	return reason
+}
+
+// authorizationSchema returns the JSON schema for an EIP-3009 authorization input
+func authorizationSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "object",
+		"description": "EIP-3009 receiveWithAuthorization parameters",
+		"properties": map[string]interface{}{
+			"from": map[string]interface{}{
+				"type":        "string",
+				"description": "Payer address (0x-prefixed hex)",
+				"pattern":     "^0x[a-fA-F0-9]{40}$",
+			},
+			"to": map[string]interface{}{
+				"type":        "string",
+				"description": "Payee address (0x-prefixed hex)",
+				"pattern":     "^0x[a-fA-F0-9]{40}$",
+			},
+			"value": map[string]interface{}{
+				"type":        "string",
+				"description": "Amount in atomic units of the network's asset (USDC: 6 decimals)",
+				"pattern":     "^[1-9][0-9]*$",
+			},
+			"validAfter": map[string]interface{}{
+				"type":        "integer",
+				"description": "Unix timestamp (seconds) after which the authorization is valid",
+			},
+			"validBefore": map[string]interface{}{
+				"type":        "integer",
+				"description": "Unix timestamp (seconds) before which the authorization is valid",
+			},
+			"nonce": map[string]interface{}{
+				"type":        "string",
+				"description": "Unique nonce as 32-byte hex string (0x-prefixed) or base64",
+				"pattern":     bytes32InputPattern,
+			},
+			"v": map[string]interface{}{
+				"type":        "integer",
+				"description": "ECDSA recovery parameter (27 or 28); EIP-155 encoded values (chainId*2 + 35/36) are accepted when verification.eip155_v allows",
+				"minimum":     27,
+			},
+			"r": map[string]interface{}{
+				"type":        "string",
+				"description": "ECDSA signature r component as 32-byte hex string or base64",
+				"pattern":     bytes32InputPattern,
+			},
+			"s": map[string]interface{}{
+				"type":        "string",
+				"description": "ECDSA signature s component as 32-byte hex string or base64",
+				"pattern":     bytes32InputPattern,
+			},
+		},
+		"required": []string{"from", "to", "value", "validAfter", "validBefore", "nonce", "v", "r", "s"},
+	}
+}
+
+// expectedValueHumanSchema returns the JSON schema for the optional human-denominated expected amount
+func expectedValueHumanSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "string",
+		"description": "Optional expected amount in the network's asset (e.g., '0.05' USDC), with at most the asset's decimals; rejected with error_code 'amount_mismatch' if authorization value differs (overpayment passes when verification.overpayment accepts it)",
+		"pattern":     "^[0-9]+(\\.[0-9]+)?$",
+	}
+}
+
+// expectedValue returns the optional expected_value_human input in atomic units of an asset
+// with the given decimals, or nil
+func expectedValue(args map[string]interface{}, decimals int) (*big.Int, error) {
+	raw, exists := args["expected_value_human"]
+	if !exists {
+		return nil, nil
+	}
+
+	expectedHuman, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected_value_human must be a string")
+	}
+
+	expected, err := units.ToAtomic(expectedHuman, decimals)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected_value_human: %w", err)
+	}
+
+	return expected, nil
+}
+
+// checkExpectedValue compares the authorization value with the optional expected_value_human input
+// Values above the expected amount pass when the overpayment policy accepts them; underpayment
+// never does. Returns a mismatch description, or "" when the amounts are acceptable or no
+// expectation was given
+func checkExpectedValue(args map[string]interface{}, auth *eip3009.EIP3009Authorization, decimals int, verification *config.VerificationConfig) (string, error) {
+	expected, err := expectedValue(args, decimals)
+	if err != nil || expected == nil {
+		return "", err
+	}
+
+	value, ok := new(big.Int).SetString(auth.Value, 10)
+	if ok && value.Cmp(expected) > 0 && verification.AcceptsOverpayment() {
+		return "", nil
+	}
+
+	if expected.String() != auth.Value {
+		return fmt.Sprintf("authorization value %s does not match expected %s (%s atomic units)",
+			auth.Value, units.ToHuman(expected, decimals), expected.String()), nil
+	}
+
+	return "", nil
+}
+
+// overpaymentExcess returns how far the authorization value exceeds expected_value_human,
+// or nil when no expectation was given or the value does not exceed it
+func overpaymentExcess(args map[string]interface{}, auth *eip3009.EIP3009Authorization, decimals int) (*big.Int, error) {
+	expected, err := expectedValue(args, decimals)
+	if err != nil || expected == nil {
+		return nil, err
+	}
+
+	value, ok := new(big.Int).SetString(auth.Value, 10)
+	if !ok || value.Cmp(expected) <= 0 {
+		return nil, nil
+	}
+
+	return value.Sub(value, expected), nil
+}
+
+// requirementSchema returns the JSON schema for an optional payment requirement bound to the authorization
+func requirementSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        []string{"object", "string"},
+		"description": "Optional payment requirement (object or JSON string) the authorization pays; rejected with error_code 'payee_mismatch' if authorization.to differs from payTo. Its extra.name/extra.version, when set, replace the configured EIP-712 domain name/version",
+	}
+}
+
+// parseRequirement decodes a payment requirement given as an object or JSON string
+func parseRequirement(raw interface{}) (*x402.PaymentRequirement, error) {
+	var data []byte
+	switch requirement := raw.(type) {
+	case string:
+		data = []byte(requirement)
+	case map[string]interface{}:
+		encoded, err := json.Marshal(requirement)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode requirement: %w", err)
+		}
+		data = encoded
+	default:
+		return nil, fmt.Errorf("requirement must be an object or JSON string")
+	}
+
+	return x402.ParsePaymentRequirement(data)
+}
+
+// checkPayee compares the authorization payee with the optional requirement's payTo
+// Addresses are compared case-insensitively. Returns a mismatch description, or ""
+// when the payee matches or no requirement was given
+func checkPayee(args map[string]interface{}, auth *eip3009.EIP3009Authorization) (string, error) {
+	raw, exists := args["requirement"]
+	if !exists {
+		return "", nil
+	}
+
+	requirement, err := parseRequirement(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.EqualFold(requirement.PayTo, auth.To) {
+		return fmt.Sprintf("authorization payee %s does not match requirement payTo %s", auth.To, requirement.PayTo), nil
+	}
+
+	return "", nil
+}
+
+// checkRequirementTimeout reports a settlement attempted later than the optional requirement's
+// maxTimeoutSeconds after its issuance, which is derived as valid_until minus the configured
+// requirement validity. Returns "" when no requirement is given or it is within its window.
+func checkRequirementTimeout(cfg *config.Config, args map[string]interface{}, now time.Time) (string, error) {
+	raw, exists := args["requirement"]
+	if !exists {
+		return "", nil
+	}
+
+	requirement, err := parseRequirement(raw)
+	if err != nil {
+		return "", err
+	}
+
+	deadline, err := requirement.SettlementDeadline(cfg.Requirements.Validity())
+	if err != nil {
+		return "", err
+	}
+	if now.After(deadline) {
+		return fmt.Sprintf("settlement window of %ds closed at %s", requirement.MaxTimeoutSeconds, deadline.UTC().Format(time.RFC3339)), nil
+	}
+
+	return "", nil
+}
+
+// requirementDomain returns the EIP-712 name/version from the optional requirement's extra
+// fields, so authorizations for requirements this server did not generate verify against
+// the domain they were signed for. A field left empty falls back to the configured value;
+// nil means no requirement (or no extra fields) and verification uses the configured domain.
+func requirementDomain(cfg *config.Config, network string, args map[string]interface{}) (*config.DomainParams, error) {
+	raw, exists := args["requirement"]
+	if !exists {
+		return nil, nil
+	}
+
+	requirement, err := parseRequirement(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return requirementDomainParams(cfg, network, requirement)
+}
+
+// requirementDomainParams returns the EIP-712 name/version named by a requirement's extra
+// fields, filling an empty field from the configured domain; nil when extra names neither
+func requirementDomainParams(cfg *config.Config, network string, requirement *x402.PaymentRequirement) (*config.DomainParams, error) {
+	if requirement.Extra.Name == "" && requirement.Extra.Version == "" {
+		return nil, nil
+	}
+
+	params, err := cfg.DomainParams(network)
+	if err != nil && (requirement.Extra.Name == "" || requirement.Extra.Version == "") {
+		return nil, err
+	}
+	if requirement.Extra.Name != "" {
+		params.Name = requirement.Extra.Name
+	}
+	if requirement.Extra.Version != "" {
+		params.Version = requirement.Extra.Version
+	}
+
+	return &params, nil
+}
+
+// checkPayeeAllowlist applies verification.payee_policy to a contract payee missing from
+// verification.payee_allowlist. It returns the violation and whether it rejects the payment;
+// in warn mode the violation is logged and returned for the result instead.
+func checkPayeeAllowlist(srv *server.Server, verifier *eip3009.SignatureVerifier, network string, auth *eip3009.EIP3009Authorization) (string, bool, error) {
+	violation, err := verifier.PayeeNotAllowlisted(network, auth.To)
+	if err != nil || violation == "" {
+		return "", false, err
+	}
+
+	if srv.GetConfig().Verification.PayeePolicy == config.PayeePolicyWarn {
+		srv.GetLogger().Warn("Payee contract is not allowlisted", map[string]interface{}{
+			"network": network,
+			"from":    auth.From,
+			"to":      auth.To,
+		})
+		return violation, false, nil
+	}
+
+	return violation, true, nil
+}
+
+// checkOffer binds the authorization to the caller's expectations (expected_value_human, in an
+// asset with the given decimals, and requirement). Returns a mismatch description and its
+// error code, or "" when all match
+func checkOffer(args map[string]interface{}, auth *eip3009.EIP3009Authorization, decimals int, verification *config.VerificationConfig) (string, string, error) {
+	mismatch, err := checkExpectedValue(args, auth, decimals, verification)
+	if err != nil || mismatch != "" {
+		return mismatch, eip3009.ErrorCodeAmountMismatch, err
+	}
+
+	mismatch, err = checkPayee(args, auth)
+	if err != nil || mismatch != "" {
+		return mismatch, eip3009.ErrorCodePayeeMismatch, err
+	}
+
+	return "", "", nil
+}
+
+// networkSchemaProperties returns the network selector properties shared by verify and settle
+// Callers pass either network or the {chain_id, asset} pair it is resolved from.
+func networkSchemaProperties(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"network": map[string]interface{}{
+			"type":        "string",
+			"description": description,
+			"enum":        x402.SupportedNetworks(),
+		},
+		"chain_id": map[string]interface{}{
+			"type":        "integer",
+			"description": "EIP-155 chain ID; with asset, selects the network when network is omitted",
+		},
+		"asset": map[string]interface{}{
+			"type":        "string",
+			"description": "USDC contract address; with chain_id, selects the network when network is omitted",
+			"pattern":     "^0x[a-fA-F0-9]{40}$",
+		},
+	}
+}
+
+// networkSelector requires either network or both chain_id and asset
+func networkSelector() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"required": []string{"network"}},
+		map[string]interface{}{"required": []string{"chain_id", "asset"}},
+	}
+}
+
+// resolveNetwork returns the network argument, or resolves it from chain_id and asset
+// Ambiguous or unknown chain/asset pairs are rejected rather than guessed.
+func resolveNetwork(cfg *config.Config, args map[string]interface{}) (string, error) {
+	if raw, exists := args["network"]; exists {
+		network, ok := raw.(string)
+		if !ok {
+			return "", fmt.Errorf("network must be a string")
+		}
+		return canonicalNetwork(cfg, network)
+	}
+
+	chainIDFloat, hasChainID := args["chain_id"].(float64)
+	asset, hasAsset := args["asset"].(string)
+	if !hasChainID || !hasAsset {
+		return "", fmt.Errorf("network must be a string (or provide chain_id and asset)")
+	}
+
+	if chainIDFloat < 1 || chainIDFloat != math.Trunc(chainIDFloat) || chainIDFloat > 1<<53 {
+		return "", fmt.Errorf("chain_id must be a positive integer")
+	}
+	chainID := uint64(chainIDFloat)
+
+	network, ok := cfg.ResolveByAsset(chainID, asset)
+	if !ok {
+		return "", fmt.Errorf("no unique configured network for asset %s on chain %d", asset, chainID)
+	}
+
+	return network, nil
+}
+
+// canonicalNetwork returns the configured name for a network argument given in any case
+func canonicalNetwork(cfg *config.Config, network string) (string, error) {
+	canonical, ok := cfg.CanonicalNetwork(network)
+	if !ok {
+		return "", fmt.Errorf("unsupported network: %s", network)
+	}
+	return canonical, nil
+}
+
+// parseAuthorization converts the input map to an EIP3009Authorization struct
+func parseAuthorization(authMap map[string]interface{}) (*eip3009.EIP3009Authorization, error) {
+	auth, err := parseAuthorizationMessage(authMap)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := parseSignature(authMap)
+	if err != nil {
+		return nil, err
+	}
+
+	auth.V = signature.V
+	auth.R = signature.R
+	auth.S = signature.S
+	auth.EIP155ChainID = signature.EIP155ChainID
+
+	return auth, nil
+}
+
+// authorizationArgs renders a signed authorization in the form parseAuthorization accepts,
+// with numbers as float64 as if decoded from JSON
+func authorizationArgs(auth *eip3009.EIP3009Authorization) map[string]interface{} {
+	return map[string]interface{}{
+		"from":        auth.From,
+		"to":          auth.To,
+		"value":       auth.Value,
+		"validAfter":  float64(auth.ValidAfter),
+		"validBefore": float64(auth.ValidBefore),
+		"nonce":       auth.Nonce,
+		"v":           float64(auth.V),
+		"r":           auth.R,
+		"s":           auth.S,
+	}
+}
+
+// parseAuthorizationMessage extracts the signed message fields, leaving v/r/s unset
+func parseAuthorizationMessage(authMap map[string]interface{}) (*eip3009.EIP3009Authorization, error) {
+	// Extract required string fields
+	from, ok := authMap["from"].(string)
+	if !ok {
+		return nil, fmt.Errorf("from must be a string")
+	}
+
+	to, ok := authMap["to"].(string)
+	if !ok {
+		return nil, fmt.Errorf("to must be a string")
+	}
+
+	value, ok := authMap["value"].(string)
+	if !ok {
+		return nil, fmt.Errorf("value must be a string")
+	}
+
+	nonce, ok := authMap["nonce"].(string)
+	if !ok {
+		return nil, fmt.Errorf("nonce must be a string")
+	}
+	nonce, err := eip3009.NormalizeBytes32(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	if err := eip3009.ValidateHex("nonce", nonce); err != nil {
+		return nil, err
+	}
+
+	// Extract uint64 fields (JSON numbers come as float64)
+	validAfter, err := parseTimestamp(authMap, "validAfter")
+	if err != nil {
+		return nil, err
+	}
+
+	validBefore, err := parseTimestamp(authMap, "validBefore")
+	if err != nil {
+		return nil, err
+	}
+
+	return &eip3009.EIP3009Authorization{
+		From:        from,
+		To:          to,
+		Value:       value,
+		ValidAfter:  validAfter,
+		ValidBefore: validBefore,
+		Nonce:       nonce,
+	}, nil
+}
+
+// parseTimestamp extracts a unix-seconds field, rejecting values a direct float64 to uint64
+// conversion would silently corrupt (negative, NaN/Inf) or that are implausibly far in the future
+func parseTimestamp(authMap map[string]interface{}, field string) (uint64, error) {
+	value, ok := authMap[field].(float64)
+	if !ok {
+		return 0, &eip3009.ValidationError{Field: field, Reason: "must be a number"}
+	}
+
+	switch {
+	case math.IsNaN(value) || math.IsInf(value, 0):
+		return 0, &eip3009.ValidationError{Field: field, Reason: "must be a finite number"}
+	case value < 0:
+		return 0, &eip3009.ValidationError{Field: field, Reason: "must not be negative"}
+	case value != math.Trunc(value):
+		return 0, &eip3009.ValidationError{Field: field, Reason: "must be a whole number of seconds"}
+	case value > float64(eip3009.MaxTimestamp):
+		return 0, &eip3009.ValidationError{Field: field, Reason: fmt.Sprintf("exceeds maximum timestamp %d", eip3009.MaxTimestamp)}
+	}
+
+	return uint64(value), nil
+}
+
+// parseSignature extracts v/r/s signature components from an input map
+func parseSignature(sigMap map[string]interface{}) (*eip3009.Signature, error) {
+	r, ok := sigMap["r"].(string)
+	if !ok {
+		return nil, fmt.Errorf("r must be a string")
+	}
+
+	s, ok := sigMap["s"].(string)
+	if !ok {
+		return nil, fmt.Errorf("s must be a string")
+	}
+
+	// Accept base64 components, validated as hex by the verifier
+	r, err := eip3009.NormalizeBytes32(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid r: %w", err)
+	}
+	s, err = eip3009.NormalizeBytes32(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s: %w", err)
+	}
+
+	// Report non-hex characters by field before any decoding truncates them
+	if err := eip3009.ValidateHex("r", r); err != nil {
+		return nil, err
+	}
+	if err := eip3009.ValidateHex("s", s); err != nil {
+		return nil, err
+	}
+
+	// Extract v (could be float64 or int)
+	var raw uint64
+	switch vVal := sigMap["v"].(type) {
+	case float64:
+		if vVal < 0 || vVal != math.Trunc(vVal) || vVal > 1<<53 {
+			return nil, fmt.Errorf("v must be a non-negative integer")
+		}
+		raw = uint64(vVal)
+	case int:
+		if vVal < 0 {
+			return nil, fmt.Errorf("v must be a non-negative integer")
+		}
+		raw = uint64(vVal)
+	default:
+		return nil, fmt.Errorf("v must be a number")
+	}
+
+	// Normalize to 27/28, keeping any EIP-155 chain ID for the verifier's eip155_v policy
+	v, chainID, err := eip3009.DecodeV(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eip3009.Signature{V: v, R: r, S: s, EIP155ChainID: chainID}, nil
+}
+
+// parseSignatures extracts an array of v/r/s signatures
+func parseSignatures(raw interface{}) ([]eip3009.Signature, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("signatures must be an array")
+	}
+
+	signatures := make([]eip3009.Signature, 0, len(list))
+	for i, item := range list {
+		sigMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("signatures[%d] must be an object", i)
+		}
+
+		signature, err := parseSignature(sigMap)
+		if err != nil {
+			return nil, fmt.Errorf("signatures[%d]: %w", i, err)
+		}
+
+		signatures = append(signatures, *signature)
+	}
+
+	return signatures, nil
+}
+
+// signaturesSchema returns the JSON schema for an array of multisig signatures
+func signaturesSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        "array",
+		"description": "Optional M-of-N owner signatures over the authorization's typed data; when present, authorization v/r/s are ignored and the payer must have a configured multisig policy",
+		"items": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"v": map[string]interface{}{
+					"type":    "integer",
+					"minimum": 27,
+				},
+				"r": map[string]interface{}{
+					"type":    "string",
+					"pattern": bytes32InputPattern,
+				},
+				"s": map[string]interface{}{
+					"type":    "string",
+					"pattern": bytes32InputPattern,
+				},
+			},
+			"required": []string{"v", "r", "s"},
+		},
+	}
+}
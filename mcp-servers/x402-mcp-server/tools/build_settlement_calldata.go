@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// BuildSettlementCalldataTool implements the build_settlement_calldata MCP tool
+type BuildSettlementCalldataTool struct {
+	server *server.Server
+}
+
+// NewBuildSettlementCalldataTool creates a new build_settlement_calldata tool
+func NewBuildSettlementCalldataTool(srv *server.Server) *BuildSettlementCalldataTool {
+	return &BuildSettlementCalldataTool{
+		server: srv,
+	}
+}
+
+// Name returns the tool name
+func (t *BuildSettlementCalldataTool) Name() string {
+	return "build_settlement_calldata"
+}
+
+// Description returns the tool description
+func (t *BuildSettlementCalldataTool) Description() string {
+	return "Build raw receiveWithAuthorization calldata for an EIP-3009 authorization. Returns hex-encoded calldata and the USDC contract address so callers can submit the settlement transaction themselves."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *BuildSettlementCalldataTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"authorization": authorizationSchema(),
+			"network": map[string]interface{}{
+				"type":        "string",
+				"description": "Blockchain network the calldata targets",
+				"enum":        x402.SupportedNetworks(),
+			},
+		},
+		"required": []string{"authorization", "network"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *BuildSettlementCalldataTool) Execute(args map[string]interface{}) (interface{}, error) {
+	// Extract network
+	network, ok := args["network"].(string)
+	if !ok {
+		return nil, fmt.Errorf("network must be a string")
+	}
+
+	// Extract authorization object
+	authMap, ok := args["authorization"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("authorization must be an object")
+	}
+
+	// Parse authorization fields
+	auth, err := parseAuthorization(authMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse authorization: %w", err)
+	}
+
+	// Get network configuration, accepting the network name in any case
+	cfg := t.server.GetConfig()
+	network, err = canonicalNetwork(cfg, network)
+	if err != nil {
+		return nil, err
+	}
+	networkCfg := cfg.Networks[network]
+
+	// Encode calldata
+	calldata, err := eip3009.EncodeReceiveWithAuthorization(auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build calldata: %w", err)
+	}
+
+	logger := t.server.GetLogger()
+	logger.Info("Built settlement calldata", map[string]interface{}{
+		"network": network,
+		"from":    auth.From,
+		"nonce":   auth.Nonce,
+	})
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"calldata": hexutil.Encode(calldata),
+		"selector": hexutil.Encode(eip3009.ReceiveWithAuthorizationSelector),
+		"to":       networkCfg.USDCContract,
+		"chain_id": networkCfg.ChainID,
+		"network":  network,
+	}, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *BuildSettlementCalldataTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// maxNonceBatch bounds how many nonces a single call may check
+	maxNonceBatch = 100
+
+	// nonceCheckConcurrency bounds parallel authorizationState reads per call
+	nonceCheckConcurrency = 8
+)
+
+// CheckNonceUsageTool implements the check_nonce_usage MCP tool
+type CheckNonceUsageTool struct {
+	server       *server.Server
+	nonceChecker *onchain.NonceChecker
+}
+
+// NewCheckNonceUsageTool creates a new check_nonce_usage tool
+func NewCheckNonceUsageTool(srv *server.Server) *CheckNonceUsageTool {
+	tool := &CheckNonceUsageTool{
+		server:       srv,
+		nonceChecker: onchain.NewNonceChecker(srv.GetConfig(), 10*time.Second, nonceCheckConcurrency),
+	}
+
+	// Check nonces against the reloaded networks and RPC endpoints
+	srv.OnConfigReload(func(_, newCfg *config.Config) {
+		tool.nonceChecker.UpdateConfig(newCfg)
+	})
+
+	return tool
+}
+
+// NonceChecker returns the on-chain nonce checker used by this tool
+func (t *CheckNonceUsageTool) NonceChecker() *onchain.NonceChecker {
+	return t.nonceChecker
+}
+
+// Name returns the tool name
+func (t *CheckNonceUsageTool) Name() string {
+	return "check_nonce_usage"
+}
+
+// Description returns the tool description
+func (t *CheckNonceUsageTool) Description() string {
+	return "Batch-check whether EIP-3009 authorization nonces have been used on-chain. Reads USDC authorizationState for each (from, nonce) pair concurrently and reports used/unused, so agents can prune pending authorizations."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *CheckNonceUsageTool) Schema() interface{} {
+	properties := map[string]interface{}{
+		"authorizations": map[string]interface{}{
+			"type":        "array",
+			"description": fmt.Sprintf("Payer/nonce pairs to check (at most %d)", maxNonceBatch),
+			"maxItems":    maxNonceBatch,
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"from": map[string]interface{}{
+						"type":        "string",
+						"description": "Payer address (0x-prefixed)",
+						"pattern":     "^0x[a-fA-F0-9]{40}$",
+					},
+					"nonce": map[string]interface{}{
+						"type":        "string",
+						"description": "Authorization nonce (bytes32 hex or base64)",
+						"pattern":     bytes32InputPattern,
+					},
+				},
+				"required": []string{"from", "nonce"},
+			},
+		},
+	}
+	for name, schema := range networkSchemaProperties("Blockchain network to check") {
+		properties[name] = schema
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"authorizations"},
+		"anyOf":      networkSelector(),
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *CheckNonceUsageTool) Execute(args map[string]interface{}) (interface{}, error) {
+	// Extract network, resolving it from chain_id and asset when omitted
+	network, err := resolveNetwork(t.server.GetConfig(), args)
+	if err != nil {
+		return nil, err
+	}
+
+	queries, err := parseNonceQueries(args["authorizations"])
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := t.nonceChecker.Check(network, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]interface{}, 0, len(statuses))
+	usedCount, unusedCount, errorCount := 0, 0, 0
+	for i := range statuses {
+		switch {
+		case statuses[i].Error != "":
+			errorCount++
+		case statuses[i].Used:
+			usedCount++
+		default:
+			unusedCount++
+		}
+		result := statuses[i].ToMap()
+		result["nonce"] = formatBytes32(t.server.GetConfig(), statuses[i].Nonce)
+		results = append(results, result)
+	}
+
+	logger := t.server.GetLogger()
+	logger.Info("Checked on-chain nonce usage", map[string]interface{}{
+		"network": network,
+		"checked": len(statuses),
+		"used":    usedCount,
+		"unused":  unusedCount,
+		"errors":  errorCount,
+	})
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"network":      network,
+		"results":      results,
+		"used_count":   usedCount,
+		"unused_count": unusedCount,
+		"error_count":  errorCount,
+	}, nil
+}
+
+// parseNonceQueries converts the authorizations input array into nonce queries
+func parseNonceQueries(raw interface{}) ([]onchain.NonceQuery, error) {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("authorizations must be an array")
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("authorizations must not be empty")
+	}
+	if len(list) > maxNonceBatch {
+		return nil, fmt.Errorf("authorizations has %d entries, maximum is %d", len(list), maxNonceBatch)
+	}
+
+	queries := make([]onchain.NonceQuery, 0, len(list))
+	for i, item := range list {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("authorizations[%d] must be an object", i)
+		}
+
+		from, ok := entry["from"].(string)
+		if !ok {
+			return nil, fmt.Errorf("authorizations[%d].from must be a string", i)
+		}
+
+		nonce, ok := entry["nonce"].(string)
+		if !ok {
+			return nil, fmt.Errorf("authorizations[%d].nonce must be a string", i)
+		}
+		// Invalid nonces are left as given for the checker to report per entry
+		if normalized, err := eip3009.NormalizeBytes32(nonce); err == nil {
+			nonce = normalized
+		}
+
+		queries = append(queries, onchain.NonceQuery{From: from, Nonce: nonce})
+	}
+
+	return queries, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *CheckNonceUsageTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
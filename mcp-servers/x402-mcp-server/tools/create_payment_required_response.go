@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// CreatePaymentRequiredResponseTool implements the create_payment_required_response MCP tool
+type CreatePaymentRequiredResponseTool struct {
+	server *server.Server
+}
+
+// NewCreatePaymentRequiredResponseTool creates a new create_payment_required_response tool
+func NewCreatePaymentRequiredResponseTool(srv *server.Server) *CreatePaymentRequiredResponseTool {
+	return &CreatePaymentRequiredResponseTool{
+		server: srv,
+	}
+}
+
+// Name returns the tool name
+func (t *CreatePaymentRequiredResponseTool) Name() string {
+	return "create_payment_required_response"
+}
+
+// Description returns the tool description
+func (t *CreatePaymentRequiredResponseTool) Description() string {
+	return "Generate the complete x402 HTTP 402 Payment Required response body ({x402Version, error, accepts: [paymentRequirements]}) ready for a resource server to serve. Returns the body as an object and as a JSON string."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *CreatePaymentRequiredResponseTool) Schema() interface{} {
+	properties := paymentRequirementProperties()
+	properties["error"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Error message included in the 402 body (default: '" + x402.DefaultPaymentRequiredError + "')",
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   []interface{}{"network"},
+		"anyOf":      paymentAmountSelector(),
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *CreatePaymentRequiredResponseTool) Execute(args map[string]interface{}) (interface{}, error) {
+	paymentReq, err := buildPaymentRequirement(t.server, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract optional error message (defaulted by NewPaymentRequiredResponse)
+	errorMessage, _ := args["error"].(string)
+
+	response, err := x402.NewPaymentRequiredResponse(errorMessage, paymentReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create 402 response: %w", err)
+	}
+
+	body, err := response.ToJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode 402 response: %w", err)
+	}
+
+	logger := t.server.GetLogger()
+	logger.Info("Created 402 payment required response", map[string]interface{}{
+		"network":  paymentReq.Network,
+		"amount":   paymentReq.MaxAmountRequired,
+		"resource": paymentReq.Resource,
+		"nonce":    paymentReq.Nonce,
+	})
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"status_code": 402,
+		"body":        response.ToMap(),
+		"body_json":   string(body),
+	}, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *CreatePaymentRequiredResponseTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -2,9 +2,13 @@ package tools
 
 import (
 	"fmt"
-	"time"
+	"math/big"
+	"strings"
 
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/pricing"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
@@ -33,43 +37,153 @@ func (t *CreatePaymentRequirementTool) Description() string {
 
 // Schema returns the JSON schema for the tool's input
 func (t *CreatePaymentRequirementTool) Schema() interface{} {
+	properties := paymentRequirementProperties()
+	properties["explain"] = map[string]interface{}{
+		"type":        "boolean",
+		"description": "Also return explain: a plain-language summary of the requirement (amount, network, payee, purpose, expiry) to show users",
+		"default":     false,
+	}
+
 	return map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"amount": map[string]interface{}{
-				"type":        "string",
-				"description": "Payment amount in USDC atomic units (6 decimals). Example: '50000' = 0.05 USDC",
-				"pattern":     "^[1-9][0-9]*$",
-			},
-			"network": map[string]interface{}{
-				"type":        "string",
-				"description": "Blockchain network for payment",
-				"enum":        []interface{}{"base", "base-sepolia", "arbitrum"},
-			},
-			"resource": map[string]interface{}{
-				"type":        "string",
-				"description": "URL of the resource being paid for (e.g., certification endpoint)",
-			},
-			"description": map[string]interface{}{
-				"type":        "string",
-				"description": "Human-readable description of what the payment is for",
-			},
-			"mime_type": map[string]interface{}{
-				"type":        "string",
-				"description": "MIME type of the resource response (default: application/json)",
-				"default":     "application/json",
-			},
+		"type":       "object",
+		"properties": properties,
+		"required":   []interface{}{"network"},
+		"anyOf":      paymentAmountSelector(),
+	}
+}
+
+// paymentAmountSelector requires either an atomic amount or a fiat amount with its currency
+func paymentAmountSelector() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"required": []interface{}{"amount"}},
+		map[string]interface{}{"required": []interface{}{"amount_fiat", "fiat_currency"}},
+	}
+}
+
+// networkEnum lists the supported networks as a JSON schema enum
+func networkEnum() []interface{} {
+	names := x402.SupportedNetworks()
+	enum := make([]interface{}, len(names))
+	for i, name := range names {
+		enum[i] = name
+	}
+	return enum
+}
+
+// paymentRequirementProperties returns the JSON schema properties accepted by buildPaymentRequirement
+func paymentRequirementProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"amount": map[string]interface{}{
+			"type":        "string",
+			"description": "Payment amount in atomic units of the network's asset (USDC: 6 decimals). Example: '50000' = 0.05 USDC",
+			"pattern":     "^[1-9][0-9]*$",
+		},
+		"amount_fiat": map[string]interface{}{
+			"type":        "string",
+			"description": "Price in fiat_currency (e.g., '4.99'), converted to atomic units with the configured price oracle; use instead of amount",
+			"pattern":     "^[0-9]+(\\.[0-9]+)?$",
+		},
+		"fiat_currency": map[string]interface{}{
+			"type":        "string",
+			"description": "ISO 4217 currency of amount_fiat (USD, or a currency with a pricing.usd_per_fiat rate)",
+			"pattern":     "^[A-Za-z]{3}$",
+		},
+		"network": map[string]interface{}{
+			"type":        "string",
+			"description": "Blockchain network for payment",
+			"enum":        networkEnum(),
+		},
+		"resource": map[string]interface{}{
+			"type":        "string",
+			"description": "URL of the resource being paid for (e.g., certification endpoint)",
+		},
+		"description": map[string]interface{}{
+			"type":        "string",
+			"description": "Human-readable description of what the payment is for",
+		},
+		"mime_type": map[string]interface{}{
+			"type":        "string",
+			"description": "MIME type of the resource response (default: application/json)",
+			"default":     "application/json",
+		},
+		"output_schema": map[string]interface{}{
+			"type":        "string",
+			"description": "Named template filling the requirement's outputSchema with a standard response contract (default: requirements.default_output_schema)",
+			"enum":        x402.OutputSchemaTemplateNames(),
 		},
-		"required": []interface{}{"amount", "network"},
 	}
 }
 
 // Execute executes the tool with the given arguments
 func (t *CreatePaymentRequirementTool) Execute(args map[string]interface{}) (interface{}, error) {
-	// Extract required fields
-	amount, ok := args["amount"].(string)
-	if !ok {
-		return nil, fmt.Errorf("amount must be a string")
+	explain := false
+	if rawExplain, exists := args["explain"]; exists {
+		var ok bool
+		if explain, ok = rawExplain.(bool); !ok {
+			return nil, fmt.Errorf("explain must be a boolean")
+		}
+	}
+
+	paymentReq, err := buildPaymentRequirement(t.server, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Log the operation
+	logger := t.server.GetLogger()
+	logger.Info("Created payment requirement", map[string]interface{}{
+		"network":     paymentReq.Network,
+		"amount":      paymentReq.MaxAmountRequired,
+		"resource":    paymentReq.Resource,
+		"description": paymentReq.Description,
+		"nonce":       paymentReq.Nonce,
+	})
+
+	// Return as map for MCP
+	result := paymentReq.ToMap()
+	if explain {
+		result["explain"] = t.explain(paymentReq)
+	}
+	return result, nil
+}
+
+// explain summarizes a requirement in plain language, e.g.
+// "Pay 0.05 USDC on Base to 0x… for API access, valid until 2026-01-02T15:04:05Z"
+func (t *CreatePaymentRequirementTool) explain(paymentReq *x402.PaymentRequirement) string {
+	amount := paymentReq.MaxAmountRequired
+	if atomic, ok := new(big.Int).SetString(amount, 10); ok {
+		amount = t.formatHuman(atomic, t.server.GetConfig().AssetDecimals(paymentReq.Network))
+	}
+
+	asset := x402.AssetSymbol(paymentReq.Network, paymentReq.Asset)
+	if asset == "" {
+		asset = "of token " + paymentReq.Asset
+	}
+
+	return fmt.Sprintf("Pay %s %s on %s to %s for %s, valid until %s",
+		amount, asset, x402.NetworkDisplayName(paymentReq.Network), paymentReq.PayTo,
+		paymentReq.Description, paymentReq.ValidUntil)
+}
+
+// formatHuman renders an atomic amount in the configured display.amount_format
+func (t *CreatePaymentRequirementTool) formatHuman(amount *big.Int, decimals int) string {
+	if t.server.GetConfig().Display.AmountFormat == config.AmountFormatGrouped {
+		return units.ToHumanGrouped(amount, decimals)
+	}
+	return units.ToHuman(amount, decimals)
+}
+
+// buildPaymentRequirement creates a payment requirement from amount/network/resource tool arguments
+// Shared by create_payment_requirement and create_payment_required_response
+func buildPaymentRequirement(srv *server.Server, args map[string]interface{}) (*x402.PaymentRequirement, error) {
+	// Extract the amount: atomic units, or a fiat price converted once the asset is known
+	amount, amountGiven := args["amount"]
+	amountFiat, fiatGiven := args["amount_fiat"]
+	if amountGiven && fiatGiven {
+		return nil, fmt.Errorf("amount and amount_fiat are mutually exclusive")
+	}
+	if !amountGiven && !fiatGiven {
+		return nil, fmt.Errorf("amount or amount_fiat is required")
 	}
 
 	network, ok := args["network"].(string)
@@ -95,40 +209,100 @@ func (t *CreatePaymentRequirementTool) Execute(args map[string]interface{}) (int
 		mimeType = "application/json"
 	}
 
-	// Get network configuration
-	cfg := t.server.GetConfig()
-	networkCfg, exists := cfg.Networks[network]
-	if !exists {
-		return nil, fmt.Errorf("unsupported network: %s", network)
+	// Extract optional output schema template, falling back to the configured default
+	cfg := srv.GetConfig()
+	outputSchema := cfg.Requirements.DefaultOutputSchema
+	if raw, exists := args["output_schema"]; exists {
+		if outputSchema, ok = raw.(string); !ok {
+			return nil, fmt.Errorf("output_schema must be a string")
+		}
+	}
+
+	// Get network configuration, accepting the network name in any case
+	network, err := canonicalNetwork(cfg, network)
+	if err != nil {
+		return nil, err
+	}
+	networkCfg := cfg.Networks[network]
+
+	atomicAmount, err := requirementAmount(srv, networkCfg.USDCContract, networkCfg.Decimals(), amount, amountFiat, args["fiat_currency"])
+	if err != nil {
+		return nil, err
 	}
 
-	// Create payment requirement with 24-hour validity
+	// Create payment requirement with the configured validity
 	paymentReq, err := x402.NewPaymentRequirement(
-		amount,
+		atomicAmount,
 		network,
 		networkCfg.PayeeAddress,
 		networkCfg.USDCContract,
 		resource,
 		description,
 		mimeType,
-		24*time.Hour,
+		cfg.Requirements.Validity(),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create payment requirement: %w", err)
 	}
 
-	// Log the operation
-	logger := t.server.GetLogger()
-	logger.Info("Created payment requirement", map[string]interface{}{
-		"network":     network,
-		"amount":      amount,
-		"resource":    resource,
-		"description": description,
-		"nonce":       paymentReq.Nonce,
+	// Describe the resource response with the selected template
+	if outputSchema != "" {
+		schema, known := x402.OutputSchemaTemplate(outputSchema)
+		if !known {
+			return nil, fmt.Errorf("unknown output_schema %q: must be one of %s",
+				outputSchema, strings.Join(x402.OutputSchemaTemplateNames(), ", "))
+		}
+		paymentReq.OutputSchema = schema
+	}
+
+	// Advertise the asset's actual EIP-712 domain so payers sign against the right name/version
+	if params, err := cfg.DomainParams(network); err == nil {
+		paymentReq.Extra.Name = params.Name
+		paymentReq.Extra.Version = params.Version
+	}
+
+	// Advertise the network's settlement timeout when configured
+	if networkCfg.SettlementTimeoutSeconds > 0 {
+		paymentReq.MaxTimeoutSeconds = networkCfg.SettlementTimeoutSeconds
+	}
+
+	return paymentReq, nil
+}
+
+// requirementAmount returns the atomic amount argument, or converts a fiat amount to the
+// asset's atomic units (decimals) with the server's price oracle
+func requirementAmount(srv *server.Server, asset string, decimals int, amount, amountFiat, fiatCurrency interface{}) (string, error) {
+	if amountFiat == nil {
+		atomic, ok := amount.(string)
+		if !ok {
+			return "", fmt.Errorf("amount must be a string")
+		}
+		return atomic, nil
+	}
+
+	fiat, ok := amountFiat.(string)
+	if !ok {
+		return "", fmt.Errorf("amount_fiat must be a string")
+	}
+	currency, ok := fiatCurrency.(string)
+	if !ok || currency == "" {
+		return "", fmt.Errorf("fiat_currency is required with amount_fiat")
+	}
+
+	atomic, err := pricing.FiatToAtomic(srv.GetPriceOracle(), asset, fiat, currency,
+		srv.GetConfig().Pricing.USDPerFiat, decimals)
+	if err != nil {
+		return "", err
+	}
+
+	srv.GetLogger().Info("Converted fiat price to atomic amount", map[string]interface{}{
+		"amount_fiat":   fiat,
+		"fiat_currency": strings.ToUpper(currency),
+		"asset":         asset,
+		"amount":        atomic.String(),
 	})
 
-	// Return as map for MCP
-	return paymentReq.ToMap(), nil
+	return atomic.String(), nil
 }
 
 // Register registers the tool with the MCP server
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// DecodePaymentRequirementTool implements the decode_payment_requirement MCP tool
+type DecodePaymentRequirementTool struct {
+	server *server.Server
+}
+
+// NewDecodePaymentRequirementTool creates a new decode_payment_requirement tool
+func NewDecodePaymentRequirementTool(srv *server.Server) *DecodePaymentRequirementTool {
+	return &DecodePaymentRequirementTool{
+		server: srv,
+	}
+}
+
+// Name returns the tool name
+func (t *DecodePaymentRequirementTool) Name() string {
+	return "decode_payment_requirement"
+}
+
+// Description returns the tool description
+func (t *DecodePaymentRequirementTool) Description() string {
+	return "Decode and validate an x402 payment requirement. Reports whether the requirement has expired and the seconds remaining, so clients can check before signing an authorization."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *DecodePaymentRequirementTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"requirement": map[string]interface{}{
+				"type":        []string{"object", "string"},
+				"description": "Payment requirement as returned by create_payment_requirement (object or JSON string)",
+			},
+		},
+		"required": []string{"requirement"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *DecodePaymentRequirementTool) Execute(args map[string]interface{}) (interface{}, error) {
+	// Accept either a JSON string or an already-decoded object
+	paymentReq, err := parseRequirement(args["requirement"])
+	if err != nil {
+		return nil, err
+	}
+
+	// Report expiry so clients can refresh before signing
+	now := time.Now()
+	expired := paymentReq.IsExpired(now)
+	secondsUntilExpiry := int64(0)
+	if !expired {
+		secondsUntilExpiry = int64(paymentReq.TimeUntilExpiry(now) / time.Second)
+	}
+
+	result := paymentReq.ToMap()
+	result["expired"] = expired
+	result["seconds_until_expiry"] = secondsUntilExpiry
+
+	if amount, ok := new(big.Int).SetString(paymentReq.MaxAmountRequired, 10); ok {
+		result["amount_human"] = t.formatHuman(amount, t.server.GetConfig().AssetDecimals(paymentReq.Network))
+	}
+
+	logger := t.server.GetLogger()
+	logger.Info("Decoded payment requirement", map[string]interface{}{
+		"network":              paymentReq.Network,
+		"nonce":                paymentReq.Nonce,
+		"expired":              expired,
+		"seconds_until_expiry": secondsUntilExpiry,
+	})
+
+	// Return as map for MCP
+	return result, nil
+}
+
+// formatHuman renders an atomic amount of an asset with the given decimals in the configured display format
+func (t *DecodePaymentRequirementTool) formatHuman(amount *big.Int, decimals int) string {
+	if t.server.GetConfig().Display.AmountFormat == config.AmountFormatGrouped {
+		return units.ToHumanGrouped(amount, decimals)
+	}
+	return units.ToHuman(amount, decimals)
+}
+
+// Register registers the tool with the MCP server
+func (t *DecodePaymentRequirementTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// Latency sources reported by estimate_settlement_time
+const (
+	latencySourceMetrics = "metrics" // Mean of settlements measured by this server
+	latencySourceDefault = "default" // estimates.submit_latency_ms (nothing measured yet)
+)
+
+// EstimateSettlementTimeTool implements the estimate_settlement_time MCP tool
+type EstimateSettlementTimeTool struct {
+	server *server.Server
+}
+
+// NewEstimateSettlementTimeTool creates a new estimate_settlement_time tool
+func NewEstimateSettlementTimeTool(srv *server.Server) *EstimateSettlementTimeTool {
+	return &EstimateSettlementTimeTool{
+		server: srv,
+	}
+}
+
+// Name returns the tool name
+func (t *EstimateSettlementTimeTool) Name() string {
+	return "estimate_settlement_time"
+}
+
+// Description returns the tool description
+func (t *EstimateSettlementTimeTool) Description() string {
+	return "Estimate end-to-end settlement time for a network so agents can set user expectations. Combines typical submission latency (measured settlements, else the configured default) with required confirmations times the network's average block time."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *EstimateSettlementTimeTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": networkSchemaProperties("Blockchain network to estimate"),
+		"anyOf":      networkSelector(),
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *EstimateSettlementTimeTool) Execute(args map[string]interface{}) (interface{}, error) {
+	cfg := t.server.GetConfig()
+	network, err := resolveNetwork(cfg, args)
+	if err != nil {
+		return nil, err
+	}
+	networkCfg := cfg.Networks[network]
+
+	// Typical submission latency: measured mean when available, else the configured default
+	latency := cfg.Estimates.SubmitLatency().Seconds()
+	source := latencySourceDefault
+	snapshot := t.server.GetMetrics().Histogram(facilitator.MetricSettlementLatency, metrics.Labels{"network": network})
+	if snapshot.Count > 0 {
+		latency = snapshot.Sum / float64(snapshot.Count)
+		source = latencySourceMetrics
+	}
+
+	// A settlement needs at least its inclusion block even when no extra confirmations are required
+	confirmations := networkCfg.Confirmations
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	blockTime := networkCfg.BlockTime()
+	confirmationSeconds := float64(confirmations) * blockTime.Seconds()
+
+	estimate := latency + confirmationSeconds
+
+	logger := t.server.GetLogger()
+	logger.Debug("Estimated settlement time", map[string]interface{}{
+		"network":        network,
+		"estimate":       estimate,
+		"latency_source": source,
+	})
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"network":                      network,
+		"estimated_settlement_seconds": roundSeconds(estimate),
+		"submit_latency_seconds":       roundSeconds(latency),
+		"latency_source":               source,
+		"latency_samples":              snapshot.Count,
+		"confirmations":                confirmations,
+		"block_time_seconds":           roundSeconds(blockTime.Seconds()),
+		"confirmation_seconds":         roundSeconds(confirmationSeconds),
+	}, nil
+}
+
+// roundSeconds rounds to milliseconds so estimates read cleanly
+func roundSeconds(seconds float64) float64 {
+	return math.Round(seconds*1000) / 1000
+}
+
+// Register registers the tool with the MCP server
+func (t *EstimateSettlementTimeTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// defaultTestAmount is the requirement amount (atomic units) when none is given: 0.01 USDC
+const defaultTestAmount = "10000"
+
+// GenerateTestAuthorizationTool implements the generate_test_authorization MCP tool
+// It is only available in the test environment.
+type GenerateTestAuthorizationTool struct {
+	server   *server.Server
+	verifier *eip3009.SignatureVerifier
+}
+
+// NewGenerateTestAuthorizationTool creates a new generate_test_authorization tool
+func NewGenerateTestAuthorizationTool(srv *server.Server) *GenerateTestAuthorizationTool {
+	tool := &GenerateTestAuthorizationTool{
+		server:   srv,
+		verifier: eip3009.NewSignatureVerifier(srv.GetConfig()),
+	}
+
+	// Sign under the domain verification uses after a config reload
+	srv.OnConfigReload(func(_, newCfg *config.Config) {
+		tool.verifier.UpdateConfig(newCfg)
+	})
+
+	return tool
+}
+
+// Name returns the tool name
+func (t *GenerateTestAuthorizationTool) Name() string {
+	return "generate_test_authorization"
+}
+
+// Description returns the tool description
+func (t *GenerateTestAuthorizationTool) Description() string {
+	return "Test environment only: create a payment requirement and pay it with a freshly generated throwaway key. Returns the requirement, the signed authorization (ready for verify_payment or settle_payment), and the key, as a working example for integrators. The key holds no funds and must never be used for real payments."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *GenerateTestAuthorizationTool) Schema() interface{} {
+	properties := paymentRequirementProperties()
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"network": properties["network"],
+			"amount": map[string]interface{}{
+				"type":        "string",
+				"description": "Payment amount in atomic units of the network's asset (default: " + defaultTestAmount + ")",
+				"pattern":     "^[1-9][0-9]*$",
+			},
+		},
+		"required": []string{"network"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *GenerateTestAuthorizationTool) Execute(args map[string]interface{}) (interface{}, error) {
+	cfg := t.server.GetConfig()
+	if !cfg.TestMode() {
+		return nil, fmt.Errorf("%s is only available when environment is %q", t.Name(), config.EnvironmentTest)
+	}
+
+	amount := defaultTestAmount
+	if raw, exists := args["amount"]; exists {
+		var ok bool
+		if amount, ok = raw.(string); !ok {
+			return nil, fmt.Errorf("amount must be a string")
+		}
+	}
+
+	requirement, err := buildPaymentRequirement(t.server, map[string]interface{}{
+		"amount":      amount,
+		"network":     args["network"],
+		"description": "Test authorization",
+	})
+	if err != nil {
+		return nil, err
+	}
+	network := requirement.Network
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	var nonce [32]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	// Valid from now for the requirement's settlement timeout
+	validAfter := uint64(time.Now().Unix())
+	value, _ := new(big.Int).SetString(requirement.MaxAmountRequired, 10)
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        crypto.PubkeyToAddress(privateKey.PublicKey),
+		To:          common.HexToAddress(requirement.PayTo),
+		Value:       value,
+		ValidAfter:  new(big.Int).SetUint64(validAfter),
+		ValidBefore: new(big.Int).SetUint64(validAfter + uint64(requirement.MaxTimeoutSeconds)),
+		Nonce:       nonce,
+	}
+
+	params, err := requirementDomainParams(cfg, network, requirement)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := t.verifier.SigningDomain(network, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build EIP-712 domain: %w", err)
+	}
+
+	auth, err := eip3009.SignAuthorization(message, domain, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign authorization: %w", err)
+	}
+
+	t.server.GetLogger().Warn("Generated test authorization with a throwaway key", map[string]interface{}{
+		"network": network,
+		"from":    auth.From,
+		"nonce":   auth.Nonce,
+	})
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"network":       network,
+		"requirement":   requirement.ToMap(),
+		"authorization": authorizationArgs(auth),
+		"private_key":   hexutil.Encode(crypto.FromECDSA(privateKey)),
+		"address":       auth.From,
+	}, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *GenerateTestAuthorizationTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/rpc"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// GetBalanceTool implements the get_balance MCP tool
+type GetBalanceTool struct {
+	server *server.Server
+
+	mu       sync.Mutex
+	fetchers map[string]*rpc.BalanceFetcher
+}
+
+// NewGetBalanceTool creates a new get_balance tool
+func NewGetBalanceTool(srv *server.Server) *GetBalanceTool {
+	tool := &GetBalanceTool{
+		server:   srv,
+		fetchers: make(map[string]*rpc.BalanceFetcher),
+	}
+
+	// RPC URLs may change on reload; reconnect on next use
+	srv.OnConfigReload(func(_, _ *config.Config) {
+		tool.mu.Lock()
+		defer tool.mu.Unlock()
+
+		for network, fetcher := range tool.fetchers {
+			fetcher.Close()
+			delete(tool.fetchers, network)
+		}
+	})
+
+	return tool
+}
+
+// SetBalanceFetcher overrides the balance fetcher used for a network
+func (t *GetBalanceTool) SetBalanceFetcher(network string, fetcher *rpc.BalanceFetcher) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.fetchers[network] = fetcher
+}
+
+// fetcher returns the balance fetcher for a network, dialing its rpc_url on first use
+func (t *GetBalanceTool) fetcher(network string, networkCfg config.NetworkConfig) (*rpc.BalanceFetcher, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if fetcher, exists := t.fetchers[network]; exists {
+		return fetcher, nil
+	}
+
+	fetcher, err := rpc.NewBalanceFetcher(networkCfg.RPCURL, t.server.GetConfig().AllowPrivateURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	t.fetchers[network] = fetcher
+	return fetcher, nil
+}
+
+// Name returns the tool name
+func (t *GetBalanceTool) Name() string {
+	return "get_balance"
+}
+
+// Description returns the tool description
+func (t *GetBalanceTool) Description() string {
+	return "Read an address's on-chain USDC balance on a network via balanceOf, e.g. to confirm a payer can cover a payment before creating its requirement. Returns the balance in atomic units and as a human-readable USDC amount."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *GetBalanceTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"address": map[string]interface{}{
+				"type":        "string",
+				"description": "Account whose USDC balance to read",
+				"pattern":     "^0x[a-fA-F0-9]{40}$",
+			},
+			"network": map[string]interface{}{
+				"type":        "string",
+				"description": "Blockchain network to read the balance on",
+				"enum":        networkEnum(),
+			},
+		},
+		"required": []string{"address", "network"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *GetBalanceTool) Execute(args map[string]interface{}) (interface{}, error) {
+	address, ok := args["address"].(string)
+	if !ok || !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("address must be a 0x-prefixed 20-byte hex address")
+	}
+
+	rawNetwork, ok := args["network"].(string)
+	if !ok {
+		return nil, fmt.Errorf("network must be a string")
+	}
+
+	cfg := t.server.GetConfig()
+	network, err := canonicalNetwork(cfg, rawNetwork)
+	if err != nil {
+		return nil, err
+	}
+	networkCfg := cfg.Networks[network]
+
+	fetcher, err := t.fetcher(network, networkCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	account := common.HexToAddress(address)
+	balance, err := fetcher.BalanceOf(common.HexToAddress(networkCfg.USDCContract), account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read balance on %s: %w", network, err)
+	}
+
+	decimals := networkCfg.Decimals()
+	return map[string]interface{}{
+		"address":        account.Hex(),
+		"network":        network,
+		"asset":          networkCfg.USDCContract,
+		"balance_atomic": balance.String(),
+		"balance_usdc":   units.ToHuman(balance, decimals),
+		"decimals":       decimals,
+	}, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *GetBalanceTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
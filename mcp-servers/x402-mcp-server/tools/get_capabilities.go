@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// CapabilitiesVersion versions the capabilities document layout and feature set
+// Bump it whenever a feature is added to or removed from the document.
+const CapabilitiesVersion = 5
+
+// GetCapabilitiesTool implements the get_capabilities MCP tool
+type GetCapabilitiesTool struct {
+	server  *server.Server
+	version string
+}
+
+// NewGetCapabilitiesTool creates a new get_capabilities tool reporting serverVersion
+func NewGetCapabilitiesTool(srv *server.Server, serverVersion string) *GetCapabilitiesTool {
+	return &GetCapabilitiesTool{
+		server:  srv,
+		version: serverVersion,
+	}
+}
+
+// Name returns the tool name
+func (t *GetCapabilitiesTool) Name() string {
+	return "get_capabilities"
+}
+
+// Description returns the tool description
+func (t *GetCapabilitiesTool) Description() string {
+	return "Describe what this server supports: accepted x402 schemes, configured networks and their assets, settlement mode, optional verification features (multisig, EIP-1271, EIP-155 v), enabled tools, and build versions. capabilities_version changes whenever the feature set does."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *GetCapabilitiesTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *GetCapabilitiesTool) Execute(args map[string]interface{}) (interface{}, error) {
+	cfg := t.server.GetConfig()
+
+	schemes := cfg.Verification.AcceptedSchemes
+	if len(schemes) == 0 {
+		schemes = []string{x402.SchemeExact}
+	}
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"capabilities_version": CapabilitiesVersion,
+		"server":               t.buildInfo(),
+		"x402_version":         x402.Version,
+		"schemes":              schemes,
+		"networks":             t.networks(cfg),
+		"settlement": map[string]interface{}{
+			"mode":                        orDefault(cfg.Settlement.Mode, config.SettlementModeFacilitator),
+			"onchain":                     cfg.Settlement.IsOnChain(),
+			"require_prior_verify":        cfg.Settlement.RequirePriorVerify,
+			"require_attestation":         cfg.Settlement.RequireAttestation,
+			"enforce_requirement_timeout": cfg.Settlement.EnforceRequirementTimeout,
+			"facilitator_override":        cfg.Settlement.AllowFacilitatorOverride,
+		},
+		"features": map[string]interface{}{
+			"eip1271":              cfg.Verification.EIP1271,
+			"multisig":             len(cfg.Verification.Multisig) > 0,
+			"profile":              orDefault(cfg.Verification.Profile, config.ProfileLenient),
+			"eip155_v":             orDefault(cfg.Verification.EIP155V, config.EIP155VReject),
+			"overpayment":          orDefault(cfg.Verification.Overpayment, config.OverpaymentReject),
+			"r_value_reuse":        orDefault(cfg.Verification.RValueReuse, config.RValueReuseOff),
+			"weak_nonce":           orDefault(cfg.Verification.WeakNonce, config.WeakNonceOff),
+			"payee_allowlist":      cfg.Verification.ChecksPayeeAllowlist(),
+			"payee_policy":         orDefault(cfg.Verification.PayeePolicy, config.PayeePolicyReject),
+			"compact_proof":        true,
+			"compact_proof_layout": int(eip3009.CompactProofVersion),
+			"fiat_pricing":         true,
+			"contract_checks":      cfg.ContractChecks.Enabled(),
+			"reconciliation":       cfg.Reconciliation.Enabled(),
+			"webhook":              cfg.Webhook.Enabled(),
+			"settled_volume":       cfg.Audit.RecordReceipts,
+			"test_mode":            cfg.TestMode(),
+		},
+		"tools": t.server.ToolNames(),
+	}, nil
+}
+
+// orDefault returns value, or fallback when the setting is unset
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// buildInfo describes the server version and, when available, the Go build that produced it
+func (t *GetCapabilitiesTool) buildInfo() map[string]interface{} {
+	info := map[string]interface{}{
+		"version": t.version,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info["go_version"] = build.GoVersion
+	for _, setting := range build.Settings {
+		if setting.Key == "vcs.revision" {
+			info["revision"] = setting.Value
+		}
+	}
+
+	return info
+}
+
+// networks lists each configured network with its chain and payment asset, sorted by name
+func (t *GetCapabilitiesTool) networks(cfg *config.Config) []interface{} {
+	names := make([]string, 0, len(cfg.Networks))
+	for name := range cfg.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	networks := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		networkCfg := cfg.Networks[name]
+		networks = append(networks, map[string]interface{}{
+			"name":     name,
+			"chain_id": networkCfg.ChainID,
+			"mainnet":  networkCfg.IsMainnet(),
+			"degraded": t.server.NetworkDegraded(name),
+			"assets": []interface{}{
+				map[string]interface{}{
+					"symbol":   "USDC",
+					"address":  networkCfg.USDCContract,
+					"decimals": networkCfg.Decimals(),
+				},
+			},
+			"attested": networkCfg.FacilitatorPublicKey != "",
+		})
+	}
+
+	return networks
+}
+
+// Register registers the tool with the MCP server
+func (t *GetCapabilitiesTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// GetDomainSeparatorTool implements the get_domain_separator MCP tool
+type GetDomainSeparatorTool struct {
+	server   *server.Server
+	verifier *eip3009.SignatureVerifier
+}
+
+// NewGetDomainSeparatorTool creates a new get_domain_separator tool
+func NewGetDomainSeparatorTool(srv *server.Server) *GetDomainSeparatorTool {
+	tool := &GetDomainSeparatorTool{
+		server:   srv,
+		verifier: eip3009.NewSignatureVerifier(srv.GetConfig()),
+	}
+
+	// Report the domain verification uses after a config reload
+	srv.OnConfigReload(func(_, newCfg *config.Config) {
+		tool.verifier.UpdateConfig(newCfg)
+	})
+
+	return tool
+}
+
+// Name returns the tool name
+func (t *GetDomainSeparatorTool) Name() string {
+	return "get_domain_separator"
+}
+
+// Description returns the tool description
+func (t *GetDomainSeparatorTool) Description() string {
+	return "Return the EIP-712 domain (name, version, chain ID, verifying contract) and domain separator this server verifies USDC authorizations against for a network. Compare with your signer's separator when debugging signature mismatches."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *GetDomainSeparatorTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": networkSchemaProperties("Blockchain network whose domain to return"),
+		"anyOf":      networkSelector(),
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *GetDomainSeparatorTool) Execute(args map[string]interface{}) (interface{}, error) {
+	network, err := resolveNetwork(t.server.GetConfig(), args)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, err := t.verifier.VerifyDomain(network)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build EIP-712 domain: %w", err)
+	}
+
+	separator := domain.DomainSeparator()
+
+	logger := t.server.GetLogger()
+	logger.Debug("Computed domain separator", map[string]interface{}{
+		"network":          network,
+		"domain_separator": separator.Hex(),
+	})
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"network":            network,
+		"domain_separator":   separator.Hex(),
+		"name":               domain.Name,
+		"version":            domain.Version,
+		"chain_id":           domain.ChainID.Uint64(),
+		"verifying_contract": domain.VerifyingContract.Hex(),
+	}, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *GetDomainSeparatorTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// GetPaymentFieldsTool implements the get_payment_fields MCP tool
+type GetPaymentFieldsTool struct {
+	server *server.Server
+}
+
+// NewGetPaymentFieldsTool creates a new get_payment_fields tool
+func NewGetPaymentFieldsTool(srv *server.Server) *GetPaymentFieldsTool {
+	return &GetPaymentFieldsTool{
+		server: srv,
+	}
+}
+
+// Name returns the tool name
+func (t *GetPaymentFieldsTool) Name() string {
+	return "get_payment_fields"
+}
+
+// Description returns the tool description
+func (t *GetPaymentFieldsTool) Description() string {
+	return "Reduce an x402 payment requirement to the fields a client needs to pay it: scheme, network, maxAmountRequired, payTo, asset, nonce, and validBefore_hint (the latest unix time to use as the authorization's validBefore). Descriptive and extension fields are dropped."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *GetPaymentFieldsTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"requirement": map[string]interface{}{
+				"type":        []string{"object", "string"},
+				"description": "Payment requirement as returned by create_payment_requirement (object or JSON string)",
+			},
+		},
+		"required": []string{"requirement"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *GetPaymentFieldsTool) Execute(args map[string]interface{}) (interface{}, error) {
+	// Accept either a JSON string or an already-decoded object
+	paymentReq, err := parseRequirement(args["requirement"])
+	if err != nil {
+		return nil, err
+	}
+
+	validUntil, err := time.Parse(time.RFC3339, paymentReq.ValidUntil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid valid_until format: %w", err)
+	}
+
+	// The authorization must not outlive the settlement window or the requirement itself
+	hint := validUntil
+	deadline, err := paymentReq.SettlementDeadline(t.server.GetConfig().Requirements.Validity())
+	if err == nil && deadline.Before(hint) {
+		hint = deadline
+	}
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"scheme":            paymentReq.Scheme,
+		"network":           paymentReq.Network,
+		"maxAmountRequired": paymentReq.MaxAmountRequired,
+		"payTo":             paymentReq.PayTo,
+		"asset":             paymentReq.Asset,
+		"nonce":             paymentReq.Nonce,
+		"validBefore_hint":  hint.Unix(),
+	}, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *GetPaymentFieldsTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// Payment statuses reported by get_payment_status
+const (
+	PaymentStatusConsumed  = "consumed"  // The authorization nonce has been used on-chain
+	PaymentStatusAvailable = "available" // The nonce is unused; the authorization can still settle
+)
+
+// paymentStatusCachePrefix keeps payment status entries apart from other keys in the server cache
+const paymentStatusCachePrefix = "payment_status:"
+
+// GetPaymentStatusTool implements the get_payment_status MCP tool
+type GetPaymentStatusTool struct {
+	server       *server.Server
+	nonceChecker *onchain.NonceChecker
+}
+
+// NewGetPaymentStatusTool creates a new get_payment_status tool
+func NewGetPaymentStatusTool(srv *server.Server) *GetPaymentStatusTool {
+	tool := &GetPaymentStatusTool{
+		server:       srv,
+		nonceChecker: onchain.NewNonceChecker(srv.GetConfig(), 10*time.Second, 1),
+	}
+
+	// Check nonces against the reloaded networks and RPC endpoints
+	srv.OnConfigReload(func(_, newCfg *config.Config) {
+		tool.nonceChecker.UpdateConfig(newCfg)
+	})
+
+	return tool
+}
+
+// NonceChecker returns the on-chain nonce checker used by this tool
+func (t *GetPaymentStatusTool) NonceChecker() *onchain.NonceChecker {
+	return t.nonceChecker
+}
+
+// Name returns the tool name
+func (t *GetPaymentStatusTool) Name() string {
+	return "get_payment_status"
+}
+
+// Description returns the tool description
+func (t *GetPaymentStatusTool) Description() string {
+	return "Check whether an EIP-3009 authorization has already been consumed on-chain. Reads the USDC contract's authorizationState(from, nonce) and returns status 'consumed' or 'available'. Consumed results are cached; available results are re-checked once the pending cache TTL lapses."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *GetPaymentStatusTool) Schema() interface{} {
+	// Only from and nonce identify the authorization on-chain; other fields are accepted but unused
+	authorization := authorizationSchema()
+	authorization["required"] = []string{"from", "nonce"}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"authorization": authorization,
+			"network": map[string]interface{}{
+				"type":        "string",
+				"description": "Blockchain network the authorization is for",
+				"enum":        x402.SupportedNetworks(),
+			},
+		},
+		"required": []string{"authorization", "network"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *GetPaymentStatusTool) Execute(args map[string]interface{}) (interface{}, error) {
+	cfg := t.server.GetConfig()
+
+	// Extract network, accepting the name in any case
+	network, ok := args["network"].(string)
+	if !ok {
+		return nil, fmt.Errorf("network must be a string")
+	}
+	network, err := canonicalNetwork(cfg, network)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract authorization object
+	authMap, ok := args["authorization"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("authorization must be an object")
+	}
+
+	from, ok := authMap["from"].(string)
+	if !ok {
+		return nil, fmt.Errorf("authorization.from must be a string")
+	}
+	nonce, ok := authMap["nonce"].(string)
+	if !ok {
+		return nil, fmt.Errorf("authorization.nonce must be a string")
+	}
+	nonce, err = eip3009.NormalizeBytes32(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization.nonce: %w", err)
+	}
+
+	// Step 1: A consumed nonce stays consumed, so cached results are reused
+	cacheKey := paymentStatusCachePrefix + network + ":" + strings.ToLower(from) + ":" + strings.ToLower(nonce)
+	if cached, found := t.server.GetCache().Get(cacheKey); found {
+		if result, ok := cached.(map[string]interface{}); ok {
+			return paymentStatusResult(result, true), nil
+		}
+	}
+
+	// Step 2: Read authorizationState from the network's USDC contract
+	statuses, err := t.nonceChecker.Check(network, []onchain.NonceQuery{{From: from, Nonce: nonce}})
+	if err != nil {
+		return nil, err
+	}
+	status := statuses[0]
+	if status.Error != "" {
+		return nil, fmt.Errorf("failed to check authorization state: %s", status.Error)
+	}
+
+	result := map[string]interface{}{
+		"status":     PaymentStatusAvailable,
+		"nonce_used": status.Used,
+		"network":    network,
+		"from":       from,
+		"nonce":      formatBytes32(cfg, nonce),
+	}
+
+	// Step 3: Cache consumed results for the settlement TTL, available ones only as long as pending results
+	if status.Used {
+		result["status"] = PaymentStatusConsumed
+		t.server.GetCache().Set(cacheKey, result)
+	} else if ttl := cfg.Cache.PendingTTL(); ttl > 0 {
+		t.server.GetCache().SetWithTTL(cacheKey, result, ttl)
+	}
+
+	t.server.GetLogger().Info("Checked payment status", map[string]interface{}{
+		"network": network,
+		"from":    from,
+		"nonce":   nonce,
+		"status":  result["status"],
+	})
+
+	// Return as map for MCP
+	return paymentStatusResult(result, false), nil
+}
+
+// paymentStatusResult copies a status result, marking whether it was served from the cache
+func paymentStatusResult(result map[string]interface{}, cached bool) map[string]interface{} {
+	copied := make(map[string]interface{}, len(result)+1)
+	for key, value := range result {
+		copied[key] = value
+	}
+	copied["cached"] = cached
+	return copied
+}
+
+// Register registers the tool with the MCP server
+func (t *GetPaymentStatusTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
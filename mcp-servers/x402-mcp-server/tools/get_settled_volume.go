@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// GetSettledVolumeTool implements the get_settled_volume MCP tool
+type GetSettledVolumeTool struct {
+	server *server.Server
+}
+
+// NewGetSettledVolumeTool creates a new get_settled_volume tool
+func NewGetSettledVolumeTool(srv *server.Server) *GetSettledVolumeTool {
+	return &GetSettledVolumeTool{
+		server: srv,
+	}
+}
+
+// Name returns the tool name
+func (t *GetSettledVolumeTool) Name() string {
+	return "get_settled_volume"
+}
+
+// Description returns the tool description
+func (t *GetSettledVolumeTool) Description() string {
+	return "Report total USDC settled per payee over a time range, aggregated from settlement receipts in the audit trail. Pending settlements count once reconciliation or a facilitator callback reports them settled. Requires audit.record_receipts."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *GetSettledVolumeTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"since": map[string]interface{}{
+				"type":        "integer",
+				"description": "Unix timestamp (seconds); include settlements at or after this time (default: all)",
+				"minimum":     0,
+			},
+			"until": map[string]interface{}{
+				"type":        "integer",
+				"description": "Unix timestamp (seconds); include settlements before this time (default: now)",
+				"minimum":     0,
+			},
+			"network": map[string]interface{}{
+				"type":        "string",
+				"description": "Only count settlements on this network (default: all networks)",
+				"enum":        x402.SupportedNetworks(),
+			},
+		},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *GetSettledVolumeTool) Execute(args map[string]interface{}) (interface{}, error) {
+	cfg := t.server.GetConfig()
+	if !cfg.Audit.RecordReceipts {
+		return nil, fmt.Errorf("settled volume is unavailable: audit.record_receipts is disabled")
+	}
+
+	since, err := parseVolumeTime(args, "since", time.Unix(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseVolumeTime(args, "until", time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !since.Before(until) {
+		return nil, fmt.Errorf("since must be before until")
+	}
+
+	network := ""
+	if raw, exists := args["network"]; exists {
+		name, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("network must be a string")
+		}
+		if network, err = canonicalNetwork(cfg, name); err != nil {
+			return nil, err
+		}
+	}
+
+	records, err := t.server.GetAuditStore().Records()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit records: %w", err)
+	}
+	if network != "" {
+		filtered := records[:0]
+		for _, record := range records {
+			if record.Network == network {
+				filtered = append(filtered, record)
+			}
+		}
+		records = filtered
+	}
+
+	// Unfiltered totals are rendered in the default USDC decimals
+	decimals := cfg.AssetDecimals(network)
+	total := new(big.Int)
+	count := 0
+	payees := make([]interface{}, 0)
+	for _, volume := range audit.SettledVolume(records, since, until) {
+		total.Add(total, volume.Total)
+		count += volume.Settlements
+		payees = append(payees, map[string]interface{}{
+			"payee":       volume.Payee,
+			"total":       volume.Total.String(),
+			"total_human": t.formatHuman(volume.Total, decimals),
+			"settlements": volume.Settlements,
+		})
+	}
+
+	// Return as map for MCP
+	result := map[string]interface{}{
+		"since":       since.Unix(),
+		"until":       until.Unix(),
+		"payees":      payees,
+		"total":       total.String(),
+		"total_human": t.formatHuman(total, decimals),
+		"settlements": count,
+	}
+	if network != "" {
+		result["network"] = network
+	}
+
+	return result, nil
+}
+
+// parseVolumeTime returns the optional unix-seconds argument field, or fallback when absent
+func parseVolumeTime(args map[string]interface{}, field string, fallback time.Time) (time.Time, error) {
+	raw, exists := args[field]
+	if !exists {
+		return fallback, nil
+	}
+
+	seconds, ok := raw.(float64)
+	if !ok || seconds < 0 || seconds != math.Trunc(seconds) || seconds > 1<<53 {
+		return time.Time{}, fmt.Errorf("%s must be a non-negative unix timestamp in seconds", field)
+	}
+
+	return time.Unix(int64(seconds), 0), nil
+}
+
+// formatHuman renders an atomic amount of an asset with the given decimals in the configured display format
+func (t *GetSettledVolumeTool) formatHuman(amount *big.Int, decimals int) string {
+	if t.server.GetConfig().Display.AmountFormat == config.AmountFormatGrouped {
+		return units.ToHumanGrouped(amount, decimals)
+	}
+	return units.ToHuman(amount, decimals)
+}
+
+// Register registers the tool with the MCP server
+func (t *GetSettledVolumeTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
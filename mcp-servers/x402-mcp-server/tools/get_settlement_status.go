@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// GetSettlementStatusTool implements the get_settlement_status MCP tool
+type GetSettlementStatusTool struct {
+	server  *server.Server
+	counter *onchain.ConfirmationCounter
+}
+
+// NewGetSettlementStatusTool creates a new get_settlement_status tool
+func NewGetSettlementStatusTool(srv *server.Server) *GetSettlementStatusTool {
+	tool := &GetSettlementStatusTool{
+		server:  srv,
+		counter: onchain.NewConfirmationCounter(srv.GetConfig(), 10*time.Second),
+	}
+
+	// Count confirmations against the reloaded networks and RPC endpoints
+	srv.OnConfigReload(func(_, newCfg *config.Config) {
+		tool.counter.UpdateConfig(newCfg)
+	})
+
+	return tool
+}
+
+// ConfirmationCounter returns the on-chain confirmation counter used by this tool
+func (t *GetSettlementStatusTool) ConfirmationCounter() *onchain.ConfirmationCounter {
+	return t.counter
+}
+
+// Name returns the tool name
+func (t *GetSettlementStatusTool) Name() string {
+	return "get_settlement_status"
+}
+
+// Description returns the tool description
+func (t *GetSettlementStatusTool) Description() string {
+	return "Report the live status of a settlement transaction. Reads the transaction's block and the current block over RPC and returns the confirmation count; the settlement is reported settled only once it has the network's configured confirmations, pending before that, and failed if the transaction reverted."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *GetSettlementStatusTool) Schema() interface{} {
+	properties := networkSchemaProperties("Blockchain network the transaction was submitted to")
+	properties["tx_hash"] = map[string]interface{}{
+		"type":        "string",
+		"description": "Settlement transaction hash (0x-prefixed)",
+		"pattern":     "^0x[a-fA-F0-9]{64}$",
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"tx_hash"},
+		"anyOf":      networkSelector(),
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *GetSettlementStatusTool) Execute(args map[string]interface{}) (interface{}, error) {
+	cfg := t.server.GetConfig()
+
+	// Extract tx hash
+	txHash, ok := args["tx_hash"].(string)
+	if !ok {
+		return nil, fmt.Errorf("tx_hash must be a string")
+	}
+
+	network, err := resolveNetwork(cfg, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := t.counter.Status(network, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	t.server.GetLogger().Debug("Settlement confirmations checked", map[string]interface{}{
+		"network":                network,
+		"tx_hash":                result.TxHash,
+		"status":                 result.Status,
+		"confirmations":          result.Confirmations,
+		"required_confirmations": result.Required,
+	})
+
+	// Return as map for MCP
+	response := result.ToMap()
+	response["network"] = network
+	return addExplorerURL(cfg, network, response), nil
+}
+
+// Register registers the tool with the MCP server
+func (t *GetSettlementStatusTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// paymentPayloadSchema returns the JSON schema for an x402 PaymentPayload argument
+func paymentPayloadSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":        []string{"object", "string"},
+		"description": "x402 PaymentPayload {x402Version, scheme, network, payload: {signature, authorization}} as an object, JSON string, or the base64 X-PAYMENT header value",
+	}
+}
+
+// payloadToolSchema derives a payload tool's schema from the wrapped tool's: the network
+// selector and authorization inputs are replaced by payment_payload, other options are kept
+func payloadToolSchema(inner map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"payment_payload": paymentPayloadSchema(),
+		"compact_proof": map[string]interface{}{
+			"type":        "string",
+			"description": "Base64 compact binary proof (authorization and network) as returned by verify_payment's include_compact_proof, instead of payment_payload",
+		},
+	}
+	for name, schema := range inner["properties"].(map[string]interface{}) {
+		switch name {
+		case "authorization", "signatures", "network", "chain_id", "asset", "include_compact_proof":
+			continue
+		}
+		properties[name] = schema
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"oneOf": []interface{}{
+			map[string]interface{}{"required": []string{"payment_payload"}},
+			map[string]interface{}{"required": []string{"compact_proof"}},
+		},
+	}
+}
+
+// compactProofArgs maps a compact_proof argument to the network and authorization
+// arguments of verify_payment and settle_payment, keeping any other arguments
+func compactProofArgs(args map[string]interface{}) (map[string]interface{}, error) {
+	if _, exists := args["payment_payload"]; exists {
+		return nil, fmt.Errorf("provide either payment_payload or compact_proof, not both")
+	}
+
+	encoded, ok := args["compact_proof"].(string)
+	if !ok {
+		return nil, fmt.Errorf("compact_proof must be a base64 string")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("compact_proof is not valid base64: %w", err)
+	}
+
+	auth, network, err := eip3009.DecodeCompact(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := make(map[string]interface{}, len(args)+1)
+	for name, value := range args {
+		if name != "compact_proof" {
+			mapped[name] = value
+		}
+	}
+	mapped["network"] = network
+	mapped["authorization"] = authorizationArgs(auth)
+
+	return mapped, nil
+}
+
+// paymentPayloadArgs maps a payment_payload argument to the network and authorization
+// arguments of verify_payment and settle_payment, keeping any other arguments
+// A scheme outside verification.accepted_schemes yields an *x402.UnsupportedSchemeError.
+func paymentPayloadArgs(cfg *config.Config, args map[string]interface{}) (map[string]interface{}, error) {
+	if _, exists := args["compact_proof"]; exists {
+		return compactProofArgs(args)
+	}
+
+	var encoded string
+	switch raw := args["payment_payload"].(type) {
+	case string:
+		encoded = raw
+	case map[string]interface{}:
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode payment_payload: %w", err)
+		}
+		encoded = string(data)
+	default:
+		return nil, fmt.Errorf("payment_payload must be an object or string")
+	}
+
+	payload, err := x402.ParsePaymentPayload(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Verification.AcceptsScheme(payload.Scheme) {
+		return nil, &x402.UnsupportedSchemeError{Scheme: payload.Scheme}
+	}
+
+	v, r, s, err := payload.Payload.SplitSignature()
+	if err != nil {
+		return nil, err
+	}
+
+	authorization := payload.Payload.Authorization
+	validAfter, err := authorization.ValidAfter.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid validAfter: must be a decimal timestamp")
+	}
+	validBefore, err := authorization.ValidBefore.Float64()
+	if err != nil {
+		return nil, fmt.Errorf("invalid validBefore: must be a decimal timestamp")
+	}
+
+	mapped := make(map[string]interface{}, len(args)+2)
+	for name, value := range args {
+		if name != "payment_payload" {
+			mapped[name] = value
+		}
+	}
+	mapped["network"] = payload.Network
+	mapped["authorization"] = map[string]interface{}{
+		"from":        authorization.From,
+		"to":          authorization.To,
+		"value":       authorization.Value,
+		"validAfter":  validAfter,
+		"validBefore": validBefore,
+		"nonce":       authorization.Nonce,
+		"v":           float64(v),
+		"r":           r,
+		"s":           s,
+	}
+
+	return mapped, nil
+}
+
+// VerifyPaymentPayloadTool implements the verify_payment_payload MCP tool
+type VerifyPaymentPayloadTool struct {
+	verify *VerifyPaymentTool
+}
+
+// NewVerifyPaymentPayloadTool creates a new verify_payment_payload tool delegating to verify
+// Sharing the verify_payment instance shares its verification cache.
+func NewVerifyPaymentPayloadTool(verify *VerifyPaymentTool) *VerifyPaymentPayloadTool {
+	return &VerifyPaymentPayloadTool{
+		verify: verify,
+	}
+}
+
+// Name returns the tool name
+func (t *VerifyPaymentPayloadTool) Name() string {
+	return "verify_payment_payload"
+}
+
+// Description returns the tool description
+func (t *VerifyPaymentPayloadTool) Description() string {
+	return "Verify an x402 PaymentPayload exactly as sent in the X-PAYMENT header (object, JSON, or base64), or a compact_proof from verify_payment. Maps the payload's network, authorization, and 65-byte signature to verify_payment and returns its result."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *VerifyPaymentPayloadTool) Schema() interface{} {
+	return payloadToolSchema(t.verify.Schema().(map[string]interface{}))
+}
+
+// Execute executes the tool with the given arguments
+func (t *VerifyPaymentPayloadTool) Execute(args map[string]interface{}) (interface{}, error) {
+	mapped, err := paymentPayloadArgs(t.verify.server.GetConfig(), args)
+	var schemeErr *x402.UnsupportedSchemeError
+	if errors.As(err, &schemeErr) {
+		return (&eip3009.VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     err.Error(),
+			ErrorCode: x402.ErrorCodeUnsupportedScheme,
+		}).ToMap(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t.verify.Execute(mapped)
+}
+
+// Register registers the tool with the MCP server
+func (t *VerifyPaymentPayloadTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
+
+// SettlePaymentPayloadTool implements the settle_payment_payload MCP tool
+type SettlePaymentPayloadTool struct {
+	settle *SettlePaymentTool
+}
+
+// NewSettlePaymentPayloadTool creates a new settle_payment_payload tool delegating to settle
+// Sharing the settle_payment instance shares its idempotency cache, so a payload settled
+// through either tool is not submitted twice.
+func NewSettlePaymentPayloadTool(settle *SettlePaymentTool) *SettlePaymentPayloadTool {
+	return &SettlePaymentPayloadTool{
+		settle: settle,
+	}
+}
+
+// Name returns the tool name
+func (t *SettlePaymentPayloadTool) Name() string {
+	return "settle_payment_payload"
+}
+
+// Description returns the tool description
+func (t *SettlePaymentPayloadTool) Description() string {
+	return "Settle an x402 PaymentPayload exactly as sent in the X-PAYMENT header (object, JSON, or base64), or a compact_proof from verify_payment. Maps the payload's network, authorization, and 65-byte signature to settle_payment and returns its result."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *SettlePaymentPayloadTool) Schema() interface{} {
+	return payloadToolSchema(t.settle.Schema().(map[string]interface{}))
+}
+
+// Execute executes the tool with the given arguments
+func (t *SettlePaymentPayloadTool) Execute(args map[string]interface{}) (interface{}, error) {
+	mapped, err := paymentPayloadArgs(t.settle.server.GetConfig(), args)
+	var schemeErr *x402.UnsupportedSchemeError
+	if errors.As(err, &schemeErr) {
+		return (&facilitator.FacilitatorResponse{
+			Status:    "failed",
+			Error:     err.Error(),
+			ErrorCode: x402.ErrorCodeUnsupportedScheme,
+		}).ToMap(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t.settle.Execute(mapped)
+}
+
+// Register registers the tool with the MCP server
+func (t *SettlePaymentPayloadTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
@@ -0,0 +1,273 @@
+package tools
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// maxDigestBatch bounds how many digests a single call may prepare
+const maxDigestBatch = 50
+
+// PrepareAuthorizationDigestsTool implements the prepare_authorization_digests MCP tool
+type PrepareAuthorizationDigestsTool struct {
+	server   *server.Server
+	verifier *eip3009.SignatureVerifier
+}
+
+// NewPrepareAuthorizationDigestsTool creates a new prepare_authorization_digests tool
+func NewPrepareAuthorizationDigestsTool(srv *server.Server) *PrepareAuthorizationDigestsTool {
+	tool := &PrepareAuthorizationDigestsTool{
+		server:   srv,
+		verifier: eip3009.NewSignatureVerifier(srv.GetConfig()),
+	}
+
+	// Prepare digests under the domain verification uses after a config reload
+	srv.OnConfigReload(func(_, newCfg *config.Config) {
+		tool.verifier.UpdateConfig(newCfg)
+	})
+
+	return tool
+}
+
+// Name returns the tool name
+func (t *PrepareAuthorizationDigestsTool) Name() string {
+	return "prepare_authorization_digests"
+}
+
+// Description returns the tool description
+func (t *PrepareAuthorizationDigestsTool) Description() string {
+	return "Prepare the EIP-712 typed-data hashes to sign for a batch of (requirement, from) pairs, so a payer can sign many authorizations offline. Returns, in input order, each digest with the authorization fields it commits to, or a per-item error."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *PrepareAuthorizationDigestsTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"items": map[string]interface{}{
+				"type":        "array",
+				"description": fmt.Sprintf("Requirements to pay and their payers (at most %d)", maxDigestBatch),
+				"maxItems":    maxDigestBatch,
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"requirement": map[string]interface{}{
+							"type":        []string{"object", "string"},
+							"description": "Payment requirement (object or JSON string); payTo, maxAmountRequired, and maxTimeoutSeconds shape the authorization, extra.name/extra.version the domain",
+						},
+						"from": map[string]interface{}{
+							"type":        "string",
+							"description": "Payer address (0x-prefixed hex)",
+							"pattern":     "^0x[a-fA-F0-9]{40}$",
+						},
+						"nonce": map[string]interface{}{
+							"type":        "string",
+							"description": "Authorization nonce as 32-byte hex or base64 (random when omitted)",
+							"pattern":     bytes32InputPattern,
+						},
+						"validAfter": map[string]interface{}{
+							"type":        "integer",
+							"description": "Unix timestamp (seconds) after which the authorization is valid (default: now); validBefore is validAfter + maxTimeoutSeconds",
+						},
+					},
+					"required": []string{"requirement", "from"},
+				},
+			},
+		},
+		"required": []string{"items"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *PrepareAuthorizationDigestsTool) Execute(args map[string]interface{}) (interface{}, error) {
+	list, ok := args["items"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("items must be an array")
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("items must not be empty")
+	}
+	if len(list) > maxDigestBatch {
+		return nil, fmt.Errorf("items has %d entries, maximum is %d", len(list), maxDigestBatch)
+	}
+
+	now := time.Now()
+	results := make([]interface{}, 0, len(list))
+	prepared := 0
+
+	for i, raw := range list {
+		entry, err := t.prepare(raw, now)
+		if err != nil {
+			entry = map[string]interface{}{
+				"error":      err.Error(),
+				"error_code": x402.ErrorCodeInvalidRequirement,
+			}
+			if expired, ok := err.(*expiredRequirementError); ok {
+				entry["error_code"] = x402.ErrorCodeRequirementExpired
+				entry["error"] = expired.Error()
+			}
+		} else {
+			prepared++
+		}
+		entry["index"] = i
+		results = append(results, entry)
+	}
+
+	t.server.GetLogger().Info("Prepared authorization digests", map[string]interface{}{
+		"items":    len(list),
+		"prepared": prepared,
+	})
+
+	// Return as map for MCP
+	return map[string]interface{}{
+		"results":        results,
+		"prepared_count": prepared,
+	}, nil
+}
+
+// expiredRequirementError reports a requirement whose valid_until has passed
+type expiredRequirementError struct {
+	validUntil string
+}
+
+// Error implements the error interface
+func (e *expiredRequirementError) Error() string {
+	return fmt.Sprintf("requirement expired at %s", e.validUntil)
+}
+
+// prepare builds the authorization for one item and returns its digest entry
+func (t *PrepareAuthorizationDigestsTool) prepare(raw interface{}, now time.Time) (map[string]interface{}, error) {
+	item, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("item must be an object")
+	}
+
+	requirementArg, exists := item["requirement"]
+	if !exists {
+		return nil, fmt.Errorf("requirement is required")
+	}
+	requirement, err := parseRequirement(requirementArg)
+	if err != nil {
+		return nil, err
+	}
+	if requirement.IsExpired(now) {
+		return nil, &expiredRequirementError{validUntil: requirement.ValidUntil}
+	}
+
+	cfg := t.server.GetConfig()
+	network, err := canonicalNetwork(cfg, requirement.Network)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(requirement.Asset, cfg.Networks[network].USDCContract) {
+		return nil, fmt.Errorf("requirement asset %s is not the configured USDC contract for %s", requirement.Asset, network)
+	}
+
+	from, ok := item["from"].(string)
+	if !ok || !strings.HasPrefix(from, "0x") || !common.IsHexAddress(from) {
+		return nil, fmt.Errorf("from must be a 0x-prefixed address")
+	}
+	if err := eip3009.ValidateChecksum(from); err != nil {
+		return nil, err
+	}
+
+	nonce, err := digestNonce(item)
+	if err != nil {
+		return nil, err
+	}
+
+	validAfter := uint64(now.Unix())
+	if rawAfter, exists := item["validAfter"]; exists {
+		after, ok := rawAfter.(float64)
+		if !ok || after < 0 || after != math.Trunc(after) || after > float64(eip3009.MaxTimestamp) {
+			return nil, fmt.Errorf("validAfter must be a non-negative integer timestamp")
+		}
+		validAfter = uint64(after)
+	}
+	validBefore := validAfter + uint64(requirement.MaxTimeoutSeconds)
+
+	// Validate guarantees a positive integer amount
+	value, _ := new(big.Int).SetString(requirement.MaxAmountRequired, 10)
+	message := &eip3009.ReceiveWithAuthorizationMessage{
+		From:        common.HexToAddress(from),
+		To:          common.HexToAddress(requirement.PayTo),
+		Value:       value,
+		ValidAfter:  new(big.Int).SetUint64(validAfter),
+		ValidBefore: new(big.Int).SetUint64(validBefore),
+		Nonce:       nonce,
+	}
+
+	params, err := requirementDomainParams(cfg, network, requirement)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := t.verifier.SigningDomain(network, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build EIP-712 domain: %w", err)
+	}
+
+	digest, err := eip3009.TypedDataHash(domain, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute typed data hash: %w", err)
+	}
+
+	return map[string]interface{}{
+		"network":          network,
+		"digest":           digest.Hex(),
+		"domain_separator": domain.DomainSeparator().Hex(),
+		"authorization": map[string]interface{}{
+			"from":        from,
+			"to":          requirement.PayTo,
+			"value":       requirement.MaxAmountRequired,
+			"validAfter":  validAfter,
+			"validBefore": validBefore,
+			"nonce":       eip3009.EncodeBytes32(nonce, cfg.Display.ByteEncoding),
+		},
+	}, nil
+}
+
+// digestNonce returns the item's nonce, or a random one when omitted
+func digestNonce(item map[string]interface{}) ([32]byte, error) {
+	var nonce [32]byte
+
+	raw, exists := item["nonce"]
+	if !exists {
+		if _, err := rand.Read(nonce[:]); err != nil {
+			return nonce, fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		return nonce, nil
+	}
+
+	encoded, ok := raw.(string)
+	if !ok {
+		return nonce, fmt.Errorf("nonce must be a string")
+	}
+	nonce, err := eip3009.DecodeBytes32(encoded)
+	if err != nil {
+		return nonce, fmt.Errorf("nonce %w", err)
+	}
+
+	return nonce, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *PrepareAuthorizationDigestsTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
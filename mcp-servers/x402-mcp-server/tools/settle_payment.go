@@ -1,29 +1,229 @@
 package tools
 
 import (
+	"context"
 	"fmt"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/audit"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/cache"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/deadletter"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/events"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/facilitator"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/inflight"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/metrics"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/netguard"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/reconciler"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/refunds"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/units"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
+// queueFullRetryAfterSeconds is the retry hint returned when a network's in-flight limit is saturated
+const queueFullRetryAfterSeconds = 1
+
+// timeoutRetryAfterSeconds is the retry hint returned for a settlement still running when
+// its limits.tool_timeouts budget expires
+const timeoutRetryAfterSeconds = 5
+
+// idempotencyTokenPattern limits caller tokens to printable ASCII that is safe as a header value
+var idempotencyTokenPattern = regexp.MustCompile(`^[\x20-\x7E]{1,255}$`)
+
 // SettlePaymentTool implements the settle_payment MCP tool
 type SettlePaymentTool struct {
 	server            *server.Server
 	verifier          *eip3009.SignatureVerifier
 	facilitatorClient *facilitator.Client
+	reconciler        *reconciler.Reconciler
+	limiter           atomic.Pointer[inflight.Limiter]
+
+	onchainMu      sync.RWMutex
+	onchainSettler *onchain.Settler
+	onchainErr     error // Why onchainSettler is nil in on-chain mode
+
+	refundsMu      sync.Mutex
+	pendingRefunds map[string]pendingRefund // Overpayment refunds owed once a pending settlement settles, by network:nonce
+
+	started  atomic.Bool   // Set once Start runs the reconciler
+	stopped  chan struct{} // Closed by Stop
+	stopOnce sync.Once
 }
 
 // NewSettlePaymentTool creates a new settle_payment tool
 func NewSettlePaymentTool(srv *server.Server) *SettlePaymentTool {
-	return &SettlePaymentTool{
+	cfg := srv.GetConfig()
+	tool := &SettlePaymentTool{
 		server:            srv,
-		verifier:          eip3009.NewSignatureVerifier(srv.GetConfig()),
-		facilitatorClient: facilitator.NewClient(srv.GetConfig(), 5*time.Second),
+		verifier:          eip3009.NewSignatureVerifier(cfg),
+		facilitatorClient: facilitator.NewClient(cfg, 5*time.Second),
+		pendingRefunds:    make(map[string]pendingRefund),
+		stopped:           make(chan struct{}),
+	}
+	tool.limiter.Store(inflight.NewLimiter(cfg.Settlement.MaxInFlight, cfg.Settlement.QueueTimeout()))
+
+	// Track entry age at eviction to tune cache TTLs
+	tool.verifier.UseResultStore(srv.GetVerificationStore())
+	tool.verifier.OnCacheEvict(cache.EvictionRecorder(srv.GetMetrics(), "verification"))
+	tool.verifier.UseRValueMonitor(srv.GetRValueMonitor())
+	tool.facilitatorClient.OnCacheEvict(cache.EvictionRecorder(srv.GetMetrics(), "settlement"))
+
+	// Queue refunds for overpayments whose settlement was pending until reconciliation or a callback
+	tool.facilitatorClient.OnPendingResolved(tool.resolvePendingRefund)
+
+	// On-chain mode submits directly with a relayer key instead of the facilitator
+	tool.configureOnChain(nil, cfg)
+
+	// Settle against the reloaded networks, facilitator, limits and settlement mode;
+	// cached domains/results are flushed for networks whose domain changed
+	srv.OnConfigReload(func(oldCfg, newCfg *config.Config) {
+		tool.verifier.UpdateConfig(newCfg)
+		tool.facilitatorClient.UpdateConfig(newCfg)
+		tool.limiter.Store(inflight.NewLimiter(newCfg.Settlement.MaxInFlight, newCfg.Settlement.QueueTimeout()))
+		tool.configureOnChain(oldCfg, newCfg)
+	})
+
+	// Re-check facilitator settlements left pending (disabled by default; runs once Start is called)
+	if cfg.Reconciliation.Enabled() {
+		tool.reconciler = reconciler.New(
+			tool.facilitatorClient,
+			srv.GetAuditStore(),
+			srv.GetMetrics(),
+			srv.GetLogger(),
+			time.Duration(cfg.Reconciliation.IntervalSeconds)*time.Second,
+			cfg.Reconciliation.BatchSize,
+		)
+		tool.reconciler.ExpireUnattestedAfter(cfg.Reconciliation.UnattestedMaxAge())
+	}
+
+	return tool
+}
+
+// configureOnChain prepares the on-chain settler for cfg. The relayer key is loaded when
+// on-chain mode is first enabled or relayer_key_env changes; otherwise an existing settler
+// only takes the new networks. oldCfg is nil at startup.
+func (t *SettlePaymentTool) configureOnChain(oldCfg, cfg *config.Config) {
+	t.onchainMu.Lock()
+	defer t.onchainMu.Unlock()
+
+	keyChanged := oldCfg == nil || oldCfg.Settlement.RelayerKeyEnv != cfg.Settlement.RelayerKeyEnv
+	if t.onchainSettler != nil && !keyChanged {
+		t.onchainSettler.UpdateConfig(cfg)
+		return
 	}
+	if !cfg.Settlement.IsOnChain() {
+		t.onchainSettler = nil
+		t.onchainErr = nil
+		return
+	}
+
+	relayerKey, err := onchain.LoadRelayerKey(cfg.Settlement.RelayerKeyEnv)
+	if err != nil {
+		t.server.GetLogger().Error("On-chain settlement unavailable", map[string]interface{}{
+			"error": err.Error(),
+		})
+		t.onchainSettler = nil
+		t.onchainErr = err
+		return
+	}
+
+	t.onchainSettler = onchain.NewSettler(cfg, relayerKey, 30*time.Second)
+	t.onchainErr = nil
+}
+
+// Start runs the pending settlement reconciler, when enabled, until ctx is done or Stop is called
+func (t *SettlePaymentTool) Start(ctx context.Context) {
+	if t.reconciler == nil || !t.started.CompareAndSwap(false, true) {
+		return
+	}
+
+	t.reconciler.Start()
+	go func() {
+		select {
+		case <-ctx.Done():
+			t.reconciler.Stop()
+		case <-t.stopped:
+		}
+	}()
+}
+
+// Stop halts the reconciler started by Start and waits for an in-flight pass to finish
+func (t *SettlePaymentTool) Stop() {
+	if !t.started.Load() {
+		return
+	}
+
+	t.stopOnce.Do(func() {
+		close(t.stopped)
+	})
+	t.reconciler.Stop()
+}
+
+// FacilitatorClient returns the facilitator client used for settlement
+func (t *SettlePaymentTool) FacilitatorClient() *facilitator.Client {
+	return t.facilitatorClient
+}
+
+// Reconciler returns the pending settlement reconciler, or nil when disabled
+func (t *SettlePaymentTool) Reconciler() *reconciler.Reconciler {
+	return t.reconciler
+}
+
+// OnChainSettler returns the on-chain settler, or nil when settling via the facilitator
+func (t *SettlePaymentTool) OnChainSettler() *onchain.Settler {
+	t.onchainMu.RLock()
+	defer t.onchainMu.RUnlock()
+
+	return t.onchainSettler
+}
+
+// Warmup pre-builds the EIP-712 domains for every configured network and, when
+// audit.warm_cache_minutes is set, seeds the settlement cache from the audit trail
+func (t *SettlePaymentTool) Warmup(ctx context.Context) error {
+	if err := t.verifier.PrewarmDomains(); err != nil {
+		return err
+	}
+	return t.warmSettlementCache()
+}
+
+// warmSettlementCache seeds the idempotency cache with recently settled nonces from the
+// audit trail, so resubmissions right after a restart do not reach the facilitator again
+func (t *SettlePaymentTool) warmSettlementCache() error {
+	auditCfg := &t.server.GetConfig().Audit
+	if !auditCfg.WarmsCache() {
+		return nil
+	}
+
+	records, err := t.server.GetAuditStore().Records()
+	if err != nil {
+		return fmt.Errorf("failed to read audit records: %w", err)
+	}
+
+	seeded := 0
+	for _, record := range audit.RecentSettlements(records, time.Now().Add(-auditCfg.WarmCacheWindow()), auditCfg.WarmCacheLimit()) {
+		response := &facilitator.FacilitatorResponse{
+			Status: "settled",
+			TxHash: record.TxHash,
+		}
+		if t.facilitatorClient.SeedSettlement(record.Network, record.Nonce, response, record.Timestamp) {
+			seeded++
+		}
+	}
+
+	t.server.GetLogger().Info("Warmed settlement cache from audit trail", map[string]interface{}{
+		"seeded":         seeded,
+		"window_minutes": auditCfg.WarmCacheMinutes,
+	})
+	return nil
 }
 
 // Name returns the tool name
@@ -33,80 +233,83 @@ func (t *SettlePaymentTool) Name() string {
 
 // Description returns the tool description
 func (t *SettlePaymentTool) Description() string {
-	return "Submit verified EIP-3009 payment authorization to x402 facilitator for on-chain settlement. Returns settlement status (settled/pending/failed) with transaction details. Implements idempotency caching to prevent duplicate submissions."
+	return "Submit verified EIP-3009 payment authorization to x402 facilitator for on-chain settlement. Returns settlement status (settled/pending/failed) with transaction details. Implements idempotency caching to prevent duplicate submissions. Under the accept_and_refund_excess overpayment policy, the excess is queued to the configured refunds file for the payee's operator to send; the server never sends refunds itself."
 }
 
 // Schema returns the JSON schema for the tool's input
 func (t *SettlePaymentTool) Schema() interface{} {
-	return map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"authorization": map[string]interface{}{
-				"type":        "object",
-				"description": "EIP-3009 receiveWithAuthorization parameters",
-				"properties": map[string]interface{}{
-					"from": map[string]interface{}{
-						"type":        "string",
-						"description": "Payer address (0x-prefixed hex)",
-						"pattern":     "^0x[a-fA-F0-9]{40}$",
-					},
-					"to": map[string]interface{}{
-						"type":        "string",
-						"description": "Payee address (0x-prefixed hex)",
-						"pattern":     "^0x[a-fA-F0-9]{40}$",
-					},
-					"value": map[string]interface{}{
-						"type":        "string",
-						"description": "Amount in USDC atomic units (6 decimals)",
-						"pattern":     "^[1-9][0-9]*$",
-					},
-					"validAfter": map[string]interface{}{
-						"type":        "integer",
-						"description": "Unix timestamp (seconds) after which the authorization is valid",
-					},
-					"validBefore": map[string]interface{}{
-						"type":        "integer",
-						"description": "Unix timestamp (seconds) before which the authorization is valid",
-					},
-					"nonce": map[string]interface{}{
-						"type":        "string",
-						"description": "Unique nonce as 32-byte hex string (0x-prefixed)",
-						"pattern":     "^0x[a-fA-F0-9]{64}$",
-					},
-					"v": map[string]interface{}{
-						"type":        "integer",
-						"description": "ECDSA recovery parameter (27 or 28)",
-						"enum":        []int{27, 28},
-					},
-					"r": map[string]interface{}{
-						"type":        "string",
-						"description": "ECDSA signature r component as 32-byte hex string",
-						"pattern":     "^0x[a-fA-F0-9]{64}$",
-					},
-					"s": map[string]interface{}{
-						"type":        "string",
-						"description": "ECDSA signature s component as 32-byte hex string",
-						"pattern":     "^0x[a-fA-F0-9]{64}$",
-					},
-				},
-				"required": []string{"from", "to", "value", "validAfter", "validBefore", "nonce", "v", "r", "s"},
-			},
-			"network": map[string]interface{}{
-				"type":        "string",
-				"description": "Blockchain network for settlement",
-				"enum":        []string{"base", "base-sepolia", "arbitrum"},
-			},
+	properties := map[string]interface{}{
+		"authorization":        authorizationSchema(),
+		"expected_value_human": expectedValueHumanSchema(),
+		"allow_mainnet": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Permit settlement on a mainnet network when the server runs in test mode (environment: test)",
+			"default":     false,
+		},
+		"facilitator_url_override": map[string]interface{}{
+			"type":        "string",
+			"description": "Submit to this facilitator instead of the network's configured one, e.g. a staging facilitator (only with settlement.allow_facilitator_override in the test environment; results are not cached)",
+			"format":      "uri",
 		},
-		"required": []string{"authorization", "network"},
+		"idempotency_token": map[string]interface{}{
+			"type":        "string",
+			"description": "Optional caller idempotency key (e.g., order ID); repeats with the same token reuse the first result even with a new nonce, and it is forwarded as the facilitator Idempotency-Key (facilitator mode)",
+			"minLength":   1,
+			"maxLength":   255,
+		},
+	}
+	requirement := requirementSchema()
+	requirement["description"] = requirement["description"].(string) +
+		". With settlement.enforce_requirement_timeout, settling later than its maxTimeoutSeconds after issuance fails with error_code 'timeout_exceeded'"
+	properties["requirement"] = requirement
+	for name, schema := range networkSchemaProperties("Blockchain network for settlement") {
+		properties[name] = schema
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"authorization"},
+		"anyOf":      networkSelector(),
 	}
 }
 
 // Execute executes the tool with the given arguments
 func (t *SettlePaymentTool) Execute(args map[string]interface{}) (interface{}, error) {
-	// Extract network
-	network, ok := args["network"].(string)
-	if !ok {
-		return nil, fmt.Errorf("network must be a string")
+	return t.ExecuteWithProgress(args, nil)
+}
+
+// TimeoutResult reports a settlement that outlived its limits.tool_timeouts budget as pending.
+// The submission is not cancelled: a retry carrying the same idempotency_key (the caller's
+// idempotency_token, else the authorization nonce) joins it while it runs and returns its
+// recorded result afterwards, instead of submitting the authorization again.
+func (t *SettlePaymentTool) TimeoutResult(args map[string]interface{}, timeoutMs int64) map[string]interface{} {
+	result := map[string]interface{}{
+		"status":      "pending",
+		"error":       fmt.Sprintf("settlement did not complete within %dms and continues in the background", timeoutMs),
+		"error_code":  server.ErrorCodeToolTimeout,
+		"retry_after": timeoutRetryAfterSeconds,
+		"timeout_ms":  timeoutMs,
+	}
+
+	if token, err := parseIdempotencyToken(args); err == nil && token != "" {
+		result["idempotency_key"] = token
+	} else if authMap, ok := args["authorization"].(map[string]interface{}); ok {
+		if nonce, ok := authMap["nonce"].(string); ok {
+			result["idempotency_key"] = nonce
+		}
+	}
+
+	return result
+}
+
+// ExecuteWithProgress executes the tool, reporting each settlement phase to progress
+// Phases are emitted in order: verifying, submitting, then pending, settled, or failed
+func (t *SettlePaymentTool) ExecuteWithProgress(args map[string]interface{}, progress SettlementProgressFunc) (interface{}, error) {
+	// Extract network, resolving it from chain_id and asset when omitted
+	network, err := resolveNetwork(t.server.GetConfig(), args)
+	if err != nil {
+		return nil, err
 	}
 
 	// Extract authorization object
@@ -116,51 +319,208 @@ func (t *SettlePaymentTool) Execute(args map[string]interface{}) (interface{}, e
 	}
 
 	// Parse authorization fields
-	auth, err := t.parseAuthorization(authMap)
+	auth, err := parseAuthorization(authMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse authorization: %w", err)
 	}
 
+	// Bind the authorization to the expected amount and requirement payee, when given
+	verification := &t.server.GetConfig().Verification
+	mismatch, mismatchCode, err := checkOffer(args, auth, t.server.GetConfig().AssetDecimals(network), verification)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optionally hold the settlement to the requirement's x402 timeout window
+	var timeoutExceeded string
+	if t.server.GetConfig().Settlement.EnforceRequirementTimeout {
+		if timeoutExceeded, err = checkRequirementTimeout(t.server.GetConfig(), args, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify against the requirement's EIP-712 domain when it names one
+	domainParams, err := requirementDomain(t.server.GetConfig(), network, args)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := parseIdempotencyToken(args)
+	if err != nil {
+		return nil, err
+	}
+
+	facilitatorURL, err := parseFacilitatorOverride(t.server.GetConfig(), args)
+	if err != nil {
+		return nil, err
+	}
+
+	allowMainnet := false
+	if raw, exists := args["allow_mainnet"]; exists {
+		if allowMainnet, ok = raw.(bool); !ok {
+			return nil, fmt.Errorf("allow_mainnet must be a boolean")
+		}
+	}
+
 	logger := t.server.GetLogger()
-	logger.Info("Settling payment authorization", map[string]interface{}{
+	logContext := map[string]interface{}{
 		"network": network,
 		"from":    auth.From,
 		"to":      auth.To,
 		"value":   auth.Value,
 		"nonce":   auth.Nonce,
-	})
+	}
+	if token != "" {
+		logContext["idempotency_token"] = token
+	}
+	if facilitatorURL != "" {
+		logContext["facilitator_url_override"] = facilitatorURL
+	}
+	logger.Info("Settling payment authorization", logContext)
+
+	emit := func(phase, txHash, errMsg string) {
+		if progress == nil {
+			return
+		}
+		progress(SettlementEvent{
+			Phase:     phase,
+			Timestamp: time.Now(),
+			Network:   network,
+			Nonce:     auth.Nonce,
+			TxHash:    txHash,
+			Error:     errMsg,
+		})
+	}
+
+	// Test mode guards real funds: mainnet settlement needs an explicit per-call override
+	if cfg := t.server.GetConfig(); cfg.TestMode() && !allowMainnet {
+		if networkCfg := cfg.Networks[network]; networkCfg.IsMainnet() {
+			message := fmt.Sprintf("refusing to settle on mainnet network %s in test mode (pass allow_mainnet to override)", network)
+			logger.Warn("Mainnet settlement blocked in test mode", map[string]interface{}{
+				"network": network,
+				"from":    auth.From,
+				"nonce":   auth.Nonce,
+			})
+			emit(SettlementPhaseFailed, "", message)
+			response := &facilitator.FacilitatorResponse{
+				Status:    "failed",
+				Error:     message,
+				ErrorCode: config.ErrorCodeMainnetBlocked,
+			}
+			return response.ToMap(), nil
+		}
+	}
 
 	// Step 1: Verify signature before settlement (FR-011 requirement)
-	verifyResult, err := t.verifier.VerifyAuthorization(auth, network)
+	emit(SettlementPhaseVerifying, "", "")
+	if mismatch != "" {
+		logger.Warn("Authorization does not match offer - refusing settlement", map[string]interface{}{
+			"network":    network,
+			"from":       auth.From,
+			"to":         auth.To,
+			"value":      auth.Value,
+			"error_code": mismatchCode,
+		})
+		emit(SettlementPhaseFailed, "", mismatch)
+		response := &facilitator.FacilitatorResponse{
+			Status:    "failed",
+			Error:     mismatch,
+			ErrorCode: mismatchCode,
+		}
+		return response.ToMap(), nil
+	}
+
+	if timeoutExceeded != "" {
+		logger.Warn("Requirement timeout exceeded - refusing settlement", map[string]interface{}{
+			"network": network,
+			"from":    auth.From,
+			"nonce":   auth.Nonce,
+		})
+		emit(SettlementPhaseFailed, "", timeoutExceeded)
+		response := &facilitator.FacilitatorResponse{
+			Status:    "failed",
+			Error:     timeoutExceeded,
+			ErrorCode: x402.ErrorCodeTimeoutExceeded,
+		}
+		return response.ToMap(), nil
+	}
+
+	// Optionally settle only what verify_payment (on any replica sharing the store) already accepted
+	if t.server.GetConfig().Settlement.RequirePriorVerify && !t.verifier.PreviouslyVerified(auth, network, domainParams) {
+		message := "authorization has not been verified: call verify_payment before settle_payment"
+		logger.Warn("Settlement refused without prior verification", map[string]interface{}{
+			"network": network,
+			"from":    auth.From,
+			"nonce":   auth.Nonce,
+		})
+		emit(SettlementPhaseFailed, "", message)
+		response := &facilitator.FacilitatorResponse{
+			Status:    "failed",
+			Error:     message,
+			ErrorCode: eip3009.ErrorCodeNotVerified,
+		}
+		return response.ToMap(), nil
+	}
+
+	// Contract payees must be allowlisted when verification.payee_allowlist is set
+	payeeViolation, rejectPayee, err := checkPayeeAllowlist(t.server, t.verifier, network, auth)
+	if err != nil {
+		emit(SettlementPhaseFailed, "", err.Error())
+		return nil, fmt.Errorf("payee allowlist check failed: %w", err)
+	}
+	if rejectPayee {
+		logger.Warn("Payee is not allowlisted - refusing settlement", map[string]interface{}{
+			"network": network,
+			"from":    auth.From,
+			"to":      auth.To,
+		})
+		emit(SettlementPhaseFailed, "", payeeViolation)
+		response := &facilitator.FacilitatorResponse{
+			Status:    "failed",
+			Error:     payeeViolation,
+			ErrorCode: eip3009.ErrorCodePayeeNotAllowlisted,
+		}
+		return response.ToMap(), nil
+	}
+
+	verifyResult, err := t.verifier.VerifyAuthorizationWithDomain(auth, network, domainParams)
 	if err != nil {
 		logger.Error("Signature verification failed before settlement", map[string]interface{}{
 			"error":   err.Error(),
 			"network": network,
 			"from":    auth.From,
 		})
+		emit(SettlementPhaseFailed, "", err.Error())
 		return nil, fmt.Errorf("signature verification failed: %w", err)
 	}
 
 	if !verifyResult.IsValid {
 		logger.Warn("Invalid signature - refusing settlement", map[string]interface{}{
-			"network": network,
-			"from":    auth.From,
-			"error":   verifyResult.Error,
+			"network":    network,
+			"from":       auth.From,
+			"error":      verifyResult.Error,
+			"error_code": verifyResult.ErrorCode,
 		})
-		return map[string]interface{}{
-			"status": "failed",
-			"error":  fmt.Sprintf("invalid signature: %s", verifyResult.Error),
-		}, nil
+		emit(SettlementPhaseFailed, "", verifyResult.Error)
+		response := &facilitator.FacilitatorResponse{
+			Status:    "failed",
+			Error:     fmt.Sprintf("invalid signature: %s", verifyResult.Error),
+			ErrorCode: verifyResult.ErrorCode,
+		}
+		return response.ToMap(), nil
 	}
 
+	weakNonceWarning(t.server, network, auth)
+
 	logger.Info("Signature verified successfully, submitting to facilitator", map[string]interface{}{
 		"network":        network,
 		"signer_address": verifyResult.SignerAddress,
 	})
 
-	// Step 2: Submit to facilitator
+	// Step 2: Submit to facilitator (or directly on-chain when configured)
+	emit(SettlementPhaseSubmitting, "", "")
 	startTime := time.Now()
-	result, err := t.facilitatorClient.SubmitSettlement(auth, network)
+	result, err := t.submit(auth, network, token, facilitatorURL)
 	duration := time.Since(startTime).Milliseconds()
 
 	if err != nil {
@@ -170,11 +530,15 @@ func (t *SettlePaymentTool) Execute(args map[string]interface{}) (interface{}, e
 			"from":        auth.From,
 			"duration_ms": duration,
 		})
+		emit(SettlementPhaseFailed, "", err.Error())
+		t.countFacilitatorError(network, facilitatorErrorTransport)
 		return nil, fmt.Errorf("facilitator submission failed: %w", err)
 	}
 
+	emit(settlementPhase(result.Status), result.TxHash, result.Error)
+
 	// Log result
-	logContext := map[string]interface{}{
+	logContext = map[string]interface{}{
 		"network":     network,
 		"status":      result.Status,
 		"duration_ms": duration,
@@ -186,91 +550,348 @@ func (t *SettlePaymentTool) Execute(args map[string]interface{}) (interface{}, e
 		logContext["tx_hash"] = result.TxHash
 		logContext["block_number"] = result.BlockNumber
 		logger.Info("Payment settled successfully", logContext)
+		t.server.GetMetrics().ObserveHistogram(facilitator.MetricSettlementLatency, facilitator.SettlementLatencyBuckets,
+			metrics.Labels{"network": network}, time.Since(startTime).Seconds())
 	} else if result.Status == "pending" {
 		logContext["retry_after"] = result.RetryAfter
 		logger.Info("Payment settlement pending", logContext)
 	} else {
 		logContext["error"] = result.Error
+		logContext["error_code"] = result.ErrorCode
 		logger.Warn("Payment settlement failed", logContext)
+		t.countFacilitatorError(network, result.ErrorCode)
 	}
 
 	// Return facilitator response
-	return result.ToMap(), nil
+	resultMap := addExplorerURL(t.server.GetConfig(), network, result.ToMap())
+	if verification.Overpayment == config.OverpaymentAcceptAndRefundExcess {
+		decimals := t.server.GetConfig().AssetDecimals(network)
+		if excess, err := overpaymentExcess(args, auth, decimals); err == nil && excess != nil {
+			switch result.Status {
+			case "settled":
+				t.takePendingRefund(network, auth.Nonce)
+				refund := refundDue(auth, excess, decimals)
+				refund["status"] = t.queueRefund(network, auth, result.TxHash, excess, decimals)
+				resultMap["refund"] = refund
+				logger.Info("Overpayment settled, refund due to payer", map[string]interface{}{
+					"network": network,
+					"to":      auth.From,
+					"amount":  excess.String(),
+					"nonce":   auth.Nonce,
+					"refund":  refund["status"],
+				})
+			case "pending":
+				// Queued by resolvePendingRefund once reconciliation or a callback settles it
+				t.holdPendingRefund(network, auth, excess, decimals)
+			}
+		}
+	}
+
+	// Flag settlements on a network whose USDC contract failed its latest health check
+	if t.server.NetworkDegraded(network) {
+		resultMap["network_degraded"] = true
+	}
+
+	t.markSeen(network, auth, result)
+	t.publishOutcome(network, auth, resultMap)
+	t.recordReceipt(network, auth, result)
+	t.deadLetter(network, auth, result)
+	logger.Debug("Settlement cache stats", t.facilitatorClient.CacheStats().ToMap())
+	return resultMap, nil
 }
 
-// parseAuthorization converts the input map to an EIP3009Authorization struct
-func (t *SettlePaymentTool) parseAuthorization(authMap map[string]interface{}) (*eip3009.EIP3009Authorization, error) {
-	// Extract required string fields
-	from, ok := authMap["from"].(string)
-	if !ok {
-		return nil, fmt.Errorf("from must be a string")
+// markSeen records a settled or pending (submitted) authorization in the seen nonce store,
+// so a later verify_payment of it reports already_seen
+func (t *SettlePaymentTool) markSeen(network string, auth *eip3009.EIP3009Authorization, result *facilitator.FacilitatorResponse) {
+	if result.Status != "settled" && result.Status != "pending" {
+		return
 	}
+	t.server.GetSeenNonceStore().Set(seenNonceKey(network, auth), []byte{1}, eip3009.VerificationResultTTL)
+}
 
-	to, ok := authMap["to"].(string)
-	if !ok {
-		return nil, fmt.Errorf("to must be a string")
+// deadLetter keeps a settlement the backend rejected permanently, so the signed authorization
+// is not lost; failures carrying retry_after (queue full, gas ceiling) are transient and skipped.
+// Like publishing, it is best effort.
+func (t *SettlePaymentTool) deadLetter(network string, auth *eip3009.EIP3009Authorization, result *facilitator.FacilitatorResponse) {
+	if result.Status != "failed" || result.RetryAfter > 0 {
+		return
 	}
 
-	value, ok := authMap["value"].(string)
-	if !ok {
-		return nil, fmt.Errorf("value must be a string")
+	if err := t.server.GetDeadLetterStore().Append(deadletter.Entry{
+		Network:       network,
+		Authorization: auth,
+		Error:         result.Error,
+		ErrorCode:     result.ErrorCode,
+	}); err != nil {
+		t.server.GetMetrics().IncCounter(deadletter.MetricAppendFailures, metrics.Labels{"network": network})
+		t.server.GetLogger().Error("Failed to dead-letter settlement", map[string]interface{}{
+			"error":   err.Error(),
+			"network": network,
+			"nonce":   auth.Nonce,
+		})
 	}
+}
 
-	nonce, ok := authMap["nonce"].(string)
-	if !ok {
-		return nil, fmt.Errorf("nonce must be a string")
+// recordReceipt appends a settled or pending result to the audit trail for volume reporting
+// when audit.record_receipts is set. Like publishing, it is best effort.
+func (t *SettlePaymentTool) recordReceipt(network string, auth *eip3009.EIP3009Authorization, result *facilitator.FacilitatorResponse) {
+	if !t.server.GetConfig().Audit.RecordReceipts || (result.Status != "settled" && result.Status != "pending") {
+		return
 	}
 
-	r, ok := authMap["r"].(string)
-	if !ok {
-		return nil, fmt.Errorf("r must be a string")
+	if err := t.server.GetAuditStore().Append(audit.Record{
+		Event:   audit.EventSettlementReceipt,
+		Network: network,
+		Nonce:   auth.Nonce,
+		Status:  result.Status,
+		TxHash:  result.TxHash,
+		Payee:   auth.To,
+		Value:   auth.Value,
+	}); err != nil {
+		t.server.GetLogger().Warn("Failed to record settlement receipt", map[string]interface{}{
+			"error":   err.Error(),
+			"network": network,
+			"nonce":   auth.Nonce,
+		})
+	}
+}
+
+// facilitatorErrorTransport labels facilitator submissions that failed before a response was received
+const facilitatorErrorTransport = "transport"
+
+// countFacilitatorError counts a failed facilitator settlement; on-chain settlement has no facilitator
+func (t *SettlePaymentTool) countFacilitatorError(network, errorCode string) {
+	if t.server.GetConfig().Settlement.IsOnChain() {
+		return
+	}
+	t.server.GetMetrics().IncCounter(facilitator.MetricErrors, metrics.Labels{"network": network, "error_code": errorCode})
+}
+
+// publishOutcome publishes a settled or failed result to the configured event bus
+// Publishing is best effort: failures are logged and counted, never surfaced to the caller.
+func (t *SettlePaymentTool) publishOutcome(network string, auth *eip3009.EIP3009Authorization, receipt map[string]interface{}) {
+	var eventType string
+	switch receipt["status"] {
+	case "settled":
+		eventType = events.EventSettlementSettled
+	case "failed":
+		eventType = events.EventSettlementFailed
+	default:
+		return
+	}
+
+	event := events.Event{
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Network:   network,
+		Nonce:     auth.Nonce,
+		From:      auth.From,
+		To:        auth.To,
+		Value:     auth.Value,
+		Receipt:   receipt,
+	}
+
+	if err := t.server.GetEventPublisher().Publish(context.Background(), event); err != nil {
+		t.server.GetMetrics().IncCounter(events.MetricPublishFailures, metrics.Labels{"network": network})
+		t.server.GetLogger().Warn("Failed to publish settlement event", map[string]interface{}{
+			"error":   err.Error(),
+			"network": network,
+			"nonce":   auth.Nonce,
+			"status":  receipt["status"],
+		})
+	}
+}
+
+// Refund statuses reported on settlements that accepted an overpayment
+const (
+	refundStatusDue    = "due"    // Owed but not queued (no refunds.path, or the queue write failed)
+	refundStatusQueued = "queued" // Recorded in refunds.path for the operator to send
+)
+
+// queueRefund records the excess of an accepted overpayment in the refund queue and
+// returns the refund status; the server holds no payee key, so it never sends refunds itself
+func (t *SettlePaymentTool) queueRefund(network string, auth *eip3009.EIP3009Authorization, txHash string, excess *big.Int, decimals int) string {
+	cfg := t.server.GetConfig()
+	if !cfg.Refunds.Enabled() {
+		return refundStatusDue
+	}
+
+	if err := t.server.GetRefundQueue().Enqueue(refunds.Entry{
+		Network:     network,
+		Asset:       cfg.Networks[network].USDCContract,
+		From:        auth.To,
+		To:          auth.From,
+		Amount:      excess.String(),
+		AmountHuman: units.ToHuman(excess, decimals),
+		Nonce:       auth.Nonce,
+		TxHash:      txHash,
+	}); err != nil {
+		t.server.GetMetrics().IncCounter(refunds.MetricEnqueueFailures, metrics.Labels{"network": network})
+		t.server.GetLogger().Error("Failed to queue refund", map[string]interface{}{
+			"error":   err.Error(),
+			"network": network,
+			"nonce":   auth.Nonce,
+		})
+		return refundStatusDue
+	}
+	return refundStatusQueued
+}
+
+// pendingRefund is the excess of an accepted overpayment whose settlement is still pending
+type pendingRefund struct {
+	auth     *eip3009.EIP3009Authorization
+	excess   *big.Int
+	decimals int
+}
+
+// pendingRefundKey identifies a pending settlement the way the facilitator client tracks it
+func pendingRefundKey(network, nonce string) string {
+	return network + ":" + nonce
+}
+
+// holdPendingRefund remembers the excess owed if a pending settlement later settles
+func (t *SettlePaymentTool) holdPendingRefund(network string, auth *eip3009.EIP3009Authorization, excess *big.Int, decimals int) {
+	t.refundsMu.Lock()
+	defer t.refundsMu.Unlock()
+
+	t.pendingRefunds[pendingRefundKey(network, auth.Nonce)] = pendingRefund{auth: auth, excess: excess, decimals: decimals}
+}
+
+// takePendingRefund removes and returns the refund held for a pending settlement
+func (t *SettlePaymentTool) takePendingRefund(network, nonce string) (pendingRefund, bool) {
+	t.refundsMu.Lock()
+	defer t.refundsMu.Unlock()
+
+	key := pendingRefundKey(network, nonce)
+	refund, exists := t.pendingRefunds[key]
+	delete(t.pendingRefunds, key)
+	return refund, exists
+}
+
+// resolvePendingRefund queues the refund held for a pending settlement once it settles,
+// and drops it when the settlement fails (nothing was transferred)
+func (t *SettlePaymentTool) resolvePendingRefund(network, nonce string, response *facilitator.FacilitatorResponse) {
+	refund, exists := t.takePendingRefund(network, nonce)
+	if !exists || response.Status != "settled" {
+		return
+	}
+
+	status := t.queueRefund(network, refund.auth, response.TxHash, refund.excess, refund.decimals)
+	t.server.GetLogger().Info("Overpayment settled, refund due to payer", map[string]interface{}{
+		"network": network,
+		"to":      refund.auth.From,
+		"amount":  refund.excess.String(),
+		"nonce":   nonce,
+		"refund":  status,
+	})
+}
+
+// refundDue describes the excess of an accepted overpayment owed back to the payer
+func refundDue(auth *eip3009.EIP3009Authorization, excess *big.Int, decimals int) map[string]interface{} {
+	return map[string]interface{}{
+		"status":       refundStatusDue,
+		"to":           auth.From,
+		"amount":       excess.String(),
+		"amount_human": units.ToHuman(excess, decimals),
 	}
+}
 
-	s, ok := authMap["s"].(string)
+// parseIdempotencyToken returns the optional idempotency_token argument, or ""
+func parseIdempotencyToken(args map[string]interface{}) (string, error) {
+	raw, exists := args["idempotency_token"]
+	if !exists {
+		return "", nil
+	}
+
+	token, ok := raw.(string)
 	if !ok {
-		return nil, fmt.Errorf("s must be a string")
+		return "", fmt.Errorf("idempotency_token must be a string")
+	}
+	if !idempotencyTokenPattern.MatchString(token) {
+		return "", fmt.Errorf("idempotency_token must be 1-255 printable ASCII characters")
 	}
 
-	// Extract uint64 fields (JSON numbers come as float64)
-	validAfterFloat, ok := authMap["validAfter"].(float64)
+	return token, nil
+}
+
+// parseFacilitatorOverride returns the optional facilitator_url_override argument, or ""
+// It is rejected unless settlement.allow_facilitator_override is set and the server settles
+// through a facilitator, and must pass the same SSRF policy as configured facilitator URLs.
+func parseFacilitatorOverride(cfg *config.Config, args map[string]interface{}) (string, error) {
+	raw, exists := args["facilitator_url_override"]
+	if !exists {
+		return "", nil
+	}
+
+	facilitatorURL, ok := raw.(string)
 	if !ok {
-		return nil, fmt.Errorf("validAfter must be a number")
+		return "", fmt.Errorf("facilitator_url_override must be a string")
+	}
+	if !cfg.Settlement.AllowFacilitatorOverride {
+		return "", fmt.Errorf("facilitator_url_override is not allowed: settlement.allow_facilitator_override is off")
+	}
+	if cfg.Settlement.IsOnChain() {
+		return "", fmt.Errorf("facilitator_url_override does not apply to onchain settlement")
 	}
-	validAfter := uint64(validAfterFloat)
+	if parsed, err := url.Parse(facilitatorURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", fmt.Errorf("facilitator_url_override must be an HTTP/HTTPS URL")
+	}
+	if err := netguard.CheckURL(facilitatorURL, cfg.AllowPrivateURLs); err != nil {
+		return "", fmt.Errorf("facilitator_url_override rejected: %w", err)
+	}
+
+	return facilitatorURL, nil
+}
 
-	validBeforeFloat, ok := authMap["validBefore"].(float64)
+// addExplorerURL sets explorer_url from the network's explorer base when the result has a tx_hash
+func addExplorerURL(cfg *config.Config, network string, result map[string]interface{}) map[string]interface{} {
+	txHash, _ := result["tx_hash"].(string)
+	netCfg, ok := cfg.Networks[network]
 	if !ok {
-		return nil, fmt.Errorf("validBefore must be a number")
+		return result
+	}
+	if link := netCfg.TxExplorerURL(txHash); link != "" {
+		result["explorer_url"] = link
+	}
+	return result
+}
+
+// submit routes the authorization to the configured settlement backend, bounded by the
+// network's in-flight limit; a saturated network yields error_code "settlement_queue_full"
+func (t *SettlePaymentTool) submit(auth *eip3009.EIP3009Authorization, network, token, facilitatorURL string) (*facilitator.FacilitatorResponse, error) {
+	release, err := t.limiter.Load().Acquire(network)
+	if err != nil {
+		return &facilitator.FacilitatorResponse{
+			Status:     "failed",
+			ErrorCode:  inflight.ErrorCodeQueueFull,
+			Error:      fmt.Sprintf("too many settlements in flight for %s", network),
+			RetryAfter: queueFullRetryAfterSeconds,
+		}, nil
 	}
-	validBefore := uint64(validBeforeFloat)
+	defer release()
 
-	// Extract v (could be float64 or int)
-	var v uint8
-	switch vVal := authMap["v"].(type) {
-	case float64:
-		v = uint8(vVal)
-	case int:
-		v = uint8(vVal)
-	default:
-		return nil, fmt.Errorf("v must be a number")
+	if !t.server.GetConfig().Settlement.IsOnChain() {
+		if facilitatorURL != "" {
+			return t.facilitatorClient.SubmitSettlementVia(auth, network, token, facilitatorURL)
+		}
+		return t.facilitatorClient.SubmitSettlementWithToken(auth, network, token)
 	}
 
-	// Validate v is 27 or 28
-	if v != 27 && v != 28 {
-		return nil, fmt.Errorf("v must be 27 or 28, got %d", v)
+	t.onchainMu.RLock()
+	settler, settlerErr := t.onchainSettler, t.onchainErr
+	t.onchainMu.RUnlock()
+	if settler == nil {
+		if settlerErr == nil {
+			// A reload switched to on-chain mode and its handlers have not run yet
+			settlerErr = fmt.Errorf("relayer not initialized")
+		}
+		return nil, fmt.Errorf("on-chain settlement unavailable: %w", settlerErr)
 	}
 
-	return &eip3009.EIP3009Authorization{
-		From:        from,
-		To:          to,
-		Value:       value,
-		ValidAfter:  validAfter,
-		ValidBefore: validBefore,
-		Nonce:       nonce,
-		V:           v,
-		R:           r,
-		S:           s,
-	}, nil
+	// Share the idempotency cache, so a retry returns the broadcast tx instead of a second one
+	return t.facilitatorClient.SubmitOnce(network, auth.From, auth.Nonce, func() (*facilitator.FacilitatorResponse, error) {
+		return settler.Settle(auth, network)
+	})
 }
 
 // Register registers the tool with the MCP server
@@ -0,0 +1,58 @@
+package tools
+
+import "time"
+
+// Settlement progress phases, emitted in order by SettlePaymentTool.ExecuteWithProgress
+const (
+	SettlementPhaseVerifying  = "verifying"
+	SettlementPhaseSubmitting = "submitting"
+	SettlementPhasePending    = "pending"
+	SettlementPhaseSettled    = "settled"
+	SettlementPhaseFailed     = "failed"
+)
+
+// SettlementEvent reports a settlement phase transition
+type SettlementEvent struct {
+	Phase     string    `json:"phase"`
+	Timestamp time.Time `json:"timestamp"`
+	Network   string    `json:"network"`
+	Nonce     string    `json:"nonce"`
+	TxHash    string    `json:"tx_hash,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// SettlementProgressFunc receives settlement events as they occur
+// Callbacks run synchronously on the settling goroutine and should return quickly
+type SettlementProgressFunc func(event SettlementEvent)
+
+// ToMap converts the event to a map for MCP progress notifications
+func (e SettlementEvent) ToMap() map[string]interface{} {
+	result := map[string]interface{}{
+		"phase":     e.Phase,
+		"timestamp": e.Timestamp.UTC().Format(time.RFC3339Nano),
+		"network":   e.Network,
+		"nonce":     e.Nonce,
+	}
+
+	if e.TxHash != "" {
+		result["tx_hash"] = e.TxHash
+	}
+
+	if e.Error != "" {
+		result["error"] = e.Error
+	}
+
+	return result
+}
+
+// settlementPhase maps a settlement status to its terminal progress phase
+func settlementPhase(status string) string {
+	switch status {
+	case "settled":
+		return SettlementPhaseSettled
+	case "pending":
+		return SettlementPhasePending
+	default:
+		return SettlementPhaseFailed
+	}
+}
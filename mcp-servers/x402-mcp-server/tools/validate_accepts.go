@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// maxAcceptsEntries bounds how many requirements a single call may validate
+const maxAcceptsEntries = 50
+
+// ValidateAcceptsTool implements the validate_accepts MCP tool
+type ValidateAcceptsTool struct {
+	server *server.Server
+}
+
+// NewValidateAcceptsTool creates a new validate_accepts tool
+func NewValidateAcceptsTool(srv *server.Server) *ValidateAcceptsTool {
+	return &ValidateAcceptsTool{
+		server: srv,
+	}
+}
+
+// Name returns the tool name
+func (t *ValidateAcceptsTool) Name() string {
+	return "validate_accepts"
+}
+
+// Description returns the tool description
+func (t *ValidateAcceptsTool) Description() string {
+	return "Validate every payment requirement in an x402 402 response's \"accepts\" array. Reports per entry whether it is valid, expired, and affordable given an optional balance, and recommends the cheapest usable option."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *ValidateAcceptsTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"accepts": map[string]interface{}{
+				"type":        "array",
+				"description": fmt.Sprintf("Payment requirements offered by the resource server (at most %d; objects or JSON strings)", maxAcceptsEntries),
+				"maxItems":    maxAcceptsEntries,
+				"items": map[string]interface{}{
+					"type": []string{"object", "string"},
+				},
+			},
+			"balance": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional payer balance in atomic units; entries requiring more are reported as not affordable",
+				"pattern":     "^[0-9]+$",
+			},
+		},
+		"required": []string{"accepts"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *ValidateAcceptsTool) Execute(args map[string]interface{}) (interface{}, error) {
+	list, ok := args["accepts"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("accepts must be an array")
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("accepts must not be empty")
+	}
+	if len(list) > maxAcceptsEntries {
+		return nil, fmt.Errorf("accepts has %d entries, maximum is %d", len(list), maxAcceptsEntries)
+	}
+
+	var balance *big.Int
+	if raw, exists := args["balance"]; exists {
+		balanceStr, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("balance must be a string")
+		}
+		parsed, ok := new(big.Int).SetString(balanceStr, 10)
+		if !ok || parsed.Sign() < 0 {
+			return nil, fmt.Errorf("balance must be a non-negative integer string")
+		}
+		balance = parsed
+	}
+
+	now := time.Now()
+	results := make([]interface{}, 0, len(list))
+	validCount, affordableCount := 0, 0
+	recommended := -1
+	var recommendedAmount *big.Int
+
+	for i, item := range list {
+		entry := map[string]interface{}{
+			"index": i,
+			"valid": false,
+		}
+		results = append(results, entry)
+
+		paymentReq, err := parseRequirement(item)
+		if err != nil {
+			entry["error"] = err.Error()
+			entry["error_code"] = x402.ErrorCodeInvalidRequirement
+			continue
+		}
+
+		entry["network"] = paymentReq.Network
+		entry["maxAmountRequired"] = paymentReq.MaxAmountRequired
+
+		if paymentReq.IsExpired(now) {
+			entry["expired"] = true
+			entry["error"] = fmt.Sprintf("requirement expired at %s", paymentReq.ValidUntil)
+			entry["error_code"] = x402.ErrorCodeRequirementExpired
+			continue
+		}
+
+		entry["valid"] = true
+		entry["expired"] = false
+		validCount++
+
+		// Validate guarantees a positive integer amount
+		amount, _ := new(big.Int).SetString(paymentReq.MaxAmountRequired, 10)
+		if balance != nil {
+			affordable := balance.Cmp(amount) >= 0
+			entry["affordable"] = affordable
+			if !affordable {
+				continue
+			}
+			affordableCount++
+		}
+
+		// Prefer the cheapest usable requirement; ties keep the server's order
+		if recommendedAmount == nil || amount.Cmp(recommendedAmount) < 0 {
+			recommended = i
+			recommendedAmount = amount
+		}
+	}
+
+	logger := t.server.GetLogger()
+	logger.Info("Validated accepts array", map[string]interface{}{
+		"entries":           len(list),
+		"valid":             validCount,
+		"recommended_index": recommended,
+	})
+
+	result := map[string]interface{}{
+		"results":     results,
+		"valid_count": validCount,
+	}
+	if balance != nil {
+		result["affordable_count"] = affordableCount
+	}
+	if recommended >= 0 {
+		result["recommended_index"] = recommended
+	}
+
+	// Return as map for MCP
+	return result, nil
+}
+
+// Register registers the tool with the MCP server
+func (t *ValidateAcceptsTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}
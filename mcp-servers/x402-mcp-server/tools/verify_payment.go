@@ -1,10 +1,16 @@
 package tools
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
+	"strings"
 
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/cache"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/eip3009"
 	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 )
 
@@ -12,14 +18,31 @@ import (
 type VerifyPaymentTool struct {
 	server   *server.Server
 	verifier *eip3009.SignatureVerifier
+	seen     cache.Store // Nonces verified or settled within VerificationResultTTL (see seenNonceKey)
 }
 
 // NewVerifyPaymentTool creates a new verify_payment tool
 func NewVerifyPaymentTool(srv *server.Server) *VerifyPaymentTool {
-	return &VerifyPaymentTool{
+	tool := &VerifyPaymentTool{
 		server:   srv,
 		verifier: eip3009.NewSignatureVerifier(srv.GetConfig()),
+		seen:     srv.GetSeenNonceStore(),
 	}
+	tool.verifier.UseResultStore(srv.GetVerificationStore())
+	tool.verifier.OnCacheEvict(cache.EvictionRecorder(srv.GetMetrics(), "verification"))
+	tool.verifier.UseRValueMonitor(srv.GetRValueMonitor())
+
+	// Flush cached domains/results for networks affected by a config reload
+	srv.OnConfigReload(func(_, newCfg *config.Config) {
+		tool.verifier.UpdateConfig(newCfg)
+	})
+
+	return tool
+}
+
+// Warmup pre-builds the EIP-712 domains for every configured network
+func (t *VerifyPaymentTool) Warmup(ctx context.Context) error {
+	return t.verifier.PrewarmDomains()
 }
 
 // Name returns the tool name
@@ -29,80 +52,50 @@ func (t *VerifyPaymentTool) Name() string {
 
 // Description returns the tool description
 func (t *VerifyPaymentTool) Description() string {
-	return "Verify EIP-3009 payment authorization signature using secp256k1 ECDSA recovery. Validates signature authenticity, time bounds, and EIP-712 domain matching for blockchain payment verification."
+	return "Verify EIP-3009 payment authorization signature using secp256k1 ECDSA recovery. Validates signature authenticity, time bounds, and EIP-712 domain matching for blockchain payment verification. already_seen is true when the same network, payer, and nonce verified or settled earlier, which may mean a replayed or already spent authorization. A contract payee missing from the configured payee allowlist fails with payee_not_allowlisted, or is flagged in payee_not_allowlisted under the warn policy."
 }
 
 // Schema returns the JSON schema for the tool's input
 func (t *VerifyPaymentTool) Schema() interface{} {
-	return map[string]interface{}{
-		"type": "object",
-		"properties": map[string]interface{}{
-			"authorization": map[string]interface{}{
-				"type":        "object",
-				"description": "EIP-3009 receiveWithAuthorization parameters",
-				"properties": map[string]interface{}{
-					"from": map[string]interface{}{
-						"type":        "string",
-						"description": "Payer address (0x-prefixed hex)",
-						"pattern":     "^0x[a-fA-F0-9]{40}$",
-					},
-					"to": map[string]interface{}{
-						"type":        "string",
-						"description": "Payee address (0x-prefixed hex)",
-						"pattern":     "^0x[a-fA-F0-9]{40}$",
-					},
-					"value": map[string]interface{}{
-						"type":        "string",
-						"description": "Amount in USDC atomic units (6 decimals)",
-						"pattern":     "^[1-9][0-9]*$",
-					},
-					"validAfter": map[string]interface{}{
-						"type":        "integer",
-						"description": "Unix timestamp (seconds) after which the authorization is valid",
-					},
-					"validBefore": map[string]interface{}{
-						"type":        "integer",
-						"description": "Unix timestamp (seconds) before which the authorization is valid",
-					},
-					"nonce": map[string]interface{}{
-						"type":        "string",
-						"description": "Unique nonce as 32-byte hex string (0x-prefixed)",
-						"pattern":     "^0x[a-fA-F0-9]{64}$",
-					},
-					"v": map[string]interface{}{
-						"type":        "integer",
-						"description": "ECDSA recovery parameter (27 or 28)",
-						"enum":        []int{27, 28},
-					},
-					"r": map[string]interface{}{
-						"type":        "string",
-						"description": "ECDSA signature r component as 32-byte hex string",
-						"pattern":     "^0x[a-fA-F0-9]{64}$",
-					},
-					"s": map[string]interface{}{
-						"type":        "string",
-						"description": "ECDSA signature s component as 32-byte hex string",
-						"pattern":     "^0x[a-fA-F0-9]{64}$",
-					},
-				},
-				"required": []string{"from", "to", "value", "validAfter", "validBefore", "nonce", "v", "r", "s"},
-			},
-			"network": map[string]interface{}{
-				"type":        "string",
-				"description": "Blockchain network for verification",
-				"enum":        []string{"base", "base-sepolia", "arbitrum"},
-			},
+	properties := map[string]interface{}{
+		"authorization":        authorizationSchema(),
+		"expected_value_human": expectedValueHumanSchema(),
+		"requirement":          requirementSchema(),
+		"signatures":           signaturesSchema(),
+		"address_format": map[string]interface{}{
+			"type":        "string",
+			"description": "Format for signer_address, from and to in the result (defaults to verification.address_format)",
+			"enum":        []string{config.AddressFormatHex, config.AddressFormatCAIP10},
+		},
+		"verbose": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Include diagnostic fields such as s_normalized (whether every signature's s is low-s)",
+			"default":     false,
+		},
+		"include_compact_proof": map[string]interface{}{
+			"type":        "boolean",
+			"description": "On a valid single-signer verification, also return compact_proof: the authorization and network as a base64 binary accepted by the *_payment_payload tools",
+			"default":     false,
 		},
-		"required": []string{"authorization", "network"},
+	}
+	for name, schema := range networkSchemaProperties("Blockchain network for verification") {
+		properties[name] = schema
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"authorization"},
+		"anyOf":      networkSelector(),
 	}
 }
 
 // Execute executes the tool with the given arguments
 func (t *VerifyPaymentTool) Execute(args map[string]interface{}) (interface{}, error) {
-	// Extract network
-	network, ok := args["network"].(string)
-	if !ok {
-		return nil, fmt.Errorf("network must be a string")
+	// Extract network, resolving it from chain_id and asset when omitted
+	network, err := resolveNetwork(t.server.GetConfig(), args)
+	if err != nil {
+		return nil, err
 	}
 
 	// Extract authorization object
@@ -111,12 +104,58 @@ func (t *VerifyPaymentTool) Execute(args map[string]interface{}) (interface{}, e
 		return nil, fmt.Errorf("authorization must be an object")
 	}
 
-	// Parse authorization fields
-	auth, err := t.parseAuthorization(authMap)
+	// Multisig payers supply owner signatures separately from the authorization
+	var signatures []eip3009.Signature
+	var auth *eip3009.EIP3009Authorization
+	if rawSignatures, exists := args["signatures"]; exists {
+		signatures, err = parseSignatures(rawSignatures)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signatures: %w", err)
+		}
+		auth, err = parseAuthorizationMessage(authMap)
+	} else {
+		auth, err = parseAuthorization(authMap)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse authorization: %w", err)
 	}
 
+	// Bind the authorization to the expected amount and requirement payee, when given
+	mismatch, mismatchCode, err := checkOffer(args, auth, t.server.GetConfig().AssetDecimals(network), &t.server.GetConfig().Verification)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify against the requirement's EIP-712 domain when it names one
+	domainParams, err := requirementDomain(t.server.GetConfig(), network, args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Per-call address format overrides the configured default
+	addressFormat := t.server.GetConfig().Verification.AddressFormat
+	if rawFormat, exists := args["address_format"]; exists {
+		format, ok := rawFormat.(string)
+		if !ok || !config.ValidAddressFormat(format) {
+			return nil, fmt.Errorf("address_format must be 'hex' or 'caip10'")
+		}
+		addressFormat = format
+	}
+
+	verbose := false
+	if rawVerbose, exists := args["verbose"]; exists {
+		if verbose, ok = rawVerbose.(bool); !ok {
+			return nil, fmt.Errorf("verbose must be a boolean")
+		}
+	}
+
+	includeCompact := false
+	if rawCompact, exists := args["include_compact_proof"]; exists {
+		if includeCompact, ok = rawCompact.(bool); !ok {
+			return nil, fmt.Errorf("include_compact_proof must be a boolean")
+		}
+	}
+
 	// Log verification attempt
 	logger := t.server.GetLogger()
 	logger.Info("Verifying payment authorization", map[string]interface{}{
@@ -127,8 +166,49 @@ func (t *VerifyPaymentTool) Execute(args map[string]interface{}) (interface{}, e
 		"nonce":   auth.Nonce,
 	})
 
+	if mismatch != "" {
+		logger.Info("Authorization does not match offer", map[string]interface{}{
+			"network":    network,
+			"from":       auth.From,
+			"to":         auth.To,
+			"value":      auth.Value,
+			"error_code": mismatchCode,
+		})
+		output := &eip3009.VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     mismatch,
+			ErrorCode: mismatchCode,
+		}
+		return t.resultMap(output, auth, network, addressFormat), nil
+	}
+
+	// Contract payees must be allowlisted when verification.payee_allowlist is set
+	payeeViolation, rejectPayee, err := checkPayeeAllowlist(t.server, t.verifier, network, auth)
+	if err != nil {
+		return nil, fmt.Errorf("payee allowlist check failed: %w", err)
+	}
+	if rejectPayee {
+		logger.Info("Payee is not allowlisted", map[string]interface{}{
+			"network":    network,
+			"from":       auth.From,
+			"to":         auth.To,
+			"error_code": eip3009.ErrorCodePayeeNotAllowlisted,
+		})
+		output := &eip3009.VerifyPaymentOutput{
+			IsValid:   false,
+			Error:     payeeViolation,
+			ErrorCode: eip3009.ErrorCodePayeeNotAllowlisted,
+		}
+		return t.resultMap(output, auth, network, addressFormat), nil
+	}
+
 	// Verify the authorization
-	result, err := t.verifier.VerifyAuthorization(auth, network)
+	var result *eip3009.VerifyPaymentOutput
+	if signatures != nil {
+		result, err = t.verifier.VerifyMultisigAuthorizationWithDomain(auth, signatures, network, domainParams)
+	} else {
+		result, err = t.verifier.VerifyAuthorizationWithDomain(auth, network, domainParams)
+	}
 	if err != nil {
 		logger.Error("Verification failed", map[string]interface{}{
 			"error":   err.Error(),
@@ -147,89 +227,109 @@ func (t *VerifyPaymentTool) Execute(args map[string]interface{}) (interface{}, e
 		})
 	} else {
 		logger.Info("Signature verification failed", map[string]interface{}{
+			"network":    network,
+			"error":      result.Error,
+			"error_code": result.ErrorCode,
+			"from":       auth.From,
+		})
+	}
+
+	// Flag replays of a nonce already verified; the result itself is unaffected
+	alreadySeen := t.markSeen(network, auth, result.IsValid)
+	if alreadySeen {
+		logger.Warn("Authorization nonce was already verified", map[string]interface{}{
 			"network": network,
-			"error":   result.Error,
 			"from":    auth.From,
+			"nonce":   auth.Nonce,
 		})
 	}
 
 	// Return as map for MCP
-	return result.ToMap(), nil
+	resultMap := t.resultMap(result, auth, network, addressFormat)
+	resultMap["already_seen"] = alreadySeen
+	if excess, err := overpaymentExcess(args, auth, t.server.GetConfig().AssetDecimals(network)); err == nil && excess != nil {
+		resultMap["overpayment"] = excess.String()
+	}
+	if warning := weakNonceWarning(t.server, network, auth); warning != "" {
+		resultMap["weak_nonce"] = warning
+	}
+	if payeeViolation != "" {
+		resultMap["payee_not_allowlisted"] = payeeViolation
+	}
+	if verbose {
+		resultMap["s_normalized"] = signaturesLowS(auth, signatures)
+	}
+	if includeCompact && result.IsValid && signatures == nil {
+		proof, err := eip3009.EncodeCompact(auth, network)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode compact proof: %w", err)
+		}
+		resultMap["compact_proof"] = base64.StdEncoding.EncodeToString(proof)
+	}
+	return resultMap, nil
 }
 
-// parseAuthorization converts the input map to an EIP3009Authorization struct
-func (t *VerifyPaymentTool) parseAuthorization(authMap map[string]interface{}) (*eip3009.EIP3009Authorization, error) {
-	// Extract required string fields
-	from, ok := authMap["from"].(string)
-	if !ok {
-		return nil, fmt.Errorf("from must be a string")
+// markSeen reports whether the authorization's nonce was verified or settled earlier within
+// VerificationResultTTL, and records it when this verification succeeded
+func (t *VerifyPaymentTool) markSeen(network string, auth *eip3009.EIP3009Authorization, valid bool) bool {
+	key := seenNonceKey(network, auth)
+	_, seen := t.seen.Get(key)
+	if valid && !seen {
+		t.seen.Set(key, []byte{1}, eip3009.VerificationResultTTL)
 	}
+	return seen
+}
 
-	to, ok := authMap["to"].(string)
-	if !ok {
-		return nil, fmt.Errorf("to must be a string")
-	}
+// seenNonceKey identifies an authorization in the server's seen nonce store
+func seenNonceKey(network string, auth *eip3009.EIP3009Authorization) string {
+	return network + ":" + strings.ToLower(auth.From) + ":" + strings.ToLower(auth.Nonce)
+}
 
-	value, ok := authMap["value"].(string)
-	if !ok {
-		return nil, fmt.Errorf("value must be a string")
+// signaturesLowS reports whether the authorization's signature (or every multisig
+// owner signature) uses low-s, the form required once low-s enforcement is enabled
+func signaturesLowS(auth *eip3009.EIP3009Authorization, signatures []eip3009.Signature) bool {
+	if signatures == nil {
+		return (&eip3009.Signature{V: auth.V, R: auth.R, S: auth.S}).IsLowS()
 	}
 
-	nonce, ok := authMap["nonce"].(string)
-	if !ok {
-		return nil, fmt.Errorf("nonce must be a string")
+	for i := range signatures {
+		if !signatures[i].IsLowS() {
+			return false
+		}
 	}
+	return true
+}
 
-	r, ok := authMap["r"].(string)
-	if !ok {
-		return nil, fmt.Errorf("r must be a string")
+// resultMap converts a verification result to a map for MCP, adding the authorization's
+// from/to and rendering all addresses as CAIP-10 account IDs when requested
+func (t *VerifyPaymentTool) resultMap(output *eip3009.VerifyPaymentOutput, auth *eip3009.EIP3009Authorization, network string, addressFormat string) map[string]interface{} {
+	result := output.ToMap()
+	result["from"] = auth.From
+	result["to"] = auth.To
+
+	networkCfg, exists := t.server.GetConfig().Networks[network]
+	if addressFormat != config.AddressFormatCAIP10 || !exists {
+		return result
 	}
 
-	s, ok := authMap["s"].(string)
-	if !ok {
-		return nil, fmt.Errorf("s must be a string")
+	caip10 := func(address string) string {
+		return x402.FormatCAIP10(networkCfg.ChainID, address)
 	}
 
-	// Extract uint64 fields (JSON numbers come as float64)
-	validAfterFloat, ok := authMap["validAfter"].(float64)
-	if !ok {
-		return nil, fmt.Errorf("validAfter must be a number")
+	result["from"] = caip10(auth.From)
+	result["to"] = caip10(auth.To)
+	if output.SignerAddress != "" {
+		result["signer_address"] = caip10(output.SignerAddress)
+	}
+	if len(output.Signers) > 0 {
+		signers := make([]string, len(output.Signers))
+		for i, signer := range output.Signers {
+			signers[i] = caip10(signer)
+		}
+		result["signers"] = signers
 	}
-	validAfter := uint64(validAfterFloat)
 
-	validBeforeFloat, ok := authMap["validBefore"].(float64)
-	if !ok {
-		return nil, fmt.Errorf("validBefore must be a number")
-	}
-	validBefore := uint64(validBeforeFloat)
-
-	// Extract v (could be float64 or int)
-	var v uint8
-	switch vVal := authMap["v"].(type) {
-	case float64:
-		v = uint8(vVal)
-	case int:
-		v = uint8(vVal)
-	default:
-		return nil, fmt.Errorf("v must be a number")
-	}
-
-	// Validate v is 27 or 28
-	if v != 27 && v != 28 {
-		return nil, fmt.Errorf("v must be 27 or 28, got %d", v)
-	}
-
-	return &eip3009.EIP3009Authorization{
-		From:        from,
-		To:          to,
-		Value:       value,
-		ValidAfter:  validAfter,
-		ValidBefore: validBefore,
-		Nonce:       nonce,
-		V:           v,
-		R:           r,
-		S:           s,
-	}, nil
+	return result
 }
 
 // Register registers the tool with the MCP server
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/config"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/onchain"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/server"
+	"github.com/lessuseless/agents-notary/mcp-servers/x402-mcp-server/internal/x402"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+)
+
+// VerifySettlementTxTool implements the verify_settlement_tx MCP tool
+type VerifySettlementTxTool struct {
+	server     *server.Server
+	txVerifier *onchain.TxVerifier
+}
+
+// NewVerifySettlementTxTool creates a new verify_settlement_tx tool
+func NewVerifySettlementTxTool(srv *server.Server) *VerifySettlementTxTool {
+	tool := &VerifySettlementTxTool{
+		server:     srv,
+		txVerifier: onchain.NewTxVerifier(srv.GetConfig(), 10*time.Second),
+	}
+
+	// Verify transactions against the reloaded networks and RPC endpoints
+	srv.OnConfigReload(func(_, newCfg *config.Config) {
+		tool.txVerifier.UpdateConfig(newCfg)
+	})
+
+	return tool
+}
+
+// TxVerifier returns the on-chain transaction verifier used by this tool
+func (t *VerifySettlementTxTool) TxVerifier() *onchain.TxVerifier {
+	return t.txVerifier
+}
+
+// Name returns the tool name
+func (t *VerifySettlementTxTool) Name() string {
+	return "verify_settlement_tx"
+}
+
+// Description returns the tool description
+func (t *VerifySettlementTxTool) Description() string {
+	return "Verify a facilitator-reported settlement transaction. Fetches the transaction by tx_hash, decodes its receiveWithAuthorization parameters and checks from/to/value/nonce match the original authorization, detecting substituted parameters."
+}
+
+// Schema returns the JSON schema for the tool's input
+func (t *VerifySettlementTxTool) Schema() interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"tx_hash": map[string]interface{}{
+				"type":        "string",
+				"description": "Settlement transaction hash reported by the facilitator (0x-prefixed)",
+				"pattern":     "^0x[a-fA-F0-9]{64}$",
+			},
+			"authorization": authorizationSchema(),
+			"network": map[string]interface{}{
+				"type":        "string",
+				"description": "Blockchain network the transaction was submitted to",
+				"enum":        x402.SupportedNetworks(),
+			},
+		},
+		"required": []string{"tx_hash", "authorization", "network"},
+	}
+}
+
+// Execute executes the tool with the given arguments
+func (t *VerifySettlementTxTool) Execute(args map[string]interface{}) (interface{}, error) {
+	// Extract tx hash
+	txHash, ok := args["tx_hash"].(string)
+	if !ok {
+		return nil, fmt.Errorf("tx_hash must be a string")
+	}
+
+	// Extract network, accepting the name in any case
+	network, ok := args["network"].(string)
+	if !ok {
+		return nil, fmt.Errorf("network must be a string")
+	}
+	network, err := canonicalNetwork(t.server.GetConfig(), network)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract authorization object
+	authMap, ok := args["authorization"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("authorization must be an object")
+	}
+
+	// Parse authorization fields
+	auth, err := parseAuthorization(authMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse authorization: %w", err)
+	}
+
+	result, err := t.txVerifier.Verify(auth, network, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := t.server.GetLogger()
+	if result.Match {
+		logger.Info("Settlement transaction matches authorization", map[string]interface{}{
+			"network": network,
+			"tx_hash": result.TxHash,
+			"nonce":   auth.Nonce,
+		})
+	} else {
+		logger.Warn("Settlement transaction does not match authorization", map[string]interface{}{
+			"network":    network,
+			"tx_hash":    result.TxHash,
+			"error_code": result.ErrorCode,
+			"error":      result.Error,
+		})
+	}
+
+	// Return as map for MCP
+	return addExplorerURL(t.server.GetConfig(), network, result.ToMap()), nil
+}
+
+// Register registers the tool with the MCP server
+func (t *VerifySettlementTxTool) Register(mcpServer *mcpserver.MCPServer) error {
+	if mcpServer == nil {
+		return fmt.Errorf("MCP server is nil")
+	}
+
+	// For now, registration will be handled externally
+	// The mcp-go API requires different registration approach
+	return nil
+}